@@ -0,0 +1,7 @@
+package main
+
+import "github.com/talx-hub/gopher-bonus/internal/service/accrualsim"
+
+func main() {
+	accrualsim.Run()
+}
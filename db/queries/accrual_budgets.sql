@@ -0,0 +1,59 @@
+-- name: EnsureAccrualBudget :exec
+-- темп задаёт первая запустившаяся реплика, дальше его меняют ответы 429 и RaiseAccrualBudget;
+-- выше начального темп не растёт, пока accrual не объявит свой лимит
+INSERT INTO accrual_budgets (name_provider, rate_rpm, ceiling_rpm, tokens)
+VALUES ($1, $2, $2, 1)
+ON CONFLICT (name_provider) DO NOTHING;
+
+-- name: TakeAccrualToken :one
+-- токен берётся в долг, wait_ms -- через сколько он станет действительным;
+-- на паузе после 429 токен не берётся, а wait_ms -- сколько осталось до её конца
+WITH budget AS (
+    SELECT name_provider, rate_rpm,
+           COALESCE(paused_until > now(), false) AS paused,
+           paused_until,
+           CASE WHEN rate_rpm = 0 THEN 0
+                ELSE LEAST(GREATEST(1, rate_rpm / 60.0),
+                           tokens + EXTRACT(EPOCH FROM now() - refilled_at) * rate_rpm / 60.0)
+           END AS tokens
+    FROM accrual_budgets
+    WHERE name_provider = sqlc.arg(name_provider)::text
+    FOR UPDATE)
+UPDATE accrual_budgets AS a
+SET tokens = CASE WHEN budget.paused OR budget.rate_rpm = 0 THEN budget.tokens ELSE budget.tokens - 1 END,
+    refilled_at = now()
+FROM budget
+WHERE a.name_provider = budget.name_provider
+RETURNING budget.paused::bool AS paused,
+          (CASE WHEN budget.paused THEN EXTRACT(EPOCH FROM budget.paused_until - now()) * 1000
+                WHEN budget.rate_rpm = 0 OR budget.tokens >= 1 THEN 0
+                ELSE (1 - budget.tokens) * 60000 / budget.rate_rpm
+           END)::bigint AS wait_ms;
+
+-- name: ThrottleAccrualBudget :exec
+-- 429 на любой реплике ставит на паузу все; без объявленного лимита темп снижается вдвое,
+-- но только первым 429 паузы: остальные -- ответы на запросы, отправленные до неё
+UPDATE accrual_budgets
+SET rate_rpm = CASE WHEN sqlc.arg(rate_rpm)::bigint > 0 THEN sqlc.arg(rate_rpm)::bigint
+                    WHEN rate_rpm > 0 AND COALESCE(paused_until <= now(), true) THEN GREATEST(1, rate_rpm / 2)
+                    ELSE rate_rpm
+               END,
+    ceiling_rpm = CASE WHEN sqlc.arg(rate_rpm)::bigint > 0 THEN sqlc.arg(rate_rpm)::bigint ELSE ceiling_rpm END,
+    changed_at = now(),
+    paused_until = GREATEST(paused_until, now() + sqlc.arg(retry_after_ms)::bigint * interval '1 millisecond'),
+    tokens = LEAST(tokens, 0),
+    refilled_at = now()
+WHERE name_provider = sqlc.arg(name_provider);
+
+-- name: RaiseAccrualBudget :one
+-- темп растёт на 5%, но не выше потолка, если с последнего 429 и изменения темпа прошло quiet_ms
+-- и пауза после 429 кончилась; поднимает его первая заметившая это реплика, остальным строка не вернётся
+UPDATE accrual_budgets
+SET rate_rpm = LEAST(ceiling_rpm, rate_rpm + GREATEST(1, rate_rpm / 20)),
+    changed_at = now()
+WHERE name_provider = sqlc.arg(name_provider)
+  AND rate_rpm > 0
+  AND rate_rpm < ceiling_rpm
+  AND GREATEST(changed_at, COALESCE(paused_until, changed_at))
+      <= now() - sqlc.arg(quiet_ms)::bigint * interval '1 millisecond'
+RETURNING rate_rpm;
@@ -0,0 +1,9 @@
+-- name: DeleteExpiredCallbackReplays :exec
+DELETE FROM accrual_callback_replays
+WHERE expires_at <= now();
+
+-- name: CreateCallbackReplay :execrows
+-- подпись, уже принятая любой репликой, не вставляется
+INSERT INTO accrual_callback_replays (signature, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (signature) DO NOTHING;
@@ -0,0 +1,38 @@
+-- name: HoldAccrualProviders :exec
+INSERT INTO accrual_controls (name_provider, held)
+SELECT unnest(sqlc.arg(providers)::text[]), true
+ON CONFLICT (name_provider) DO UPDATE SET held = true;
+
+-- name: ReleaseAccrualProviders :exec
+-- снимается пауза всех провайдеров, в том числе уже убранных из конфигурации
+UPDATE accrual_controls
+SET held = false
+WHERE held;
+
+-- name: SetAccrualMaxRequests :exec
+INSERT INTO accrual_controls (name_provider, max_requests)
+VALUES ($1, $2)
+ON CONFLICT (name_provider) DO UPDATE SET max_requests = EXCLUDED.max_requests;
+
+-- name: ListAccrualControls :many
+SELECT name_provider, held, max_requests
+FROM accrual_controls;
+
+-- name: DeleteAccrualReplica :exec
+-- вместе с прежним состоянием реплики удаляются давно не обновлявшиеся:
+-- у перезапущенной реплики новый ID
+DELETE FROM accrual_replicas
+WHERE id_instance = sqlc.arg(id_instance)
+   OR reported_at < sqlc.arg(stale_before);
+
+-- name: CreateAccrualReplicaProvider :exec
+INSERT INTO accrual_replicas (id_instance, name_provider, paused, max_requests, rate_limit_rpm,
+                              rpm, in_flight, workers, throttled, retry_after)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: ListAccrualReplicas :many
+SELECT id_instance, name_provider, paused, max_requests, rate_limit_rpm,
+       rpm, in_flight, workers, throttled, retry_after, reported_at
+FROM accrual_replicas
+WHERE reported_at >= sqlc.arg(reported_since)
+ORDER BY id_instance, name_provider;
@@ -0,0 +1,45 @@
+-- name: UserExists :one
+SELECT EXISTS(SELECT 1
+              FROM user_hashes
+              WHERE id_user=$1);
+
+-- name: CreateAdjustment :one
+INSERT INTO balance_adjustments (id_user, id_admin, direction, amount, reason_code, comment, forced)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id_adjustment, created_at;
+
+-- name: ListAdjustmentsByUser :many
+SELECT id_adjustment, id_user, id_admin, direction, amount, reason_code, comment, forced, created_at
+FROM balance_adjustments
+WHERE id_user=$1
+ORDER BY created_at DESC, id_adjustment DESC;
+
+-- name: GetAdjustmentAmount :one
+SELECT COALESCE(sum(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)::decimal(12,2)
+    AS adjusted
+FROM balance_adjustments
+WHERE id_user=$1;
+
+-- name: ListBalanceHistory :many
+SELECT 'accrual'::text AS kind, name_order::text AS reference, amount::decimal(12,2) AS amount,
+       processed_at::timestamptz AS happened_at
+FROM accrued_orders
+WHERE accrued_orders.id_user=$1 AND processed_at IS NOT NULL AND amount > 0
+UNION ALL
+SELECT 'withdrawal', name_order, (-amount)::decimal(12,2), processed_at
+FROM withdrawn_orders
+WHERE withdrawn_orders.id_user=$1
+UNION ALL
+SELECT 'campaign_bonus', name_order, amount::decimal(12,2), credited_at
+FROM bonus_credits
+WHERE bonus_credits.id_user=$1
+UNION ALL
+SELECT 'referral_bonus', '', amount::decimal(12,2), credited_at
+FROM referral_credits
+WHERE referral_credits.id_user=$1
+UNION ALL
+SELECT 'adjustment', reason_code,
+       (CASE WHEN direction = 'credit' THEN amount ELSE -amount END)::decimal(12,2), created_at
+FROM balance_adjustments
+WHERE balance_adjustments.id_user=$1
+ORDER BY happened_at DESC;
@@ -0,0 +1,87 @@
+-- name: CreateCampaign :one
+INSERT INTO campaigns (name_campaign, starts_at, ends_at, first_order_only, id_min_tier,
+                       registered_after, registered_before, bonus_type, bonus_value)
+VALUES (sqlc.arg(name_campaign), sqlc.arg(starts_at), sqlc.arg(ends_at), sqlc.arg(first_order_only),
+        sqlc.narg(id_min_tier),
+        sqlc.narg(registered_after), sqlc.narg(registered_before),
+        sqlc.arg(bonus_type), sqlc.arg(bonus_value))
+RETURNING id_campaign;
+
+-- name: UpdateCampaign :execresult
+UPDATE campaigns
+SET name_campaign=sqlc.arg(name_campaign),
+    starts_at=sqlc.arg(starts_at),
+    ends_at=sqlc.arg(ends_at),
+    first_order_only=sqlc.arg(first_order_only),
+    id_min_tier=sqlc.narg(id_min_tier),
+    registered_after=sqlc.narg(registered_after),
+    registered_before=sqlc.narg(registered_before),
+    bonus_type=sqlc.arg(bonus_type),
+    bonus_value=sqlc.arg(bonus_value)
+WHERE id_campaign=sqlc.arg(id_campaign)
+  AND archived_at IS NULL;
+
+-- name: ArchiveCampaign :execresult
+UPDATE campaigns
+SET archived_at=now()
+WHERE id_campaign=$1
+  AND archived_at IS NULL;
+
+-- name: FindCampaignByID :one
+SELECT campaigns.id_campaign, campaigns.name_campaign, campaigns.starts_at, campaigns.ends_at,
+       campaigns.first_order_only, tiers.name_tier AS min_tier, tiers.min_points AS min_tier_points,
+       campaigns.registered_after, campaigns.registered_before,
+       campaigns.bonus_type, campaigns.bonus_value
+FROM campaigns LEFT JOIN tiers ON campaigns.id_min_tier = tiers.id_tier
+WHERE campaigns.id_campaign=$1
+  AND campaigns.archived_at IS NULL;
+
+-- name: ListCampaigns :many
+SELECT campaigns.id_campaign, campaigns.name_campaign, campaigns.starts_at, campaigns.ends_at,
+       campaigns.first_order_only, tiers.name_tier AS min_tier, tiers.min_points AS min_tier_points,
+       campaigns.registered_after, campaigns.registered_before,
+       campaigns.bonus_type, campaigns.bonus_value
+FROM campaigns LEFT JOIN tiers ON campaigns.id_min_tier = tiers.id_tier
+WHERE campaigns.archived_at IS NULL
+ORDER BY campaigns.starts_at DESC, campaigns.id_campaign DESC;
+
+-- name: ListActiveCampaigns :many
+SELECT campaigns.id_campaign, campaigns.name_campaign, campaigns.starts_at, campaigns.ends_at,
+       campaigns.first_order_only, tiers.name_tier AS min_tier, tiers.min_points AS min_tier_points,
+       campaigns.registered_after, campaigns.registered_before,
+       campaigns.bonus_type, campaigns.bonus_value
+FROM campaigns LEFT JOIN tiers ON campaigns.id_min_tier = tiers.id_tier
+WHERE campaigns.archived_at IS NULL
+  AND campaigns.starts_at <= sqlc.arg(at)
+  AND campaigns.ends_at > sqlc.arg(at)
+ORDER BY campaigns.id_campaign;
+
+-- name: GetOrderCampaignFacts :one
+SELECT acc_o.id_user,
+       user_hashes.registered_at,
+       acc_o.processed_at,
+       COALESCE(acc_o.raw_amount, 0)::decimal(12,2) AS raw_amount,
+       NOT EXISTS(SELECT 1
+                  FROM accrued_orders AS prev
+                  WHERE prev.id_user = acc_o.id_user
+                    AND prev.name_order <> acc_o.name_order
+                    AND prev.processed_at IS NOT NULL
+                    AND prev.processed_at <= acc_o.processed_at) AS is_first_order,
+       COALESCE((SELECT tiers.min_points
+                 FROM user_tiers JOIN tiers ON user_tiers.id_tier = tiers.id_tier
+                 WHERE user_tiers.id_user = acc_o.id_user
+                 ORDER BY user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+                 LIMIT 1), 0)::decimal(12,2) AS tier_points
+FROM accrued_orders AS acc_o
+         JOIN user_hashes ON acc_o.id_user = user_hashes.id_user
+WHERE acc_o.name_order=$1;
+
+-- name: CreateBonusCredit :execrows
+INSERT INTO bonus_credits (id_user, name_order, id_campaign, amount, credited_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (name_order, id_campaign) DO NOTHING;
+
+-- name: GetBonusCreditAmount :one
+SELECT (COALESCE((SELECT sum(amount) FROM bonus_credits WHERE bonus_credits.id_user=$1), 0) +
+        COALESCE((SELECT sum(amount) FROM referral_credits WHERE referral_credits.id_user=$1), 0)
+       )::decimal(12,2) AS credited;
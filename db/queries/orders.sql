@@ -25,13 +25,80 @@ WHERE acc_o.id_user=$1
 ORDER BY uploaded_at DESC;
 
 -- name: UpdateAccrualStatus :execresult
-UPDATE accrued_orders
+UPDATE accrued_orders AS acc_o
 SET id_status=(
     SELECT id_status
     FROM statuses
-    WHERE name_status=$2),
-    amount=$3
-WHERE name_order=$1;
+    WHERE name_status=sqlc.arg(name_status)::text),
+    raw_amount=sqlc.arg(amount),
+    amount=round(sqlc.arg(amount) * COALESCE(
+        (SELECT tiers.multiplier
+         FROM user_tiers JOIN tiers ON user_tiers.id_tier = tiers.id_tier
+         WHERE user_tiers.id_user = acc_o.id_user
+         ORDER BY user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+         LIMIT 1),
+        (SELECT tiers.multiplier
+         FROM tiers
+         ORDER BY tiers.min_points
+         LIMIT 1),
+        1.00), 2),
+    processed_at=CASE
+        WHEN sqlc.arg(name_status)::text = 'PROCESSED' THEN now()
+        ELSE acc_o.processed_at
+    END,
+    -- начисления по обработанному заказу записываются после статуса: до CompleteHooks
+    -- заказ ждёт повтора, даже если реплика упадёт между записью статуса и начислением
+    hooks_due_at=CASE
+        WHEN sqlc.arg(name_status)::text = 'PROCESSED' THEN now() + interval '1 minute'
+        ELSE acc_o.hooks_due_at
+    END,
+    leased_by=NULL,
+    lease_until=NULL
+WHERE name_order=sqlc.arg(name_order)
+  AND id_status IN (
+    SELECT id_status
+    FROM statuses
+    WHERE name_status IN ('NEW', 'PROCESSING', 'REVIEW', 'DEAD_LETTER'))
+  -- опоздавший ответ не трогает заказ, который уже захватила другая реплика
+  AND (leased_by IS NULL
+       OR leased_by = sqlc.arg(leased_by)::text
+       OR lease_until <= now());
+
+-- name: UpdatePushedAccrualStatus :execresult
+-- финальный результат, который accrual прислал сам, записывается и поверх чужой аренды
+UPDATE accrued_orders AS acc_o
+SET id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status=sqlc.arg(name_status)::text),
+    raw_amount=sqlc.arg(amount),
+    amount=round(sqlc.arg(amount) * COALESCE(
+        (SELECT tiers.multiplier
+         FROM user_tiers JOIN tiers ON user_tiers.id_tier = tiers.id_tier
+         WHERE user_tiers.id_user = acc_o.id_user
+         ORDER BY user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+         LIMIT 1),
+        (SELECT tiers.multiplier
+         FROM tiers
+         ORDER BY tiers.min_points
+         LIMIT 1),
+        1.00), 2),
+    processed_at=CASE
+        WHEN sqlc.arg(name_status)::text = 'PROCESSED' THEN now()
+        ELSE acc_o.processed_at
+    END,
+    -- как в UpdateAccrualStatus
+    hooks_due_at=CASE
+        WHEN sqlc.arg(name_status)::text = 'PROCESSED' THEN now() + interval '1 minute'
+        ELSE acc_o.hooks_due_at
+    END,
+    leased_by=NULL,
+    lease_until=NULL
+WHERE name_order=sqlc.arg(name_order)
+  AND id_status IN (
+    SELECT id_status
+    FROM statuses
+    WHERE name_status IN ('NEW', 'PROCESSING', 'REVIEW', 'DEAD_LETTER'));
 
 -- name: GetAccruedAmount :one
 SELECT sum(amount)::decimal(12,2) as accrued
@@ -51,10 +118,129 @@ FROM withdrawn_orders
 WHERE id_user=$1
 ORDER BY processed_at DESC;
 
--- name: SelectOrdersForProcessing :many
-SELECT name_order FROM accrued_orders
+-- name: ClaimOrdersForProcessing :many
+WITH due AS (
+    SELECT id_acc_order
+    FROM accrued_orders
+    WHERE id_status IN (
+        SELECT id_status
+        FROM statuses
+        WHERE name_status IN ('NEW', 'PROCESSING')
+    )
+      AND next_check_at <= now()
+      AND (lease_until IS NULL OR lease_until <= now())
+      AND accrued_orders.uploaded_at <= sqlc.arg(uploaded_before)::timestamptz
+    ORDER BY uploaded_at, id_acc_order
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+), claimed AS (
+    UPDATE accrued_orders AS acc_o
+    SET id_status=(
+        SELECT id_status
+        FROM statuses
+        WHERE name_status='PROCESSING'),
+        leased_by=sqlc.arg(leased_by)::text,
+        lease_until=sqlc.arg(lease_until)
+    FROM due
+    WHERE acc_o.id_acc_order = due.id_acc_order
+    RETURNING acc_o.id_acc_order, acc_o.name_order, acc_o.uploaded_at
+)
+SELECT name_order FROM claimed
+ORDER BY uploaded_at, id_acc_order;
+
+-- name: ReleaseLeases :execrows
+-- заказы, которые реплика захватила, но не успела отдать агенту, сразу доступны другим
+UPDATE accrued_orders
+SET leased_by=NULL,
+    lease_until=NULL
+WHERE leased_by=sqlc.arg(leased_by)::text
+  AND name_order = ANY(sqlc.arg(orders)::text[]);
+
+-- name: ClaimPendingHooks :many
+-- заказы, начисления по которым не записаны; повтор откладывается, пока его выполняет эта реплика
+WITH due AS (
+    SELECT id_acc_order
+    FROM accrued_orders
+    WHERE hooks_due_at <= now()
+    ORDER BY hooks_due_at, id_acc_order
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE accrued_orders AS acc_o
+SET hooks_due_at=now() + interval '1 minute'
+FROM due
+WHERE acc_o.id_acc_order = due.id_acc_order
+RETURNING acc_o.name_order;
+
+-- name: CompleteHooks :exec
+UPDATE accrued_orders
+SET hooks_due_at=NULL
+WHERE name_order=$1;
+
+-- name: GetAccrualSchedule :one
+SELECT acc_o.attempts, acc_o.failures, acc_o.uploaded_at
+FROM accrued_orders AS acc_o
+         JOIN statuses ON acc_o.id_status = statuses.id_status
+WHERE acc_o.name_order=sqlc.arg(name_order)
+  AND statuses.name_status IN ('NEW', 'PROCESSING')
+  -- RescheduleAccrual идёт в той же транзакции: заказ чужой активной аренды не переносим
+  AND (acc_o.leased_by IS NULL
+       OR acc_o.leased_by = sqlc.arg(leased_by)::text
+       OR acc_o.lease_until <= now())
+FOR UPDATE OF acc_o;
+
+-- name: RescheduleAccrual :exec
+UPDATE accrued_orders
+SET id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status=sqlc.arg(name_status)::text),
+    attempts=attempts + 1,
+    failures=sqlc.arg(failures),
+    last_error=COALESCE(sqlc.narg(last_error), last_error),
+    dead_lettered_at=CASE
+        WHEN sqlc.arg(name_status)::text = 'DEAD_LETTER' THEN now()
+    END,
+    next_check_at=sqlc.arg(next_check_at),
+    leased_by=NULL,
+    lease_until=NULL
+WHERE name_order=sqlc.arg(name_order);
+
+-- name: ListDeadLetters :many
+SELECT acc_o.name_order, acc_o.id_user, acc_o.uploaded_at,
+       acc_o.failures, COALESCE(acc_o.last_error, '')::text AS last_error,
+       acc_o.dead_lettered_at
+FROM accrued_orders AS acc_o
+         JOIN statuses ON acc_o.id_status = statuses.id_status
+WHERE statuses.name_status = 'DEAD_LETTER'
+ORDER BY acc_o.dead_lettered_at, acc_o.id_acc_order;
+
+-- name: RequeueDeadLetters :execrows
+UPDATE accrued_orders
+SET id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status='PROCESSING'),
+    attempts=0,
+    failures=0,
+    dead_lettered_at=NULL,
+    next_check_at=now(),
+    lease_until=NULL,
+    leased_by=NULL
+WHERE id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status='DEAD_LETTER')
+  AND (cardinality(sqlc.arg(orders)::text[]) = 0
+       OR name_order = ANY(sqlc.arg(orders)::text[]));
+
+-- name: RecheckOrders :execrows
+-- заказ в аренде уже ждёт ответа accrual: его не трогаем, чтобы не спросить дважды
+UPDATE accrued_orders
+SET next_check_at=now()
 WHERE id_status IN (
     SELECT id_status
     FROM statuses
-    WHERE name_status IN ('NEW', 'PROCESSING')
-);
+    WHERE name_status IN ('NEW', 'PROCESSING'))
+  AND (lease_until IS NULL OR lease_until <= now())
+  AND name_order = ANY(sqlc.arg(orders)::text[]);
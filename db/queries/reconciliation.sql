@@ -0,0 +1,52 @@
+-- name: ListOrdersForReconciliation :many
+-- amount -- всё начисленное по заказу: исходное начисление и корректировки прошлых сверок
+SELECT acc_o.name_order, acc_o.id_user, statuses.name_status,
+       COALESCE(acc_o.raw_amount, 0)::decimal(12,2) AS raw_amount,
+       (COALESCE(acc_o.amount, 0) + COALESCE(
+           (SELECT sum(CASE WHEN adj.direction = 'credit' THEN adj.amount ELSE -adj.amount END)
+            FROM accrual_discrepancies AS d
+                     JOIN balance_adjustments AS adj ON d.id_adjustment = adj.id_adjustment
+            WHERE d.name_order = acc_o.name_order), 0))::decimal(12,2) AS amount
+FROM accrued_orders AS acc_o
+         JOIN statuses ON acc_o.id_status = statuses.id_status
+WHERE statuses.name_status IN ('PROCESSED', 'INVALID')
+ORDER BY acc_o.reconciled_at NULLS FIRST, acc_o.processed_at, acc_o.id_acc_order
+LIMIT NULLIF(sqlc.arg(batch_size)::int, 0);
+
+-- name: MarkOrderReconciled :exec
+UPDATE accrued_orders
+SET reconciled_at=now()
+WHERE name_order=$1;
+
+-- name: CreateDiscrepancy :one
+-- то же расхождение, найденное повторно, не записывается и не исправляется второй раз;
+-- сравниваем только с последним: если accrual вернулся к прежнему ответу, это новое расхождение
+INSERT INTO accrual_discrepancies (name_order, id_user, stored_status, stored_raw_amount, stored_amount,
+                                   accrual_status, accrual_amount)
+SELECT sqlc.arg(name_order), sqlc.arg(id_user), sqlc.arg(stored_status), sqlc.arg(stored_raw_amount),
+       sqlc.arg(stored_amount), sqlc.arg(accrual_status), sqlc.arg(accrual_amount)
+WHERE NOT EXISTS(
+    SELECT 1
+    FROM (SELECT d.accrual_status, d.accrual_amount
+          FROM accrual_discrepancies AS d
+          WHERE d.name_order = sqlc.arg(name_order)
+          ORDER BY d.found_at DESC, d.id_discrepancy DESC
+          LIMIT 1) AS last
+    WHERE last.accrual_status = sqlc.arg(accrual_status)
+      AND last.accrual_amount = sqlc.arg(accrual_amount))
+RETURNING id_discrepancy, found_at;
+
+-- name: ApplyReconciledResult :exec
+-- amount не меняется: разница начислена корректировкой, связанной с расхождением
+UPDATE accrued_orders
+SET id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status=sqlc.arg(name_status)::text),
+    raw_amount=sqlc.arg(raw_amount)
+WHERE name_order=sqlc.arg(name_order);
+
+-- name: SetDiscrepancyAdjustment :exec
+UPDATE accrual_discrepancies
+SET id_adjustment=$2
+WHERE id_discrepancy=$1;
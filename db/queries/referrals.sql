@@ -0,0 +1,45 @@
+-- name: FindUserIDByReferralCode :one
+SELECT id_user
+FROM user_hashes
+WHERE referral_code=$1;
+
+-- name: GetReferralCode :one
+SELECT COALESCE(referral_code, '')::text AS referral_code
+FROM user_hashes
+WHERE id_user=$1;
+
+-- name: IsReferralAncestor :one
+-- проверяет, есть ли ancestor в цепочке пригласивших descendant
+WITH RECURSIVE chain AS (
+    SELECT r.id_referrer
+    FROM referrals r
+    WHERE r.id_referee=sqlc.arg(descendant)
+    UNION
+    SELECT r.id_referrer
+    FROM referrals r JOIN chain c ON r.id_referee=c.id_referrer
+)
+SELECT EXISTS(SELECT 1 FROM chain WHERE id_referrer=sqlc.arg(ancestor)::text);
+
+-- name: CreateReferral :exec
+INSERT INTO referrals (id_referrer, id_referee)
+VALUES ($1, $2);
+
+-- name: ClaimReferralReward :one
+UPDATE referrals
+SET rewarded_at=now(),
+    name_order=sqlc.arg(name_order)::varchar
+WHERE rewarded_at IS NULL
+  AND id_referee=(SELECT ao.id_user FROM accrued_orders ao WHERE ao.name_order=sqlc.arg(name_order))
+RETURNING id_referral, id_referrer, id_referee;
+
+-- name: CreateReferralCredit :exec
+INSERT INTO referral_credits (id_referral, id_user, amount, credited_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (id_referral, id_user) DO NOTHING;
+
+-- name: ListReferralsByReferrer :many
+SELECT r.created_at, r.rewarded_at, COALESCE(rc.amount, 0)::decimal(12,2) AS earned
+FROM referrals r
+    LEFT JOIN referral_credits rc ON rc.id_referral=r.id_referral AND rc.id_user=r.id_referrer
+WHERE r.id_referrer=$1
+ORDER BY r.created_at DESC;
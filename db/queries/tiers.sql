@@ -0,0 +1,51 @@
+-- name: ListTiers :many
+SELECT name_tier, min_points, multiplier
+FROM tiers
+ORDER BY min_points;
+
+-- name: FindTierIDByName :one
+SELECT id_tier
+FROM tiers
+WHERE name_tier=$1;
+
+-- name: GetCurrentUserTier :one
+SELECT tiers.name_tier, tiers.min_points, tiers.multiplier
+FROM user_tiers JOIN tiers ON user_tiers.id_tier = tiers.id_tier
+WHERE user_tiers.id_user=$1
+ORDER BY user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+LIMIT 1;
+
+-- name: GetRollingAccrued :one
+SELECT COALESCE(sum(raw_amount), 0)::decimal(12,2) AS points
+FROM accrued_orders
+WHERE id_user=$1
+  AND processed_at >= now() - INTERVAL '12 months';
+
+-- name: RecalculateTiers :execrows
+WITH rolling AS (
+    SELECT user_hashes.id_user,
+           COALESCE(sum(acc_o.raw_amount) FILTER (
+               WHERE acc_o.processed_at >= now() - INTERVAL '12 months'), 0)::decimal(12,2) AS points
+    FROM user_hashes
+             LEFT JOIN accrued_orders AS acc_o ON acc_o.id_user = user_hashes.id_user
+    GROUP BY user_hashes.id_user
+), computed AS (
+    SELECT rolling.id_user,
+           rolling.points,
+           (SELECT tiers.id_tier
+            FROM tiers
+            WHERE tiers.min_points <= rolling.points
+            ORDER BY tiers.min_points DESC
+            LIMIT 1) AS id_tier
+    FROM rolling
+), latest AS (
+    SELECT DISTINCT ON (user_tiers.id_user) user_tiers.id_user, user_tiers.id_tier
+    FROM user_tiers
+    ORDER BY user_tiers.id_user, user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+)
+INSERT INTO user_tiers (id_user, id_tier, rolling_points, assigned_at)
+SELECT computed.id_user, computed.id_tier, computed.points, now()
+FROM computed
+         LEFT JOIN latest ON latest.id_user = computed.id_user
+WHERE computed.id_tier IS NOT NULL
+  AND computed.id_tier IS DISTINCT FROM latest.id_tier;
@@ -1,6 +1,6 @@
 -- name: InsertUser :one
-INSERT INTO user_hashes (id_user, hash_login)
-VALUES ($1, $2)
+INSERT INTO user_hashes (id_user, hash_login, referral_code)
+VALUES ($1, $2, sqlc.narg(referral_code))
 RETURNING id_user;
 
 -- name: InsertPasswordHash :exec
@@ -13,11 +13,18 @@ SELECT EXISTS(SELECT 1
               WHERE hash_login = $1);
 
 -- name: FindUserByLogin :one
-SELECT user_hashes.id_user, user_hashes.hash_login, ph.hash_password
+SELECT user_hashes.id_user, user_hashes.hash_login, ph.hash_password, user_hashes.role,
+       COALESCE(user_hashes.referral_code, '')::text AS referral_code
 FROM user_hashes JOIN password_hashes ph on user_hashes.id_user = ph.id_user
 WHERE hash_login = $1;
 
 -- name: FindUserByID :one
-SELECT user_hashes.id_user, user_hashes.hash_login, ph.hash_password
+SELECT user_hashes.id_user, user_hashes.hash_login, ph.hash_password, user_hashes.role,
+       COALESCE(user_hashes.referral_code, '')::text AS referral_code
 FROM user_hashes JOIN password_hashes ph on user_hashes.id_user = ph.id_user
 WHERE user_hashes.id_user = $1;
+
+-- name: SetUserRole :execrows
+UPDATE user_hashes
+SET role=$2
+WHERE id_user=$1;
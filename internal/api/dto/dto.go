@@ -3,13 +3,15 @@ package dto
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	passwordvalidator "github.com/wagslane/go-password-validator"
 )
 
 type UserRequest struct {
-	Login    string `json:"login"`
-	Password string `json:"password"`
+	Login        string `json:"login"`
+	Password     string `json:"password"`
+	ReferralCode string `json:"referral_code,omitempty"`
 }
 
 func (r *UserRequest) IsValid() error {
@@ -32,3 +34,155 @@ type WithdrawRequest struct {
 	OrderID string      `json:"order"`
 	Sum     json.Number `json:"sum"`
 }
+
+type TierResponse struct {
+	Tier          string      `json:"tier"`
+	Multiplier    json.Number `json:"multiplier"`
+	RollingPoints json.Number `json:"rolling_points"`
+	NextTier      string      `json:"next_tier,omitempty"`
+	PointsToNext  json.Number `json:"points_to_next,omitempty"`
+}
+
+type InviteeResponse struct {
+	InvitedAt  time.Time   `json:"invited_at"`
+	RewardedAt *time.Time  `json:"rewarded_at,omitempty"`
+	Earned     json.Number `json:"earned"`
+}
+
+type ReferralsResponse struct {
+	Code     string            `json:"referral_code"`
+	Earned   json.Number       `json:"earned"`
+	Invitees []InviteeResponse `json:"invitees"`
+}
+
+type AdminUserResponse struct {
+	ID           string `json:"id"`
+	Role         string `json:"role"`
+	ReferralCode string `json:"referral_code,omitempty"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+type AdminBalanceResponse struct {
+	Current   json.Number            `json:"current"`
+	Withdrawn json.Number            `json:"withdrawn"`
+	History   []HistoryEntryResponse `json:"history"`
+}
+
+type HistoryEntryResponse struct {
+	OccurredAt time.Time   `json:"occurred_at"`
+	Kind       string      `json:"kind"`
+	Reference  string      `json:"reference,omitempty"`
+	Amount     json.Number `json:"amount"`
+}
+
+type DeadLetterResponse struct {
+	UploadedAt     time.Time `json:"uploaded_at"`
+	DeadLetteredAt time.Time `json:"dead_lettered_at"`
+	Number         string    `json:"number"`
+	UserID         string    `json:"user_id"`
+	LastError      string    `json:"last_error"`
+	Failures       int32     `json:"failures"`
+}
+
+type RequeueRequest struct {
+	Orders []string `json:"orders"`
+}
+
+type RequeueResponse struct {
+	Requeued int64 `json:"requeued"`
+}
+
+type AccrualStateResponse struct {
+	Providers []AccrualProviderResponse `json:"providers"`
+	// Replicas -- состояние всех работающих реплик, включая ответившую
+	Replicas []AccrualReplicaResponse `json:"replicas"`
+	Paused   bool                     `json:"paused"`
+	Running  bool                     `json:"running"`
+}
+
+type AccrualReplicaResponse struct {
+	ReportedAt time.Time                 `json:"reported_at"`
+	InstanceID string                    `json:"instance_id"`
+	Providers  []AccrualProviderResponse `json:"providers"`
+	Paused     bool                      `json:"paused"`
+}
+
+type AccrualProviderResponse struct {
+	RetryAfter  *time.Time `json:"retry_after,omitempty"`
+	Name        string     `json:"name"`
+	MaxRequests uint64     `json:"max_requests"`
+	RateLimit   uint64     `json:"rate_limit_rpm"`
+	RPM         uint64     `json:"rpm"`
+	InFlight    int64      `json:"in_flight"`
+	Workers     int        `json:"workers"`
+	Throttled   bool       `json:"throttled"`
+}
+
+type MaxRequestsRequest struct {
+	MaxRequests uint64 `json:"max_requests"`
+}
+
+type RecheckRequest struct {
+	Orders []string `json:"orders"`
+}
+
+type RecheckResponse struct {
+	Rechecked int64 `json:"rechecked"`
+}
+
+type AdjustmentRequest struct {
+	Direction string      `json:"direction"`
+	Amount    json.Number `json:"amount"`
+	Reason    string      `json:"reason"`
+	Comment   string      `json:"comment"`
+	Force     bool        `json:"force,omitempty"`
+}
+
+type AdjustmentResponse struct {
+	CreatedAt time.Time   `json:"created_at"`
+	UserID    string      `json:"user_id"`
+	AdminID   string      `json:"admin_id"`
+	Direction string      `json:"direction"`
+	Amount    json.Number `json:"amount"`
+	Reason    string      `json:"reason"`
+	Comment   string      `json:"comment"`
+	ID        int64       `json:"id"`
+	Forced    bool        `json:"forced"`
+}
+
+type CampaignRequest struct {
+	StartsAt         time.Time   `json:"starts_at"`
+	EndsAt           time.Time   `json:"ends_at"`
+	RegisteredAfter  *time.Time  `json:"registered_after,omitempty"`
+	RegisteredBefore *time.Time  `json:"registered_before,omitempty"`
+	Name             string      `json:"name"`
+	MinTier          string      `json:"min_tier,omitempty"`
+	BonusType        string      `json:"bonus_type"`
+	BonusValue       json.Number `json:"bonus_value"`
+	FirstOrderOnly   bool        `json:"first_order_only"`
+}
+
+type CampaignResponse struct {
+	StartsAt         time.Time   `json:"starts_at"`
+	EndsAt           time.Time   `json:"ends_at"`
+	RegisteredAfter  *time.Time  `json:"registered_after,omitempty"`
+	RegisteredBefore *time.Time  `json:"registered_before,omitempty"`
+	Name             string      `json:"name"`
+	MinTier          string      `json:"min_tier,omitempty"`
+	BonusType        string      `json:"bonus_type"`
+	BonusValue       json.Number `json:"bonus_value"`
+	ID               int64       `json:"id"`
+	FirstOrderOnly   bool        `json:"first_order_only"`
+}
+
+type HealthResponse struct {
+	// AccrualBreakers -- состояние breaker'а каждого провайдера accrual
+	AccrualBreakers map[string]string `json:"accrual_breakers"`
+	Status          string            `json:"status"`
+	InstanceID      string            `json:"instance_id"`
+	LeaderTerm      int64             `json:"leader_term,omitempty"`
+	Leader          bool              `json:"leader"`
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type AccrualApplier interface {
+	ApplyCallback(ctx context.Context, info dto.AccrualInfo) error
+}
+
+type AccrualCallbackHandler struct {
+	logger  *slog.Logger
+	applier AccrualApplier
+}
+
+func NewAccrualCallbackHandler(applier AccrualApplier, log *slog.Logger) *AccrualCallbackHandler {
+	return &AccrualCallbackHandler{
+		logger:  log,
+		applier: applier,
+	}
+}
+
+// AccrualCallback принимает результат расчёта, который accrual прислал сам.
+// Подпись и метка времени проверяются middleware до вызова.
+func (h *AccrualCallbackHandler) AccrualCallback(w http.ResponseWriter, r *http.Request) {
+	var info dto.AccrualInfo
+	if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedReadBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := r.Body.Close(); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedCloseBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+	if info.Order == "" {
+		http.Error(w, "order number is required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.applier.ApplyCallback(r.Context(), info)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusOK)
+	case errors.Is(err, serviceerrs.ErrUnknownAccrualStatus),
+		errors.Is(err, model.ErrFromString):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, serviceerrs.ErrNotFound):
+		// заказа нет или он уже обработан: повторный callback не ошибка accrual
+		http.Error(w, "order is not awaiting accrual", http.StatusNotFound)
+	default:
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to apply accrual callback",
+			slog.String("order_no", info.Order),
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/handlers/mocks"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func TestAccrualCallbackHandler_AccrualCallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		applyErr error
+		apply    bool
+		wantCode int
+	}{
+		{
+			name:     "processed",
+			body:     `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			apply:    true,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "broken json",
+			body:     `{"order":`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "no order number",
+			body:     `{"status":"PROCESSED"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown status",
+			body:     `{"order":"12345678903","status":"DONE"}`,
+			applyErr: serviceerrs.ErrUnknownAccrualStatus,
+			apply:    true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "bad accrual",
+			body:     `{"order":"12345678903","status":"PROCESSED","accrual":1.234}`,
+			applyErr: model.ErrFromString,
+			apply:    true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "order already processed",
+			body:     `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			applyErr: serviceerrs.ErrNotFound,
+			apply:    true,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "repo failure",
+			body:     `{"order":"12345678903","status":"PROCESSED","accrual":500}`,
+			applyErr: errors.New("connection reset"),
+			apply:    true,
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applier := mocks.NewMockAccrualApplier(t)
+			if tt.apply {
+				applier.EXPECT().
+					ApplyCallback(mock.Anything, mock.MatchedBy(func(info dto.AccrualInfo) bool {
+						return info.Order == "12345678903"
+					})).
+					Return(tt.applyErr)
+			}
+			h := NewAccrualCallbackHandler(applier, slog.Default())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/internal/accrual/callback",
+				strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			h.AccrualCallback(rr, req)
+			res := rr.Result()
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/dto"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type AdjustmentRepository interface {
+	Create(ctx context.Context, a *adjustment.Adjustment) error
+	ListByUser(ctx context.Context, userID string) ([]adjustment.Adjustment, error)
+}
+
+type AdjustmentHandler struct {
+	logger *slog.Logger
+	repo   AdjustmentRepository
+}
+
+func NewAdjustmentHandler(repo AdjustmentRepository, log *slog.Logger) *AdjustmentHandler {
+	return &AdjustmentHandler{
+		logger: log,
+		repo:   repo,
+	}
+}
+
+func (h *AdjustmentHandler) CreateAdjustment(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(model.KeyContextUserID).(string)
+	if !ok {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed retrieve admin ID from context",
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var request dto.AdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedReadBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := r.Body.Close(); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedCloseBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+
+	amount, err := model.FromString(request.Amount.String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a := adjustment.Adjustment{
+		UserID:    chi.URLParam(r, "userID"),
+		AdminID:   adminID,
+		Direction: adjustment.Direction(request.Direction),
+		Reason:    adjustment.Reason(request.Reason),
+		Comment:   request.Comment,
+		Amount:    amount,
+		Forced:    request.Force,
+	}
+	if err = a.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// списание сверх остатка -- отдельное право, его нет у обычных администраторов
+	role, _ := r.Context().Value(model.KeyContextUserRole).(user.Role)
+	if a.Forced && !role.CanForceDebit() {
+		http.Error(w, "not allowed to force a debit", http.StatusForbidden)
+		return
+	}
+
+	err = h.repo.Create(r.Context(), &a)
+	if err != nil && errors.Is(err, serviceerrs.ErrNotFound) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	if err != nil && errors.Is(err, serviceerrs.ErrInsufficientFunds) {
+		http.Error(w, err.Error(), http.StatusPaymentRequired)
+		return
+	}
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to adjust balance",
+			slog.String("user_id", a.UserID),
+			slog.String("admin_id", adminID),
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.LogAttrs(r.Context(),
+		slog.LevelInfo,
+		"balance adjusted",
+		slog.Int64("adjustment_id", a.ID),
+		slog.String("user_id", a.UserID),
+		slog.String("admin_id", adminID),
+		slog.String("direction", string(a.Direction)),
+		slog.String("amount", a.Amount.String()),
+		slog.Bool("forced", a.Forced),
+	)
+	w.Header().Set(model.HeaderContentType, "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(toAdjustmentResponse(&a)); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedWriteResponseMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+}
+
+func (h *AdjustmentHandler) ListAdjustments(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	adjustments, err := h.repo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to list adjustments",
+			slog.String("user_id", userID),
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(adjustments) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := make([]dto.AdjustmentResponse, len(adjustments))
+	for i := range adjustments {
+		response[i] = toAdjustmentResponse(&adjustments[i])
+	}
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedWriteResponseMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func toAdjustmentResponse(a *adjustment.Adjustment) dto.AdjustmentResponse {
+	return dto.AdjustmentResponse{
+		CreatedAt: a.CreatedAt,
+		UserID:    a.UserID,
+		AdminID:   a.AdminID,
+		Direction: string(a.Direction),
+		Amount:    json.Number(a.Amount.String()),
+		Reason:    string(a.Reason),
+		Comment:   a.Comment,
+		ID:        a.ID,
+		Forced:    a.Forced,
+	}
+}
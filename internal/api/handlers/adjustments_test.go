@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/handlers/mocks"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+	"github.com/talx-hub/gopher-bonus/internal/model/ledger"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func adminRequest(method, target, body, actorID string, role user.Role, userID string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userID", userID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, model.KeyContextUserID, actorID)
+	ctx = context.WithValue(ctx, model.KeyContextUserRole, role)
+	return req.WithContext(ctx)
+}
+
+func TestAdjustmentHandler_CreateAdjustment(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminID    string
+		role       user.Role
+		body       string
+		mockCreate func() error
+		wantCode   int
+	}{
+		{
+			name:       "credit",
+			adminID:    "support",
+			role:       user.RoleSupport,
+			body:       `{"direction":"credit","amount":150.5,"reason":"compensation","comment":"ticket 1"}`,
+			mockCreate: func() error { return nil },
+			wantCode:   http.StatusCreated,
+		},
+		{
+			name:    "debit above balance",
+			adminID: "support",
+			role:    user.RoleSupport,
+			body:    `{"direction":"debit","amount":1000,"reason":"fraud_reversal","comment":"ticket 2"}`,
+			mockCreate: func() error {
+				return serviceerrs.ErrInsufficientFunds
+			},
+			wantCode: http.StatusPaymentRequired,
+		},
+		{
+			name:    "forced debit by superadmin",
+			adminID: "root",
+			role:    user.RoleSuperAdmin,
+			body: `{"direction":"debit","amount":1000,"reason":"fraud_reversal",` +
+				`"comment":"ticket 3","force":true}`,
+			mockCreate: func() error { return nil },
+			wantCode:   http.StatusCreated,
+		},
+		{
+			name:    "forced debit by admin",
+			adminID: "admin",
+			role:    user.RoleAdmin,
+			body: `{"direction":"debit","amount":1000,"reason":"fraud_reversal",` +
+				`"comment":"ticket 3","force":true}`,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:    "forced debit by support",
+			adminID: "support",
+			role:    user.RoleSupport,
+			body: `{"direction":"debit","amount":1000,"reason":"fraud_reversal",` +
+				`"comment":"ticket 4","force":true}`,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "missing comment",
+			adminID:  "support",
+			role:     user.RoleSupport,
+			body:     `{"direction":"credit","amount":10,"reason":"correction"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown reason",
+			adminID:  "support",
+			role:     user.RoleSupport,
+			body:     `{"direction":"credit","amount":10,"reason":"mood","comment":"x"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:    "unknown user",
+			adminID: "support",
+			role:    user.RoleSupport,
+			body:    `{"direction":"credit","amount":10,"reason":"correction","comment":"x"}`,
+			mockCreate: func() error {
+				return serviceerrs.ErrNotFound
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockAdjustmentRepository(t)
+			h := NewAdjustmentHandler(repo, slog.Default())
+			if tt.mockCreate != nil {
+				err := tt.mockCreate()
+				repo.EXPECT().
+					Create(mock.Anything, mock.Anything).
+					RunAndReturn(func(_ context.Context, a *adjustment.Adjustment) error {
+						assert.Equal(t, "user-1", a.UserID)
+						assert.Equal(t, tt.adminID, a.AdminID)
+						return err
+					})
+			}
+
+			req := adminRequest(http.MethodPost, "/users/user-1/adjustments",
+				tt.body, tt.adminID, tt.role, "user-1")
+			rr := httptest.NewRecorder()
+			h.CreateAdjustment(rr, req)
+			res := rr.Result()
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestAdjustmentHandler_ListAdjustments(t *testing.T) {
+	repo := mocks.NewMockAdjustmentRepository(t)
+	h := NewAdjustmentHandler(repo, slog.Default())
+
+	repo.EXPECT().ListByUser(mock.Anything, "user-1").Return([]adjustment.Adjustment{
+		{
+			CreatedAt: time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC),
+			UserID:    "user-1",
+			AdminID:   "root",
+			Direction: adjustment.DirectionDebit,
+			Reason:    adjustment.ReasonFraudReversal,
+			Comment:   "ticket 3",
+			Amount:    model.NewAmount(1000, 0),
+			ID:        3,
+			Forced:    true,
+		},
+	}, nil).Once()
+	repo.EXPECT().ListByUser(mock.Anything, "user-2").Return(nil, nil).Once()
+
+	req := adminRequest(http.MethodGet, "/users/user-1/adjustments", "", "root", user.RoleAdmin, "user-1")
+	rr := httptest.NewRecorder()
+	h.ListAdjustments(rr, req)
+	res := rr.Result()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `[{"id":3,"user_id":"user-1","admin_id":"root","direction":"debit",`+
+		`"amount":1000,"reason":"fraud_reversal","comment":"ticket 3","forced":true,`+
+		`"created_at":"2026-04-01T12:00:00Z"}]`, string(body))
+
+	req = adminRequest(http.MethodGet, "/users/user-2/adjustments", "", "root", user.RoleAdmin, "user-2")
+	rr = httptest.NewRecorder()
+	h.ListAdjustments(rr, req)
+	res = rr.Result()
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestOrderHandler_GetBalanceHistory(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(t)
+	orderRepo := mocks.NewMockOrderRepository(t)
+	h := NewOrderHandler(userRepo, orderRepo, slog.Default(), nil)
+
+	userRepo.EXPECT().FindByID(mock.Anything, "user-1").Return(user.User{ID: "user-1"}, nil)
+	at := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	orderRepo.EXPECT().ListHistory(mock.Anything, "user-1").Return([]ledger.Entry{
+		{At: at, Kind: ledger.KindAdjustment, Reference: "fraud_reversal", Amount: model.NewAmount(0, -1050)},
+		{At: at, Kind: ledger.KindAccrual, Reference: "12345678903", Amount: model.NewAmount(500, 0)},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/balance/history", http.NoBody)
+	req = req.WithContext(context.WithValue(req.Context(), model.KeyContextUserID, "user-1"))
+	rr := httptest.NewRecorder()
+	h.GetBalanceHistory(rr, req)
+	res := rr.Result()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(body), `"kind":"adjustment","reference":"fraud_reversal","amount":-10.50`)
+	assert.Contains(t, string(body), `"kind":"accrual","reference":"12345678903","amount":500`)
+	assert.Contains(t, string(body), `"occurred_at":"`+at.Local().Format(time.RFC3339)+`"`)
+	assert.NotContains(t, string(body), "processed_at")
+}
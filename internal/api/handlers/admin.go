@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/dto"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type AdminHandler struct {
+	logger    *slog.Logger
+	userRepo  UserRepository
+	orderRepo OrderRepository
+}
+
+func NewAdminHandler(
+	userRepo UserRepository, orderRepo OrderRepository, log *slog.Logger,
+) *AdminHandler {
+	return &AdminHandler{
+		logger:    log,
+		userRepo:  userRepo,
+		orderRepo: orderRepo,
+	}
+}
+
+// FindUser ищет пользователя по логину: в базе хранится только хеш.
+func (h *AdminHandler) FindUser(w http.ResponseWriter, r *http.Request) {
+	login := r.URL.Query().Get("login")
+	if login == "" {
+		http.Error(w, "login query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	hasher := sha256.New()
+	hasher.Write([]byte(login))
+	u, err := h.userRepo.FindByLogin(r.Context(), hex.EncodeToString(hasher.Sum(nil)))
+	if err != nil {
+		h.handleUserError(w, r, "", err)
+		return
+	}
+	h.writeJSON(w, r, toAdminUserResponse(&u))
+}
+
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	u, err := h.userRepo.FindByID(r.Context(), userID)
+	if err != nil {
+		h.handleUserError(w, r, userID, err)
+		return
+	}
+	h.writeJSON(w, r, toAdminUserResponse(&u))
+}
+
+func (h *AdminHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	if _, err := h.userRepo.FindByID(r.Context(), userID); err != nil {
+		h.handleUserError(w, r, userID, err)
+		return
+	}
+
+	orders, err := h.orderRepo.ListOrdersByUser(r.Context(), userID, order.TypeAccrual)
+	if err != nil {
+		h.handleUserError(w, r, userID, err)
+		return
+	}
+	if len(orders) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.writeJSON(w, r, orders)
+}
+
+func (h *AdminHandler) GetUserBalance(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	if _, err := h.userRepo.FindByID(r.Context(), userID); err != nil {
+		h.handleUserError(w, r, userID, err)
+		return
+	}
+
+	current, withdrawn, err := h.orderRepo.GetBalance(r.Context(), userID)
+	if err != nil {
+		h.handleUserError(w, r, userID, err)
+		return
+	}
+	entries, err := h.orderRepo.ListHistory(r.Context(), userID)
+	if err != nil {
+		h.handleUserError(w, r, userID, err)
+		return
+	}
+
+	response := dto.AdminBalanceResponse{
+		Current:   json.Number(current.String()),
+		Withdrawn: json.Number(withdrawn.String()),
+		History:   make([]dto.HistoryEntryResponse, len(entries)),
+	}
+	for i, e := range entries {
+		response.History[i] = dto.HistoryEntryResponse{
+			OccurredAt: e.At.Local(),
+			Kind:       string(e.Kind),
+			Reference:  e.Reference,
+			Amount:     json.Number(e.Amount.String()),
+		}
+	}
+	h.writeJSON(w, r, response)
+}
+
+func (h *AdminHandler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	actorID, _ := r.Context().Value(model.KeyContextUserID).(string)
+	if actorID == userID {
+		http.Error(w, "can not change own role", http.StatusForbidden)
+		return
+	}
+
+	var request dto.RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedReadBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := r.Body.Close(); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedCloseBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+	role := user.Role(request.Role)
+	if !role.IsValid() {
+		http.Error(w, "unknown role: "+request.Role, http.StatusBadRequest)
+		return
+	}
+	// иначе администратор выдал бы себе через другую учётку право, которого у него нет
+	actorRole, _ := r.Context().Value(model.KeyContextUserRole).(user.Role)
+	if actorRole != user.RoleSuperAdmin {
+		if role == user.RoleSuperAdmin {
+			http.Error(w, "not allowed to grant role: "+request.Role, http.StatusForbidden)
+			return
+		}
+		u, err := h.userRepo.FindByID(r.Context(), userID)
+		if err != nil {
+			h.handleUserError(w, r, userID, err)
+			return
+		}
+		if u.Role == user.RoleSuperAdmin {
+			http.Error(w, "not allowed to change role of a superadmin", http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := h.userRepo.SetRole(r.Context(), userID, role); err != nil {
+		h.handleUserError(w, r, userID, err)
+		return
+	}
+	h.logger.LogAttrs(r.Context(),
+		slog.LevelInfo,
+		"user role changed",
+		slog.String("user_id", userID),
+		slog.String("role", string(role)),
+		slog.String("actor_id", actorID),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) handleUserError(w http.ResponseWriter, r *http.Request,
+	userID string, err error,
+) {
+	if errors.Is(err, serviceerrs.ErrNotFound) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	h.logger.LogAttrs(r.Context(),
+		slog.LevelError,
+		"unexpected admin repo error",
+		slog.String("user_id", userID),
+		slog.Any(model.KeyLoggerError, err),
+	)
+	http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+}
+
+func (h *AdminHandler) writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedWriteResponseMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+	}
+}
+
+func toAdminUserResponse(u *user.User) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		ID:           u.ID,
+		Role:         string(u.Role),
+		ReferralCode: u.ReferralCode,
+	}
+}
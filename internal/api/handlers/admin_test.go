@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/handlers/mocks"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/ledger"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func TestAdminHandler_GetUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		findErr  error
+		wantCode int
+		resp     string
+	}{
+		{
+			name:     "existing user",
+			userID:   "user-1",
+			wantCode: http.StatusOK,
+			resp:     `{"id":"user-1","role":"support","referral_code":"ABCD2345"}`,
+		},
+		{
+			name:     "unknown user",
+			userID:   "user-2",
+			findErr:  serviceerrs.ErrNotFound,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "repo failure",
+			userID:   "user-3",
+			findErr:  serviceerrs.ErrUnexpected,
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewMockUserRepository(t)
+			h := NewAdminHandler(userRepo, mocks.NewMockOrderRepository(t), slog.Default())
+			found := user.User{ID: tt.userID, Role: user.RoleSupport, ReferralCode: "ABCD2345"}
+			if tt.findErr != nil {
+				found = user.User{}
+			}
+			userRepo.EXPECT().FindByID(mock.Anything, tt.userID).Return(found, tt.findErr)
+
+			req := adminRequest(http.MethodGet, "/users/"+tt.userID, "", "root", user.RoleAdmin, tt.userID)
+			rr := httptest.NewRecorder()
+			h.GetUser(rr, req)
+			res := rr.Result()
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+			if tt.wantCode == http.StatusOK {
+				assert.JSONEq(t, tt.resp, string(body))
+			}
+		})
+	}
+}
+
+func TestAdminHandler_FindUser(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(t)
+	h := NewAdminHandler(userRepo, mocks.NewMockOrderRepository(t), slog.Default())
+
+	// sha256("login")
+	const loginHash = "428821350e9691491f616b754cd8315fb86d797ab35d843479e732ef90665324"
+	userRepo.EXPECT().FindByLogin(mock.Anything, loginHash).
+		Return(user.User{ID: "user-1", Role: user.RoleUser}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users?login=login", http.NoBody)
+	rr := httptest.NewRecorder()
+	h.FindUser(rr, req)
+	res := rr.Result()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `{"id":"user-1","role":"user"}`, string(body))
+
+	req = httptest.NewRequest(http.MethodGet, "/users", http.NoBody)
+	rr = httptest.NewRecorder()
+	h.FindUser(rr, req)
+	res = rr.Result()
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestAdminHandler_GetUserBalance(t *testing.T) {
+	userRepo := mocks.NewMockUserRepository(t)
+	orderRepo := mocks.NewMockOrderRepository(t)
+	h := NewAdminHandler(userRepo, orderRepo, slog.Default())
+
+	userRepo.EXPECT().FindByID(mock.Anything, "user-1").Return(user.User{ID: "user-1"}, nil)
+	orderRepo.EXPECT().GetBalance(mock.Anything, "user-1").
+		Return(model.NewAmount(470, 0), model.NewAmount(30, 0), nil)
+	orderRepo.EXPECT().ListHistory(mock.Anything, "user-1").Return([]ledger.Entry{
+		{
+			At:        time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC),
+			Kind:      ledger.KindWithdrawal,
+			Reference: "2377225624",
+			Amount:    model.NewAmount(-30, 0),
+		},
+	}, nil)
+
+	req := adminRequest(http.MethodGet, "/users/user-1/balance", "", "support", user.RoleSupport, "user-1")
+	rr := httptest.NewRecorder()
+	h.GetUserBalance(rr, req)
+	res := rr.Result()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(body), `"current":470,"withdrawn":30`)
+	assert.Contains(t, string(body), `"kind":"withdrawal","reference":"2377225624","amount":-30`)
+}
+
+func TestAdminHandler_SetUserRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		actorID    string
+		actorRole  user.Role
+		body       string
+		targetRole user.Role
+		findErr    error
+		setErr     error
+		mockFind   bool
+		mockSet    bool
+		wantCode   int
+	}{
+		{name: "promote to support", actorID: "root", actorRole: user.RoleAdmin, body: `{"role":"support"}`,
+			targetRole: user.RoleUser, mockFind: true, mockSet: true, wantCode: http.StatusNoContent},
+		{name: "unknown user", actorID: "root", actorRole: user.RoleAdmin, body: `{"role":"admin"}`,
+			findErr: serviceerrs.ErrNotFound, mockFind: true, wantCode: http.StatusNotFound},
+		{name: "unknown role", actorID: "root", actorRole: user.RoleAdmin, body: `{"role":"owner"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "own role", actorID: "user-1", actorRole: user.RoleAdmin, body: `{"role":"user"}`,
+			wantCode: http.StatusForbidden},
+		{name: "admin grants superadmin", actorID: "root", actorRole: user.RoleAdmin,
+			body: `{"role":"superadmin"}`, wantCode: http.StatusForbidden},
+		{name: "admin demotes superadmin", actorID: "root", actorRole: user.RoleAdmin, body: `{"role":"user"}`,
+			targetRole: user.RoleSuperAdmin, mockFind: true, wantCode: http.StatusForbidden},
+		{name: "superadmin grants superadmin", actorID: "root", actorRole: user.RoleSuperAdmin,
+			body: `{"role":"superadmin"}`, mockSet: true, wantCode: http.StatusNoContent},
+		{name: "superadmin sets role of unknown user", actorID: "root", actorRole: user.RoleSuperAdmin,
+			body: `{"role":"admin"}`, setErr: serviceerrs.ErrNotFound, mockSet: true, wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewMockUserRepository(t)
+			h := NewAdminHandler(userRepo, mocks.NewMockOrderRepository(t), slog.Default())
+			if tt.mockFind {
+				userRepo.EXPECT().FindByID(mock.Anything, "user-1").
+					Return(user.User{ID: "user-1", Role: tt.targetRole}, tt.findErr)
+			}
+			if tt.mockSet {
+				userRepo.EXPECT().SetRole(mock.Anything, "user-1", mock.Anything).Return(tt.setErr)
+			}
+
+			req := adminRequest(http.MethodPut, "/users/user-1/role", tt.body,
+				tt.actorID, tt.actorRole, "user-1")
+			rr := httptest.NewRecorder()
+			h.SetUserRole(rr, req)
+			res := rr.Result()
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
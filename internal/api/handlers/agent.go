@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/dto"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/accrual"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+// AccrualAgent -- управление опросом accrual во время работы сервиса.
+type AccrualAgent interface {
+	Pause(ctx context.Context) error
+	Resume(ctx context.Context) error
+	SetMaxRequests(ctx context.Context, provider string, n uint64) error
+	State() agent.State
+	Replicas(ctx context.Context) ([]accrual.Replica, error)
+}
+
+type RecheckRepository interface {
+	RecheckOrders(ctx context.Context, orderIDs []string) (int64, error)
+}
+
+type AgentHandler struct {
+	logger *slog.Logger
+	agent  AccrualAgent
+	repo   RecheckRepository
+}
+
+func NewAgentHandler(a AccrualAgent, repo RecheckRepository, log *slog.Logger) *AgentHandler {
+	return &AgentHandler{
+		logger: log,
+		agent:  a,
+		repo:   repo,
+	}
+}
+
+func (h *AgentHandler) GetAgentState(w http.ResponseWriter, r *http.Request) {
+	replicas, err := h.agent.Replicas(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	state := h.agent.State()
+	response := dto.AccrualStateResponse{
+		Providers: providersResponse(state.Providers),
+		Replicas:  make([]dto.AccrualReplicaResponse, len(replicas)),
+		Paused:    state.Paused,
+		Running:   state.Running,
+	}
+	for i, replica := range replicas {
+		response.Replicas[i] = dto.AccrualReplicaResponse{
+			ReportedAt: replica.ReportedAt.Local(),
+			InstanceID: replica.InstanceID,
+			Providers:  providersResponse(replica.Providers),
+			Paused:     replica.Paused,
+		}
+	}
+	h.writeJSON(w, r, response)
+}
+
+func providersResponse(providers []accrual.ProviderState) []dto.AccrualProviderResponse {
+	response := make([]dto.AccrualProviderResponse, len(providers))
+	for i, p := range providers {
+		response[i] = dto.AccrualProviderResponse{
+			Name:        p.Name,
+			MaxRequests: p.MaxRequests,
+			RateLimit:   p.RateLimit,
+			RPM:         p.RPM,
+			InFlight:    p.InFlight,
+			Workers:     p.Workers,
+			Throttled:   p.Throttled,
+		}
+		if !p.RetryAfter.IsZero() {
+			retryAfter := p.RetryAfter.Local()
+			response[i].RetryAfter = &retryAfter
+		}
+	}
+	return response
+}
+
+func (h *AgentHandler) PauseAgent(w http.ResponseWriter, r *http.Request) {
+	if err := h.agent.Pause(r.Context()); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.logChange(r, "accrual polling paused")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AgentHandler) ResumeAgent(w http.ResponseWriter, r *http.Request) {
+	if err := h.agent.Resume(r.Context()); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.logChange(r, "accrual polling resumed")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AgentHandler) SetMaxRequests(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	var request dto.MaxRequestsRequest
+	if !h.decode(w, r, &request) {
+		return
+	}
+	if request.MaxRequests == 0 {
+		http.Error(w, "max_requests must be positive", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.agent.SetMaxRequests(r.Context(), provider, request.MaxRequests); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.logChange(r, "accrual max requests changed",
+		slog.String("provider", provider),
+		slog.Uint64("max_requests", request.MaxRequests),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecheckOrders назначает немедленную проверку заказов, ещё ждущих ответа accrual.
+func (h *AgentHandler) RecheckOrders(w http.ResponseWriter, r *http.Request) {
+	var request dto.RecheckRequest
+	if !h.decode(w, r, &request) {
+		return
+	}
+	if len(request.Orders) == 0 {
+		http.Error(w, "orders are required", http.StatusBadRequest)
+		return
+	}
+
+	n, err := h.repo.RecheckOrders(r.Context(), request.Orders)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.logChange(r, "accrual recheck scheduled",
+		slog.Int64("rechecked", n),
+		slog.Any("orders", request.Orders),
+	)
+	h.writeJSON(w, r, dto.RecheckResponse{Rechecked: n})
+}
+
+func (h *AgentHandler) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedReadBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if err := r.Body.Close(); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedCloseBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+	return true
+}
+
+func (h *AgentHandler) logChange(r *http.Request, msg string, attrs ...slog.Attr) {
+	actorID, _ := r.Context().Value(model.KeyContextUserID).(string)
+	h.logger.LogAttrs(r.Context(),
+		slog.LevelInfo,
+		msg,
+		append(attrs, slog.String("actor_id", actorID))...,
+	)
+}
+
+func (h *AgentHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, serviceerrs.ErrNotFound):
+		http.Error(w, "accrual provider not found", http.StatusNotFound)
+		return
+	case errors.Is(err, serviceerrs.ErrAgentNotRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	h.logger.LogAttrs(r.Context(),
+		slog.LevelError,
+		"unexpected accrual agent error",
+		slog.Any(model.KeyLoggerError, err),
+	)
+	http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+}
+
+func (h *AgentHandler) writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedWriteResponseMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/handlers/mocks"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/accrual"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func TestAgentHandler_GetAgentState(t *testing.T) {
+	a := mocks.NewMockAccrualAgent(t)
+	a.EXPECT().State().Return(agent.State{
+		Providers: []agent.ProviderState{
+			{Name: "default", MaxRequests: 10, RateLimit: 540, RPM: 120, InFlight: 3, Workers: 8},
+			{
+				RetryAfter:  time.Date(2026, 10, 18, 12, 1, 0, 0, time.UTC),
+				Name:        "partner",
+				MaxRequests: 5,
+				Throttled:   true,
+			},
+		},
+		Paused:  true,
+		Running: true,
+	})
+	a.EXPECT().Replicas(mock.Anything).Return([]accrual.Replica{{
+		ReportedAt: time.Date(2026, 10, 18, 12, 0, 30, 0, time.UTC),
+		InstanceID: "replica-2",
+		Providers:  []accrual.ProviderState{{Name: "default", MaxRequests: 10, Workers: 8}},
+		Paused:     true,
+	}}, nil)
+	h := NewAgentHandler(a, mocks.NewMockRecheckRepository(t), slog.Default())
+
+	rr := httptest.NewRecorder()
+	h.GetAgentState(rr, httptest.NewRequest(http.MethodGet, "/accrual", http.NoBody))
+	res := rr.Result()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `{"paused":true,"running":true,"providers":[`+
+		`{"name":"default","max_requests":10,"rate_limit_rpm":540,"rpm":120,"in_flight":3,"workers":8,`+
+		`"throttled":false},`+
+		`{"name":"partner","max_requests":5,"rate_limit_rpm":0,"rpm":0,"in_flight":0,"workers":0,"throttled":true,`+
+		`"retry_after":"2026-10-18T12:01:00Z"}],`+
+		`"replicas":[{"instance_id":"replica-2","reported_at":"2026-10-18T12:00:30Z","paused":true,"providers":[`+
+		`{"name":"default","max_requests":10,"rate_limit_rpm":0,"rpm":0,"in_flight":0,"workers":8,`+
+		`"throttled":false}]}]}`, string(body))
+
+	t.Run("replicas are unavailable", func(t *testing.T) {
+		a := mocks.NewMockAccrualAgent(t)
+		a.EXPECT().Replicas(mock.Anything).Return(nil, errors.New("connection refused"))
+		h := NewAgentHandler(a, mocks.NewMockRecheckRepository(t), slog.Default())
+
+		rr := httptest.NewRecorder()
+		h.GetAgentState(rr, httptest.NewRequest(http.MethodGet, "/accrual", http.NoBody))
+		res := rr.Result()
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+}
+
+func TestAgentHandler_PauseAgent(t *testing.T) {
+	a := mocks.NewMockAccrualAgent(t)
+	a.EXPECT().Pause(mock.Anything).Return(nil).Once()
+	a.EXPECT().Resume(mock.Anything).Return(errors.New("deadline exceeded")).Once()
+	logs := &bytes.Buffer{}
+	h := NewAgentHandler(a, mocks.NewMockRecheckRepository(t), slog.New(slog.NewTextHandler(logs, nil)))
+
+	req := httptest.NewRequest(http.MethodPost, "/accrual/pause", http.NoBody)
+	req = req.WithContext(context.WithValue(req.Context(), model.KeyContextUserID, "admin-1"))
+	rr := httptest.NewRecorder()
+	h.PauseAgent(rr, req)
+	res := rr.Result()
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Contains(t, logs.String(), "accrual polling paused")
+	assert.Contains(t, logs.String(), "actor_id=admin-1")
+
+	rr = httptest.NewRecorder()
+	h.ResumeAgent(rr, httptest.NewRequest(http.MethodPost, "/accrual/resume", http.NoBody))
+	res = rr.Result()
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+}
+
+func TestAgentHandler_SetMaxRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		mockErr  error
+		wantCall bool
+		wantCode int
+	}{
+		{"changed", `{"max_requests":5}`, nil, true, http.StatusNoContent},
+		{"unknown provider", `{"max_requests":5}`, serviceerrs.ErrNotFound, true, http.StatusNotFound},
+		{"not running", `{"max_requests":5}`, serviceerrs.ErrAgentNotRunning, true, http.StatusConflict},
+		{"zero", `{"max_requests":0}`, nil, false, http.StatusBadRequest},
+		{"bad json", `{"max_requests":`, nil, false, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := mocks.NewMockAccrualAgent(t)
+			if tt.wantCall {
+				a.EXPECT().SetMaxRequests(mock.Anything, "partner", uint64(5)).Return(tt.mockErr).Once()
+			}
+			h := NewAgentHandler(a, mocks.NewMockRecheckRepository(t), slog.Default())
+
+			req := httptest.NewRequest(http.MethodPut, "/accrual/providers/partner/max-requests",
+				strings.NewReader(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("provider", "partner")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+			h.SetMaxRequests(rr, req)
+			res := rr.Result()
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestAgentHandler_RecheckOrders(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"selected", `{"orders":["12345678903","4561261212345467"]}`, http.StatusOK, `{"rechecked":1}`},
+		{"no orders", `{"orders":[]}`, http.StatusBadRequest, ""},
+		{"bad json", `{"orders":`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockRecheckRepository(t)
+			if tt.wantCode == http.StatusOK {
+				repo.EXPECT().RecheckOrders(mock.Anything, []string{"12345678903", "4561261212345467"}).
+					Return(1, nil).Once()
+			}
+			h := NewAgentHandler(mocks.NewMockAccrualAgent(t), repo, slog.Default())
+
+			rr := httptest.NewRecorder()
+			h.RecheckOrders(rr, httptest.NewRequest(http.MethodPost, "/accrual/recheck", strings.NewReader(tt.body)))
+			res := rr.Result()
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, string(body))
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/dto"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/campaign"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type CampaignRepository interface {
+	Create(ctx context.Context, c *campaign.Campaign) error
+	Update(ctx context.Context, c *campaign.Campaign) error
+	Archive(ctx context.Context, id int64) error
+	FindByID(ctx context.Context, id int64) (campaign.Campaign, error)
+	List(ctx context.Context) ([]campaign.Campaign, error)
+}
+
+type CampaignHandler struct {
+	logger *slog.Logger
+	repo   CampaignRepository
+}
+
+func NewCampaignHandler(repo CampaignRepository, log *slog.Logger) *CampaignHandler {
+	return &CampaignHandler{
+		logger: log,
+		repo:   repo,
+	}
+}
+
+const failedWriteResponseMsg = "failed to write response"
+
+func (h *CampaignHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.decodeCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Create(r.Context(), &c); err != nil {
+		h.handleRepoError(w, r, 0, err)
+		return
+	}
+
+	h.writeCampaign(w, r, http.StatusCreated, &c)
+}
+
+func (h *CampaignHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.repo.List(r.Context())
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to list campaigns",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(campaigns) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := make([]dto.CampaignResponse, len(campaigns))
+	for i := range campaigns {
+		response[i] = toCampaignResponse(&campaigns[i])
+	}
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedWriteResponseMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *CampaignHandler) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := h.repo.FindByID(r.Context(), id)
+	if err != nil {
+		h.handleRepoError(w, r, id, err)
+		return
+	}
+	h.writeCampaign(w, r, http.StatusOK, &c)
+}
+
+func (h *CampaignHandler) UpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+	c, ok := h.decodeCampaign(w, r)
+	if !ok {
+		return
+	}
+	c.ID = id
+
+	if err := h.repo.Update(r.Context(), &c); err != nil {
+		h.handleRepoError(w, r, id, err)
+		return
+	}
+	h.writeCampaign(w, r, http.StatusOK, &c)
+}
+
+func (h *CampaignHandler) DeleteCampaign(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Archive(r.Context(), id); err != nil {
+		h.handleRepoError(w, r, id, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CampaignHandler) campaignID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 32)
+	if err != nil || id <= 0 {
+		http.Error(w, "campaign ID must be a positive number", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *CampaignHandler) decodeCampaign(w http.ResponseWriter, r *http.Request,
+) (campaign.Campaign, bool) {
+	var request dto.CampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedReadBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return campaign.Campaign{}, false
+	}
+	if err := r.Body.Close(); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedCloseBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+
+	bonusValue, err := model.FromString(request.BonusValue.String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return campaign.Campaign{}, false
+	}
+	c := campaign.Campaign{
+		StartsAt:         request.StartsAt.UTC(),
+		EndsAt:           request.EndsAt.UTC(),
+		RegisteredAfter:  request.RegisteredAfter,
+		RegisteredBefore: request.RegisteredBefore,
+		Name:             request.Name,
+		MinTier:          request.MinTier,
+		BonusType:        campaign.BonusType(request.BonusType),
+		BonusValue:       bonusValue,
+		FirstOrderOnly:   request.FirstOrderOnly,
+	}
+	if err = c.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return campaign.Campaign{}, false
+	}
+	return c, true
+}
+
+func (h *CampaignHandler) handleRepoError(w http.ResponseWriter, r *http.Request,
+	id int64, err error,
+) {
+	if errors.Is(err, serviceerrs.ErrUnknownTier) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, serviceerrs.ErrNotFound) {
+		http.Error(w, fmt.Sprintf("campaign %d not found", id), http.StatusNotFound)
+		return
+	}
+	h.logger.LogAttrs(r.Context(),
+		slog.LevelError,
+		"unexpected campaign repo error",
+		slog.Int64("campaign_id", id),
+		slog.Any(model.KeyLoggerError, err),
+	)
+	http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+}
+
+func (h *CampaignHandler) writeCampaign(w http.ResponseWriter, r *http.Request,
+	code int, c *campaign.Campaign,
+) {
+	w.Header().Set(model.HeaderContentType, "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(toCampaignResponse(c)); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedWriteResponseMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+}
+
+func toCampaignResponse(c *campaign.Campaign) dto.CampaignResponse {
+	return dto.CampaignResponse{
+		StartsAt:         c.StartsAt,
+		EndsAt:           c.EndsAt,
+		RegisteredAfter:  c.RegisteredAfter,
+		RegisteredBefore: c.RegisteredBefore,
+		Name:             c.Name,
+		MinTier:          c.MinTier,
+		BonusType:        string(c.BonusType),
+		BonusValue:       json.Number(c.BonusValue.String()),
+		ID:               c.ID,
+		FirstOrderOnly:   c.FirstOrderOnly,
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/handlers/mocks"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/campaign"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func withCampaignID(req *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestCampaignHandler_CreateCampaign(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		mockCreate func() error
+		wantCode   int
+		resp       string
+	}{
+		{
+			name: "fixed bonus",
+			body: `{"name":"spring","starts_at":"2026-03-01T00:00:00Z",` +
+				`"ends_at":"2026-04-01T00:00:00Z","bonus_type":"fixed","bonus_value":100}`,
+			mockCreate: func() error { return nil },
+			wantCode:   http.StatusCreated,
+			resp: `{"id":1,"name":"spring","starts_at":"2026-03-01T00:00:00Z",` +
+				`"ends_at":"2026-04-01T00:00:00Z","first_order_only":false,` +
+				`"bonus_type":"fixed","bonus_value":100}`,
+		},
+		{
+			name: "multiplier for gold members",
+			body: `{"name":"gold-week","starts_at":"2026-03-01T00:00:00Z",` +
+				`"ends_at":"2026-03-08T00:00:00Z","min_tier":"gold",` +
+				`"bonus_type":"multiplier","bonus_value":1.5}`,
+			mockCreate: func() error { return nil },
+			wantCode:   http.StatusCreated,
+			resp: `{"id":1,"name":"gold-week","starts_at":"2026-03-01T00:00:00Z",` +
+				`"ends_at":"2026-03-08T00:00:00Z","first_order_only":false,"min_tier":"gold",` +
+				`"bonus_type":"multiplier","bonus_value":1.5}`,
+		},
+		{
+			name: "unknown tier",
+			body: `{"name":"x","starts_at":"2026-03-01T00:00:00Z",` +
+				`"ends_at":"2026-03-08T00:00:00Z","min_tier":"platinum",` +
+				`"bonus_type":"fixed","bonus_value":10}`,
+			mockCreate: func() error {
+				return fmt.Errorf("%w: platinum", serviceerrs.ErrUnknownTier)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "window ends before start",
+			body: `{"name":"x","starts_at":"2026-03-08T00:00:00Z",` +
+				`"ends_at":"2026-03-01T00:00:00Z","bonus_type":"fixed","bonus_value":10}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "multiplier not above one",
+			body: `{"name":"x","starts_at":"2026-03-01T00:00:00Z",` +
+				`"ends_at":"2026-03-08T00:00:00Z","bonus_type":"multiplier","bonus_value":1}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "broken json",
+			body:     `{"name":`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "repo failure",
+			body: `{"name":"x","starts_at":"2026-03-01T00:00:00Z",` +
+				`"ends_at":"2026-03-08T00:00:00Z","bonus_type":"fixed","bonus_value":10}`,
+			mockCreate: func() error { return serviceerrs.ErrUnexpected },
+			wantCode:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockCampaignRepository(t)
+			h := NewCampaignHandler(repo, slog.Default())
+			if tt.mockCreate != nil {
+				err := tt.mockCreate()
+				repo.EXPECT().
+					Create(mock.Anything, mock.AnythingOfType("*campaign.Campaign")).
+					RunAndReturn(func(_ context.Context, c *campaign.Campaign) error {
+						c.ID = 1
+						return err
+					})
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPost, "/campaigns", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			h.CreateCampaign(rr, req)
+			res := rr.Result()
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			if tt.wantCode == http.StatusCreated {
+				assert.JSONEq(t, tt.resp, string(body))
+			}
+		})
+	}
+}
+
+func TestCampaignHandler_GetCampaign(t *testing.T) {
+	stored := campaign.Campaign{
+		ID:         7,
+		Name:       "summer",
+		StartsAt:   time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:     time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		BonusType:  campaign.BonusFixed,
+		BonusValue: model.NewAmount(50, 0),
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		mockFind func() (campaign.Campaign, error)
+		wantCode int
+	}{
+		{
+			name:     "found",
+			id:       "7",
+			mockFind: func() (campaign.Campaign, error) { return stored, nil },
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "not found",
+			id:   "8",
+			mockFind: func() (campaign.Campaign, error) {
+				return campaign.Campaign{}, serviceerrs.ErrNotFound
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "bad id",
+			id:       "abc",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockCampaignRepository(t)
+			h := NewCampaignHandler(repo, slog.Default())
+			if tt.mockFind != nil {
+				c, err := tt.mockFind()
+				repo.EXPECT().FindByID(mock.Anything, mock.Anything).Return(c, err)
+			}
+
+			req := withCampaignID(
+				httptest.NewRequest(http.MethodGet, "/campaigns/"+tt.id, http.NoBody), tt.id)
+			rr := httptest.NewRecorder()
+			h.GetCampaign(rr, req)
+			res := rr.Result()
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestCampaignHandler_DeleteCampaign(t *testing.T) {
+	tests := []struct {
+		name     string
+		archive  error
+		wantCode int
+	}{
+		{name: "archived", archive: nil, wantCode: http.StatusNoContent},
+		{name: "not found", archive: serviceerrs.ErrNotFound, wantCode: http.StatusNotFound},
+		{name: "repo failure", archive: serviceerrs.ErrUnexpected, wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockCampaignRepository(t)
+			h := NewCampaignHandler(repo, slog.Default())
+			repo.EXPECT().Archive(mock.Anything, int64(3)).Return(tt.archive)
+
+			req := withCampaignID(
+				httptest.NewRequest(http.MethodDelete, "/campaigns/3", http.NoBody), "3")
+			rr := httptest.NewRecorder()
+			h.DeleteCampaign(rr, req)
+			res := rr.Result()
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/dto"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type DeadLetterRepository interface {
+	ListDeadLetters(ctx context.Context) ([]order.DeadLetter, error)
+	RequeueDeadLetters(ctx context.Context, orderIDs []string) (int64, error)
+}
+
+type DeadLetterHandler struct {
+	logger *slog.Logger
+	repo   DeadLetterRepository
+}
+
+func NewDeadLetterHandler(repo DeadLetterRepository, log *slog.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		logger: log,
+		repo:   repo,
+	}
+}
+
+func (h *DeadLetterHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.repo.ListDeadLetters(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if len(orders) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := make([]dto.DeadLetterResponse, len(orders))
+	for i, o := range orders {
+		response[i] = dto.DeadLetterResponse{
+			UploadedAt:     o.UploadedAt.Local(),
+			DeadLetteredAt: o.DeadLetteredAt.Local(),
+			Number:         o.ID,
+			UserID:         o.UserID,
+			LastError:      o.LastError,
+			Failures:       o.Failures,
+		}
+	}
+	h.writeJSON(w, r, response)
+}
+
+func (h *DeadLetterHandler) RequeueDeadLetter(w http.ResponseWriter, r *http.Request) {
+	number := chi.URLParam(r, "number")
+	n, err := h.repo.RequeueDeadLetters(r.Context(), []string{number})
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "dead-lettered order not found", http.StatusNotFound)
+		return
+	}
+	h.logRequeued(r, n, number)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequeueDeadLetters возвращает в опрос перечисленные заказы, без тела -- все.
+func (h *DeadLetterHandler) RequeueDeadLetters(w http.ResponseWriter, r *http.Request) {
+	var request dto.RequeueRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedReadBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := r.Body.Close(); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedCloseBodyMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+
+	n, err := h.repo.RequeueDeadLetters(r.Context(), request.Orders)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	h.logRequeued(r, n, request.Orders...)
+	h.writeJSON(w, r, dto.RequeueResponse{Requeued: n})
+}
+
+func (h *DeadLetterHandler) logRequeued(r *http.Request, n int64, orderIDs ...string) {
+	actorID, _ := r.Context().Value(model.KeyContextUserID).(string)
+	h.logger.LogAttrs(r.Context(),
+		slog.LevelInfo,
+		"dead-lettered orders requeued",
+		slog.Int64("requeued", n),
+		slog.Any("orders", orderIDs),
+		slog.String("actor_id", actorID),
+	)
+}
+
+func (h *DeadLetterHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.LogAttrs(r.Context(),
+		slog.LevelError,
+		"unexpected dead letter repo error",
+		slog.Any(model.KeyLoggerError, err),
+	)
+	http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+}
+
+func (h *DeadLetterHandler) writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedWriteResponseMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+	}
+}
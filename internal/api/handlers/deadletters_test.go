@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/handlers/mocks"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+)
+
+func TestDeadLetterHandler_ListDeadLetters(t *testing.T) {
+	repo := mocks.NewMockDeadLetterRepository(t)
+	h := NewDeadLetterHandler(repo, slog.Default())
+
+	at := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	repo.EXPECT().ListDeadLetters(mock.Anything).Return([]order.DeadLetter{
+		{
+			UploadedAt:     at,
+			DeadLetteredAt: at.Add(time.Hour),
+			ID:             "12345678903",
+			UserID:         "user-1",
+			LastError:      "accrual service error",
+			Failures:       10,
+		},
+	}, nil).Once()
+	repo.EXPECT().ListDeadLetters(mock.Anything).Return(nil, nil).Once()
+
+	rr := httptest.NewRecorder()
+	h.ListDeadLetters(rr, httptest.NewRequest(http.MethodGet, "/orders/dead-letter", http.NoBody))
+	res := rr.Result()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `[{"number":"12345678903","user_id":"user-1","failures":10,`+
+		`"last_error":"accrual service error","uploaded_at":"2026-04-01T12:00:00Z",`+
+		`"dead_lettered_at":"2026-04-01T13:00:00Z"}]`, string(body))
+
+	rr = httptest.NewRecorder()
+	h.ListDeadLetters(rr, httptest.NewRequest(http.MethodGet, "/orders/dead-letter", http.NoBody))
+	res = rr.Result()
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestDeadLetterHandler_RequeueDeadLetter(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   string
+		mockN    int64
+		mockErr  error
+		wantCode int
+	}{
+		{"requeued", "12345678903", 1, nil, http.StatusNoContent},
+		{"not dead-lettered", "4561261212345467", 0, nil, http.StatusNotFound},
+		{"repo error", "12345678903", 0, errors.New("db is down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockDeadLetterRepository(t)
+			repo.EXPECT().RequeueDeadLetters(mock.Anything, []string{tt.number}).
+				Return(tt.mockN, tt.mockErr).Once()
+			h := NewDeadLetterHandler(repo, slog.Default())
+
+			req := httptest.NewRequest(http.MethodPost, "/orders/dead-letter/"+tt.number+"/requeue", http.NoBody)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("number", tt.number)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+			h.RequeueDeadLetter(rr, req)
+			res := rr.Result()
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestDeadLetterHandler_RequeueDeadLetters(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantOrders []string
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "all",
+			body:       "",
+			wantOrders: nil,
+			wantCode:   http.StatusOK,
+			wantBody:   `{"requeued":2}`,
+		},
+		{
+			name:       "selected",
+			body:       `{"orders":["12345678903"]}`,
+			wantOrders: []string{"12345678903"},
+			wantCode:   http.StatusOK,
+			wantBody:   `{"requeued":2}`,
+		},
+		{
+			name:     "bad json",
+			body:     `{"orders":`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockDeadLetterRepository(t)
+			if tt.wantCode == http.StatusOK {
+				repo.EXPECT().RequeueDeadLetters(mock.Anything, tt.wantOrders).Return(2, nil).Once()
+			}
+			h := NewDeadLetterHandler(repo, slog.Default())
+
+			req := httptest.NewRequest(http.MethodPost, "/orders/dead-letter/requeue", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			h.RequeueDeadLetters(rr, req)
+			res := rr.Result()
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, string(body))
+			}
+		})
+	}
+}
@@ -19,11 +19,15 @@ import (
 
 	"github.com/talx-hub/gopher-bonus/internal/api/dto"
 	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/ledger"
 	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/model/referral"
+	"github.com/talx-hub/gopher-bonus/internal/model/tier"
 	"github.com/talx-hub/gopher-bonus/internal/model/user"
 	"github.com/talx-hub/gopher-bonus/internal/service/dbmanager"
 	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
 	"github.com/talx-hub/gopher-bonus/internal/utils/auth"
+	"github.com/talx-hub/gopher-bonus/internal/utils/breaker"
 )
 
 const errRetrieveUserID = "failed retrieve userID from Ctx or check it with UserRepo"
@@ -33,6 +37,7 @@ type UserRepository interface {
 	Exists(ctx context.Context, loginHash string) bool
 	FindByLogin(ctx context.Context, loginHash string) (user.User, error)
 	FindByID(ctx context.Context, id string) (user.User, error)
+	SetRole(ctx context.Context, id string, role user.Role) error
 }
 
 type AuthHandler struct {
@@ -53,35 +58,86 @@ type OrderRepository interface {
 	CreateOrder(ctx context.Context, o *order.Order) error
 	FindUserIDByAccrualID(ctx context.Context, accrualID string) (string, error)
 	ListOrdersByUser(ctx context.Context, userID string, tp order.Type) ([]order.Order, error)
-	UpdateAccrualStatus(ctx context.Context, o *order.Order) error
+	UpdateAccrualStatus(ctx context.Context, owner string, o *order.Order) error
 	GetBalance(ctx context.Context, userID string) (model.Amount, model.Amount, error)
+	ListHistory(ctx context.Context, userID string) ([]ledger.Entry, error)
 }
 
 type userRetriever struct{}
 
+type OrderMetrics interface {
+	OrderUploaded()
+	PointsWithdrawn(a model.Amount)
+}
+
 type OrderHandler struct {
 	userRetriever
 	logger    *slog.Logger
 	orderRepo OrderRepository
 	userRepo  UserRepository
+	metrics   OrderMetrics
 }
 
 func NewOrderHandler(
-	userRepo UserRepository, orderRepo OrderRepository, log *slog.Logger,
+	userRepo UserRepository, orderRepo OrderRepository, log *slog.Logger, m OrderMetrics,
 ) *OrderHandler {
 	return &OrderHandler{
 		logger:    log,
 		orderRepo: orderRepo,
 		userRepo:  userRepo,
+		metrics:   m,
+	}
+}
+
+type TierRepository interface {
+	ListTiers(ctx context.Context) ([]tier.Tier, error)
+	GetUserTier(ctx context.Context, userID string) (tier.Tier, error)
+	GetRollingPoints(ctx context.Context, userID string) (model.Amount, error)
+}
+
+type TierHandler struct {
+	userRetriever
+	logger   *slog.Logger
+	tierRepo TierRepository
+	userRepo UserRepository
+}
+
+func NewTierHandler(
+	userRepo UserRepository, tierRepo TierRepository, log *slog.Logger,
+) *TierHandler {
+	return &TierHandler{
+		logger:   log,
+		tierRepo: tierRepo,
+		userRepo: userRepo,
 	}
 }
 
+type LeaderStatus interface {
+	IsLeader() bool
+	Term() int64
+}
+
+type AccrualBreaker interface {
+	State() breaker.State
+}
+
 type HealthHandler struct {
-	db *dbmanager.DBManager
+	db     *dbmanager.DBManager
+	leader LeaderStatus
+	// breakers -- breaker каждого провайдера accrual по имени
+	breakers   map[string]AccrualBreaker
+	instanceID string
 }
 
-func NewHealthHandler(db *dbmanager.DBManager) *HealthHandler {
-	return &HealthHandler{db: db}
+func NewHealthHandler(db *dbmanager.DBManager,
+	leader LeaderStatus, breakers map[string]AccrualBreaker, instanceID string,
+) *HealthHandler {
+	return &HealthHandler{
+		db:         db,
+		leader:     leader,
+		breakers:   breakers,
+		instanceID: instanceID,
+	}
 }
 
 const failedReadBodyMsg = "failed to read the request body"
@@ -141,18 +197,30 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	hasher.Write([]byte(data.Password))
 	passwordHash := hex.EncodeToString(hasher.Sum(nil))
 
+	referralCode, err := referral.NewCode()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	u := user.User{
 		ID:           uuid.NewString(),
 		LoginHash:    loginHash,
 		PasswordHash: passwordHash,
+		ReferralCode: referralCode,
+		ReferrerCode: referral.NormalizeCode(data.ReferralCode),
 	}
 	err = h.repo.Create(r.Context(), &u)
+	if err != nil && (errors.Is(err, serviceerrs.ErrUnknownReferralCode) ||
+		errors.Is(err, serviceerrs.ErrReferralNotAllowed)) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	jwtCookie, err := auth.Authenticate(u.ID, []byte(h.secret))
+	jwtCookie, err := auth.Authenticate(u.ID, user.RoleUser, []byte(h.secret))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -225,7 +293,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jwtCookie, err := auth.Authenticate(u.ID, []byte(h.secret))
+	jwtCookie, err := auth.Authenticate(u.ID, u.Role, []byte(h.secret))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -297,6 +365,9 @@ func (h *OrderHandler) PostOrder(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if h.metrics != nil {
+			h.metrics.OrderUploaded()
+		}
 		w.WriteHeader(http.StatusAccepted)
 		return
 	} else if err != nil {
@@ -447,7 +518,11 @@ func (h *OrderHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		},
 	)
 	if err == nil {
+		if h.metrics != nil {
+			h.metrics.PointsWithdrawn(amount)
+		}
 		w.WriteHeader(http.StatusOK)
+		return
 	}
 
 	if errors.Is(err, serviceerrs.ErrInsufficientFunds) {
@@ -522,6 +597,125 @@ func (h *OrderHandler) GetWithdrawals(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *OrderHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.retrieveUserID(r.Context(), h.userRepo)
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			errRetrieveUserID,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(),
+			http.StatusInternalServerError)
+		return
+	}
+
+	entries, err := h.orderRepo.ListHistory(r.Context(), userID)
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to list balance history",
+			slog.String("user_id", userID),
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(entries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := make([]dto.HistoryEntryResponse, len(entries))
+	for i, e := range entries {
+		response[i] = dto.HistoryEntryResponse{
+			OccurredAt: e.At.Local(),
+			Kind:       string(e.Kind),
+			Reference:  e.Reference,
+			Amount:     json.Number(e.Amount.String()),
+		}
+	}
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to write response",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *TierHandler) GetTier(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.retrieveUserID(r.Context(), h.userRepo)
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			errRetrieveUserID,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(),
+			http.StatusInternalServerError)
+		return
+	}
+
+	tiers, err := h.tierRepo.ListTiers(r.Context())
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to list tiers",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+	current, err := h.tierRepo.GetUserTier(r.Context(), userID)
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to get user tier",
+			slog.String("user_id", userID),
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+	points, err := h.tierRepo.GetRollingPoints(r.Context(), userID)
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to get rolling points",
+			slog.String("user_id", userID),
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	progress := tier.CalculateProgress(tiers, current, points)
+	response := dto.TierResponse{
+		Tier:          progress.Current.Name,
+		Multiplier:    json.Number(progress.Current.Multiplier.String()),
+		RollingPoints: json.Number(progress.RollingPoints.String()),
+	}
+	if progress.Next != nil {
+		response.NextTier = progress.Next.Name
+		response.PointsToNext = json.Number(progress.PointsToNext.String())
+	}
+
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to write response",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h *HealthHandler) Ping(w http.ResponseWriter, r *http.Request) {
 	h.db.Ping(r.Context())
 	if err := h.db.Error(); err != nil {
@@ -529,3 +723,33 @@ func (h *HealthHandler) Ping(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	resp := dto.HealthResponse{
+		Status:          "ok",
+		InstanceID:      h.instanceID,
+		AccrualBreakers: make(map[string]string, len(h.breakers)),
+	}
+	for provider, b := range h.breakers {
+		state := b.State()
+		resp.AccrualBreakers[provider] = string(state)
+		// без accrual сервис работает, но новые начисления не считаются
+		if state != breaker.StateClosed {
+			resp.Status = "degraded"
+		}
+	}
+	if h.leader.IsLeader() {
+		resp.Leader = true
+		resp.LeaderTerm = h.leader.Term()
+	}
+	status := http.StatusOK
+	h.db.Ping(r.Context())
+	if h.db.Error() != nil {
+		resp.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set(model.HeaderContentType, "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/talx-hub/gopher-bonus/internal/api/handlers/mocks"
 	"github.com/talx-hub/gopher-bonus/internal/model"
 	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/model/tier"
 	"github.com/talx-hub/gopher-bonus/internal/model/user"
 	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
 )
@@ -1071,3 +1072,152 @@ func TestOrderHandler_GetWithdrawals(t *testing.T) {
 	}
 	orderRepo.AssertNumberOfCalls(t, "ListOrdersByUser", 4)
 }
+
+func TestTierHandler_GetTier(t *testing.T) {
+	tiers := []tier.Tier{
+		{Name: "bronze", MinPoints: model.NewAmount(0, 0), Multiplier: model.NewAmount(1, 0)},
+		{Name: "silver", MinPoints: model.NewAmount(1000, 0), Multiplier: model.NewAmount(1, 10)},
+		{Name: "gold", MinPoints: model.NewAmount(5000, 0), Multiplier: model.NewAmount(1, 25)},
+	}
+
+	tests := []struct {
+		name               string
+		userID             string
+		mockRetrieveUserID func() (user.User, error)
+		mockListTiers      func() ([]tier.Tier, error)
+		mockGetUserTier    func() (tier.Tier, error)
+		mockGetPoints      func() (model.Amount, error)
+		wantCode           int
+		resp               string
+	}{
+		{
+			name:   "bronze user",
+			userID: "user-1",
+			mockRetrieveUserID: func() (user.User, error) {
+				return user.User{ID: "user-1"}, nil
+			},
+			mockListTiers: func() ([]tier.Tier, error) {
+				return tiers, nil
+			},
+			mockGetUserTier: func() (tier.Tier, error) {
+				return tiers[0], nil
+			},
+			mockGetPoints: func() (model.Amount, error) {
+				return model.NewAmount(250, 50), nil
+			},
+			wantCode: http.StatusOK,
+			resp: `{"tier":"bronze","multiplier":1,"rolling_points":250.5,` +
+				`"next_tier":"silver","points_to_next":749.5}`,
+		},
+		{
+			name:   "gold user",
+			userID: "user-2",
+			mockRetrieveUserID: func() (user.User, error) {
+				return user.User{ID: "user-2"}, nil
+			},
+			mockListTiers: func() ([]tier.Tier, error) {
+				return tiers, nil
+			},
+			mockGetUserTier: func() (tier.Tier, error) {
+				return tiers[2], nil
+			},
+			mockGetPoints: func() (model.Amount, error) {
+				return model.NewAmount(6000, 0), nil
+			},
+			wantCode: http.StatusOK,
+			resp:     `{"tier":"gold","multiplier":1.25,"rolling_points":6000}`,
+		},
+		{
+			name:   "fail to list tiers",
+			userID: "user-3",
+			mockRetrieveUserID: func() (user.User, error) {
+				return user.User{ID: "user-3"}, nil
+			},
+			mockListTiers: func() ([]tier.Tier, error) {
+				return nil, serviceerrs.ErrUnexpected
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "fail to get rolling points",
+			userID: "user-4",
+			mockRetrieveUserID: func() (user.User, error) {
+				return user.User{ID: "user-4"}, nil
+			},
+			mockListTiers: func() ([]tier.Tier, error) {
+				return tiers, nil
+			},
+			mockGetUserTier: func() (tier.Tier, error) {
+				return tiers[0], nil
+			},
+			mockGetPoints: func() (model.Amount, error) {
+				return model.Amount{}, serviceerrs.ErrUnexpected
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "middleware failure: no user in ctx",
+			userID:   "dont-put-to-ctx",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	userRepo := mocks.NewMockUserRepository(t)
+	tierRepo := mocks.NewMockTierRepository(t)
+
+	h := TierHandler{
+		userRetriever: userRetriever{},
+		logger:        slog.Default(),
+		tierRepo:      tierRepo,
+		userRepo:      userRepo,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockRetrieveUserID != nil {
+				u, err := tt.mockRetrieveUserID()
+				userRepo.EXPECT().
+					FindByID(mock.Anything, tt.userID).
+					Return(u, err)
+			}
+			if tt.mockListTiers != nil {
+				tiers, err := tt.mockListTiers()
+				tierRepo.EXPECT().
+					ListTiers(mock.Anything).
+					Return(tiers, err).
+					Once()
+			}
+			if tt.mockGetUserTier != nil {
+				current, err := tt.mockGetUserTier()
+				tierRepo.EXPECT().
+					GetUserTier(mock.Anything, tt.userID).
+					Return(current, err)
+			}
+			if tt.mockGetPoints != nil {
+				points, err := tt.mockGetPoints()
+				tierRepo.EXPECT().
+					GetRollingPoints(mock.Anything, tt.userID).
+					Return(points, err)
+			}
+
+			req := httptest.NewRequest(
+				http.MethodGet, "/tier", http.NoBody)
+			if tt.userID != "dont-put-to-ctx" {
+				userIDCtx := context.WithValue(
+					req.Context(), model.KeyContextUserID, tt.userID)
+				req = req.WithContext(userIDCtx)
+			}
+			rr := httptest.NewRecorder()
+			h.GetTier(rr, req)
+			res := rr.Result()
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			if tt.wantCode == http.StatusOK {
+				assert.JSONEq(t, tt.resp, string(body))
+			}
+		})
+	}
+}
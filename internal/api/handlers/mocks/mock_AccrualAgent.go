@@ -0,0 +1,311 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/talx-hub/gopher-bonus/internal/model/accrual"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent"
+)
+
+// NewMockAccrualAgent creates a new instance of MockAccrualAgent. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAccrualAgent(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAccrualAgent {
+	mock := &MockAccrualAgent{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAccrualAgent is an autogenerated mock type for the AccrualAgent type
+type MockAccrualAgent struct {
+	mock.Mock
+}
+
+type MockAccrualAgent_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAccrualAgent) EXPECT() *MockAccrualAgent_Expecter {
+	return &MockAccrualAgent_Expecter{mock: &_m.Mock}
+}
+
+// Pause provides a mock function for the type MockAccrualAgent
+func (_mock *MockAccrualAgent) Pause(ctx context.Context) error {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pause")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccrualAgent_Pause_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pause'
+type MockAccrualAgent_Pause_Call struct {
+	*mock.Call
+}
+
+// Pause is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAccrualAgent_Expecter) Pause(ctx interface{}) *MockAccrualAgent_Pause_Call {
+	return &MockAccrualAgent_Pause_Call{Call: _e.mock.On("Pause", ctx)}
+}
+
+func (_c *MockAccrualAgent_Pause_Call) Run(run func(ctx context.Context)) *MockAccrualAgent_Pause_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccrualAgent_Pause_Call) Return(err error) *MockAccrualAgent_Pause_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccrualAgent_Pause_Call) RunAndReturn(run func(ctx context.Context) error) *MockAccrualAgent_Pause_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Replicas provides a mock function for the type MockAccrualAgent
+func (_mock *MockAccrualAgent) Replicas(ctx context.Context) ([]accrual.Replica, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Replicas")
+	}
+
+	var r0 []accrual.Replica
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]accrual.Replica, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []accrual.Replica); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]accrual.Replica)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccrualAgent_Replicas_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Replicas'
+type MockAccrualAgent_Replicas_Call struct {
+	*mock.Call
+}
+
+// Replicas is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAccrualAgent_Expecter) Replicas(ctx interface{}) *MockAccrualAgent_Replicas_Call {
+	return &MockAccrualAgent_Replicas_Call{Call: _e.mock.On("Replicas", ctx)}
+}
+
+func (_c *MockAccrualAgent_Replicas_Call) Run(run func(ctx context.Context)) *MockAccrualAgent_Replicas_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccrualAgent_Replicas_Call) Return(replicas []accrual.Replica, err error) *MockAccrualAgent_Replicas_Call {
+	_c.Call.Return(replicas, err)
+	return _c
+}
+
+func (_c *MockAccrualAgent_Replicas_Call) RunAndReturn(run func(ctx context.Context) ([]accrual.Replica, error)) *MockAccrualAgent_Replicas_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Resume provides a mock function for the type MockAccrualAgent
+func (_mock *MockAccrualAgent) Resume(ctx context.Context) error {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Resume")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccrualAgent_Resume_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Resume'
+type MockAccrualAgent_Resume_Call struct {
+	*mock.Call
+}
+
+// Resume is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAccrualAgent_Expecter) Resume(ctx interface{}) *MockAccrualAgent_Resume_Call {
+	return &MockAccrualAgent_Resume_Call{Call: _e.mock.On("Resume", ctx)}
+}
+
+func (_c *MockAccrualAgent_Resume_Call) Run(run func(ctx context.Context)) *MockAccrualAgent_Resume_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccrualAgent_Resume_Call) Return(err error) *MockAccrualAgent_Resume_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccrualAgent_Resume_Call) RunAndReturn(run func(ctx context.Context) error) *MockAccrualAgent_Resume_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMaxRequests provides a mock function for the type MockAccrualAgent
+func (_mock *MockAccrualAgent) SetMaxRequests(ctx context.Context, provider string, n uint64) error {
+	ret := _mock.Called(ctx, provider, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMaxRequests")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, uint64) error); ok {
+		r0 = returnFunc(ctx, provider, n)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccrualAgent_SetMaxRequests_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMaxRequests'
+type MockAccrualAgent_SetMaxRequests_Call struct {
+	*mock.Call
+}
+
+// SetMaxRequests is a helper method to define mock.On call
+//   - ctx context.Context
+//   - provider string
+//   - n uint64
+func (_e *MockAccrualAgent_Expecter) SetMaxRequests(ctx interface{}, provider interface{}, n interface{}) *MockAccrualAgent_SetMaxRequests_Call {
+	return &MockAccrualAgent_SetMaxRequests_Call{Call: _e.mock.On("SetMaxRequests", ctx, provider, n)}
+}
+
+func (_c *MockAccrualAgent_SetMaxRequests_Call) Run(run func(ctx context.Context, provider string, n uint64)) *MockAccrualAgent_SetMaxRequests_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 uint64
+		if args[2] != nil {
+			arg2 = args[2].(uint64)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccrualAgent_SetMaxRequests_Call) Return(err error) *MockAccrualAgent_SetMaxRequests_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccrualAgent_SetMaxRequests_Call) RunAndReturn(run func(ctx context.Context, provider string, n uint64) error) *MockAccrualAgent_SetMaxRequests_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// State provides a mock function for the type MockAccrualAgent
+func (_mock *MockAccrualAgent) State() agent.State {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for State")
+	}
+
+	var r0 agent.State
+	if returnFunc, ok := ret.Get(0).(func() agent.State); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(agent.State)
+	}
+	return r0
+}
+
+// MockAccrualAgent_State_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'State'
+type MockAccrualAgent_State_Call struct {
+	*mock.Call
+}
+
+// State is a helper method to define mock.On call
+func (_e *MockAccrualAgent_Expecter) State() *MockAccrualAgent_State_Call {
+	return &MockAccrualAgent_State_Call{Call: _e.mock.On("State")}
+}
+
+func (_c *MockAccrualAgent_State_Call) Run(run func()) *MockAccrualAgent_State_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAccrualAgent_State_Call) Return(state agent.State) *MockAccrualAgent_State_Call {
+	_c.Call.Return(state)
+	return _c
+}
+
+func (_c *MockAccrualAgent_State_Call) RunAndReturn(run func() agent.State) *MockAccrualAgent_State_Call {
+	_c.Call.Return(run)
+	return _c
+}
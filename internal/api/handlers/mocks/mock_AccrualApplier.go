@@ -0,0 +1,96 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+)
+
+// NewMockAccrualApplier creates a new instance of MockAccrualApplier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAccrualApplier(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAccrualApplier {
+	mock := &MockAccrualApplier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAccrualApplier is an autogenerated mock type for the AccrualApplier type
+type MockAccrualApplier struct {
+	mock.Mock
+}
+
+type MockAccrualApplier_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAccrualApplier) EXPECT() *MockAccrualApplier_Expecter {
+	return &MockAccrualApplier_Expecter{mock: &_m.Mock}
+}
+
+// ApplyCallback provides a mock function for the type MockAccrualApplier
+func (_mock *MockAccrualApplier) ApplyCallback(ctx context.Context, info dto.AccrualInfo) error {
+	ret := _mock.Called(ctx, info)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyCallback")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, dto.AccrualInfo) error); ok {
+		r0 = returnFunc(ctx, info)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccrualApplier_ApplyCallback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ApplyCallback'
+type MockAccrualApplier_ApplyCallback_Call struct {
+	*mock.Call
+}
+
+// ApplyCallback is a helper method to define mock.On call
+//   - ctx context.Context
+//   - info dto.AccrualInfo
+func (_e *MockAccrualApplier_Expecter) ApplyCallback(ctx interface{}, info interface{}) *MockAccrualApplier_ApplyCallback_Call {
+	return &MockAccrualApplier_ApplyCallback_Call{Call: _e.mock.On("ApplyCallback", ctx, info)}
+}
+
+func (_c *MockAccrualApplier_ApplyCallback_Call) Run(run func(ctx context.Context, info dto.AccrualInfo)) *MockAccrualApplier_ApplyCallback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 dto.AccrualInfo
+		if args[1] != nil {
+			arg1 = args[1].(dto.AccrualInfo)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccrualApplier_ApplyCallback_Call) Return(err error) *MockAccrualApplier_ApplyCallback_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccrualApplier_ApplyCallback_Call) RunAndReturn(run func(ctx context.Context, info dto.AccrualInfo) error) *MockAccrualApplier_ApplyCallback_Call {
+	_c.Call.Return(run)
+	return _c
+}
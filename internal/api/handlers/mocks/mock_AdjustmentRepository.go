@@ -0,0 +1,164 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+)
+
+// NewMockAdjustmentRepository creates a new instance of MockAdjustmentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAdjustmentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAdjustmentRepository {
+	mock := &MockAdjustmentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAdjustmentRepository is an autogenerated mock type for the AdjustmentRepository type
+type MockAdjustmentRepository struct {
+	mock.Mock
+}
+
+type MockAdjustmentRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAdjustmentRepository) EXPECT() *MockAdjustmentRepository_Expecter {
+	return &MockAdjustmentRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockAdjustmentRepository
+func (_mock *MockAdjustmentRepository) Create(ctx context.Context, a *adjustment.Adjustment) error {
+	ret := _mock.Called(ctx, a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *adjustment.Adjustment) error); ok {
+		r0 = returnFunc(ctx, a)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAdjustmentRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockAdjustmentRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - a *adjustment.Adjustment
+func (_e *MockAdjustmentRepository_Expecter) Create(ctx interface{}, a interface{}) *MockAdjustmentRepository_Create_Call {
+	return &MockAdjustmentRepository_Create_Call{Call: _e.mock.On("Create", ctx, a)}
+}
+
+func (_c *MockAdjustmentRepository_Create_Call) Run(run func(ctx context.Context, a *adjustment.Adjustment)) *MockAdjustmentRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *adjustment.Adjustment
+		if args[1] != nil {
+			arg1 = args[1].(*adjustment.Adjustment)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAdjustmentRepository_Create_Call) Return(err error) *MockAdjustmentRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAdjustmentRepository_Create_Call) RunAndReturn(run func(ctx context.Context, a *adjustment.Adjustment) error) *MockAdjustmentRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListByUser provides a mock function for the type MockAdjustmentRepository
+func (_mock *MockAdjustmentRepository) ListByUser(ctx context.Context, userID string) ([]adjustment.Adjustment, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByUser")
+	}
+
+	var r0 []adjustment.Adjustment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]adjustment.Adjustment, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []adjustment.Adjustment); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]adjustment.Adjustment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAdjustmentRepository_ListByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByUser'
+type MockAdjustmentRepository_ListByUser_Call struct {
+	*mock.Call
+}
+
+// ListByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockAdjustmentRepository_Expecter) ListByUser(ctx interface{}, userID interface{}) *MockAdjustmentRepository_ListByUser_Call {
+	return &MockAdjustmentRepository_ListByUser_Call{Call: _e.mock.On("ListByUser", ctx, userID)}
+}
+
+func (_c *MockAdjustmentRepository_ListByUser_Call) Run(run func(ctx context.Context, userID string)) *MockAdjustmentRepository_ListByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAdjustmentRepository_ListByUser_Call) Return(adjustments []adjustment.Adjustment, err error) *MockAdjustmentRepository_ListByUser_Call {
+	_c.Call.Return(adjustments, err)
+	return _c
+}
+
+func (_c *MockAdjustmentRepository_ListByUser_Call) RunAndReturn(run func(ctx context.Context, userID string) ([]adjustment.Adjustment, error)) *MockAdjustmentRepository_ListByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,338 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/talx-hub/gopher-bonus/internal/model/campaign"
+)
+
+// NewMockCampaignRepository creates a new instance of MockCampaignRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCampaignRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCampaignRepository {
+	mock := &MockCampaignRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCampaignRepository is an autogenerated mock type for the CampaignRepository type
+type MockCampaignRepository struct {
+	mock.Mock
+}
+
+type MockCampaignRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCampaignRepository) EXPECT() *MockCampaignRepository_Expecter {
+	return &MockCampaignRepository_Expecter{mock: &_m.Mock}
+}
+
+// Archive provides a mock function for the type MockCampaignRepository
+func (_mock *MockCampaignRepository) Archive(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Archive")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCampaignRepository_Archive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Archive'
+type MockCampaignRepository_Archive_Call struct {
+	*mock.Call
+}
+
+// Archive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockCampaignRepository_Expecter) Archive(ctx interface{}, id interface{}) *MockCampaignRepository_Archive_Call {
+	return &MockCampaignRepository_Archive_Call{Call: _e.mock.On("Archive", ctx, id)}
+}
+
+func (_c *MockCampaignRepository_Archive_Call) Run(run func(ctx context.Context, id int64)) *MockCampaignRepository_Archive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCampaignRepository_Archive_Call) Return(err error) *MockCampaignRepository_Archive_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCampaignRepository_Archive_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockCampaignRepository_Archive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function for the type MockCampaignRepository
+func (_mock *MockCampaignRepository) Create(ctx context.Context, c *campaign.Campaign) error {
+	ret := _mock.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *campaign.Campaign) error); ok {
+		r0 = returnFunc(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCampaignRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockCampaignRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - c *campaign.Campaign
+func (_e *MockCampaignRepository_Expecter) Create(ctx interface{}, c interface{}) *MockCampaignRepository_Create_Call {
+	return &MockCampaignRepository_Create_Call{Call: _e.mock.On("Create", ctx, c)}
+}
+
+func (_c *MockCampaignRepository_Create_Call) Run(run func(ctx context.Context, c *campaign.Campaign)) *MockCampaignRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *campaign.Campaign
+		if args[1] != nil {
+			arg1 = args[1].(*campaign.Campaign)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCampaignRepository_Create_Call) Return(err error) *MockCampaignRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCampaignRepository_Create_Call) RunAndReturn(run func(ctx context.Context, c *campaign.Campaign) error) *MockCampaignRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindByID provides a mock function for the type MockCampaignRepository
+func (_mock *MockCampaignRepository) FindByID(ctx context.Context, id int64) (campaign.Campaign, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 campaign.Campaign
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (campaign.Campaign, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) campaign.Campaign); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(campaign.Campaign)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCampaignRepository_FindByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByID'
+type MockCampaignRepository_FindByID_Call struct {
+	*mock.Call
+}
+
+// FindByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockCampaignRepository_Expecter) FindByID(ctx interface{}, id interface{}) *MockCampaignRepository_FindByID_Call {
+	return &MockCampaignRepository_FindByID_Call{Call: _e.mock.On("FindByID", ctx, id)}
+}
+
+func (_c *MockCampaignRepository_FindByID_Call) Run(run func(ctx context.Context, id int64)) *MockCampaignRepository_FindByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCampaignRepository_FindByID_Call) Return(campaign1 campaign.Campaign, err error) *MockCampaignRepository_FindByID_Call {
+	_c.Call.Return(campaign1, err)
+	return _c
+}
+
+func (_c *MockCampaignRepository_FindByID_Call) RunAndReturn(run func(ctx context.Context, id int64) (campaign.Campaign, error)) *MockCampaignRepository_FindByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockCampaignRepository
+func (_mock *MockCampaignRepository) List(ctx context.Context) ([]campaign.Campaign, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []campaign.Campaign
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]campaign.Campaign, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []campaign.Campaign); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]campaign.Campaign)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCampaignRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockCampaignRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockCampaignRepository_Expecter) List(ctx interface{}) *MockCampaignRepository_List_Call {
+	return &MockCampaignRepository_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockCampaignRepository_List_Call) Run(run func(ctx context.Context)) *MockCampaignRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCampaignRepository_List_Call) Return(campaigns []campaign.Campaign, err error) *MockCampaignRepository_List_Call {
+	_c.Call.Return(campaigns, err)
+	return _c
+}
+
+func (_c *MockCampaignRepository_List_Call) RunAndReturn(run func(ctx context.Context) ([]campaign.Campaign, error)) *MockCampaignRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockCampaignRepository
+func (_mock *MockCampaignRepository) Update(ctx context.Context, c *campaign.Campaign) error {
+	ret := _mock.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *campaign.Campaign) error); ok {
+		r0 = returnFunc(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCampaignRepository_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockCampaignRepository_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - c *campaign.Campaign
+func (_e *MockCampaignRepository_Expecter) Update(ctx interface{}, c interface{}) *MockCampaignRepository_Update_Call {
+	return &MockCampaignRepository_Update_Call{Call: _e.mock.On("Update", ctx, c)}
+}
+
+func (_c *MockCampaignRepository_Update_Call) Run(run func(ctx context.Context, c *campaign.Campaign)) *MockCampaignRepository_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *campaign.Campaign
+		if args[1] != nil {
+			arg1 = args[1].(*campaign.Campaign)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCampaignRepository_Update_Call) Return(err error) *MockCampaignRepository_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCampaignRepository_Update_Call) RunAndReturn(run func(ctx context.Context, c *campaign.Campaign) error) *MockCampaignRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
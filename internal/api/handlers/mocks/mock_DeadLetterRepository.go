@@ -0,0 +1,167 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+)
+
+// NewMockDeadLetterRepository creates a new instance of MockDeadLetterRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDeadLetterRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDeadLetterRepository {
+	mock := &MockDeadLetterRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockDeadLetterRepository is an autogenerated mock type for the DeadLetterRepository type
+type MockDeadLetterRepository struct {
+	mock.Mock
+}
+
+type MockDeadLetterRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDeadLetterRepository) EXPECT() *MockDeadLetterRepository_Expecter {
+	return &MockDeadLetterRepository_Expecter{mock: &_m.Mock}
+}
+
+// ListDeadLetters provides a mock function for the type MockDeadLetterRepository
+func (_mock *MockDeadLetterRepository) ListDeadLetters(ctx context.Context) ([]order.DeadLetter, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeadLetters")
+	}
+
+	var r0 []order.DeadLetter
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]order.DeadLetter, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []order.DeadLetter); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]order.DeadLetter)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockDeadLetterRepository_ListDeadLetters_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDeadLetters'
+type MockDeadLetterRepository_ListDeadLetters_Call struct {
+	*mock.Call
+}
+
+// ListDeadLetters is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockDeadLetterRepository_Expecter) ListDeadLetters(ctx interface{}) *MockDeadLetterRepository_ListDeadLetters_Call {
+	return &MockDeadLetterRepository_ListDeadLetters_Call{Call: _e.mock.On("ListDeadLetters", ctx)}
+}
+
+func (_c *MockDeadLetterRepository_ListDeadLetters_Call) Run(run func(ctx context.Context)) *MockDeadLetterRepository_ListDeadLetters_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockDeadLetterRepository_ListDeadLetters_Call) Return(deadLetters []order.DeadLetter, err error) *MockDeadLetterRepository_ListDeadLetters_Call {
+	_c.Call.Return(deadLetters, err)
+	return _c
+}
+
+func (_c *MockDeadLetterRepository_ListDeadLetters_Call) RunAndReturn(run func(ctx context.Context) ([]order.DeadLetter, error)) *MockDeadLetterRepository_ListDeadLetters_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequeueDeadLetters provides a mock function for the type MockDeadLetterRepository
+func (_mock *MockDeadLetterRepository) RequeueDeadLetters(ctx context.Context, orderIDs []string) (int64, error) {
+	ret := _mock.Called(ctx, orderIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequeueDeadLetters")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (int64, error)); ok {
+		return returnFunc(ctx, orderIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) int64); ok {
+		r0 = returnFunc(ctx, orderIDs)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockDeadLetterRepository_RequeueDeadLetters_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequeueDeadLetters'
+type MockDeadLetterRepository_RequeueDeadLetters_Call struct {
+	*mock.Call
+}
+
+// RequeueDeadLetters is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderIDs []string
+func (_e *MockDeadLetterRepository_Expecter) RequeueDeadLetters(ctx interface{}, orderIDs interface{}) *MockDeadLetterRepository_RequeueDeadLetters_Call {
+	return &MockDeadLetterRepository_RequeueDeadLetters_Call{Call: _e.mock.On("RequeueDeadLetters", ctx, orderIDs)}
+}
+
+func (_c *MockDeadLetterRepository_RequeueDeadLetters_Call) Run(run func(ctx context.Context, orderIDs []string)) *MockDeadLetterRepository_RequeueDeadLetters_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockDeadLetterRepository_RequeueDeadLetters_Call) Return(n int64, err error) *MockDeadLetterRepository_RequeueDeadLetters_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockDeadLetterRepository_RequeueDeadLetters_Call) RunAndReturn(run func(ctx context.Context, orderIDs []string) (int64, error)) *MockDeadLetterRepository_RequeueDeadLetters_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,110 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+// NewMockOrderMetrics creates a new instance of MockOrderMetrics. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderMetrics(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderMetrics {
+	mock := &MockOrderMetrics{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderMetrics is an autogenerated mock type for the OrderMetrics type
+type MockOrderMetrics struct {
+	mock.Mock
+}
+
+type MockOrderMetrics_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderMetrics) EXPECT() *MockOrderMetrics_Expecter {
+	return &MockOrderMetrics_Expecter{mock: &_m.Mock}
+}
+
+// OrderUploaded provides a mock function for the type MockOrderMetrics
+func (_mock *MockOrderMetrics) OrderUploaded() {
+	_mock.Called()
+	return
+}
+
+// MockOrderMetrics_OrderUploaded_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrderUploaded'
+type MockOrderMetrics_OrderUploaded_Call struct {
+	*mock.Call
+}
+
+// OrderUploaded is a helper method to define mock.On call
+func (_e *MockOrderMetrics_Expecter) OrderUploaded() *MockOrderMetrics_OrderUploaded_Call {
+	return &MockOrderMetrics_OrderUploaded_Call{Call: _e.mock.On("OrderUploaded")}
+}
+
+func (_c *MockOrderMetrics_OrderUploaded_Call) Run(run func()) *MockOrderMetrics_OrderUploaded_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockOrderMetrics_OrderUploaded_Call) Return() *MockOrderMetrics_OrderUploaded_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockOrderMetrics_OrderUploaded_Call) RunAndReturn(run func()) *MockOrderMetrics_OrderUploaded_Call {
+	_c.Run(run)
+	return _c
+}
+
+// PointsWithdrawn provides a mock function for the type MockOrderMetrics
+func (_mock *MockOrderMetrics) PointsWithdrawn(a model.Amount) {
+	_mock.Called(a)
+	return
+}
+
+// MockOrderMetrics_PointsWithdrawn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PointsWithdrawn'
+type MockOrderMetrics_PointsWithdrawn_Call struct {
+	*mock.Call
+}
+
+// PointsWithdrawn is a helper method to define mock.On call
+//   - a model.Amount
+func (_e *MockOrderMetrics_Expecter) PointsWithdrawn(a interface{}) *MockOrderMetrics_PointsWithdrawn_Call {
+	return &MockOrderMetrics_PointsWithdrawn_Call{Call: _e.mock.On("PointsWithdrawn", a)}
+}
+
+func (_c *MockOrderMetrics_PointsWithdrawn_Call) Run(run func(a model.Amount)) *MockOrderMetrics_PointsWithdrawn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 model.Amount
+		if args[0] != nil {
+			arg0 = args[0].(model.Amount)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderMetrics_PointsWithdrawn_Call) Return() *MockOrderMetrics_PointsWithdrawn_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockOrderMetrics_PointsWithdrawn_Call) RunAndReturn(run func(a model.Amount)) *MockOrderMetrics_PointsWithdrawn_Call {
+	_c.Run(run)
+	return _c
+}
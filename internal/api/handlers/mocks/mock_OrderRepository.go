@@ -9,6 +9,7 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/ledger"
 	"github.com/talx-hub/gopher-bonus/internal/model/order"
 )
 
@@ -234,6 +235,74 @@ func (_c *MockOrderRepository_GetBalance_Call) RunAndReturn(run func(ctx context
 	return _c
 }
 
+// ListHistory provides a mock function for the type MockOrderRepository
+func (_mock *MockOrderRepository) ListHistory(ctx context.Context, userID string) ([]ledger.Entry, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListHistory")
+	}
+
+	var r0 []ledger.Entry
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]ledger.Entry, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []ledger.Entry); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]ledger.Entry)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepository_ListHistory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListHistory'
+type MockOrderRepository_ListHistory_Call struct {
+	*mock.Call
+}
+
+// ListHistory is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockOrderRepository_Expecter) ListHistory(ctx interface{}, userID interface{}) *MockOrderRepository_ListHistory_Call {
+	return &MockOrderRepository_ListHistory_Call{Call: _e.mock.On("ListHistory", ctx, userID)}
+}
+
+func (_c *MockOrderRepository_ListHistory_Call) Run(run func(ctx context.Context, userID string)) *MockOrderRepository_ListHistory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepository_ListHistory_Call) Return(entrys []ledger.Entry, err error) *MockOrderRepository_ListHistory_Call {
+	_c.Call.Return(entrys, err)
+	return _c
+}
+
+func (_c *MockOrderRepository_ListHistory_Call) RunAndReturn(run func(ctx context.Context, userID string) ([]ledger.Entry, error)) *MockOrderRepository_ListHistory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListOrdersByUser provides a mock function for the type MockOrderRepository
 func (_mock *MockOrderRepository) ListOrdersByUser(ctx context.Context, userID string, tp order.Type) ([]order.Order, error) {
 	ret := _mock.Called(ctx, userID, tp)
@@ -309,16 +378,16 @@ func (_c *MockOrderRepository_ListOrdersByUser_Call) RunAndReturn(run func(ctx c
 }
 
 // UpdateAccrualStatus provides a mock function for the type MockOrderRepository
-func (_mock *MockOrderRepository) UpdateAccrualStatus(ctx context.Context, o *order.Order) error {
-	ret := _mock.Called(ctx, o)
+func (_mock *MockOrderRepository) UpdateAccrualStatus(ctx context.Context, owner string, o *order.Order) error {
+	ret := _mock.Called(ctx, owner, o)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateAccrualStatus")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *order.Order) error); ok {
-		r0 = returnFunc(ctx, o)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *order.Order) error); ok {
+		r0 = returnFunc(ctx, owner, o)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -332,24 +401,30 @@ type MockOrderRepository_UpdateAccrualStatus_Call struct {
 
 // UpdateAccrualStatus is a helper method to define mock.On call
 //   - ctx context.Context
+//   - owner string
 //   - o *order.Order
-func (_e *MockOrderRepository_Expecter) UpdateAccrualStatus(ctx interface{}, o interface{}) *MockOrderRepository_UpdateAccrualStatus_Call {
-	return &MockOrderRepository_UpdateAccrualStatus_Call{Call: _e.mock.On("UpdateAccrualStatus", ctx, o)}
+func (_e *MockOrderRepository_Expecter) UpdateAccrualStatus(ctx interface{}, owner interface{}, o interface{}) *MockOrderRepository_UpdateAccrualStatus_Call {
+	return &MockOrderRepository_UpdateAccrualStatus_Call{Call: _e.mock.On("UpdateAccrualStatus", ctx, owner, o)}
 }
 
-func (_c *MockOrderRepository_UpdateAccrualStatus_Call) Run(run func(ctx context.Context, o *order.Order)) *MockOrderRepository_UpdateAccrualStatus_Call {
+func (_c *MockOrderRepository_UpdateAccrualStatus_Call) Run(run func(ctx context.Context, owner string, o *order.Order)) *MockOrderRepository_UpdateAccrualStatus_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *order.Order
+		var arg1 string
 		if args[1] != nil {
-			arg1 = args[1].(*order.Order)
+			arg1 = args[1].(string)
+		}
+		var arg2 *order.Order
+		if args[2] != nil {
+			arg2 = args[2].(*order.Order)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
@@ -360,7 +435,7 @@ func (_c *MockOrderRepository_UpdateAccrualStatus_Call) Return(err error) *MockO
 	return _c
 }
 
-func (_c *MockOrderRepository_UpdateAccrualStatus_Call) RunAndReturn(run func(ctx context.Context, o *order.Order) error) *MockOrderRepository_UpdateAccrualStatus_Call {
+func (_c *MockOrderRepository_UpdateAccrualStatus_Call) RunAndReturn(run func(ctx context.Context, owner string, o *order.Order) error) *MockOrderRepository_UpdateAccrualStatus_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,104 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockRecheckRepository creates a new instance of MockRecheckRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRecheckRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRecheckRepository {
+	mock := &MockRecheckRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRecheckRepository is an autogenerated mock type for the RecheckRepository type
+type MockRecheckRepository struct {
+	mock.Mock
+}
+
+type MockRecheckRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRecheckRepository) EXPECT() *MockRecheckRepository_Expecter {
+	return &MockRecheckRepository_Expecter{mock: &_m.Mock}
+}
+
+// RecheckOrders provides a mock function for the type MockRecheckRepository
+func (_mock *MockRecheckRepository) RecheckOrders(ctx context.Context, orderIDs []string) (int64, error) {
+	ret := _mock.Called(ctx, orderIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecheckOrders")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) (int64, error)); ok {
+		return returnFunc(ctx, orderIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) int64); ok {
+		r0 = returnFunc(ctx, orderIDs)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRecheckRepository_RecheckOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecheckOrders'
+type MockRecheckRepository_RecheckOrders_Call struct {
+	*mock.Call
+}
+
+// RecheckOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderIDs []string
+func (_e *MockRecheckRepository_Expecter) RecheckOrders(ctx interface{}, orderIDs interface{}) *MockRecheckRepository_RecheckOrders_Call {
+	return &MockRecheckRepository_RecheckOrders_Call{Call: _e.mock.On("RecheckOrders", ctx, orderIDs)}
+}
+
+func (_c *MockRecheckRepository_RecheckOrders_Call) Run(run func(ctx context.Context, orderIDs []string)) *MockRecheckRepository_RecheckOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRecheckRepository_RecheckOrders_Call) Return(n int64, err error) *MockRecheckRepository_RecheckOrders_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockRecheckRepository_RecheckOrders_Call) RunAndReturn(run func(ctx context.Context, orderIDs []string) (int64, error)) *MockRecheckRepository_RecheckOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
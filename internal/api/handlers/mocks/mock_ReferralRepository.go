@@ -0,0 +1,105 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/talx-hub/gopher-bonus/internal/model/referral"
+)
+
+// NewMockReferralRepository creates a new instance of MockReferralRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockReferralRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockReferralRepository {
+	mock := &MockReferralRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockReferralRepository is an autogenerated mock type for the ReferralRepository type
+type MockReferralRepository struct {
+	mock.Mock
+}
+
+type MockReferralRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockReferralRepository) EXPECT() *MockReferralRepository_Expecter {
+	return &MockReferralRepository_Expecter{mock: &_m.Mock}
+}
+
+// GetSummary provides a mock function for the type MockReferralRepository
+func (_mock *MockReferralRepository) GetSummary(ctx context.Context, userID string) (referral.Summary, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSummary")
+	}
+
+	var r0 referral.Summary
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (referral.Summary, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) referral.Summary); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(referral.Summary)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReferralRepository_GetSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSummary'
+type MockReferralRepository_GetSummary_Call struct {
+	*mock.Call
+}
+
+// GetSummary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockReferralRepository_Expecter) GetSummary(ctx interface{}, userID interface{}) *MockReferralRepository_GetSummary_Call {
+	return &MockReferralRepository_GetSummary_Call{Call: _e.mock.On("GetSummary", ctx, userID)}
+}
+
+func (_c *MockReferralRepository_GetSummary_Call) Run(run func(ctx context.Context, userID string)) *MockReferralRepository_GetSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockReferralRepository_GetSummary_Call) Return(summary referral.Summary, err error) *MockReferralRepository_GetSummary_Call {
+	_c.Call.Return(summary, err)
+	return _c
+}
+
+func (_c *MockReferralRepository_GetSummary_Call) RunAndReturn(run func(ctx context.Context, userID string) (referral.Summary, error)) *MockReferralRepository_GetSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
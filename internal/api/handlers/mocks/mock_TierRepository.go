@@ -0,0 +1,234 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/tier"
+)
+
+// NewMockTierRepository creates a new instance of MockTierRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTierRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTierRepository {
+	mock := &MockTierRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTierRepository is an autogenerated mock type for the TierRepository type
+type MockTierRepository struct {
+	mock.Mock
+}
+
+type MockTierRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTierRepository) EXPECT() *MockTierRepository_Expecter {
+	return &MockTierRepository_Expecter{mock: &_m.Mock}
+}
+
+// GetRollingPoints provides a mock function for the type MockTierRepository
+func (_mock *MockTierRepository) GetRollingPoints(ctx context.Context, userID string) (model.Amount, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRollingPoints")
+	}
+
+	var r0 model.Amount
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (model.Amount, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) model.Amount); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(model.Amount)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTierRepository_GetRollingPoints_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRollingPoints'
+type MockTierRepository_GetRollingPoints_Call struct {
+	*mock.Call
+}
+
+// GetRollingPoints is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTierRepository_Expecter) GetRollingPoints(ctx interface{}, userID interface{}) *MockTierRepository_GetRollingPoints_Call {
+	return &MockTierRepository_GetRollingPoints_Call{Call: _e.mock.On("GetRollingPoints", ctx, userID)}
+}
+
+func (_c *MockTierRepository_GetRollingPoints_Call) Run(run func(ctx context.Context, userID string)) *MockTierRepository_GetRollingPoints_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTierRepository_GetRollingPoints_Call) Return(amount model.Amount, err error) *MockTierRepository_GetRollingPoints_Call {
+	_c.Call.Return(amount, err)
+	return _c
+}
+
+func (_c *MockTierRepository_GetRollingPoints_Call) RunAndReturn(run func(ctx context.Context, userID string) (model.Amount, error)) *MockTierRepository_GetRollingPoints_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserTier provides a mock function for the type MockTierRepository
+func (_mock *MockTierRepository) GetUserTier(ctx context.Context, userID string) (tier.Tier, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserTier")
+	}
+
+	var r0 tier.Tier
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (tier.Tier, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) tier.Tier); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(tier.Tier)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTierRepository_GetUserTier_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserTier'
+type MockTierRepository_GetUserTier_Call struct {
+	*mock.Call
+}
+
+// GetUserTier is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTierRepository_Expecter) GetUserTier(ctx interface{}, userID interface{}) *MockTierRepository_GetUserTier_Call {
+	return &MockTierRepository_GetUserTier_Call{Call: _e.mock.On("GetUserTier", ctx, userID)}
+}
+
+func (_c *MockTierRepository_GetUserTier_Call) Run(run func(ctx context.Context, userID string)) *MockTierRepository_GetUserTier_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTierRepository_GetUserTier_Call) Return(tier1 tier.Tier, err error) *MockTierRepository_GetUserTier_Call {
+	_c.Call.Return(tier1, err)
+	return _c
+}
+
+func (_c *MockTierRepository_GetUserTier_Call) RunAndReturn(run func(ctx context.Context, userID string) (tier.Tier, error)) *MockTierRepository_GetUserTier_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTiers provides a mock function for the type MockTierRepository
+func (_mock *MockTierRepository) ListTiers(ctx context.Context) ([]tier.Tier, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTiers")
+	}
+
+	var r0 []tier.Tier
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]tier.Tier, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []tier.Tier); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]tier.Tier)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTierRepository_ListTiers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTiers'
+type MockTierRepository_ListTiers_Call struct {
+	*mock.Call
+}
+
+// ListTiers is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTierRepository_Expecter) ListTiers(ctx interface{}) *MockTierRepository_ListTiers_Call {
+	return &MockTierRepository_ListTiers_Call{Call: _e.mock.On("ListTiers", ctx)}
+}
+
+func (_c *MockTierRepository_ListTiers_Call) Run(run func(ctx context.Context)) *MockTierRepository_ListTiers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTierRepository_ListTiers_Call) Return(tiers []tier.Tier, err error) *MockTierRepository_ListTiers_Call {
+	_c.Call.Return(tiers, err)
+	return _c
+}
+
+func (_c *MockTierRepository_ListTiers_Call) RunAndReturn(run func(ctx context.Context) ([]tier.Tier, error)) *MockTierRepository_ListTiers_Call {
+	_c.Call.Return(run)
+	return _c
+}
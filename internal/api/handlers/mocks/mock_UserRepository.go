@@ -283,3 +283,66 @@ func (_c *MockUserRepository_FindByLogin_Call) RunAndReturn(run func(ctx context
 	_c.Call.Return(run)
 	return _c
 }
+
+// SetRole provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) SetRole(ctx context.Context, id string, role user.Role) error {
+	ret := _mock.Called(ctx, id, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, user.Role) error); ok {
+		r0 = returnFunc(ctx, id, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_SetRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRole'
+type MockUserRepository_SetRole_Call struct {
+	*mock.Call
+}
+
+// SetRole is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - role user.Role
+func (_e *MockUserRepository_Expecter) SetRole(ctx interface{}, id interface{}, role interface{}) *MockUserRepository_SetRole_Call {
+	return &MockUserRepository_SetRole_Call{Call: _e.mock.On("SetRole", ctx, id, role)}
+}
+
+func (_c *MockUserRepository_SetRole_Call) Run(run func(ctx context.Context, id string, role user.Role)) *MockUserRepository_SetRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 user.Role
+		if args[2] != nil {
+			arg2 = args[2].(user.Role)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_SetRole_Call) Return(err error) *MockUserRepository_SetRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_SetRole_Call) RunAndReturn(run func(ctx context.Context, id string, role user.Role) error) *MockUserRepository_SetRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/dto"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/referral"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type ReferralRepository interface {
+	GetSummary(ctx context.Context, userID string) (referral.Summary, error)
+}
+
+type ReferralHandler struct {
+	userRetriever
+	logger       *slog.Logger
+	referralRepo ReferralRepository
+	userRepo     UserRepository
+}
+
+func NewReferralHandler(
+	userRepo UserRepository, referralRepo ReferralRepository, log *slog.Logger,
+) *ReferralHandler {
+	return &ReferralHandler{
+		logger:       log,
+		referralRepo: referralRepo,
+		userRepo:     userRepo,
+	}
+}
+
+func (h *ReferralHandler) GetReferrals(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.retrieveUserID(r.Context(), h.userRepo)
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			errRetrieveUserID,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(),
+			http.StatusInternalServerError)
+		return
+	}
+
+	summary, err := h.referralRepo.GetSummary(r.Context(), userID)
+	if err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to get referral summary",
+			slog.String("user_id", userID),
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	earned := summary.Earned()
+	response := dto.ReferralsResponse{
+		Code:     summary.Code,
+		Earned:   json.Number(earned.String()),
+		Invitees: make([]dto.InviteeResponse, len(summary.Invitees)),
+	}
+	for i, inv := range summary.Invitees {
+		response.Invitees[i] = dto.InviteeResponse{
+			InvitedAt:  inv.InvitedAt,
+			RewardedAt: inv.RewardedAt,
+			Earned:     json.Number(inv.Earned.String()),
+		}
+	}
+
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			failedWriteResponseMsg,
+			slog.Any(model.KeyLoggerError, err),
+		)
+		http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+		return
+	}
+}
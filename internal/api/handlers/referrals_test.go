@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/api/handlers/mocks"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/referral"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func TestAuthHandler_Register_referral(t *testing.T) {
+	tests := []struct {
+		name             string
+		referralCode     string
+		createErr        error
+		wantReferrerCode string
+		wantCode         int
+	}{
+		{
+			name:             "with referral code",
+			referralCode:     " abcd2345 ",
+			wantReferrerCode: "ABCD2345",
+			wantCode:         http.StatusOK,
+		},
+		{
+			name:     "without referral code",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:             "unknown code",
+			referralCode:     "ZZZZ9999",
+			createErr:        fmt.Errorf("%w: ZZZZ9999", serviceerrs.ErrUnknownReferralCode),
+			wantReferrerCode: "ZZZZ9999",
+			wantCode:         http.StatusBadRequest,
+		},
+		{
+			name:             "referral loop",
+			referralCode:     "ABCD2345",
+			createErr:        fmt.Errorf("%w: referral loop", serviceerrs.ErrReferralNotAllowed),
+			wantReferrerCode: "ABCD2345",
+			wantCode:         http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockUserRepository(t)
+			repo.EXPECT().Exists(mock.Anything, mock.Anything).Return(false)
+			repo.EXPECT().
+				Create(mock.Anything, mock.Anything).
+				RunAndReturn(func(_ context.Context, u *user.User) error {
+					assert.Len(t, u.ReferralCode, referral.CodeLength)
+					assert.Equal(t, tt.wantReferrerCode, u.ReferrerCode)
+					return tt.createErr
+				})
+
+			authHandler := NewAuthHandler(repo, slog.Default(), "super-secret-key")
+			body := fmt.Sprintf(
+				`{"login":"login","password":"very-strong-password","referral_code":%q}`,
+				tt.referralCode)
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+			authHandler.Register(rr, req)
+			res := rr.Result()
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestReferralHandler_GetReferrals(t *testing.T) {
+	rewardedAt := time.Date(2026, 5, 2, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name               string
+		userID             string
+		mockRetrieveUserID func() (user.User, error)
+		mockGetSummary     func() (referral.Summary, error)
+		wantCode           int
+		resp               string
+	}{
+		{
+			name:   "with invitees",
+			userID: "user-1",
+			mockRetrieveUserID: func() (user.User, error) {
+				return user.User{ID: "user-1"}, nil
+			},
+			mockGetSummary: func() (referral.Summary, error) {
+				return referral.Summary{
+					Code: "ABCD2345",
+					Invitees: []referral.Invitee{
+						{
+							InvitedAt:  time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC),
+							RewardedAt: &rewardedAt,
+							Earned:     model.NewAmount(100, 0),
+						},
+						{
+							InvitedAt: time.Date(2026, 5, 3, 10, 0, 0, 0, time.UTC),
+							Earned:    model.NewAmount(0, 0),
+						},
+					},
+				}, nil
+			},
+			wantCode: http.StatusOK,
+			resp: `{"referral_code":"ABCD2345","earned":100,"invitees":[` +
+				`{"invited_at":"2026-05-01T10:00:00Z","rewarded_at":"2026-05-02T10:00:00Z","earned":100},` +
+				`{"invited_at":"2026-05-03T10:00:00Z","earned":0}]}`,
+		},
+		{
+			name:   "no invitees",
+			userID: "user-2",
+			mockRetrieveUserID: func() (user.User, error) {
+				return user.User{ID: "user-2"}, nil
+			},
+			mockGetSummary: func() (referral.Summary, error) {
+				return referral.Summary{Code: "QWER2345"}, nil
+			},
+			wantCode: http.StatusOK,
+			resp:     `{"referral_code":"QWER2345","earned":0,"invitees":[]}`,
+		},
+		{
+			name:   "repo failure",
+			userID: "user-3",
+			mockRetrieveUserID: func() (user.User, error) {
+				return user.User{ID: "user-3"}, nil
+			},
+			mockGetSummary: func() (referral.Summary, error) {
+				return referral.Summary{}, serviceerrs.ErrUnexpected
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "middleware failure: no user in ctx",
+			userID:   "dont-put-to-ctx",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	userRepo := mocks.NewMockUserRepository(t)
+	referralRepo := mocks.NewMockReferralRepository(t)
+	h := NewReferralHandler(userRepo, referralRepo, slog.Default())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockRetrieveUserID != nil {
+				u, err := tt.mockRetrieveUserID()
+				userRepo.EXPECT().
+					FindByID(mock.Anything, tt.userID).
+					Return(u, err)
+			}
+			if tt.mockGetSummary != nil {
+				summary, err := tt.mockGetSummary()
+				referralRepo.EXPECT().
+					GetSummary(mock.Anything, tt.userID).
+					Return(summary, err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/referrals", http.NoBody)
+			if tt.userID != "dont-put-to-ctx" {
+				userIDCtx := context.WithValue(
+					req.Context(), model.KeyContextUserID, tt.userID)
+				req = req.WithContext(userIDCtx)
+			}
+			rr := httptest.NewRecorder()
+			h.GetReferrals(rr, req)
+			res := rr.Result()
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			if tt.wantCode == http.StatusOK {
+				assert.JSONEq(t, tt.resp, string(body))
+			}
+		})
+	}
+}
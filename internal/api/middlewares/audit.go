@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
+)
+
+// Audit logs every request of the staff API with the acting user.
+func Audit(log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		auditFunc := func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			actorID, _ := r.Context().Value(model.KeyContextUserID).(string)
+			role, _ := r.Context().Value(model.KeyContextUserRole).(user.Role)
+			log.LogAttrs(r.Context(),
+				slog.LevelInfo,
+				"admin action",
+				slog.String("actor_id", actorID),
+				slog.String("role", string(role)),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", ww.Status()),
+				slog.Duration("duration", time.Since(start)),
+			)
+		}
+		return http.HandlerFunc(auditFunc)
+	}
+}
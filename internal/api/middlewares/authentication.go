@@ -38,8 +38,10 @@ func Authentication(secret []byte, log *slog.Logger) func(http.Handler) http.Han
 			initial := r.Context()
 			idCtx := context.WithValue(
 				initial, model.KeyContextUserID, claims.UserID)
+			roleCtx := context.WithValue(
+				idCtx, model.KeyContextUserRole, claims.Role)
 
-			rWithID := r.WithContext(idCtx)
+			rWithID := r.WithContext(roleCtx)
 			next.ServeHTTP(w, rWithID)
 		}
 		return http.HandlerFunc(authFunc)
@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type RoleSource interface {
+	FindByID(ctx context.Context, id string) (user.User, error)
+}
+
+// StoredRole заменяет роль из токена ролью, сохранённой у пользователя:
+// так снятая роль перестаёт действовать сразу, а не когда истечёт выданный токен.
+// Должен стоять после Authentication и перед RequireRole.
+func StoredRole(users RoleSource, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		roleFunc := func(w http.ResponseWriter, r *http.Request) {
+			userID, _ := r.Context().Value(model.KeyContextUserID).(string)
+			u, err := users.FindByID(r.Context(), userID)
+			if err != nil && errors.Is(err, serviceerrs.ErrNotFound) {
+				http.Error(w, "authentication failed", http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				log.LogAttrs(r.Context(),
+					slog.LevelError,
+					"failed to get stored user role",
+					slog.String("user_id", userID),
+					slog.Any(model.KeyLoggerError, err),
+				)
+				http.Error(w, serviceerrs.ErrUnexpected.Error(), http.StatusInternalServerError)
+				return
+			}
+			ctx := context.WithValue(r.Context(), model.KeyContextUserRole, u.Role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(roleFunc)
+	}
+}
+
+// RequireRole пропускает только пользователей с одной из перечисленных ролей.
+// Должен стоять после Authentication.
+func RequireRole(log *slog.Logger, roles ...user.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		roleFunc := func(w http.ResponseWriter, r *http.Request) {
+			role, ok := r.Context().Value(model.KeyContextUserRole).(user.Role)
+			if !ok || !slices.Contains(roles, role) {
+				userID, _ := r.Context().Value(model.KeyContextUserID).(string)
+				log.LogAttrs(r.Context(),
+					slog.LevelWarn,
+					"access denied",
+					slog.String("user_id", userID),
+					slog.String("role", string(role)),
+					slog.String("path", r.URL.Path),
+				)
+				http.Error(w, "access denied", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(roleFunc)
+	}
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type HTTPMetrics interface {
+	ObserveHTTPRequest(route, method string, code int, d time.Duration)
+}
+
+// Metrics считает запросы по шаблону маршрута chi, а не по пути:
+// иначе /api/admin/users/{userID} дал бы по серии на каждого пользователя.
+func Metrics(m HTTPMetrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		metricsFunc := func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			route := "unmatched"
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			m.ObserveHTTPRequest(route, r.Method, ww.Status(), time.Since(start))
+		}
+		return http.HandlerFunc(metricsFunc)
+	}
+}
@@ -0,0 +1,143 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+const (
+	HeaderAccrualTimestamp = "X-Accrual-Timestamp"
+	HeaderAccrualSignature = "X-Accrual-Signature"
+	// MaxAccrualCallbackBody -- тело читается до проверки подписи, поэтому его размер ограничен
+	MaxAccrualCallbackBody = 8 << 10
+)
+
+// ReplayStore помнит подписи принятых callback'ов. Remember возвращает false,
+// если подпись уже принята и ещё не истекла.
+type ReplayStore interface {
+	Remember(ctx context.Context, signature string, expiresAt time.Time) (bool, error)
+}
+
+// SignAccrualCallback возвращает hex(HMAC-SHA256(secret, timestamp + "." + body)).
+func SignAccrualCallback(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAccrualSignature пропускает только подписанные accrual запросы
+// с меткой времени не старше tolerance. Повтор уже принятой подписи
+// в пределах tolerance отклоняется. Без общего для реплик replays
+// повтор отклоняет только реплика, принявшая callback первой.
+func VerifyAccrualSignature(secret []byte, tolerance time.Duration, replays ReplayStore, log *slog.Logger,
+) func(http.Handler) http.Handler {
+	if replays == nil {
+		replays = &replayCache{seen: make(map[string]time.Time)}
+	}
+	return func(next http.Handler) http.Handler {
+		verifyFunc := func(w http.ResponseWriter, r *http.Request) {
+			reject := func(msg string, attrs ...slog.Attr) {
+				log.LogAttrs(r.Context(), slog.LevelWarn, msg, attrs...)
+				http.Error(w, "invalid signature", http.StatusUnauthorized)
+			}
+
+			timestamp := r.Header.Get(HeaderAccrualTimestamp)
+			unix, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				reject("invalid accrual callback timestamp", slog.String("timestamp", timestamp))
+				return
+			}
+			now := time.Now()
+			sent := time.Unix(unix, 0)
+			if sent.Before(now.Add(-tolerance)) || sent.After(now.Add(tolerance)) {
+				reject("stale accrual callback", slog.String("timestamp", timestamp))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxAccrualCallbackBody))
+			var tooLarge *http.MaxBytesError
+			if err != nil && errors.As(err, &tooLarge) {
+				log.LogAttrs(r.Context(),
+					slog.LevelWarn,
+					"accrual callback body is too large",
+					slog.Int64("limit", tooLarge.Limit),
+				)
+				http.Error(w, "request body is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				log.LogAttrs(r.Context(),
+					slog.LevelError,
+					"failed to read the request body",
+					slog.Any(model.KeyLoggerError, err),
+				)
+				http.Error(w, "failed to read the request body", http.StatusBadRequest)
+				return
+			}
+			_ = r.Body.Close()
+
+			signature := r.Header.Get(HeaderAccrualSignature)
+			want := SignAccrualCallback(secret, timestamp, body)
+			if !hmac.Equal([]byte(signature), []byte(want)) {
+				reject("accrual callback signature mismatch")
+				return
+			}
+			fresh, err := replays.Remember(r.Context(), signature, sent.Add(tolerance))
+			if err != nil {
+				log.LogAttrs(r.Context(),
+					slog.LevelError,
+					"failed to check accrual callback replay",
+					slog.Any(model.KeyLoggerError, err),
+				)
+				// accrual повторит callback позже
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			if !fresh {
+				reject("replayed accrual callback", slog.String("timestamp", timestamp))
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(verifyFunc)
+	}
+}
+
+// replayCache помнит принятые подписи в памяти реплики, пока их метка времени проходит по tolerance.
+type replayCache struct {
+	seen map[string]time.Time
+	mu   sync.Mutex
+}
+
+func (c *replayCache) Remember(_ context.Context, signature string, expiresAt time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for s, exp := range c.seen {
+		if exp.Before(now) {
+			delete(c.seen, s)
+		}
+	}
+	if _, ok := c.seen[signature]; ok {
+		return false, nil
+	}
+	c.seen[signature] = expiresAt
+	return true, nil
+}
@@ -0,0 +1,35 @@
+package accrual
+
+import "time"
+
+// ProviderState -- то, что видно оператору о провайдере на одной реплике.
+type ProviderState struct {
+	// RetryAfter -- до какого момента accrual просил не опрашивать его после 429
+	RetryAfter  time.Time
+	Name        string
+	MaxRequests uint64
+	// RateLimit -- допустимый темп запросов в минуту, 0 -- без ограничения
+	RateLimit uint64
+	// RPM -- сколько запросов отправлено за последнюю минуту
+	RPM uint64
+	// InFlight -- заказы, отданные провайдеру и ещё не получившие ответа
+	InFlight int64
+	Workers  int
+	// Throttled -- пауза после 429
+	Throttled bool
+}
+
+// Control -- команды оператора провайдеру, общие для всех реплик.
+type Control struct {
+	// MaxRequests -- число одновременных запросов, 0 -- как в конфигурации реплики
+	MaxRequests uint64
+	Held        bool
+}
+
+// Replica -- состояние опроса accrual на одной реплике, каким она сообщила его последний раз.
+type Replica struct {
+	ReportedAt time.Time
+	InstanceID string
+	Providers  []ProviderState
+	Paused     bool
+}
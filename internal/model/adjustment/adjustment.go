@@ -0,0 +1,74 @@
+package adjustment
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+type Direction string
+
+const (
+	DirectionCredit Direction = "credit"
+	DirectionDebit  Direction = "debit"
+)
+
+type Reason string
+
+const (
+	ReasonCompensation  Reason = "compensation"
+	ReasonCorrection    Reason = "correction"
+	ReasonFraudReversal Reason = "fraud_reversal"
+	ReasonLostAccrual   Reason = "lost_accrual"
+	ReasonOther         Reason = "other"
+)
+
+func (r Reason) IsValid() bool {
+	switch r {
+	case ReasonCompensation, ReasonCorrection, ReasonFraudReversal,
+		ReasonLostAccrual, ReasonOther:
+		return true
+	default:
+		return false
+	}
+}
+
+type Adjustment struct {
+	CreatedAt time.Time
+	UserID    string
+	AdminID   string
+	Direction Direction
+	Reason    Reason
+	Comment   string
+	Amount    model.Amount
+	ID        int64
+	// списание без проверки остатка
+	Forced bool
+}
+
+const maxCommentLength = 1000
+
+func (a *Adjustment) Validate() error {
+	var errs []error
+	if a.Direction != DirectionCredit && a.Direction != DirectionDebit {
+		errs = append(errs, errors.New("unknown adjustment direction: "+string(a.Direction)))
+	}
+	if a.Amount.TotalKopecks() <= 0 {
+		errs = append(errs, errors.New("adjustment amount must be positive"))
+	}
+	if !a.Reason.IsValid() {
+		errs = append(errs, errors.New("unknown reason code: "+string(a.Reason)))
+	}
+	if strings.TrimSpace(a.Comment) == "" {
+		errs = append(errs, errors.New("comment is required"))
+	}
+	if len(a.Comment) > maxCommentLength {
+		errs = append(errs, errors.New("comment is too long"))
+	}
+	if a.Forced && a.Direction != DirectionDebit {
+		errs = append(errs, errors.New("force applies to debits only"))
+	}
+	return errors.Join(errs...)
+}
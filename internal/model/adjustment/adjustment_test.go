@@ -0,0 +1,50 @@
+package adjustment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+func TestAdjustment_Validate(t *testing.T) {
+	valid := func() Adjustment {
+		return Adjustment{
+			Direction: DirectionDebit,
+			Reason:    ReasonFraudReversal,
+			Comment:   "ticket #42",
+			Amount:    model.NewAmount(10, 0),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(a *Adjustment)
+		wantErr bool
+	}{
+		{"valid debit", func(*Adjustment) {}, false},
+		{"valid credit", func(a *Adjustment) { a.Direction = DirectionCredit }, false},
+		{"forced debit", func(a *Adjustment) { a.Forced = true }, false},
+		{"forced credit", func(a *Adjustment) {
+			a.Direction = DirectionCredit
+			a.Forced = true
+		}, true},
+		{"unknown direction", func(a *Adjustment) { a.Direction = "refund" }, true},
+		{"zero amount", func(a *Adjustment) { a.Amount = model.NewAmount(0, 0) }, true},
+		{"unknown reason", func(a *Adjustment) { a.Reason = "because" }, true},
+		{"blank comment", func(a *Adjustment) { a.Comment = "  " }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := valid()
+			tt.modify(&a)
+			err := a.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
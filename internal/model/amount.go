@@ -25,6 +25,11 @@ func NewAmount(roubles, kopeck int64) Amount {
 }
 
 func (a *Amount) String() string {
+	// после ручного списания баланс может уйти в минус
+	if a.TotalKopecks() < 0 {
+		abs := NewAmount(0, -a.TotalKopecks())
+		return "-" + abs.String()
+	}
 	if a.kopeck == 0 {
 		return strconv.FormatInt(a.roubles, 10)
 	}
@@ -181,6 +181,16 @@ func TestAmount_String(t *testing.T) {
 			input:    Amount{roubles: 123456789, kopeck: 1},
 			expected: "123456789.01",
 		},
+		{
+			name:     "negative with kopecks",
+			input:    NewAmount(0, -550),
+			expected: "-5.50",
+		},
+		{
+			name:     "negative kopecks only",
+			input:    NewAmount(0, -7),
+			expected: "-0.07",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,109 @@
+package campaign
+
+import (
+	"errors"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+type BonusType string
+
+const (
+	BonusMultiplier BonusType = "multiplier"
+	BonusFixed      BonusType = "fixed"
+)
+
+type Campaign struct {
+	StartsAt         time.Time
+	EndsAt           time.Time
+	RegisteredAfter  *time.Time
+	RegisteredBefore *time.Time
+	Name             string
+	MinTier          string
+	BonusType        BonusType
+	BonusValue       model.Amount
+	MinTierPoints    model.Amount
+	ID               int64
+	FirstOrderOnly   bool
+}
+
+// Facts describes the processed order the campaigns are evaluated against.
+type Facts struct {
+	ProcessedAt  time.Time
+	RegisteredAt time.Time
+	UserID       string
+	OrderID      string
+	Accrual      model.Amount
+	TierPoints   model.Amount
+	IsFirstOrder bool
+}
+
+type Credit struct {
+	CreditedAt time.Time
+	UserID     string
+	OrderID    string
+	Amount     model.Amount
+	CampaignID int64
+}
+
+const multiplierOne = 100 // множитель хранится в копейках: 1.00 -> 100
+
+func (c *Campaign) Validate() error {
+	var errs []error
+	if c.Name == "" {
+		errs = append(errs, errors.New("campaign name is empty"))
+	}
+	if !c.StartsAt.Before(c.EndsAt) {
+		errs = append(errs, errors.New("campaign must start before it ends"))
+	}
+	if c.RegisteredAfter != nil && c.RegisteredBefore != nil &&
+		!c.RegisteredAfter.Before(*c.RegisteredBefore) {
+		errs = append(errs, errors.New("registered_after must be before registered_before"))
+	}
+	switch c.BonusType {
+	case BonusMultiplier:
+		if c.BonusValue.TotalKopecks() <= multiplierOne {
+			errs = append(errs, errors.New("bonus multiplier must be greater than 1"))
+		}
+	case BonusFixed:
+		if c.BonusValue.TotalKopecks() <= 0 {
+			errs = append(errs, errors.New("fixed bonus must be positive"))
+		}
+	default:
+		errs = append(errs, errors.New("unknown bonus type: "+string(c.BonusType)))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Campaign) IsEligible(f *Facts) bool {
+	if f.ProcessedAt.Before(c.StartsAt) || !f.ProcessedAt.Before(c.EndsAt) {
+		return false
+	}
+	if c.FirstOrderOnly && !f.IsFirstOrder {
+		return false
+	}
+	if c.MinTier != "" && f.TierPoints.TotalKopecks() < c.MinTierPoints.TotalKopecks() {
+		return false
+	}
+	if c.RegisteredAfter != nil && f.RegisteredAt.Before(*c.RegisteredAfter) {
+		return false
+	}
+	if c.RegisteredBefore != nil && !f.RegisteredAt.Before(*c.RegisteredBefore) {
+		return false
+	}
+	return true
+}
+
+// Bonus returns the extra points on top of the accrual: a "double points"
+// multiplier of 2 credits the accrual once more.
+func (c *Campaign) Bonus(accrual model.Amount) model.Amount {
+	switch c.BonusType {
+	case BonusMultiplier:
+		extra := accrual.TotalKopecks() * (c.BonusValue.TotalKopecks() - multiplierOne) / multiplierOne
+		return model.NewAmount(0, extra)
+	case BonusFixed:
+		return c.BonusValue
+	}
+	return model.NewAmount(0, 0)
+}
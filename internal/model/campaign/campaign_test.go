@@ -0,0 +1,153 @@
+package campaign
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+func TestCampaign_IsEligible(t *testing.T) {
+	start := time.Date(2025, time.June, 7, 0, 0, 0, 0, time.UTC)
+	end := start.Add(48 * time.Hour)
+	registeredAfter := start.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		campaign Campaign
+		facts    Facts
+		want     bool
+	}{
+		{
+			name:     "inside the window",
+			campaign: Campaign{StartsAt: start, EndsAt: end},
+			facts:    Facts{ProcessedAt: start.Add(time.Hour)},
+			want:     true,
+		},
+		{
+			name:     "before the window",
+			campaign: Campaign{StartsAt: start, EndsAt: end},
+			facts:    Facts{ProcessedAt: start.Add(-time.Second)},
+			want:     false,
+		},
+		{
+			name:     "end is exclusive",
+			campaign: Campaign{StartsAt: start, EndsAt: end},
+			facts:    Facts{ProcessedAt: end},
+			want:     false,
+		},
+		{
+			name:     "first order only: first order",
+			campaign: Campaign{StartsAt: start, EndsAt: end, FirstOrderOnly: true},
+			facts:    Facts{ProcessedAt: start, IsFirstOrder: true},
+			want:     true,
+		},
+		{
+			name:     "first order only: repeated order",
+			campaign: Campaign{StartsAt: start, EndsAt: end, FirstOrderOnly: true},
+			facts:    Facts{ProcessedAt: start, IsFirstOrder: false},
+			want:     false,
+		},
+		{
+			name: "tier below minimum",
+			campaign: Campaign{
+				StartsAt: start, EndsAt: end,
+				MinTier: "silver", MinTierPoints: model.NewAmount(1000, 0),
+			},
+			facts: Facts{ProcessedAt: start, TierPoints: model.NewAmount(0, 0)},
+			want:  false,
+		},
+		{
+			name: "tier above minimum",
+			campaign: Campaign{
+				StartsAt: start, EndsAt: end,
+				MinTier: "silver", MinTierPoints: model.NewAmount(1000, 0),
+			},
+			facts: Facts{ProcessedAt: start, TierPoints: model.NewAmount(5000, 0)},
+			want:  true,
+		},
+		{
+			name:     "registered too early",
+			campaign: Campaign{StartsAt: start, EndsAt: end, RegisteredAfter: &registeredAfter},
+			facts:    Facts{ProcessedAt: start, RegisteredAt: registeredAfter.Add(-time.Hour)},
+			want:     false,
+		},
+		{
+			name:     "registered too late",
+			campaign: Campaign{StartsAt: start, EndsAt: end, RegisteredBefore: &registeredAfter},
+			facts:    Facts{ProcessedAt: start, RegisteredAt: registeredAfter},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.campaign.IsEligible(&tt.facts))
+		})
+	}
+}
+
+func TestCampaign_Bonus(t *testing.T) {
+	tests := []struct {
+		name     string
+		campaign Campaign
+		accrual  model.Amount
+		want     model.Amount
+	}{
+		{
+			name:     "double points",
+			campaign: Campaign{BonusType: BonusMultiplier, BonusValue: model.NewAmount(2, 0)},
+			accrual:  model.NewAmount(500, 50),
+			want:     model.NewAmount(500, 50),
+		},
+		{
+			name:     "one and a half, rounded down",
+			campaign: Campaign{BonusType: BonusMultiplier, BonusValue: model.NewAmount(1, 50)},
+			accrual:  model.NewAmount(0, 3),
+			want:     model.NewAmount(0, 1),
+		},
+		{
+			name:     "fixed bonus ignores accrual",
+			campaign: Campaign{BonusType: BonusFixed, BonusValue: model.NewAmount(100, 0)},
+			accrual:  model.NewAmount(0, 0),
+			want:     model.NewAmount(100, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.campaign.Bonus(tt.accrual))
+		})
+	}
+}
+
+func TestCampaign_Validate(t *testing.T) {
+	start := time.Date(2025, time.June, 7, 0, 0, 0, 0, time.UTC)
+
+	valid := Campaign{
+		Name:       "weekend",
+		StartsAt:   start,
+		EndsAt:     start.Add(time.Hour),
+		BonusType:  BonusMultiplier,
+		BonusValue: model.NewAmount(2, 0),
+	}
+	assert.NoError(t, valid.Validate())
+
+	noName := valid
+	noName.Name = ""
+	assert.Error(t, noName.Validate())
+
+	badWindow := valid
+	badWindow.EndsAt = start
+	assert.Error(t, badWindow.Validate())
+
+	badMultiplier := valid
+	badMultiplier.BonusValue = model.NewAmount(1, 0)
+	assert.Error(t, badMultiplier.Validate())
+
+	unknownType := valid
+	unknownType.BonusType = "percent"
+	assert.Error(t, unknownType.Validate())
+}
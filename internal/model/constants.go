@@ -15,5 +15,8 @@ type ContextKey string
 
 const KeyContextLogger ContextKey = "logger"
 const KeyContextUserID ContextKey = "userID"
+const KeyContextUserRole ContextKey = "userRole"
 
 const KeyLoggerError = "error"
+const KeyLoggerTraceID = "trace_id"
+const KeyLoggerSpanID = "span_id"
@@ -0,0 +1,25 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+type Kind string
+
+const (
+	KindAccrual       Kind = "accrual"
+	KindWithdrawal    Kind = "withdrawal"
+	KindCampaignBonus Kind = "campaign_bonus"
+	KindReferralBonus Kind = "referral_bonus"
+	KindAdjustment    Kind = "adjustment"
+)
+
+// Entry is a single balance movement; debits have a negative amount.
+type Entry struct {
+	At        time.Time
+	Kind      Kind
+	Reference string
+	Amount    model.Amount
+}
@@ -0,0 +1,52 @@
+package order
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff описывает, когда в следующий раз спрашивать accrual о заказе.
+type Backoff struct {
+	rand func() float64
+	// Base -- задержка после первого незавершённого ответа, дальше удваивается до Max
+	Base time.Duration
+	Max  time.Duration
+	// Horizon -- сколько ждать с момента загрузки, пока accrual не узнает о заказе
+	Horizon time.Duration
+	// MaxFailures -- после стольких неудачных опросов подряд заказ уходит в DEAD_LETTER;
+	// 0 -- опрашивать бесконечно
+	MaxFailures int32
+}
+
+func NewBackoff(base, maxDelay, horizon time.Duration) *Backoff {
+	return &Backoff{
+		rand:    rand.Float64,
+		Base:    base,
+		Max:     maxDelay,
+		Horizon: horizon,
+	}
+}
+
+// Delay возвращает задержку перед попыткой attempts+1: половина фиксирована,
+// половина случайна, чтобы заказы, загруженные одновременно, не опрашивались пачкой.
+func (b *Backoff) Delay(attempts int32) time.Duration {
+	d := b.Base
+	for i := int32(0); i < attempts && d < b.Max; i++ {
+		d *= 2
+	}
+	d = min(d, b.Max)
+	half := d / 2
+	return half + time.Duration(b.rand()*float64(d-half))
+}
+
+// Expired сообщает, что заказ, о котором accrual так и не узнал,
+// пора отдать на ручную проверку.
+func (b *Backoff) Expired(uploadedAt, now time.Time) bool {
+	return b.Horizon > 0 && now.Sub(uploadedAt) >= b.Horizon
+}
+
+// DeadLetter сообщает, что после failures неудачных опросов подряд
+// заказ больше не опрашивается автоматически.
+func (b *Backoff) DeadLetter(failures int32) bool {
+	return b.MaxFailures > 0 && failures >= b.MaxFailures
+}
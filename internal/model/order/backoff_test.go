@@ -0,0 +1,59 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := NewBackoff(time.Second, time.Minute, time.Hour)
+
+	b.rand = func() float64 { return 0 }
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{0, 500 * time.Millisecond},
+		{1, time.Second},
+		{3, 4 * time.Second},
+		{6, 30 * time.Second},
+		{7, 30 * time.Second},
+		{1000, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, b.Delay(tt.attempts), "attempts=%d", tt.attempts)
+	}
+
+	b.rand = func() float64 { return 0.999999 }
+	assert.InDelta(t, float64(time.Minute), float64(b.Delay(10)), float64(time.Millisecond))
+
+	b = NewBackoff(time.Second, time.Minute, time.Hour)
+	for range 100 {
+		d := b.Delay(2)
+		assert.GreaterOrEqual(t, d, 2*time.Second)
+		assert.Less(t, d, 4*time.Second)
+	}
+}
+
+func TestBackoff_Expired(t *testing.T) {
+	uploaded := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBackoff(time.Second, time.Minute, time.Hour)
+
+	assert.False(t, b.Expired(uploaded, uploaded.Add(59*time.Minute)))
+	assert.True(t, b.Expired(uploaded, uploaded.Add(time.Hour)))
+
+	b.Horizon = 0
+	assert.False(t, b.Expired(uploaded, uploaded.Add(1000*time.Hour)))
+}
+
+func TestBackoff_DeadLetter(t *testing.T) {
+	b := NewBackoff(time.Second, time.Minute, time.Hour)
+	assert.False(t, b.DeadLetter(1000))
+
+	b.MaxFailures = 3
+	assert.False(t, b.DeadLetter(2))
+	assert.True(t, b.DeadLetter(3))
+	assert.True(t, b.DeadLetter(4))
+}
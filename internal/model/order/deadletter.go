@@ -0,0 +1,28 @@
+package order
+
+import "time"
+
+// Check -- итог одного незавершённого опроса accrual о заказе.
+type Check struct {
+	// Err -- почему опрос не удался; пусто, если accrual ответил
+	Err string
+	// Answered -- accrual ответил о заказе; только ответ сбрасывает счётчик отказов подряд,
+	// а 429, занятость провайдера или остановка агента оставляют его как есть
+	Answered bool
+	// Unknown -- accrual ответил, что не знает о заказе; это тоже ответ
+	Unknown bool
+}
+
+func (c Check) Failed() bool {
+	return c.Err != ""
+}
+
+// DeadLetter -- заказ, снятый с автоматического опроса после череды ошибок.
+type DeadLetter struct {
+	UploadedAt     time.Time
+	DeadLetteredAt time.Time
+	ID             string
+	UserID         string
+	LastError      string
+	Failures       int32
+}
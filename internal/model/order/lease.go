@@ -0,0 +1,14 @@
+package order
+
+import "time"
+
+// Lease -- параметры захвата заказов на опрос accrual одной репликой.
+// Пока аренда не истекла, другие реплики заказ не выбирают.
+type Lease struct {
+	Owner     string
+	Duration  time.Duration
+	BatchSize int32
+	// MinAge -- заказы моложе MinAge не захватываются: в hybrid режиме
+	// сначала ждём push callback от accrual, опрос только подстраховка
+	MinAge time.Duration
+}
@@ -15,6 +15,10 @@ const (
 	StatusProcessing Status = "PROCESSING"
 	StatusInvalid    Status = "INVALID"
 	StatusProcessed  Status = "PROCESSED"
+	// accrual так и не узнал о заказе, нужна ручная проверка
+	StatusReview Status = "REVIEW"
+	// опрос accrual о заказе раз за разом заканчивается ошибкой
+	StatusDeadLetter Status = "DEAD_LETTER"
 )
 
 type Type string
@@ -40,6 +44,10 @@ func (o *Order) MarshalJSON() ([]byte, error) {
 	case TypeAccrual:
 		data["number"] = o.ID
 		data["status"] = o.Status
+		if o.Status == StatusReview || o.Status == StatusDeadLetter {
+			// для пользователя заказ всё ещё в обработке
+			data["status"] = StatusProcessing
+		}
 		if o.Status == StatusProcessed {
 			data["accrual"] = json.Number(o.Amount.String())
 		}
@@ -0,0 +1,68 @@
+package order
+
+import (
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+// Settled -- заказ с окончательным ответом accrual, который сверяется заново:
+// accrual может пересчитать начисление в любой момент.
+type Settled struct {
+	ID     string
+	UserID string
+	Status Status
+	// RawAmount -- начисление accrual, Amount -- оно же с множителем уровня пользователя
+	// вместе с корректировками прошлых сверок
+	RawAmount model.Amount
+	Amount    model.Amount
+}
+
+// Discrepancy -- расхождение сохранённого результата заказа с тем, что accrual отвечает сейчас.
+type Discrepancy struct {
+	FoundAt time.Time
+	Stored  Settled
+	// AccrualStatus -- статус из ответа accrual, NO_CONTENT -- accrual не знает о заказе
+	AccrualStatus string
+	AccrualAmount model.Amount
+	ID            int64
+	// AdjustmentID -- корректировка, исправившая баланс; 0 -- не исправлено
+	AdjustmentID int64
+}
+
+// Compare сверяет заказ с окончательным ответом accrual. false -- расхождения нет.
+func (s *Settled) Compare(accrualStatus Status, accrualAmount model.Amount) (Discrepancy, bool) {
+	d := Discrepancy{
+		Stored:        *s,
+		AccrualStatus: string(accrualStatus),
+		AccrualAmount: accrualAmount,
+	}
+	switch {
+	case accrualStatus != s.Status:
+		return d, true
+	case accrualStatus == StatusProcessed:
+		return d, accrualAmount.TotalKopecks() != s.RawAmount.TotalKopecks()
+	default:
+		return d, false
+	}
+}
+
+// Correction -- на сколько поправить баланс, чтобы начисление по заказу совпало с ответом accrual.
+// Используется тот же множитель уровня, что был применён к заказу; если начисления не было,
+// множитель неизвестен и считается равным 1. Для неокончательных ответов accrual поправки нет.
+func (d *Discrepancy) Correction() model.Amount {
+	var want int64
+	switch Status(d.AccrualStatus) {
+	case StatusInvalid:
+		want = 0
+	case StatusProcessed:
+		want = d.AccrualAmount.TotalKopecks()
+		if raw := d.Stored.RawAmount.TotalKopecks(); raw > 0 {
+			// округление до копейки, как в UpdateAccrualStatus
+			want = (2*want*d.Stored.Amount.TotalKopecks() + raw) / (2 * raw)
+		}
+	default:
+		return model.NewAmount(0, 0)
+	}
+	return model.NewAmount(0, want-d.Stored.Amount.TotalKopecks())
+}
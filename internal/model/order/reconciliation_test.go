@@ -0,0 +1,52 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+func TestSettled_Compare(t *testing.T) {
+	processed := Settled{
+		ID:        "1",
+		Status:    StatusProcessed,
+		RawAmount: model.NewAmount(500, 0),
+		Amount:    model.NewAmount(550, 0),
+	}
+	invalid := Settled{ID: "2", Status: StatusInvalid}
+	tests := []struct {
+		name    string
+		stored  Settled
+		status  Status
+		amount  model.Amount
+		want    bool
+		wantFix model.Amount
+	}{
+		{"same accrual", processed, StatusProcessed, model.NewAmount(500, 0), false, model.NewAmount(0, 0)},
+		{"accrual raised", processed, StatusProcessed, model.NewAmount(600, 0), true, model.NewAmount(110, 0)},
+		{"accrual lowered", processed, StatusProcessed, model.NewAmount(400, 1), true, model.NewAmount(-109, -99)},
+		{"became invalid", processed, StatusInvalid, model.NewAmount(0, 0), true, model.NewAmount(-550, 0)},
+		{"still invalid", invalid, StatusInvalid, model.NewAmount(0, 0), false, model.NewAmount(0, 0)},
+		{"became processed", invalid, StatusProcessed, model.NewAmount(70, 50), true, model.NewAmount(70, 50)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, found := tt.stored.Compare(tt.status, tt.amount)
+			assert.Equal(t, tt.want, found)
+			fix := d.Correction()
+			assert.Equal(t, tt.wantFix.TotalKopecks(), fix.TotalKopecks())
+		})
+	}
+}
+
+func TestDiscrepancy_Correction_unknownOrder(t *testing.T) {
+	d := Discrepancy{
+		Stored:        Settled{Status: StatusProcessed, RawAmount: model.NewAmount(10, 0), Amount: model.NewAmount(10, 0)},
+		AccrualStatus: "NO_CONTENT",
+	}
+	// accrual забыл о заказе -- это повод разобраться, а не списывать баллы
+	fix := d.Correction()
+	assert.Zero(t, fix.TotalKopecks())
+}
@@ -0,0 +1,55 @@
+package referral
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+// без 0/O и 1/I, чтобы код было проще продиктовать.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const CodeLength = 8
+
+func NewCode() (string, error) {
+	buf := make([]byte, CodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate referral code: %w", err)
+	}
+	for i := range buf {
+		buf[i] = codeAlphabet[int(buf[i])%len(codeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+type Invitee struct {
+	InvitedAt  time.Time
+	RewardedAt *time.Time
+	Earned     model.Amount
+}
+
+type Summary struct {
+	Code     string
+	Invitees []Invitee
+}
+
+func (s *Summary) Earned() model.Amount {
+	var total int64
+	for i := range s.Invitees {
+		total += s.Invitees[i].Earned.TotalKopecks()
+	}
+	return model.NewAmount(0, total)
+}
+
+type Reward struct {
+	ReferrerID string
+	RefereeID  string
+	ReferralID int64
+}
@@ -0,0 +1,43 @@
+package referral
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+func TestNewCode(t *testing.T) {
+	seen := make(map[string]struct{})
+	for range 100 {
+		code, err := NewCode()
+		require.NoError(t, err)
+		assert.Len(t, code, CodeLength)
+		for _, r := range code {
+			assert.True(t, strings.ContainsRune(codeAlphabet, r), "unexpected rune %q", r)
+		}
+		seen[code] = struct{}{}
+	}
+	assert.Greater(t, len(seen), 90)
+}
+
+func TestNormalizeCode(t *testing.T) {
+	assert.Equal(t, "ABCD2345", NormalizeCode("  abcd2345\n"))
+	assert.Empty(t, NormalizeCode("   "))
+}
+
+func TestSummary_Earned(t *testing.T) {
+	s := Summary{
+		Code: "ABCD2345",
+		Invitees: []Invitee{
+			{Earned: model.NewAmount(100, 0)},
+			{Earned: model.NewAmount(0, 0)},
+			{Earned: model.NewAmount(50, 50)},
+		},
+	}
+	earned := s.Earned()
+	assert.Equal(t, "150.50", earned.String())
+}
@@ -0,0 +1,37 @@
+package tier
+
+import "github.com/talx-hub/gopher-bonus/internal/model"
+
+type Tier struct {
+	Name       string
+	MinPoints  model.Amount
+	Multiplier model.Amount
+}
+
+type Progress struct {
+	Current       Tier
+	Next          *Tier
+	RollingPoints model.Amount
+	PointsToNext  model.Amount
+}
+
+// CalculateProgress expects tiers to be sorted by MinPoints ascending.
+func CalculateProgress(tiers []Tier, current Tier, points model.Amount) Progress {
+	p := Progress{
+		Current:       current,
+		RollingPoints: points,
+	}
+	for i := range tiers {
+		if tiers[i].MinPoints.TotalKopecks() <= current.MinPoints.TotalKopecks() {
+			continue
+		}
+		next := tiers[i]
+		p.Next = &next
+		left := next.MinPoints.TotalKopecks() - points.TotalKopecks()
+		if left > 0 {
+			p.PointsToNext = model.NewAmount(0, left)
+		}
+		break
+	}
+	return p
+}
@@ -0,0 +1,76 @@
+package tier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+func TestCalculateProgress(t *testing.T) {
+	bronze := Tier{Name: "bronze", MinPoints: model.NewAmount(0, 0), Multiplier: model.NewAmount(1, 0)}
+	silver := Tier{Name: "silver", MinPoints: model.NewAmount(1000, 0), Multiplier: model.NewAmount(1, 10)}
+	gold := Tier{Name: "gold", MinPoints: model.NewAmount(5000, 0), Multiplier: model.NewAmount(1, 25)}
+	tiers := []Tier{bronze, silver, gold}
+
+	tests := []struct {
+		name         string
+		current      Tier
+		points       model.Amount
+		wantNext     *Tier
+		wantPointsTo model.Amount
+	}{
+		{
+			name:         "bronze without points",
+			current:      bronze,
+			points:       model.NewAmount(0, 0),
+			wantNext:     &silver,
+			wantPointsTo: model.NewAmount(1000, 0),
+		},
+		{
+			name:         "bronze with kopecks",
+			current:      bronze,
+			points:       model.NewAmount(999, 99),
+			wantNext:     &silver,
+			wantPointsTo: model.NewAmount(0, 1),
+		},
+		{
+			name:         "bronze waiting for recalculation",
+			current:      bronze,
+			points:       model.NewAmount(1200, 0),
+			wantNext:     &silver,
+			wantPointsTo: model.NewAmount(0, 0),
+		},
+		{
+			name:         "silver",
+			current:      silver,
+			points:       model.NewAmount(3000, 50),
+			wantNext:     &gold,
+			wantPointsTo: model.NewAmount(1999, 50),
+		},
+		{
+			name:         "gold is the top tier",
+			current:      gold,
+			points:       model.NewAmount(7000, 0),
+			wantNext:     nil,
+			wantPointsTo: model.NewAmount(0, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := CalculateProgress(tiers, tt.current, tt.points)
+			assert.Equal(t, tt.current, p.Current)
+			assert.Equal(t, tt.points, p.RollingPoints)
+			assert.Equal(t, tt.wantPointsTo, p.PointsToNext)
+			if tt.wantNext == nil {
+				assert.Nil(t, p.Next)
+				return
+			}
+			require.NotNil(t, p.Next)
+			assert.Equal(t, *tt.wantNext, *p.Next)
+		})
+	}
+}
@@ -1,7 +1,36 @@
 package user
 
+type Role string
+
+const (
+	RoleUser    Role = "user"
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+	// RoleSuperAdmin -- администратор, которому ещё и разрешено списывать сверх остатка
+	// и назначать других суперадминистраторов
+	RoleSuperAdmin Role = "superadmin"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleUser, RoleSupport, RoleAdmin, RoleSuperAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanForceDebit -- может ли роль списывать сверх остатка.
+func (r Role) CanForceDebit() bool {
+	return r == RoleSuperAdmin
+}
+
 type User struct {
 	ID           string `json:"id"`
 	LoginHash    string `json:"login_hash"`
 	PasswordHash string `json:"password_hash"`
+	ReferralCode string `json:"referral_code"`
+	Role         Role   `json:"role"`
+	// код пригласившего, указанный при регистрации
+	ReferrerCode string `json:"-"`
 }
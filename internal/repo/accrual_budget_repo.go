@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+)
+
+// AccrualBudgetRepository -- общий для всех реплик token bucket запросов к accrual.
+type AccrualBudgetRepository struct {
+	DB
+}
+
+func NewAccrualBudgetRepository(pool connectionPool, log *slog.Logger) *AccrualBudgetRepository {
+	return &AccrualBudgetRepository{
+		DB{
+			pool: pool,
+			log:  log,
+		},
+	}
+}
+
+// Ensure заводит бюджет провайдера с темпом rpm запросов в минуту, 0 -- без ограничения.
+// Уже заведённый бюджет не меняется.
+func (r *AccrualBudgetRepository) Ensure(ctx context.Context, provider string, rpm uint64) error {
+	ensureFn := func() (struct{}, error) {
+		err := db.New(r.pool).EnsureAccrualBudget(ctx, db.EnsureAccrualBudgetParams{
+			NameProvider: provider,
+			RateRpm:      int64(rpm), //nolint: gosec // rpm is far below int64
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to create accrual budget of %s: %w", provider, err)
+		}
+		return struct{}{}, nil
+	}
+	_, err := WithRetry[struct{}](ensureFn, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+// Take берёт токен из бюджета и возвращает, сколько ждать, прежде чем его использовать.
+// paused -- все реплики на паузе после 429: токен не взят, и после ожидания Take нужно повторить.
+func (r *AccrualBudgetRepository) Take(ctx context.Context, provider string,
+) (wait time.Duration, paused bool, err error) {
+	row, err := db.New(r.pool).TakeAccrualToken(ctx, provider)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to take accrual token of %s: %w", provider, err)
+	}
+	return time.Duration(row.WaitMs) * time.Millisecond, row.Paused, nil
+}
+
+// Throttle ставит на паузу все реплики после 429. rpm -- новый темп, 0 -- снизить текущий вдвое.
+func (r *AccrualBudgetRepository) Throttle(ctx context.Context,
+	provider string, rpm uint64, retryAfter time.Duration,
+) error {
+	throttleFn := func() (struct{}, error) {
+		err := db.New(r.pool).ThrottleAccrualBudget(ctx, db.ThrottleAccrualBudgetParams{
+			RateRpm:      int64(rpm), //nolint: gosec // rpm is far below int64
+			RetryAfterMs: retryAfter.Milliseconds(),
+			NameProvider: provider,
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to throttle accrual budget of %s: %w", provider, err)
+		}
+		return struct{}{}, nil
+	}
+	_, err := WithRetry[struct{}](throttleFn, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+// Raise поднимает темп после 429, если accrual не отказывал дольше quiet.
+// raised ложно, если поднимать рано, некуда или темп уже поднят другой репликой.
+func (r *AccrualBudgetRepository) Raise(ctx context.Context,
+	provider string, quiet time.Duration,
+) (rpm uint64, raised bool, err error) {
+	rate, err := db.New(r.pool).RaiseAccrualBudget(ctx, db.RaiseAccrualBudgetParams{
+		NameProvider: provider,
+		QuietMs:      quiet.Milliseconds(),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to raise accrual budget of %s: %w", provider, err)
+	}
+	return uint64(rate), true, nil //nolint: gosec // rate is never negative
+}
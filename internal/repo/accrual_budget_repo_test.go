@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccrualBudgetRepository(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewAccrualBudgetRepository)
+	defer cancel()
+	rate := func(provider string) int64 {
+		var rpm int64
+		require.NoError(t, pool.QueryRow(ctx,
+			"SELECT rate_rpm FROM accrual_budgets WHERE name_provider = $1", provider).Scan(&rpm))
+		return rpm
+	}
+
+	require.NoError(t, repo.Ensure(ctx, "budget-60", 60))
+	// темп задаёт первая реплика
+	require.NoError(t, repo.Ensure(ctx, "budget-60", 600))
+	assert.Equal(t, int64(60), rate("budget-60"))
+
+	wait, paused, err := repo.Take(ctx, "budget-60")
+	require.NoError(t, err)
+	assert.False(t, paused)
+	assert.Zero(t, wait)
+	// следующий токен берётся в долг
+	wait, paused, err = repo.Take(ctx, "budget-60")
+	require.NoError(t, err)
+	assert.False(t, paused)
+	assert.InDelta(t, time.Second, wait, float64(100*time.Millisecond))
+
+	t.Run("429 pauses all replicas", func(t *testing.T) {
+		require.NoError(t, repo.Throttle(ctx, "budget-60", 0, time.Minute))
+		assert.Equal(t, int64(30), rate("budget-60"))
+
+		wait, paused, err := repo.Take(ctx, "budget-60")
+		require.NoError(t, err)
+		assert.True(t, paused)
+		assert.InDelta(t, time.Minute, wait, float64(time.Second))
+
+		// 429 на запросы, отправленные до паузы, темп больше не снижают
+		require.NoError(t, repo.Throttle(ctx, "budget-60", 0, time.Second))
+		assert.Equal(t, int64(30), rate("budget-60"))
+		_, paused, err = repo.Take(ctx, "budget-60")
+		require.NoError(t, err)
+		assert.True(t, paused)
+
+		require.NoError(t, repo.Throttle(ctx, "budget-60", 90, time.Second))
+		assert.Equal(t, int64(90), rate("budget-60"))
+	})
+
+	t.Run("rate recovers after quiet period", func(t *testing.T) {
+		require.NoError(t, repo.Ensure(ctx, "budget-recovery", 40))
+		require.NoError(t, repo.Throttle(ctx, "budget-recovery", 0, 10*time.Millisecond))
+		assert.Equal(t, int64(20), rate("budget-recovery"))
+
+		// пока не прошёл тихий период с конца паузы, темп держится
+		_, raised, err := repo.Raise(ctx, "budget-recovery", time.Hour)
+		require.NoError(t, err)
+		assert.False(t, raised)
+
+		time.Sleep(50 * time.Millisecond)
+		rpm, raised, err := repo.Raise(ctx, "budget-recovery", 20*time.Millisecond)
+		require.NoError(t, err)
+		assert.True(t, raised)
+		assert.Equal(t, uint64(21), rpm)
+		// следующий подъём -- только через тихий период после этого
+		_, raised, err = repo.Raise(ctx, "budget-recovery", time.Hour)
+		require.NoError(t, err)
+		assert.False(t, raised)
+
+		// выше начального темпа не растёт
+		for range 30 {
+			_, _, err = repo.Raise(ctx, "budget-recovery", 0)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(40), rate("budget-recovery"))
+		_, raised, err = repo.Raise(ctx, "budget-recovery", 0)
+		require.NoError(t, err)
+		assert.False(t, raised)
+
+		// объявленный accrual лимит становится потолком
+		require.NoError(t, repo.Throttle(ctx, "budget-recovery", 90, 0))
+		assert.Equal(t, int64(90), rate("budget-recovery"))
+		_, raised, err = repo.Raise(ctx, "budget-recovery", 0)
+		require.NoError(t, err)
+		assert.False(t, raised)
+	})
+
+	t.Run("unlimited budget", func(t *testing.T) {
+		require.NoError(t, repo.Ensure(ctx, "budget-unlimited", 0))
+		for range 10 {
+			wait, paused, err := repo.Take(ctx, "budget-unlimited")
+			require.NoError(t, err)
+			assert.False(t, paused)
+			assert.Zero(t, wait)
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, _, err := repo.Take(ctx, "budget-unknown")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+)
+
+// CallbackReplayRepository помнит подписи принятых callback'ов accrual,
+// чтобы повтор callback'а отклонила любая реплика, а не только принявшая его.
+type CallbackReplayRepository struct {
+	DB
+}
+
+func NewCallbackReplayRepository(pool connectionPool, log *slog.Logger) *CallbackReplayRepository {
+	return &CallbackReplayRepository{
+		DB{
+			pool: pool,
+			log:  log,
+		},
+	}
+}
+
+// Remember запоминает signature до expiresAt. Возвращает false, если подпись уже принята
+// и ещё не истекла.
+func (r *CallbackReplayRepository) Remember(ctx context.Context,
+	signature string, expiresAt time.Time,
+) (bool, error) {
+	rememberFn := func(ctx context.Context, tx connectionPool) (any, error) {
+		queries := db.New(tx)
+		if err := queries.DeleteExpiredCallbackReplays(ctx); err != nil {
+			return false, fmt.Errorf("failed to delete expired callback signatures: %w", err)
+		}
+		n, err := queries.CreateCallbackReplay(ctx, db.CreateCallbackReplayParams{
+			Signature: signature,
+			ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to save callback signature: %w", err)
+		}
+		return n != 0, nil
+	}
+	return WithTX[bool](ctx, r.pool, r.log, rememberFn) //nolint: wrapcheck // error from wrapped function
+}
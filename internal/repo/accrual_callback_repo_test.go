@@ -0,0 +1,30 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackReplayRepository_Remember(t *testing.T) {
+	repo, ctx, cancel, _ := setupRepo(t, NewCallbackReplayRepository)
+	defer cancel()
+
+	fresh, err := repo.Remember(ctx, "signature-1", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	fresh, err = repo.Remember(ctx, "signature-1", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, fresh)
+
+	// истёкшая подпись забывается, и её метка времени уже не пройдёт проверку
+	fresh, err = repo.Remember(ctx, "signature-2", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+	fresh, err = repo.Remember(ctx, "signature-2", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+}
@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/talx-hub/gopher-bonus/internal/model/accrual"
+	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+)
+
+// AccrualControlRepository хранит команды оператора опросу accrual и состояния реплик,
+// чтобы им следовали и их видели все реплики, а не только принявшая запрос.
+type AccrualControlRepository struct {
+	DB
+}
+
+func NewAccrualControlRepository(pool connectionPool, log *slog.Logger) *AccrualControlRepository {
+	return &AccrualControlRepository{
+		DB{
+			pool: pool,
+			log:  log,
+		},
+	}
+}
+
+// SetHold приостанавливает опрос providers или, если held ложно, возобновляет опрос всех провайдеров.
+func (r *AccrualControlRepository) SetHold(ctx context.Context, providers []string, held bool) error {
+	holdFn := func() (struct{}, error) {
+		var err error
+		if held {
+			err = db.New(r.pool).HoldAccrualProviders(ctx, providers)
+		} else {
+			err = db.New(r.pool).ReleaseAccrualProviders(ctx)
+		}
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to set accrual hold to %t: %w", held, err)
+		}
+		return struct{}{}, nil
+	}
+	_, err := WithRetry[struct{}](holdFn, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *AccrualControlRepository) SetMaxRequests(ctx context.Context, provider string, n uint64) error {
+	setFn := func() (struct{}, error) {
+		err := db.New(r.pool).SetAccrualMaxRequests(ctx, db.SetAccrualMaxRequestsParams{
+			NameProvider: provider,
+			MaxRequests:  int64(n), //nolint: gosec // max requests is far below int64
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to set max requests of %s: %w", provider, err)
+		}
+		return struct{}{}, nil
+	}
+	_, err := WithRetry[struct{}](setFn, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+// Controls возвращает команды оператора по провайдерам; провайдеров без команд в ответе нет.
+func (r *AccrualControlRepository) Controls(ctx context.Context) (map[string]accrual.Control, error) {
+	rows, err := db.New(r.pool).ListAccrualControls(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accrual controls: %w", err)
+	}
+	controls := make(map[string]accrual.Control, len(rows))
+	for _, row := range rows {
+		controls[row.NameProvider] = accrual.Control{
+			MaxRequests: uint64(row.MaxRequests), //nolint: gosec // max requests is never negative
+			Held:        row.Held,
+		}
+	}
+	return controls, nil
+}
+
+// Report заменяет сохранённое состояние реплики и удаляет состояния,
+// не обновлявшиеся с staleBefore.
+func (r *AccrualControlRepository) Report(ctx context.Context,
+	replica accrual.Replica, staleBefore time.Time,
+) error {
+	reportFn := func(ctx context.Context, tx connectionPool) (any, error) {
+		queries := db.New(tx)
+		err := queries.DeleteAccrualReplica(ctx, db.DeleteAccrualReplicaParams{
+			IDInstance:  replica.InstanceID,
+			StaleBefore: pgtype.Timestamptz{Time: staleBefore, Valid: true},
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to delete accrual state of %s: %w", replica.InstanceID, err)
+		}
+		for _, p := range replica.Providers {
+			err = queries.CreateAccrualReplicaProvider(ctx, db.CreateAccrualReplicaProviderParams{
+				IDInstance:   replica.InstanceID,
+				NameProvider: p.Name,
+				Paused:       replica.Paused,
+				MaxRequests:  int64(p.MaxRequests), //nolint: gosec // max requests is far below int64
+				RateLimitRpm: int64(p.RateLimit),   //nolint: gosec // rpm is far below int64
+				Rpm:          int64(p.RPM),         //nolint: gosec // rpm is far below int64
+				InFlight:     p.InFlight,
+				Workers:      int32(p.Workers), //nolint: gosec // worker count is small
+				Throttled:    p.Throttled,
+				RetryAfter:   pgtype.Timestamptz{Time: p.RetryAfter, Valid: !p.RetryAfter.IsZero()},
+			})
+			if err != nil {
+				return struct{}{}, fmt.Errorf("failed to save accrual state of %s: %w", replica.InstanceID, err)
+			}
+		}
+		return struct{}{}, nil
+	}
+	_, err := WithTX[struct{}](ctx, r.pool, r.log, reportFn)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+// Replicas возвращает состояния реплик, сообщённые не раньше since, упорядоченные по ID реплики.
+func (r *AccrualControlRepository) Replicas(ctx context.Context, since time.Time) ([]accrual.Replica, error) {
+	rows, err := db.New(r.pool).ListAccrualReplicas(ctx, pgtype.Timestamptz{Time: since, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accrual replicas: %w", err)
+	}
+	var replicas []accrual.Replica
+	for _, row := range rows {
+		if len(replicas) == 0 || replicas[len(replicas)-1].InstanceID != row.IDInstance {
+			replicas = append(replicas, accrual.Replica{
+				ReportedAt: row.ReportedAt.Time,
+				InstanceID: row.IDInstance,
+				Paused:     row.Paused,
+			})
+		}
+		replica := &replicas[len(replicas)-1]
+		replica.Providers = append(replica.Providers, accrual.ProviderState{
+			RetryAfter:  row.RetryAfter.Time,
+			Name:        row.NameProvider,
+			MaxRequests: uint64(row.MaxRequests),  //nolint: gosec // max requests is never negative
+			RateLimit:   uint64(row.RateLimitRpm), //nolint: gosec // rpm is never negative
+			RPM:         uint64(row.Rpm),          //nolint: gosec // rpm is never negative
+			InFlight:    row.InFlight,
+			Workers:     int(row.Workers),
+			Throttled:   row.Throttled,
+		})
+	}
+	return replicas, nil
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model/accrual"
+)
+
+func TestAccrualControlRepository(t *testing.T) {
+	repo, ctx, cancel, _ := setupRepo(t, NewAccrualControlRepository)
+	defer cancel()
+
+	require.NoError(t, repo.SetHold(ctx, []string{"control-a", "control-b"}, true))
+	require.NoError(t, repo.SetMaxRequests(ctx, "control-a", 5))
+	controls, err := repo.Controls(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, accrual.Control{MaxRequests: 5, Held: true}, controls["control-a"])
+	assert.Equal(t, accrual.Control{Held: true}, controls["control-b"])
+
+	// возобновление снимает паузу и с провайдеров, которых у реплики уже нет
+	require.NoError(t, repo.SetHold(ctx, nil, false))
+	controls, err = repo.Controls(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, accrual.Control{MaxRequests: 5}, controls["control-a"])
+	assert.False(t, controls["control-b"].Held)
+
+	t.Run("replicas", func(t *testing.T) {
+		retryAfter := time.Now().Add(time.Minute).Truncate(time.Microsecond)
+		require.NoError(t, repo.Report(ctx, accrual.Replica{
+			InstanceID: "replica-1",
+			Providers: []accrual.ProviderState{
+				{Name: "control-a", MaxRequests: 5, RateLimit: 60, Workers: 4},
+				{Name: "control-b", RetryAfter: retryAfter, Throttled: true},
+			},
+			Paused: true,
+		}, time.Now().Add(-time.Minute)))
+		require.NoError(t, repo.Report(ctx, accrual.Replica{
+			InstanceID: "replica-2",
+			Providers:  []accrual.ProviderState{{Name: "control-a", MaxRequests: 5}},
+		}, time.Now().Add(-time.Minute)))
+		// новый отчёт заменяет прежний
+		require.NoError(t, repo.Report(ctx, accrual.Replica{
+			InstanceID: "replica-2",
+			Providers:  []accrual.ProviderState{{Name: "control-a", MaxRequests: 7}},
+		}, time.Now().Add(-time.Minute)))
+
+		replicas, err := repo.Replicas(ctx, time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.Len(t, replicas, 2)
+		assert.Equal(t, "replica-1", replicas[0].InstanceID)
+		assert.True(t, replicas[0].Paused)
+		require.Len(t, replicas[0].Providers, 2)
+		assert.Equal(t, uint64(60), replicas[0].Providers[0].RateLimit)
+		assert.True(t, replicas[0].Providers[1].RetryAfter.Equal(retryAfter))
+		assert.Equal(t, "replica-2", replicas[1].InstanceID)
+		assert.Equal(t, []accrual.ProviderState{{Name: "control-a", MaxRequests: 7}}, replicas[1].Providers)
+
+		// отчёт одной реплики удаляет давно не обновлявшиеся
+		require.NoError(t, repo.Report(ctx, accrual.Replica{InstanceID: "replica-3"}, time.Now().Add(time.Minute)))
+		replicas, err = repo.Replicas(ctx, time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, replicas)
+	})
+}
@@ -0,0 +1,111 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type AdjustmentRepository struct {
+	DB
+}
+
+func NewAdjustmentRepository(pool connectionPool, log *slog.Logger) *AdjustmentRepository {
+	return &AdjustmentRepository{
+		DB{
+			pool: pool,
+			log:  log,
+		},
+	}
+}
+
+// Create records the adjustment. Debits fail with ErrInsufficientFunds
+// when the balance does not cover them, unless the adjustment is forced.
+func (r *AdjustmentRepository) Create(ctx context.Context, a *adjustment.Adjustment) error {
+	createLogic := func(ctx context.Context, tx connectionPool) (any, error) {
+		queries := db.New(tx)
+		exists, err := queries.UserExists(ctx, a.UserID)
+		if err != nil {
+			return db.CreateAdjustmentRow{}, fmt.Errorf("failed to check user %s: %w", a.UserID, err)
+		}
+		if !exists {
+			return db.CreateAdjustmentRow{}, fmt.Errorf("user %s: %w", a.UserID, serviceerrs.ErrNotFound)
+		}
+
+		if a.Direction == adjustment.DirectionDebit && !a.Forced {
+			orders := OrderRepository{r.DB}
+			accrued, withdrawn, err := orders.getBalanceTX(ctx, tx, a.UserID)
+			if err != nil {
+				return db.CreateAdjustmentRow{}, fmt.Errorf(
+					"failed to get balance for userID %s: %w", a.UserID, err)
+			}
+			if accrued.TotalKopecks() < a.Amount.TotalKopecks()+withdrawn.TotalKopecks() {
+				return db.CreateAdjustmentRow{}, serviceerrs.ErrInsufficientFunds
+			}
+		}
+
+		row, err := queries.CreateAdjustment(ctx, db.CreateAdjustmentParams{
+			IDUser:     a.UserID,
+			IDAdmin:    a.AdminID,
+			Direction:  string(a.Direction),
+			Amount:     a.Amount.ToPGNumeric(),
+			ReasonCode: string(a.Reason),
+			Comment:    a.Comment,
+			Forced:     a.Forced,
+		})
+		if err != nil {
+			return db.CreateAdjustmentRow{}, fmt.Errorf("failed to record adjustment: %w", err)
+		}
+		return row, nil
+	}
+
+	createWithTX := func() (db.CreateAdjustmentRow, error) {
+		return WithTX[db.CreateAdjustmentRow](ctx, r.pool, r.log, createLogic)
+	}
+
+	row, err := WithRetry[db.CreateAdjustmentRow](createWithTX, 0)
+	if err != nil {
+		return err //nolint: wrapcheck // error from wrapped function
+	}
+	a.ID = int64(row.IDAdjustment)
+	a.CreatedAt = row.CreatedAt.Time
+	return nil
+}
+
+func (r *AdjustmentRepository) ListByUser(ctx context.Context, userID string,
+) ([]adjustment.Adjustment, error) {
+	listLogic := func() ([]adjustment.Adjustment, error) {
+		queries := db.New(r.pool)
+		rows, err := queries.ListAdjustmentsByUser(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list adjustments for user %s: %w", userID, err)
+		}
+
+		adjustments := make([]adjustment.Adjustment, len(rows))
+		for i, row := range rows {
+			amount, err := model.FromPGNumeric(row.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount of adjustment %d: %w", row.IDAdjustment, err)
+			}
+			adjustments[i] = adjustment.Adjustment{
+				CreatedAt: row.CreatedAt.Time,
+				UserID:    row.IDUser,
+				AdminID:   row.IDAdmin,
+				Direction: adjustment.Direction(row.Direction),
+				Reason:    adjustment.Reason(row.ReasonCode),
+				Comment:   row.Comment,
+				Amount:    amount,
+				ID:        int64(row.IDAdjustment),
+				Forced:    row.Forced,
+			}
+		}
+		return adjustments, nil
+	}
+
+	return WithRetry[[]adjustment.Adjustment](listLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func TestAdjustmentRepository_Create(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewAdjustmentRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/adjustment_create.sql"))
+
+	newAdjustment := func(d adjustment.Direction, amount model.Amount, forced bool) *adjustment.Adjustment {
+		return &adjustment.Adjustment{
+			UserID:    "1",
+			AdminID:   "admin",
+			Direction: d,
+			Reason:    adjustment.ReasonCorrection,
+			Comment:   "test",
+			Amount:    amount,
+			Forced:    forced,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		adjustment  *adjustment.Adjustment
+		wantErr     error
+		wantCurrent model.Amount
+	}{
+		{"credit", newAdjustment(adjustment.DirectionCredit, model.NewAmount(20, 0), false),
+			nil, model.NewAmount(90, 0)},
+		{"debit within balance", newAdjustment(adjustment.DirectionDebit, model.NewAmount(40, 0), false),
+			nil, model.NewAmount(50, 0)},
+		{"debit above balance", newAdjustment(adjustment.DirectionDebit, model.NewAmount(60, 0), false),
+			serviceerrs.ErrInsufficientFunds, model.NewAmount(50, 0)},
+		{"forced debit above balance", newAdjustment(adjustment.DirectionDebit, model.NewAmount(60, 0), true),
+			nil, model.NewAmount(0, -1000)},
+	}
+
+	orderRepo := NewOrderRepository(pool, repo.log)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.Create(ctx, tt.adjustment)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.NotZero(t, tt.adjustment.ID)
+			}
+
+			current, _, err := orderRepo.GetBalance(ctx, "1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCurrent.TotalKopecks(), current.TotalKopecks())
+		})
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		a := newAdjustment(adjustment.DirectionCredit, model.NewAmount(1, 0), false)
+		a.UserID = "100500"
+		require.ErrorIs(t, repo.Create(ctx, a), serviceerrs.ErrNotFound)
+	})
+
+	t.Run("adjustments are listed and immutable", func(t *testing.T) {
+		adjustments, err := repo.ListByUser(ctx, "1")
+		require.NoError(t, err)
+		assert.Len(t, adjustments, 3)
+
+		_, err = pool.Exec(ctx, "UPDATE balance_adjustments SET amount = 1")
+		require.Error(t, err)
+	})
+
+	t.Run("history contains adjustments", func(t *testing.T) {
+		history, err := orderRepo.ListHistory(ctx, "1")
+		require.NoError(t, err)
+		assert.Len(t, history, 5)
+	})
+}
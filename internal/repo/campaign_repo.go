@@ -0,0 +1,286 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/campaign"
+	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type CampaignRepository struct {
+	DB
+}
+
+func NewCampaignRepository(pool connectionPool, log *slog.Logger) *CampaignRepository {
+	return &CampaignRepository{
+		DB{
+			pool: pool,
+			log:  log,
+		},
+	}
+}
+
+func (r *CampaignRepository) Create(ctx context.Context, c *campaign.Campaign) error {
+	createLogic := func(ctx context.Context, tx connectionPool) (any, error) {
+		queries := db.New(tx)
+		minTier, err := findMinTierID(ctx, queries, c.MinTier)
+		if err != nil {
+			return int32(0), err
+		}
+
+		id, err := queries.CreateCampaign(ctx, db.CreateCampaignParams{
+			NameCampaign:     c.Name,
+			StartsAt:         pgtype.Timestamptz{Time: c.StartsAt, Valid: true},
+			EndsAt:           pgtype.Timestamptz{Time: c.EndsAt, Valid: true},
+			FirstOrderOnly:   c.FirstOrderOnly,
+			IDMinTier:        minTier,
+			RegisteredAfter:  toPGTimestamp(c.RegisteredAfter),
+			RegisteredBefore: toPGTimestamp(c.RegisteredBefore),
+			BonusType:        string(c.BonusType),
+			BonusValue:       c.BonusValue.ToPGNumeric(),
+		})
+		if err != nil {
+			return int32(0), fmt.Errorf("failed to create campaign %s: %w", c.Name, err)
+		}
+		return id, nil
+	}
+
+	createWithTX := func() (int32, error) {
+		return WithTX[int32](ctx, r.pool, r.log, createLogic)
+	}
+
+	id, err := WithRetry[int32](createWithTX, 0)
+	if err != nil {
+		return err //nolint: wrapcheck // error from wrapped function
+	}
+	c.ID = int64(id)
+	return nil
+}
+
+func (r *CampaignRepository) Update(ctx context.Context, c *campaign.Campaign) error {
+	updateLogic := func(ctx context.Context, tx connectionPool) (any, error) {
+		queries := db.New(tx)
+		minTier, err := findMinTierID(ctx, queries, c.MinTier)
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		res, err := queries.UpdateCampaign(ctx, db.UpdateCampaignParams{
+			IDCampaign:       int32(c.ID),
+			NameCampaign:     c.Name,
+			StartsAt:         pgtype.Timestamptz{Time: c.StartsAt, Valid: true},
+			EndsAt:           pgtype.Timestamptz{Time: c.EndsAt, Valid: true},
+			FirstOrderOnly:   c.FirstOrderOnly,
+			IDMinTier:        minTier,
+			RegisteredAfter:  toPGTimestamp(c.RegisteredAfter),
+			RegisteredBefore: toPGTimestamp(c.RegisteredBefore),
+			BonusType:        string(c.BonusType),
+			BonusValue:       c.BonusValue.ToPGNumeric(),
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to update campaign %d: %w", c.ID, err)
+		}
+		if res.RowsAffected() == 0 {
+			return struct{}{}, fmt.Errorf("campaign %d: %w", c.ID, serviceerrs.ErrNotFound)
+		}
+		return struct{}{}, nil
+	}
+
+	updateWithTX := func() (struct{}, error) {
+		return WithTX[struct{}](ctx, r.pool, r.log, updateLogic)
+	}
+
+	_, err := WithRetry[struct{}](updateWithTX, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *CampaignRepository) Archive(ctx context.Context, id int64) error {
+	archiveLogic := func() (struct{}, error) {
+		queries := db.New(r.pool)
+		res, err := queries.ArchiveCampaign(ctx, int32(id))
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to archive campaign %d: %w", id, err)
+		}
+		if res.RowsAffected() == 0 {
+			return struct{}{}, fmt.Errorf("campaign %d: %w", id, serviceerrs.ErrNotFound)
+		}
+		return struct{}{}, nil
+	}
+
+	_, err := WithRetry[struct{}](archiveLogic, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *CampaignRepository) FindByID(ctx context.Context, id int64) (campaign.Campaign, error) {
+	findLogic := func() (campaign.Campaign, error) {
+		queries := db.New(r.pool)
+		row, err := queries.FindCampaignByID(ctx, int32(id))
+		if err != nil && errors.Is(err, pgx.ErrNoRows) {
+			return campaign.Campaign{}, fmt.Errorf("campaign %d: %w", id, serviceerrs.ErrNotFound)
+		}
+		if err != nil {
+			return campaign.Campaign{}, fmt.Errorf("failed to find campaign %d: %w", id, err)
+		}
+		return toCampaign(&row)
+	}
+
+	return WithRetry[campaign.Campaign](findLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *CampaignRepository) List(ctx context.Context) ([]campaign.Campaign, error) {
+	listLogic := func() ([]campaign.Campaign, error) {
+		queries := db.New(r.pool)
+		rows, err := queries.ListCampaigns(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list campaigns: %w", err)
+		}
+
+		campaigns := make([]campaign.Campaign, len(rows))
+		for i, row := range rows {
+			found := db.FindCampaignByIDRow(row)
+			if campaigns[i], err = toCampaign(&found); err != nil {
+				return nil, err
+			}
+		}
+		return campaigns, nil
+	}
+
+	return WithRetry[[]campaign.Campaign](listLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *CampaignRepository) ListActive(ctx context.Context, at time.Time) ([]campaign.Campaign, error) {
+	listLogic := func() ([]campaign.Campaign, error) {
+		queries := db.New(r.pool)
+		rows, err := queries.ListActiveCampaigns(ctx, pgtype.Timestamptz{Time: at, Valid: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list active campaigns: %w", err)
+		}
+
+		campaigns := make([]campaign.Campaign, len(rows))
+		for i, row := range rows {
+			found := db.FindCampaignByIDRow(row)
+			if campaigns[i], err = toCampaign(&found); err != nil {
+				return nil, err
+			}
+		}
+		return campaigns, nil
+	}
+
+	return WithRetry[[]campaign.Campaign](listLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *CampaignRepository) GetOrderFacts(ctx context.Context, orderID string) (campaign.Facts, error) {
+	factsLogic := func() (campaign.Facts, error) {
+		queries := db.New(r.pool)
+		row, err := queries.GetOrderCampaignFacts(ctx, orderID)
+		if err != nil {
+			return campaign.Facts{}, fmt.Errorf("failed to get campaign facts for order %s: %w", orderID, err)
+		}
+		if !row.ProcessedAt.Valid {
+			return campaign.Facts{}, fmt.Errorf("order %s is not processed yet", orderID)
+		}
+
+		accrual, err := model.FromPGNumeric(row.RawAmount)
+		if err != nil {
+			return campaign.Facts{}, fmt.Errorf("invalid accrual of order %s: %w", orderID, err)
+		}
+		tierPoints, err := model.FromPGNumeric(row.TierPoints)
+		if err != nil {
+			return campaign.Facts{}, fmt.Errorf("invalid tier points of order %s: %w", orderID, err)
+		}
+		return campaign.Facts{
+			ProcessedAt:  row.ProcessedAt.Time,
+			RegisteredAt: row.RegisteredAt.Time,
+			UserID:       row.IDUser,
+			OrderID:      orderID,
+			Accrual:      accrual,
+			TierPoints:   tierPoints,
+			IsFirstOrder: row.IsFirstOrder,
+		}, nil
+	}
+
+	return WithRetry[campaign.Facts](factsLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+// CreateCredit is idempotent: a repeated credit for the same order and campaign is ignored.
+func (r *CampaignRepository) CreateCredit(ctx context.Context, c *campaign.Credit) (bool, error) {
+	createLogic := func() (bool, error) {
+		queries := db.New(r.pool)
+		inserted, err := queries.CreateBonusCredit(ctx, db.CreateBonusCreditParams{
+			IDUser:     c.UserID,
+			NameOrder:  c.OrderID,
+			IDCampaign: int32(c.CampaignID),
+			Amount:     c.Amount.ToPGNumeric(),
+			CreditedAt: pgtype.Timestamptz{Time: c.CreditedAt, Valid: true},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to credit campaign %d bonus for order %s: %w",
+				c.CampaignID, c.OrderID, err)
+		}
+		return inserted != 0, nil
+	}
+
+	return WithRetry[bool](createLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+func findMinTierID(ctx context.Context, queries *db.Queries, name string) (pgtype.Int4, error) {
+	if name == "" {
+		return pgtype.Int4{}, nil
+	}
+	id, err := queries.FindTierIDByName(ctx, name)
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.Int4{}, fmt.Errorf("%w: %s", serviceerrs.ErrUnknownTier, name)
+	}
+	if err != nil {
+		return pgtype.Int4{}, fmt.Errorf("failed to find tier %s: %w", name, err)
+	}
+	return pgtype.Int4{Int32: id, Valid: true}, nil
+}
+
+func toCampaign(row *db.FindCampaignByIDRow) (campaign.Campaign, error) {
+	bonusValue, err := model.FromPGNumeric(row.BonusValue)
+	if err != nil {
+		return campaign.Campaign{}, fmt.Errorf("invalid bonus value of campaign %d: %w", row.IDCampaign, err)
+	}
+	c := campaign.Campaign{
+		ID:               int64(row.IDCampaign),
+		Name:             row.NameCampaign,
+		StartsAt:         row.StartsAt.Time,
+		EndsAt:           row.EndsAt.Time,
+		FirstOrderOnly:   row.FirstOrderOnly,
+		RegisteredAfter:  fromPGTimestamp(row.RegisteredAfter),
+		RegisteredBefore: fromPGTimestamp(row.RegisteredBefore),
+		BonusType:        campaign.BonusType(row.BonusType),
+		BonusValue:       bonusValue,
+	}
+	if row.MinTier.Valid {
+		c.MinTier = row.MinTier.String
+		if c.MinTierPoints, err = model.FromPGNumeric(row.MinTierPoints); err != nil {
+			return campaign.Campaign{}, fmt.Errorf("invalid min tier of campaign %d: %w", row.IDCampaign, err)
+		}
+	}
+	return c, nil
+}
+
+func toPGTimestamp(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+func fromPGTimestamp(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,19 @@
+TRUNCATE TABLE balance_adjustments RESTART IDENTITY CASCADE;
+TRUNCATE TABLE withdrawn_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE accrued_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE password_hashes RESTART IDENTITY CASCADE;
+TRUNCATE TABLE user_hashes RESTART IDENTITY CASCADE;
+
+INSERT INTO user_hashes (id_user, hash_login)
+VALUES
+    ('admin', 'adminhash'),
+    ('1', 'user1hash');
+
+INSERT INTO accrued_orders (id_user, name_order, uploaded_at, id_status, amount, raw_amount, processed_at)
+VALUES
+    ('1', 'adj-1a', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     100.00, 100.00, NOW());
+
+INSERT INTO withdrawn_orders (id_user, name_order, processed_at, amount)
+VALUES
+    ('1', 'adj-1w', NOW(), 30.00);
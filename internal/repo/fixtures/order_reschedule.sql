@@ -0,0 +1,16 @@
+TRUNCATE TABLE withdrawn_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE accrued_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE password_hashes RESTART IDENTITY CASCADE;
+TRUNCATE TABLE user_hashes RESTART IDENTITY CASCADE;
+
+INSERT INTO user_hashes (id_user, hash_login)
+VALUES ('1', 'user1hash');
+
+INSERT INTO accrued_orders (id_user, name_order, uploaded_at, id_status)
+VALUES
+    ('1', 'backoff-old', NOW() - INTERVAL '2 days',
+     (SELECT id_status FROM statuses WHERE name_status = 'PROCESSING')),
+    ('1', 'backoff-new', NOW() - INTERVAL '1 minute',
+     (SELECT id_status FROM statuses WHERE name_status = 'NEW')),
+    ('1', 'backoff-done', NOW() - INTERVAL '1 hour',
+     (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'));
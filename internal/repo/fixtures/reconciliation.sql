@@ -0,0 +1,20 @@
+TRUNCATE TABLE accrual_discrepancies RESTART IDENTITY CASCADE;
+TRUNCATE TABLE balance_adjustments RESTART IDENTITY CASCADE;
+TRUNCATE TABLE withdrawn_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE accrued_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE password_hashes RESTART IDENTITY CASCADE;
+TRUNCATE TABLE user_hashes RESTART IDENTITY CASCADE;
+
+INSERT INTO user_hashes (id_user, hash_login)
+VALUES
+    ('admin', 'adminhash'),
+    ('1', 'user1hash');
+
+INSERT INTO accrued_orders (id_user, name_order, uploaded_at, id_status, amount, raw_amount, processed_at)
+VALUES
+    ('1', 'rec-1a', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     110.00, 100.00, NOW() - INTERVAL '2 days'),
+    ('1', 'rec-1b', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'INVALID'),
+     0.00, 0.00, NOW() - INTERVAL '1 day'),
+    ('1', 'rec-1c', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSING'),
+     0.00, 0.00, NULL);
@@ -0,0 +1,27 @@
+TRUNCATE TABLE referral_credits RESTART IDENTITY CASCADE;
+TRUNCATE TABLE referrals RESTART IDENTITY CASCADE;
+TRUNCATE TABLE accrued_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE password_hashes RESTART IDENTITY CASCADE;
+TRUNCATE TABLE user_hashes RESTART IDENTITY CASCADE;
+
+INSERT INTO user_hashes (id_user, hash_login, referral_code)
+VALUES
+    ('1', 'user1hash', 'AAAA2222'),
+    ('2', 'user2hash', 'BBBB2222');
+
+INSERT INTO password_hashes (id_user, hash_password)
+VALUES
+    ('1', 'user1password-hash'),
+    ('2', 'user2password-hash');
+
+INSERT INTO referrals (id_referrer, id_referee)
+VALUES ('1', '2');
+
+INSERT INTO accrued_orders (id_user, name_order, uploaded_at, id_status, amount, raw_amount, processed_at)
+VALUES
+    ('1', 'ref-1a', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     100.00, 100.00, NOW()),
+    ('2', 'ref-2a', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     200.00, 200.00, NOW()),
+    ('2', 'ref-2b', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     300.00, 300.00, NOW());
@@ -0,0 +1,22 @@
+TRUNCATE TABLE user_tiers RESTART IDENTITY CASCADE;
+TRUNCATE TABLE withdrawn_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE accrued_orders RESTART IDENTITY CASCADE;
+TRUNCATE TABLE password_hashes RESTART IDENTITY CASCADE;
+TRUNCATE TABLE user_hashes RESTART IDENTITY CASCADE;
+
+INSERT INTO user_hashes (id_user, hash_login)
+VALUES
+    ('1', 'user1hash'),
+    ('2', 'user2hash'),
+    ('3', 'user3hash');
+
+INSERT INTO accrued_orders (id_user, name_order, uploaded_at, id_status, amount, raw_amount, processed_at)
+VALUES
+    ('1', 'tier-1a', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     700.00, 700.00, NOW()),
+    ('1', 'tier-1b', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     500.00, 500.00, NOW()),
+    ('2', 'tier-2a', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     6000.00, 6000.00, NOW() - INTERVAL '13 months'),
+    ('3', 'tier-3a', NOW(), (SELECT id_status FROM statuses WHERE name_status = 'PROCESSED'),
+     5000.00, 5000.00, NOW());
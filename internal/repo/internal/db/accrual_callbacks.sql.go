@@ -0,0 +1,42 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: accrual_callbacks.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createCallbackReplay = `-- name: CreateCallbackReplay :execrows
+INSERT INTO accrual_callback_replays (signature, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (signature) DO NOTHING
+`
+
+type CreateCallbackReplayParams struct {
+	Signature string
+	ExpiresAt pgtype.Timestamptz
+}
+
+// подпись, уже принятая любой репликой, не вставляется
+func (q *Queries) CreateCallbackReplay(ctx context.Context, arg CreateCallbackReplayParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createCallbackReplay, arg.Signature, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredCallbackReplays = `-- name: DeleteExpiredCallbackReplays :exec
+DELETE FROM accrual_callback_replays
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredCallbackReplays(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteExpiredCallbackReplays)
+	return err
+}
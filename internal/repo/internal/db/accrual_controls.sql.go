@@ -0,0 +1,169 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: accrual_controls.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createAccrualReplicaProvider = `-- name: CreateAccrualReplicaProvider :exec
+INSERT INTO accrual_replicas (id_instance, name_provider, paused, max_requests, rate_limit_rpm,
+                              rpm, in_flight, workers, throttled, retry_after)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+type CreateAccrualReplicaProviderParams struct {
+	IDInstance   string
+	NameProvider string
+	Paused       bool
+	MaxRequests  int64
+	RateLimitRpm int64
+	Rpm          int64
+	InFlight     int64
+	Workers      int32
+	Throttled    bool
+	RetryAfter   pgtype.Timestamptz
+}
+
+func (q *Queries) CreateAccrualReplicaProvider(ctx context.Context, arg CreateAccrualReplicaProviderParams) error {
+	_, err := q.db.Exec(ctx, createAccrualReplicaProvider,
+		arg.IDInstance,
+		arg.NameProvider,
+		arg.Paused,
+		arg.MaxRequests,
+		arg.RateLimitRpm,
+		arg.Rpm,
+		arg.InFlight,
+		arg.Workers,
+		arg.Throttled,
+		arg.RetryAfter,
+	)
+	return err
+}
+
+const deleteAccrualReplica = `-- name: DeleteAccrualReplica :exec
+DELETE FROM accrual_replicas
+WHERE id_instance = $1
+   OR reported_at < $2
+`
+
+type DeleteAccrualReplicaParams struct {
+	IDInstance  string
+	StaleBefore pgtype.Timestamptz
+}
+
+// вместе с прежним состоянием реплики удаляются давно не обновлявшиеся:
+// у перезапущенной реплики новый ID
+func (q *Queries) DeleteAccrualReplica(ctx context.Context, arg DeleteAccrualReplicaParams) error {
+	_, err := q.db.Exec(ctx, deleteAccrualReplica, arg.IDInstance, arg.StaleBefore)
+	return err
+}
+
+const holdAccrualProviders = `-- name: HoldAccrualProviders :exec
+INSERT INTO accrual_controls (name_provider, held)
+SELECT unnest($1::text[]), true
+ON CONFLICT (name_provider) DO UPDATE SET held = true
+`
+
+func (q *Queries) HoldAccrualProviders(ctx context.Context, providers []string) error {
+	_, err := q.db.Exec(ctx, holdAccrualProviders, providers)
+	return err
+}
+
+const listAccrualControls = `-- name: ListAccrualControls :many
+SELECT name_provider, held, max_requests
+FROM accrual_controls
+`
+
+func (q *Queries) ListAccrualControls(ctx context.Context) ([]AccrualControl, error) {
+	rows, err := q.db.Query(ctx, listAccrualControls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccrualControl
+	for rows.Next() {
+		var i AccrualControl
+		if err := rows.Scan(&i.NameProvider, &i.Held, &i.MaxRequests); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccrualReplicas = `-- name: ListAccrualReplicas :many
+SELECT id_instance, name_provider, paused, max_requests, rate_limit_rpm,
+       rpm, in_flight, workers, throttled, retry_after, reported_at
+FROM accrual_replicas
+WHERE reported_at >= $1
+ORDER BY id_instance, name_provider
+`
+
+func (q *Queries) ListAccrualReplicas(ctx context.Context, reportedSince pgtype.Timestamptz) ([]AccrualReplica, error) {
+	rows, err := q.db.Query(ctx, listAccrualReplicas, reportedSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccrualReplica
+	for rows.Next() {
+		var i AccrualReplica
+		if err := rows.Scan(
+			&i.IDInstance,
+			&i.NameProvider,
+			&i.Paused,
+			&i.MaxRequests,
+			&i.RateLimitRpm,
+			&i.Rpm,
+			&i.InFlight,
+			&i.Workers,
+			&i.Throttled,
+			&i.RetryAfter,
+			&i.ReportedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseAccrualProviders = `-- name: ReleaseAccrualProviders :exec
+UPDATE accrual_controls
+SET held = false
+WHERE held
+`
+
+// снимается пауза всех провайдеров, в том числе уже убранных из конфигурации
+func (q *Queries) ReleaseAccrualProviders(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, releaseAccrualProviders)
+	return err
+}
+
+const setAccrualMaxRequests = `-- name: SetAccrualMaxRequests :exec
+INSERT INTO accrual_controls (name_provider, max_requests)
+VALUES ($1, $2)
+ON CONFLICT (name_provider) DO UPDATE SET max_requests = EXCLUDED.max_requests
+`
+
+type SetAccrualMaxRequestsParams struct {
+	NameProvider string
+	MaxRequests  int64
+}
+
+func (q *Queries) SetAccrualMaxRequests(ctx context.Context, arg SetAccrualMaxRequestsParams) error {
+	_, err := q.db.Exec(ctx, setAccrualMaxRequests, arg.NameProvider, arg.MaxRequests)
+	return err
+}
@@ -0,0 +1,169 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: adjustments.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createAdjustment = `-- name: CreateAdjustment :one
+INSERT INTO balance_adjustments (id_user, id_admin, direction, amount, reason_code, comment, forced)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id_adjustment, created_at
+`
+
+type CreateAdjustmentParams struct {
+	IDUser     string
+	IDAdmin    string
+	Direction  string
+	Amount     pgtype.Numeric
+	ReasonCode string
+	Comment    string
+	Forced     bool
+}
+
+type CreateAdjustmentRow struct {
+	IDAdjustment int32
+	CreatedAt    pgtype.Timestamptz
+}
+
+func (q *Queries) CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (CreateAdjustmentRow, error) {
+	row := q.db.QueryRow(ctx, createAdjustment,
+		arg.IDUser,
+		arg.IDAdmin,
+		arg.Direction,
+		arg.Amount,
+		arg.ReasonCode,
+		arg.Comment,
+		arg.Forced,
+	)
+	var i CreateAdjustmentRow
+	err := row.Scan(&i.IDAdjustment, &i.CreatedAt)
+	return i, err
+}
+
+const getAdjustmentAmount = `-- name: GetAdjustmentAmount :one
+SELECT COALESCE(sum(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)::decimal(12,2)
+    AS adjusted
+FROM balance_adjustments
+WHERE id_user=$1
+`
+
+func (q *Queries) GetAdjustmentAmount(ctx context.Context, idUser string) (pgtype.Numeric, error) {
+	row := q.db.QueryRow(ctx, getAdjustmentAmount, idUser)
+	var adjusted pgtype.Numeric
+	err := row.Scan(&adjusted)
+	return adjusted, err
+}
+
+const listAdjustmentsByUser = `-- name: ListAdjustmentsByUser :many
+SELECT id_adjustment, id_user, id_admin, direction, amount, reason_code, comment, forced, created_at
+FROM balance_adjustments
+WHERE id_user=$1
+ORDER BY created_at DESC, id_adjustment DESC
+`
+
+func (q *Queries) ListAdjustmentsByUser(ctx context.Context, idUser string) ([]BalanceAdjustment, error) {
+	rows, err := q.db.Query(ctx, listAdjustmentsByUser, idUser)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BalanceAdjustment
+	for rows.Next() {
+		var i BalanceAdjustment
+		if err := rows.Scan(
+			&i.IDAdjustment,
+			&i.IDUser,
+			&i.IDAdmin,
+			&i.Direction,
+			&i.Amount,
+			&i.ReasonCode,
+			&i.Comment,
+			&i.Forced,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBalanceHistory = `-- name: ListBalanceHistory :many
+SELECT 'accrual'::text AS kind, name_order::text AS reference, amount::decimal(12,2) AS amount,
+       processed_at::timestamptz AS happened_at
+FROM accrued_orders
+WHERE accrued_orders.id_user=$1 AND processed_at IS NOT NULL AND amount > 0
+UNION ALL
+SELECT 'withdrawal', name_order, (-amount)::decimal(12,2), processed_at
+FROM withdrawn_orders
+WHERE withdrawn_orders.id_user=$1
+UNION ALL
+SELECT 'campaign_bonus', name_order, amount::decimal(12,2), credited_at
+FROM bonus_credits
+WHERE bonus_credits.id_user=$1
+UNION ALL
+SELECT 'referral_bonus', '', amount::decimal(12,2), credited_at
+FROM referral_credits
+WHERE referral_credits.id_user=$1
+UNION ALL
+SELECT 'adjustment', reason_code,
+       (CASE WHEN direction = 'credit' THEN amount ELSE -amount END)::decimal(12,2), created_at
+FROM balance_adjustments
+WHERE balance_adjustments.id_user=$1
+ORDER BY happened_at DESC
+`
+
+type ListBalanceHistoryRow struct {
+	Kind       string
+	Reference  string
+	Amount     pgtype.Numeric
+	HappenedAt pgtype.Timestamptz
+}
+
+func (q *Queries) ListBalanceHistory(ctx context.Context, idUser string) ([]ListBalanceHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listBalanceHistory, idUser)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBalanceHistoryRow
+	for rows.Next() {
+		var i ListBalanceHistoryRow
+		if err := rows.Scan(
+			&i.Kind,
+			&i.Reference,
+			&i.Amount,
+			&i.HappenedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const userExists = `-- name: UserExists :one
+SELECT EXISTS(SELECT 1
+              FROM user_hashes
+              WHERE id_user=$1)
+`
+
+func (q *Queries) UserExists(ctx context.Context, idUser string) (bool, error) {
+	row := q.db.QueryRow(ctx, userExists, idUser)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,348 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: campaigns.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const archiveCampaign = `-- name: ArchiveCampaign :execresult
+UPDATE campaigns
+SET archived_at=now()
+WHERE id_campaign=$1
+  AND archived_at IS NULL
+`
+
+func (q *Queries) ArchiveCampaign(ctx context.Context, idCampaign int32) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, archiveCampaign, idCampaign)
+}
+
+const createBonusCredit = `-- name: CreateBonusCredit :execrows
+INSERT INTO bonus_credits (id_user, name_order, id_campaign, amount, credited_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (name_order, id_campaign) DO NOTHING
+`
+
+type CreateBonusCreditParams struct {
+	IDUser     string
+	NameOrder  string
+	IDCampaign int32
+	Amount     pgtype.Numeric
+	CreditedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateBonusCredit(ctx context.Context, arg CreateBonusCreditParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createBonusCredit,
+		arg.IDUser,
+		arg.NameOrder,
+		arg.IDCampaign,
+		arg.Amount,
+		arg.CreditedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createCampaign = `-- name: CreateCampaign :one
+INSERT INTO campaigns (name_campaign, starts_at, ends_at, first_order_only, id_min_tier,
+                       registered_after, registered_before, bonus_type, bonus_value)
+VALUES ($1, $2, $3, $4,
+        $5,
+        $6, $7,
+        $8, $9)
+RETURNING id_campaign
+`
+
+type CreateCampaignParams struct {
+	NameCampaign     string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	FirstOrderOnly   bool
+	IDMinTier        pgtype.Int4
+	RegisteredAfter  pgtype.Timestamptz
+	RegisteredBefore pgtype.Timestamptz
+	BonusType        string
+	BonusValue       pgtype.Numeric
+}
+
+func (q *Queries) CreateCampaign(ctx context.Context, arg CreateCampaignParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createCampaign,
+		arg.NameCampaign,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.FirstOrderOnly,
+		arg.IDMinTier,
+		arg.RegisteredAfter,
+		arg.RegisteredBefore,
+		arg.BonusType,
+		arg.BonusValue,
+	)
+	var id_campaign int32
+	err := row.Scan(&id_campaign)
+	return id_campaign, err
+}
+
+const findCampaignByID = `-- name: FindCampaignByID :one
+SELECT campaigns.id_campaign, campaigns.name_campaign, campaigns.starts_at, campaigns.ends_at,
+       campaigns.first_order_only, tiers.name_tier AS min_tier, tiers.min_points AS min_tier_points,
+       campaigns.registered_after, campaigns.registered_before,
+       campaigns.bonus_type, campaigns.bonus_value
+FROM campaigns LEFT JOIN tiers ON campaigns.id_min_tier = tiers.id_tier
+WHERE campaigns.id_campaign=$1
+  AND campaigns.archived_at IS NULL
+`
+
+type FindCampaignByIDRow struct {
+	IDCampaign       int32
+	NameCampaign     string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	FirstOrderOnly   bool
+	MinTier          pgtype.Text
+	MinTierPoints    pgtype.Numeric
+	RegisteredAfter  pgtype.Timestamptz
+	RegisteredBefore pgtype.Timestamptz
+	BonusType        string
+	BonusValue       pgtype.Numeric
+}
+
+func (q *Queries) FindCampaignByID(ctx context.Context, idCampaign int32) (FindCampaignByIDRow, error) {
+	row := q.db.QueryRow(ctx, findCampaignByID, idCampaign)
+	var i FindCampaignByIDRow
+	err := row.Scan(
+		&i.IDCampaign,
+		&i.NameCampaign,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.FirstOrderOnly,
+		&i.MinTier,
+		&i.MinTierPoints,
+		&i.RegisteredAfter,
+		&i.RegisteredBefore,
+		&i.BonusType,
+		&i.BonusValue,
+	)
+	return i, err
+}
+
+const getBonusCreditAmount = `-- name: GetBonusCreditAmount :one
+SELECT (COALESCE((SELECT sum(amount) FROM bonus_credits WHERE bonus_credits.id_user=$1), 0) +
+        COALESCE((SELECT sum(amount) FROM referral_credits WHERE referral_credits.id_user=$1), 0)
+       )::decimal(12,2) AS credited
+`
+
+func (q *Queries) GetBonusCreditAmount(ctx context.Context, idUser string) (pgtype.Numeric, error) {
+	row := q.db.QueryRow(ctx, getBonusCreditAmount, idUser)
+	var credited pgtype.Numeric
+	err := row.Scan(&credited)
+	return credited, err
+}
+
+const getOrderCampaignFacts = `-- name: GetOrderCampaignFacts :one
+SELECT acc_o.id_user,
+       user_hashes.registered_at,
+       acc_o.processed_at,
+       COALESCE(acc_o.raw_amount, 0)::decimal(12,2) AS raw_amount,
+       NOT EXISTS(SELECT 1
+                  FROM accrued_orders AS prev
+                  WHERE prev.id_user = acc_o.id_user
+                    AND prev.name_order <> acc_o.name_order
+                    AND prev.processed_at IS NOT NULL
+                    AND prev.processed_at <= acc_o.processed_at) AS is_first_order,
+       COALESCE((SELECT tiers.min_points
+                 FROM user_tiers JOIN tiers ON user_tiers.id_tier = tiers.id_tier
+                 WHERE user_tiers.id_user = acc_o.id_user
+                 ORDER BY user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+                 LIMIT 1), 0)::decimal(12,2) AS tier_points
+FROM accrued_orders AS acc_o
+         JOIN user_hashes ON acc_o.id_user = user_hashes.id_user
+WHERE acc_o.name_order=$1
+`
+
+type GetOrderCampaignFactsRow struct {
+	IDUser       string
+	RegisteredAt pgtype.Timestamptz
+	ProcessedAt  pgtype.Timestamptz
+	RawAmount    pgtype.Numeric
+	IsFirstOrder bool
+	TierPoints   pgtype.Numeric
+}
+
+func (q *Queries) GetOrderCampaignFacts(ctx context.Context, nameOrder string) (GetOrderCampaignFactsRow, error) {
+	row := q.db.QueryRow(ctx, getOrderCampaignFacts, nameOrder)
+	var i GetOrderCampaignFactsRow
+	err := row.Scan(
+		&i.IDUser,
+		&i.RegisteredAt,
+		&i.ProcessedAt,
+		&i.RawAmount,
+		&i.IsFirstOrder,
+		&i.TierPoints,
+	)
+	return i, err
+}
+
+const listActiveCampaigns = `-- name: ListActiveCampaigns :many
+SELECT campaigns.id_campaign, campaigns.name_campaign, campaigns.starts_at, campaigns.ends_at,
+       campaigns.first_order_only, tiers.name_tier AS min_tier, tiers.min_points AS min_tier_points,
+       campaigns.registered_after, campaigns.registered_before,
+       campaigns.bonus_type, campaigns.bonus_value
+FROM campaigns LEFT JOIN tiers ON campaigns.id_min_tier = tiers.id_tier
+WHERE campaigns.archived_at IS NULL
+  AND campaigns.starts_at <= $1
+  AND campaigns.ends_at > $1
+ORDER BY campaigns.id_campaign
+`
+
+type ListActiveCampaignsRow struct {
+	IDCampaign       int32
+	NameCampaign     string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	FirstOrderOnly   bool
+	MinTier          pgtype.Text
+	MinTierPoints    pgtype.Numeric
+	RegisteredAfter  pgtype.Timestamptz
+	RegisteredBefore pgtype.Timestamptz
+	BonusType        string
+	BonusValue       pgtype.Numeric
+}
+
+func (q *Queries) ListActiveCampaigns(ctx context.Context, at pgtype.Timestamptz) ([]ListActiveCampaignsRow, error) {
+	rows, err := q.db.Query(ctx, listActiveCampaigns, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveCampaignsRow
+	for rows.Next() {
+		var i ListActiveCampaignsRow
+		if err := rows.Scan(
+			&i.IDCampaign,
+			&i.NameCampaign,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.FirstOrderOnly,
+			&i.MinTier,
+			&i.MinTierPoints,
+			&i.RegisteredAfter,
+			&i.RegisteredBefore,
+			&i.BonusType,
+			&i.BonusValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCampaigns = `-- name: ListCampaigns :many
+SELECT campaigns.id_campaign, campaigns.name_campaign, campaigns.starts_at, campaigns.ends_at,
+       campaigns.first_order_only, tiers.name_tier AS min_tier, tiers.min_points AS min_tier_points,
+       campaigns.registered_after, campaigns.registered_before,
+       campaigns.bonus_type, campaigns.bonus_value
+FROM campaigns LEFT JOIN tiers ON campaigns.id_min_tier = tiers.id_tier
+WHERE campaigns.archived_at IS NULL
+ORDER BY campaigns.starts_at DESC, campaigns.id_campaign DESC
+`
+
+type ListCampaignsRow struct {
+	IDCampaign       int32
+	NameCampaign     string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	FirstOrderOnly   bool
+	MinTier          pgtype.Text
+	MinTierPoints    pgtype.Numeric
+	RegisteredAfter  pgtype.Timestamptz
+	RegisteredBefore pgtype.Timestamptz
+	BonusType        string
+	BonusValue       pgtype.Numeric
+}
+
+func (q *Queries) ListCampaigns(ctx context.Context) ([]ListCampaignsRow, error) {
+	rows, err := q.db.Query(ctx, listCampaigns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCampaignsRow
+	for rows.Next() {
+		var i ListCampaignsRow
+		if err := rows.Scan(
+			&i.IDCampaign,
+			&i.NameCampaign,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.FirstOrderOnly,
+			&i.MinTier,
+			&i.MinTierPoints,
+			&i.RegisteredAfter,
+			&i.RegisteredBefore,
+			&i.BonusType,
+			&i.BonusValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCampaign = `-- name: UpdateCampaign :execresult
+UPDATE campaigns
+SET name_campaign=$1,
+    starts_at=$2,
+    ends_at=$3,
+    first_order_only=$4,
+    id_min_tier=$5,
+    registered_after=$6,
+    registered_before=$7,
+    bonus_type=$8,
+    bonus_value=$9
+WHERE id_campaign=$10
+  AND archived_at IS NULL
+`
+
+type UpdateCampaignParams struct {
+	NameCampaign     string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	FirstOrderOnly   bool
+	IDMinTier        pgtype.Int4
+	RegisteredAfter  pgtype.Timestamptz
+	RegisteredBefore pgtype.Timestamptz
+	BonusType        string
+	BonusValue       pgtype.Numeric
+	IDCampaign       int32
+}
+
+func (q *Queries) UpdateCampaign(ctx context.Context, arg UpdateCampaignParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, updateCampaign,
+		arg.NameCampaign,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.FirstOrderOnly,
+		arg.IDMinTier,
+		arg.RegisteredAfter,
+		arg.RegisteredBefore,
+		arg.BonusType,
+		arg.BonusValue,
+		arg.IDCampaign,
+	)
+}
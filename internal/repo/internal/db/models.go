@@ -8,13 +8,107 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccrualBudget struct {
+	NameProvider string
+	RateRpm      int64
+	Tokens       float64
+	RefilledAt   pgtype.Timestamptz
+	PausedUntil  pgtype.Timestamptz
+	CeilingRpm   int64
+	ChangedAt    pgtype.Timestamptz
+}
+
+type AccrualCallbackReplay struct {
+	Signature string
+	ExpiresAt pgtype.Timestamptz
+}
+
+type AccrualControl struct {
+	NameProvider string
+	Held         bool
+	MaxRequests  int64
+}
+
+type AccrualDiscrepancy struct {
+	IDDiscrepancy   int32
+	NameOrder       string
+	IDUser          string
+	StoredStatus    string
+	StoredRawAmount pgtype.Numeric
+	StoredAmount    pgtype.Numeric
+	AccrualStatus   string
+	AccrualAmount   pgtype.Numeric
+	IDAdjustment    pgtype.Int4
+	FoundAt         pgtype.Timestamptz
+}
+
+type AccrualReplica struct {
+	IDInstance   string
+	NameProvider string
+	Paused       bool
+	MaxRequests  int64
+	RateLimitRpm int64
+	Rpm          int64
+	InFlight     int64
+	Workers      int32
+	Throttled    bool
+	RetryAfter   pgtype.Timestamptz
+	ReportedAt   pgtype.Timestamptz
+}
+
 type AccruedOrder struct {
-	IDAccOrder int32
-	IDUser     string
-	NameOrder  string
-	UploadedAt pgtype.Timestamptz
-	IDStatus   int32
-	Amount     pgtype.Numeric
+	IDAccOrder     int32
+	IDUser         string
+	NameOrder      string
+	UploadedAt     pgtype.Timestamptz
+	IDStatus       int32
+	Amount         pgtype.Numeric
+	RawAmount      pgtype.Numeric
+	ProcessedAt    pgtype.Timestamptz
+	NextCheckAt    pgtype.Timestamptz
+	Attempts       int32
+	LeasedBy       pgtype.Text
+	LeaseUntil     pgtype.Timestamptz
+	Failures       int32
+	LastError      pgtype.Text
+	DeadLetteredAt pgtype.Timestamptz
+	ReconciledAt   pgtype.Timestamptz
+	HooksDueAt     pgtype.Timestamptz
+}
+
+type BalanceAdjustment struct {
+	IDAdjustment int32
+	IDUser       string
+	IDAdmin      string
+	Direction    string
+	Amount       pgtype.Numeric
+	ReasonCode   string
+	Comment      string
+	Forced       bool
+	CreatedAt    pgtype.Timestamptz
+}
+
+type BonusCredit struct {
+	IDBonusCredit int32
+	IDUser        string
+	NameOrder     string
+	IDCampaign    int32
+	Amount        pgtype.Numeric
+	CreditedAt    pgtype.Timestamptz
+}
+
+type Campaign struct {
+	IDCampaign       int32
+	NameCampaign     string
+	StartsAt         pgtype.Timestamptz
+	EndsAt           pgtype.Timestamptz
+	FirstOrderOnly   bool
+	IDMinTier        pgtype.Int4
+	RegisteredAfter  pgtype.Timestamptz
+	RegisteredBefore pgtype.Timestamptz
+	BonusType        string
+	BonusValue       pgtype.Numeric
+	ArchivedAt       pgtype.Timestamptz
 }
 
 type PasswordHash struct {
@@ -23,14 +117,49 @@ type PasswordHash struct {
 	HashPassword string
 }
 
+type Referral struct {
+	IDReferral int32
+	IDReferrer string
+	IDReferee  string
+	CreatedAt  pgtype.Timestamptz
+	NameOrder  pgtype.Text
+	RewardedAt pgtype.Timestamptz
+}
+
+type ReferralCredit struct {
+	IDReferralCredit int32
+	IDReferral       int32
+	IDUser           string
+	Amount           pgtype.Numeric
+	CreditedAt       pgtype.Timestamptz
+}
+
 type Status struct {
 	IDStatus   int32
 	NameStatus string
 }
 
+type Tier struct {
+	IDTier     int32
+	NameTier   string
+	MinPoints  pgtype.Numeric
+	Multiplier pgtype.Numeric
+}
+
 type UserHash struct {
-	IDUser    string
-	HashLogin string
+	IDUser       string
+	HashLogin    string
+	RegisteredAt pgtype.Timestamptz
+	ReferralCode pgtype.Text
+	Role         string
+}
+
+type UserTier struct {
+	IDUserTier    int32
+	IDUser        string
+	IDTier        int32
+	RollingPoints pgtype.Numeric
+	AssignedAt    pgtype.Timestamptz
 }
 
 type WithdrawnOrder struct {
@@ -12,6 +12,117 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimOrdersForProcessing = `-- name: ClaimOrdersForProcessing :many
+WITH due AS (
+    SELECT id_acc_order
+    FROM accrued_orders
+    WHERE id_status IN (
+        SELECT id_status
+        FROM statuses
+        WHERE name_status IN ('NEW', 'PROCESSING')
+    )
+      AND next_check_at <= now()
+      AND (lease_until IS NULL OR lease_until <= now())
+      AND accrued_orders.uploaded_at <= $1::timestamptz
+    ORDER BY uploaded_at, id_acc_order
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+), claimed AS (
+    UPDATE accrued_orders AS acc_o
+    SET id_status=(
+        SELECT id_status
+        FROM statuses
+        WHERE name_status='PROCESSING'),
+        leased_by=$3::text,
+        lease_until=$4
+    FROM due
+    WHERE acc_o.id_acc_order = due.id_acc_order
+    RETURNING acc_o.id_acc_order, acc_o.name_order, acc_o.uploaded_at
+)
+SELECT name_order FROM claimed
+ORDER BY uploaded_at, id_acc_order
+`
+
+type ClaimOrdersForProcessingParams struct {
+	UploadedBefore pgtype.Timestamptz
+	BatchSize      int32
+	LeasedBy       string
+	LeaseUntil     pgtype.Timestamptz
+}
+
+func (q *Queries) ClaimOrdersForProcessing(ctx context.Context, arg ClaimOrdersForProcessingParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, claimOrdersForProcessing,
+		arg.UploadedBefore,
+		arg.BatchSize,
+		arg.LeasedBy,
+		arg.LeaseUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name_order string
+		if err := rows.Scan(&name_order); err != nil {
+			return nil, err
+		}
+		items = append(items, name_order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimPendingHooks = `-- name: ClaimPendingHooks :many
+WITH due AS (
+    SELECT id_acc_order
+    FROM accrued_orders
+    WHERE hooks_due_at <= now()
+    ORDER BY hooks_due_at, id_acc_order
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE accrued_orders AS acc_o
+SET hooks_due_at=now() + interval '1 minute'
+FROM due
+WHERE acc_o.id_acc_order = due.id_acc_order
+RETURNING acc_o.name_order
+`
+
+// заказы, начисления по которым не записаны; повтор откладывается, пока его выполняет эта реплика
+func (q *Queries) ClaimPendingHooks(ctx context.Context, batchSize int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, claimPendingHooks, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name_order string
+		if err := rows.Scan(&name_order); err != nil {
+			return nil, err
+		}
+		items = append(items, name_order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeHooks = `-- name: CompleteHooks :exec
+UPDATE accrued_orders
+SET hooks_due_at=NULL
+WHERE name_order=$1
+`
+
+func (q *Queries) CompleteHooks(ctx context.Context, nameOrder string) error {
+	_, err := q.db.Exec(ctx, completeHooks, nameOrder)
+	return err
+}
+
 const createAccrual = `-- name: CreateAccrual :exec
 INSERT INTO accrued_orders (id_user, name_order, uploaded_at, id_status)
 VALUES ($1, $2, $3,
@@ -71,6 +182,37 @@ func (q *Queries) FindOrderByID(ctx context.Context, nameOrder string) (string,
 	return id_user, err
 }
 
+const getAccrualSchedule = `-- name: GetAccrualSchedule :one
+SELECT acc_o.attempts, acc_o.failures, acc_o.uploaded_at
+FROM accrued_orders AS acc_o
+         JOIN statuses ON acc_o.id_status = statuses.id_status
+WHERE acc_o.name_order=$1
+  AND statuses.name_status IN ('NEW', 'PROCESSING')
+  -- RescheduleAccrual идёт в той же транзакции: заказ чужой активной аренды не переносим
+  AND (acc_o.leased_by IS NULL
+       OR acc_o.leased_by = $2::text
+       OR acc_o.lease_until <= now())
+FOR UPDATE OF acc_o
+`
+
+type GetAccrualScheduleParams struct {
+	NameOrder string
+	LeasedBy  string
+}
+
+type GetAccrualScheduleRow struct {
+	Attempts   int32
+	Failures   int32
+	UploadedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetAccrualSchedule(ctx context.Context, arg GetAccrualScheduleParams) (GetAccrualScheduleRow, error) {
+	row := q.db.QueryRow(ctx, getAccrualSchedule, arg.NameOrder, arg.LeasedBy)
+	var i GetAccrualScheduleRow
+	err := row.Scan(&i.Attempts, &i.Failures, &i.UploadedAt)
+	return i, err
+}
+
 const getAccruedAmount = `-- name: GetAccruedAmount :one
 SELECT sum(amount)::decimal(12,2) as accrued
 FROM accrued_orders
@@ -143,6 +285,52 @@ func (q *Queries) ListAccrualsByUserID(ctx context.Context, idUser string) ([]Li
 	return items, nil
 }
 
+const listDeadLetters = `-- name: ListDeadLetters :many
+SELECT acc_o.name_order, acc_o.id_user, acc_o.uploaded_at,
+       acc_o.failures, COALESCE(acc_o.last_error, '')::text AS last_error,
+       acc_o.dead_lettered_at
+FROM accrued_orders AS acc_o
+         JOIN statuses ON acc_o.id_status = statuses.id_status
+WHERE statuses.name_status = 'DEAD_LETTER'
+ORDER BY acc_o.dead_lettered_at, acc_o.id_acc_order
+`
+
+type ListDeadLettersRow struct {
+	NameOrder      string
+	IDUser         string
+	UploadedAt     pgtype.Timestamptz
+	Failures       int32
+	LastError      string
+	DeadLetteredAt pgtype.Timestamptz
+}
+
+func (q *Queries) ListDeadLetters(ctx context.Context) ([]ListDeadLettersRow, error) {
+	rows, err := q.db.Query(ctx, listDeadLetters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDeadLettersRow
+	for rows.Next() {
+		var i ListDeadLettersRow
+		if err := rows.Scan(
+			&i.NameOrder,
+			&i.IDUser,
+			&i.UploadedAt,
+			&i.Failures,
+			&i.LastError,
+			&i.DeadLetteredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listWithdrawalsByUser = `-- name: ListWithdrawalsByUser :many
 SELECT name_order, amount, processed_at
 FROM withdrawn_orders
@@ -176,51 +364,213 @@ func (q *Queries) ListWithdrawalsByUser(ctx context.Context, idUser string) ([]L
 	return items, nil
 }
 
-const selectOrdersForProcessing = `-- name: SelectOrdersForProcessing :many
-SELECT name_order FROM accrued_orders
+const recheckOrders = `-- name: RecheckOrders :execrows
+UPDATE accrued_orders
+SET next_check_at=now()
 WHERE id_status IN (
     SELECT id_status
     FROM statuses
-    WHERE name_status IN ('NEW', 'PROCESSING')
-)
+    WHERE name_status IN ('NEW', 'PROCESSING'))
+  AND (lease_until IS NULL OR lease_until <= now())
+  AND name_order = ANY($1::text[])
 `
 
-func (q *Queries) SelectOrdersForProcessing(ctx context.Context) ([]string, error) {
-	rows, err := q.db.Query(ctx, selectOrdersForProcessing)
+// заказ в аренде уже ждёт ответа accrual: его не трогаем, чтобы не спросить дважды
+func (q *Queries) RecheckOrders(ctx context.Context, orders []string) (int64, error) {
+	result, err := q.db.Exec(ctx, recheckOrders, orders)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
-	var items []string
-	for rows.Next() {
-		var name_order string
-		if err := rows.Scan(&name_order); err != nil {
-			return nil, err
-		}
-		items = append(items, name_order)
+	return result.RowsAffected(), nil
+}
+
+const releaseLeases = `-- name: ReleaseLeases :execrows
+UPDATE accrued_orders
+SET leased_by=NULL,
+    lease_until=NULL
+WHERE leased_by=$1::text
+  AND name_order = ANY($2::text[])
+`
+
+type ReleaseLeasesParams struct {
+	LeasedBy string
+	Orders   []string
+}
+
+// заказы, которые реплика захватила, но не успела отдать агенту, сразу доступны другим
+func (q *Queries) ReleaseLeases(ctx context.Context, arg ReleaseLeasesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseLeases, arg.LeasedBy, arg.Orders)
+	if err != nil {
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected(), nil
+}
+
+const requeueDeadLetters = `-- name: RequeueDeadLetters :execrows
+UPDATE accrued_orders
+SET id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status='PROCESSING'),
+    attempts=0,
+    failures=0,
+    dead_lettered_at=NULL,
+    next_check_at=now(),
+    lease_until=NULL,
+    leased_by=NULL
+WHERE id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status='DEAD_LETTER')
+  AND (cardinality($1::text[]) = 0
+       OR name_order = ANY($1::text[]))
+`
+
+func (q *Queries) RequeueDeadLetters(ctx context.Context, orders []string) (int64, error) {
+	result, err := q.db.Exec(ctx, requeueDeadLetters, orders)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const updateAccrualStatus = `-- name: UpdateAccrualStatus :execresult
+const rescheduleAccrual = `-- name: RescheduleAccrual :exec
 UPDATE accrued_orders
 SET id_status=(
     SELECT id_status
     FROM statuses
-    WHERE name_status=$2),
-    amount=$3
-WHERE name_order=$1
+    WHERE name_status=$1::text),
+    attempts=attempts + 1,
+    failures=$2,
+    last_error=COALESCE($3, last_error),
+    dead_lettered_at=CASE
+        WHEN $1::text = 'DEAD_LETTER' THEN now()
+    END,
+    next_check_at=$4,
+    leased_by=NULL,
+    lease_until=NULL
+WHERE name_order=$5
+`
+
+type RescheduleAccrualParams struct {
+	NameStatus  string
+	Failures    int32
+	LastError   pgtype.Text
+	NextCheckAt pgtype.Timestamptz
+	NameOrder   string
+}
+
+func (q *Queries) RescheduleAccrual(ctx context.Context, arg RescheduleAccrualParams) error {
+	_, err := q.db.Exec(ctx, rescheduleAccrual,
+		arg.NameStatus,
+		arg.Failures,
+		arg.LastError,
+		arg.NextCheckAt,
+		arg.NameOrder,
+	)
+	return err
+}
+
+const updateAccrualStatus = `-- name: UpdateAccrualStatus :execresult
+UPDATE accrued_orders AS acc_o
+SET id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status=$1::text),
+    raw_amount=$2,
+    amount=round($2 * COALESCE(
+        (SELECT tiers.multiplier
+         FROM user_tiers JOIN tiers ON user_tiers.id_tier = tiers.id_tier
+         WHERE user_tiers.id_user = acc_o.id_user
+         ORDER BY user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+         LIMIT 1),
+        (SELECT tiers.multiplier
+         FROM tiers
+         ORDER BY tiers.min_points
+         LIMIT 1),
+        1.00), 2),
+    processed_at=CASE
+        WHEN $1::text = 'PROCESSED' THEN now()
+        ELSE acc_o.processed_at
+    END,
+    -- начисления по обработанному заказу записываются после статуса: до CompleteHooks
+    -- заказ ждёт повтора, даже если реплика упадёт между записью статуса и начислением
+    hooks_due_at=CASE
+        WHEN $1::text = 'PROCESSED' THEN now() + interval '1 minute'
+        ELSE acc_o.hooks_due_at
+    END,
+    leased_by=NULL,
+    lease_until=NULL
+WHERE name_order=$3
+  AND id_status IN (
+    SELECT id_status
+    FROM statuses
+    WHERE name_status IN ('NEW', 'PROCESSING', 'REVIEW', 'DEAD_LETTER'))
+  -- опоздавший ответ не трогает заказ, который уже захватила другая реплика
+  AND (leased_by IS NULL
+       OR leased_by = $4::text
+       OR lease_until <= now())
 `
 
 type UpdateAccrualStatusParams struct {
-	NameOrder  string
 	NameStatus string
 	Amount     pgtype.Numeric
+	NameOrder  string
+	LeasedBy   string
 }
 
 func (q *Queries) UpdateAccrualStatus(ctx context.Context, arg UpdateAccrualStatusParams) (pgconn.CommandTag, error) {
-	return q.db.Exec(ctx, updateAccrualStatus, arg.NameOrder, arg.NameStatus, arg.Amount)
+	return q.db.Exec(ctx, updateAccrualStatus,
+		arg.NameStatus,
+		arg.Amount,
+		arg.NameOrder,
+		arg.LeasedBy,
+	)
+}
+
+const updatePushedAccrualStatus = `-- name: UpdatePushedAccrualStatus :execresult
+UPDATE accrued_orders AS acc_o
+SET id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status=$1::text),
+    raw_amount=$2,
+    amount=round($2 * COALESCE(
+        (SELECT tiers.multiplier
+         FROM user_tiers JOIN tiers ON user_tiers.id_tier = tiers.id_tier
+         WHERE user_tiers.id_user = acc_o.id_user
+         ORDER BY user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+         LIMIT 1),
+        (SELECT tiers.multiplier
+         FROM tiers
+         ORDER BY tiers.min_points
+         LIMIT 1),
+        1.00), 2),
+    processed_at=CASE
+        WHEN $1::text = 'PROCESSED' THEN now()
+        ELSE acc_o.processed_at
+    END,
+    -- как в UpdateAccrualStatus
+    hooks_due_at=CASE
+        WHEN $1::text = 'PROCESSED' THEN now() + interval '1 minute'
+        ELSE acc_o.hooks_due_at
+    END,
+    leased_by=NULL,
+    lease_until=NULL
+WHERE name_order=$3
+  AND id_status IN (
+    SELECT id_status
+    FROM statuses
+    WHERE name_status IN ('NEW', 'PROCESSING', 'REVIEW', 'DEAD_LETTER'))
+`
+
+type UpdatePushedAccrualStatusParams struct {
+	NameStatus string
+	Amount     pgtype.Numeric
+	NameOrder  string
+}
+
+// финальный результат, который accrual прислал сам, записывается и поверх чужой аренды
+func (q *Queries) UpdatePushedAccrualStatus(ctx context.Context, arg UpdatePushedAccrualStatusParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, updatePushedAccrualStatus, arg.NameStatus, arg.Amount, arg.NameOrder)
 }
@@ -0,0 +1,160 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: reconciliation.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const applyReconciledResult = `-- name: ApplyReconciledResult :exec
+UPDATE accrued_orders
+SET id_status=(
+    SELECT id_status
+    FROM statuses
+    WHERE name_status=$1::text),
+    raw_amount=$2
+WHERE name_order=$3
+`
+
+type ApplyReconciledResultParams struct {
+	NameStatus string
+	RawAmount  pgtype.Numeric
+	NameOrder  string
+}
+
+// amount не меняется: разница начислена корректировкой, связанной с расхождением
+func (q *Queries) ApplyReconciledResult(ctx context.Context, arg ApplyReconciledResultParams) error {
+	_, err := q.db.Exec(ctx, applyReconciledResult, arg.NameStatus, arg.RawAmount, arg.NameOrder)
+	return err
+}
+
+const createDiscrepancy = `-- name: CreateDiscrepancy :one
+INSERT INTO accrual_discrepancies (name_order, id_user, stored_status, stored_raw_amount, stored_amount,
+                                   accrual_status, accrual_amount)
+SELECT $1, $2, $3, $4,
+       $5, $6, $7
+WHERE NOT EXISTS(
+    SELECT 1
+    FROM (SELECT d.accrual_status, d.accrual_amount
+          FROM accrual_discrepancies AS d
+          WHERE d.name_order = $1
+          ORDER BY d.found_at DESC, d.id_discrepancy DESC
+          LIMIT 1) AS last
+    WHERE last.accrual_status = $6
+      AND last.accrual_amount = $7)
+RETURNING id_discrepancy, found_at
+`
+
+type CreateDiscrepancyParams struct {
+	NameOrder       string
+	IDUser          string
+	StoredStatus    string
+	StoredRawAmount pgtype.Numeric
+	StoredAmount    pgtype.Numeric
+	AccrualStatus   string
+	AccrualAmount   pgtype.Numeric
+}
+
+type CreateDiscrepancyRow struct {
+	IDDiscrepancy int32
+	FoundAt       pgtype.Timestamptz
+}
+
+// то же расхождение, найденное повторно, не записывается и не исправляется второй раз;
+// сравниваем только с последним: если accrual вернулся к прежнему ответу, это новое расхождение
+func (q *Queries) CreateDiscrepancy(ctx context.Context, arg CreateDiscrepancyParams) (CreateDiscrepancyRow, error) {
+	row := q.db.QueryRow(ctx, createDiscrepancy,
+		arg.NameOrder,
+		arg.IDUser,
+		arg.StoredStatus,
+		arg.StoredRawAmount,
+		arg.StoredAmount,
+		arg.AccrualStatus,
+		arg.AccrualAmount,
+	)
+	var i CreateDiscrepancyRow
+	err := row.Scan(&i.IDDiscrepancy, &i.FoundAt)
+	return i, err
+}
+
+const listOrdersForReconciliation = `-- name: ListOrdersForReconciliation :many
+SELECT acc_o.name_order, acc_o.id_user, statuses.name_status,
+       COALESCE(acc_o.raw_amount, 0)::decimal(12,2) AS raw_amount,
+       (COALESCE(acc_o.amount, 0) + COALESCE(
+           (SELECT sum(CASE WHEN adj.direction = 'credit' THEN adj.amount ELSE -adj.amount END)
+            FROM accrual_discrepancies AS d
+                     JOIN balance_adjustments AS adj ON d.id_adjustment = adj.id_adjustment
+            WHERE d.name_order = acc_o.name_order), 0))::decimal(12,2) AS amount
+FROM accrued_orders AS acc_o
+         JOIN statuses ON acc_o.id_status = statuses.id_status
+WHERE statuses.name_status IN ('PROCESSED', 'INVALID')
+ORDER BY acc_o.reconciled_at NULLS FIRST, acc_o.processed_at, acc_o.id_acc_order
+LIMIT NULLIF($1::int, 0)
+`
+
+type ListOrdersForReconciliationRow struct {
+	NameOrder  string
+	IDUser     string
+	NameStatus string
+	RawAmount  pgtype.Numeric
+	Amount     pgtype.Numeric
+}
+
+// amount -- всё начисленное по заказу: исходное начисление и корректировки прошлых сверок
+func (q *Queries) ListOrdersForReconciliation(ctx context.Context, batchSize int32) ([]ListOrdersForReconciliationRow, error) {
+	rows, err := q.db.Query(ctx, listOrdersForReconciliation, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrdersForReconciliationRow
+	for rows.Next() {
+		var i ListOrdersForReconciliationRow
+		if err := rows.Scan(
+			&i.NameOrder,
+			&i.IDUser,
+			&i.NameStatus,
+			&i.RawAmount,
+			&i.Amount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOrderReconciled = `-- name: MarkOrderReconciled :exec
+UPDATE accrued_orders
+SET reconciled_at=now()
+WHERE name_order=$1
+`
+
+func (q *Queries) MarkOrderReconciled(ctx context.Context, nameOrder string) error {
+	_, err := q.db.Exec(ctx, markOrderReconciled, nameOrder)
+	return err
+}
+
+const setDiscrepancyAdjustment = `-- name: SetDiscrepancyAdjustment :exec
+UPDATE accrual_discrepancies
+SET id_adjustment=$2
+WHERE id_discrepancy=$1
+`
+
+type SetDiscrepancyAdjustmentParams struct {
+	IDDiscrepancy int32
+	IDAdjustment  pgtype.Int4
+}
+
+func (q *Queries) SetDiscrepancyAdjustment(ctx context.Context, arg SetDiscrepancyAdjustmentParams) error {
+	_, err := q.db.Exec(ctx, setDiscrepancyAdjustment, arg.IDDiscrepancy, arg.IDAdjustment)
+	return err
+}
@@ -0,0 +1,157 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: referrals.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimReferralReward = `-- name: ClaimReferralReward :one
+UPDATE referrals
+SET rewarded_at=now(),
+    name_order=$1::varchar
+WHERE rewarded_at IS NULL
+  AND id_referee=(SELECT ao.id_user FROM accrued_orders ao WHERE ao.name_order=$1)
+RETURNING id_referral, id_referrer, id_referee
+`
+
+type ClaimReferralRewardRow struct {
+	IDReferral int32
+	IDReferrer string
+	IDReferee  string
+}
+
+func (q *Queries) ClaimReferralReward(ctx context.Context, nameOrder string) (ClaimReferralRewardRow, error) {
+	row := q.db.QueryRow(ctx, claimReferralReward, nameOrder)
+	var i ClaimReferralRewardRow
+	err := row.Scan(&i.IDReferral, &i.IDReferrer, &i.IDReferee)
+	return i, err
+}
+
+const createReferral = `-- name: CreateReferral :exec
+INSERT INTO referrals (id_referrer, id_referee)
+VALUES ($1, $2)
+`
+
+type CreateReferralParams struct {
+	IDReferrer string
+	IDReferee  string
+}
+
+func (q *Queries) CreateReferral(ctx context.Context, arg CreateReferralParams) error {
+	_, err := q.db.Exec(ctx, createReferral, arg.IDReferrer, arg.IDReferee)
+	return err
+}
+
+const createReferralCredit = `-- name: CreateReferralCredit :exec
+INSERT INTO referral_credits (id_referral, id_user, amount, credited_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (id_referral, id_user) DO NOTHING
+`
+
+type CreateReferralCreditParams struct {
+	IDReferral int32
+	IDUser     string
+	Amount     pgtype.Numeric
+	CreditedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateReferralCredit(ctx context.Context, arg CreateReferralCreditParams) error {
+	_, err := q.db.Exec(ctx, createReferralCredit,
+		arg.IDReferral,
+		arg.IDUser,
+		arg.Amount,
+		arg.CreditedAt,
+	)
+	return err
+}
+
+const findUserIDByReferralCode = `-- name: FindUserIDByReferralCode :one
+SELECT id_user
+FROM user_hashes
+WHERE referral_code=$1
+`
+
+func (q *Queries) FindUserIDByReferralCode(ctx context.Context, referralCode pgtype.Text) (string, error) {
+	row := q.db.QueryRow(ctx, findUserIDByReferralCode, referralCode)
+	var id_user string
+	err := row.Scan(&id_user)
+	return id_user, err
+}
+
+const getReferralCode = `-- name: GetReferralCode :one
+SELECT COALESCE(referral_code, '')::text AS referral_code
+FROM user_hashes
+WHERE id_user=$1
+`
+
+func (q *Queries) GetReferralCode(ctx context.Context, idUser string) (string, error) {
+	row := q.db.QueryRow(ctx, getReferralCode, idUser)
+	var referral_code string
+	err := row.Scan(&referral_code)
+	return referral_code, err
+}
+
+const isReferralAncestor = `-- name: IsReferralAncestor :one
+WITH RECURSIVE chain AS (
+    SELECT r.id_referrer
+    FROM referrals r
+    WHERE r.id_referee=$2
+    UNION
+    SELECT r.id_referrer
+    FROM referrals r JOIN chain c ON r.id_referee=c.id_referrer
+)
+SELECT EXISTS(SELECT 1 FROM chain WHERE id_referrer=$1::text)
+`
+
+type IsReferralAncestorParams struct {
+	Ancestor   string
+	Descendant string
+}
+
+// проверяет, есть ли ancestor в цепочке пригласивших descendant
+func (q *Queries) IsReferralAncestor(ctx context.Context, arg IsReferralAncestorParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isReferralAncestor, arg.Ancestor, arg.Descendant)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listReferralsByReferrer = `-- name: ListReferralsByReferrer :many
+SELECT r.created_at, r.rewarded_at, COALESCE(rc.amount, 0)::decimal(12,2) AS earned
+FROM referrals r
+    LEFT JOIN referral_credits rc ON rc.id_referral=r.id_referral AND rc.id_user=r.id_referrer
+WHERE r.id_referrer=$1
+ORDER BY r.created_at DESC
+`
+
+type ListReferralsByReferrerRow struct {
+	CreatedAt  pgtype.Timestamptz
+	RewardedAt pgtype.Timestamptz
+	Earned     pgtype.Numeric
+}
+
+func (q *Queries) ListReferralsByReferrer(ctx context.Context, idReferrer string) ([]ListReferralsByReferrerRow, error) {
+	rows, err := q.db.Query(ctx, listReferralsByReferrer, idReferrer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReferralsByReferrerRow
+	for rows.Next() {
+		var i ListReferralsByReferrerRow
+		if err := rows.Scan(&i.CreatedAt, &i.RewardedAt, &i.Earned); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,130 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: tiers.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const findTierIDByName = `-- name: FindTierIDByName :one
+SELECT id_tier
+FROM tiers
+WHERE name_tier=$1
+`
+
+func (q *Queries) FindTierIDByName(ctx context.Context, nameTier string) (int32, error) {
+	row := q.db.QueryRow(ctx, findTierIDByName, nameTier)
+	var id_tier int32
+	err := row.Scan(&id_tier)
+	return id_tier, err
+}
+
+const getCurrentUserTier = `-- name: GetCurrentUserTier :one
+SELECT tiers.name_tier, tiers.min_points, tiers.multiplier
+FROM user_tiers JOIN tiers ON user_tiers.id_tier = tiers.id_tier
+WHERE user_tiers.id_user=$1
+ORDER BY user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+LIMIT 1
+`
+
+type GetCurrentUserTierRow struct {
+	NameTier   string
+	MinPoints  pgtype.Numeric
+	Multiplier pgtype.Numeric
+}
+
+func (q *Queries) GetCurrentUserTier(ctx context.Context, idUser string) (GetCurrentUserTierRow, error) {
+	row := q.db.QueryRow(ctx, getCurrentUserTier, idUser)
+	var i GetCurrentUserTierRow
+	err := row.Scan(&i.NameTier, &i.MinPoints, &i.Multiplier)
+	return i, err
+}
+
+const getRollingAccrued = `-- name: GetRollingAccrued :one
+SELECT COALESCE(sum(raw_amount), 0)::decimal(12,2) AS points
+FROM accrued_orders
+WHERE id_user=$1
+  AND processed_at >= now() - INTERVAL '12 months'
+`
+
+func (q *Queries) GetRollingAccrued(ctx context.Context, idUser string) (pgtype.Numeric, error) {
+	row := q.db.QueryRow(ctx, getRollingAccrued, idUser)
+	var points pgtype.Numeric
+	err := row.Scan(&points)
+	return points, err
+}
+
+const listTiers = `-- name: ListTiers :many
+SELECT name_tier, min_points, multiplier
+FROM tiers
+ORDER BY min_points
+`
+
+type ListTiersRow struct {
+	NameTier   string
+	MinPoints  pgtype.Numeric
+	Multiplier pgtype.Numeric
+}
+
+func (q *Queries) ListTiers(ctx context.Context) ([]ListTiersRow, error) {
+	rows, err := q.db.Query(ctx, listTiers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTiersRow
+	for rows.Next() {
+		var i ListTiersRow
+		if err := rows.Scan(&i.NameTier, &i.MinPoints, &i.Multiplier); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recalculateTiers = `-- name: RecalculateTiers :execrows
+WITH rolling AS (
+    SELECT user_hashes.id_user,
+           COALESCE(sum(acc_o.raw_amount) FILTER (
+               WHERE acc_o.processed_at >= now() - INTERVAL '12 months'), 0)::decimal(12,2) AS points
+    FROM user_hashes
+             LEFT JOIN accrued_orders AS acc_o ON acc_o.id_user = user_hashes.id_user
+    GROUP BY user_hashes.id_user
+), computed AS (
+    SELECT rolling.id_user,
+           rolling.points,
+           (SELECT tiers.id_tier
+            FROM tiers
+            WHERE tiers.min_points <= rolling.points
+            ORDER BY tiers.min_points DESC
+            LIMIT 1) AS id_tier
+    FROM rolling
+), latest AS (
+    SELECT DISTINCT ON (user_tiers.id_user) user_tiers.id_user, user_tiers.id_tier
+    FROM user_tiers
+    ORDER BY user_tiers.id_user, user_tiers.assigned_at DESC, user_tiers.id_user_tier DESC
+)
+INSERT INTO user_tiers (id_user, id_tier, rolling_points, assigned_at)
+SELECT computed.id_user, computed.id_tier, computed.points, now()
+FROM computed
+         LEFT JOIN latest ON latest.id_user = computed.id_user
+WHERE computed.id_tier IS NOT NULL
+  AND computed.id_tier IS DISTINCT FROM latest.id_tier
+`
+
+func (q *Queries) RecalculateTiers(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, recalculateTiers)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
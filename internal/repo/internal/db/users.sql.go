@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const exists = `-- name: Exists :one
@@ -23,7 +25,8 @@ func (q *Queries) Exists(ctx context.Context, hashLogin string) (bool, error) {
 }
 
 const findUserByID = `-- name: FindUserByID :one
-SELECT user_hashes.id_user, user_hashes.hash_login, ph.hash_password
+SELECT user_hashes.id_user, user_hashes.hash_login, ph.hash_password, user_hashes.role,
+       COALESCE(user_hashes.referral_code, '')::text AS referral_code
 FROM user_hashes JOIN password_hashes ph on user_hashes.id_user = ph.id_user
 WHERE user_hashes.id_user = $1
 `
@@ -32,17 +35,26 @@ type FindUserByIDRow struct {
 	IDUser       string
 	HashLogin    string
 	HashPassword string
+	Role         string
+	ReferralCode string
 }
 
 func (q *Queries) FindUserByID(ctx context.Context, idUser string) (FindUserByIDRow, error) {
 	row := q.db.QueryRow(ctx, findUserByID, idUser)
 	var i FindUserByIDRow
-	err := row.Scan(&i.IDUser, &i.HashLogin, &i.HashPassword)
+	err := row.Scan(
+		&i.IDUser,
+		&i.HashLogin,
+		&i.HashPassword,
+		&i.Role,
+		&i.ReferralCode,
+	)
 	return i, err
 }
 
 const findUserByLogin = `-- name: FindUserByLogin :one
-SELECT user_hashes.id_user, user_hashes.hash_login, ph.hash_password
+SELECT user_hashes.id_user, user_hashes.hash_login, ph.hash_password, user_hashes.role,
+       COALESCE(user_hashes.referral_code, '')::text AS referral_code
 FROM user_hashes JOIN password_hashes ph on user_hashes.id_user = ph.id_user
 WHERE hash_login = $1
 `
@@ -51,12 +63,20 @@ type FindUserByLoginRow struct {
 	IDUser       string
 	HashLogin    string
 	HashPassword string
+	Role         string
+	ReferralCode string
 }
 
 func (q *Queries) FindUserByLogin(ctx context.Context, hashLogin string) (FindUserByLoginRow, error) {
 	row := q.db.QueryRow(ctx, findUserByLogin, hashLogin)
 	var i FindUserByLoginRow
-	err := row.Scan(&i.IDUser, &i.HashLogin, &i.HashPassword)
+	err := row.Scan(
+		&i.IDUser,
+		&i.HashLogin,
+		&i.HashPassword,
+		&i.Role,
+		&i.ReferralCode,
+	)
 	return i, err
 }
 
@@ -76,19 +96,39 @@ func (q *Queries) InsertPasswordHash(ctx context.Context, arg InsertPasswordHash
 }
 
 const insertUser = `-- name: InsertUser :one
-INSERT INTO user_hashes (id_user, hash_login)
-VALUES ($1, $2)
+INSERT INTO user_hashes (id_user, hash_login, referral_code)
+VALUES ($1, $2, $3)
 RETURNING id_user
 `
 
 type InsertUserParams struct {
-	IDUser    string
-	HashLogin string
+	IDUser       string
+	HashLogin    string
+	ReferralCode pgtype.Text
 }
 
 func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (string, error) {
-	row := q.db.QueryRow(ctx, insertUser, arg.IDUser, arg.HashLogin)
+	row := q.db.QueryRow(ctx, insertUser, arg.IDUser, arg.HashLogin, arg.ReferralCode)
 	var id_user string
 	err := row.Scan(&id_user)
 	return id_user, err
 }
+
+const setUserRole = `-- name: SetUserRole :execrows
+UPDATE user_hashes
+SET role=$2
+WHERE id_user=$1
+`
+
+type SetUserRoleParams struct {
+	IDUser string
+	Role   string
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setUserRole, arg.IDUser, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
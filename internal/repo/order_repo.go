@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/ledger"
 	"github.com/talx-hub/gopher-bonus/internal/model/order"
 	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
 	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
@@ -179,22 +180,29 @@ func listWithdrawals(ctx context.Context,
 	return orders, nil
 }
 
-func (r *OrderRepository) UpdateAccrualStatus(ctx context.Context, o *order.Order) error {
+// UpdateAccrualStatus записывает финальный ответ accrual о заказе и снимает аренду owner'а.
+// Заказ в чужой активной аренде не меняется: ответ на него сохранит та реплика.
+func (r *OrderRepository) UpdateAccrualStatus(ctx context.Context, owner string, o *order.Order) error {
 	updateFn := func() (struct{}, error) {
 		queries := db.New(r.pool)
 		params := db.UpdateAccrualStatusParams{
 			NameStatus: string(o.Status),
 			NameOrder:  o.ID,
+			LeasedBy:   owner,
 		}
 		if o.Amount.TotalKopecks() != 0 {
 			params.Amount = o.Amount.ToPGNumeric()
 		}
 
 		res, err := queries.UpdateAccrualStatus(ctx, params)
-		if err != nil || res.RowsAffected() == 0 {
+		if err != nil {
 			return struct{}{}, fmt.Errorf("failed to update status->(%s) for order %s: %w",
 				string(o.Status), o.ID, err)
 		}
+		// заказа нет, он уже в финальном статусе или его опрашивает другая реплика
+		if res.RowsAffected() == 0 {
+			return struct{}{}, fmt.Errorf("pending order %s: %w", o.ID, serviceerrs.ErrNotFound)
+		}
 		return struct{}{}, nil
 	}
 
@@ -202,6 +210,190 @@ func (r *OrderRepository) UpdateAccrualStatus(ctx context.Context, o *order.Orde
 	return err //nolint: wrapcheck // error from wrapped function
 }
 
+// UpdatePushedAccrualStatus записывает финальный результат, который accrual прислал сам.
+// Он подписан accrual, поэтому записывается и для заказа в чужой аренде.
+func (r *OrderRepository) UpdatePushedAccrualStatus(ctx context.Context, o *order.Order) error {
+	updateFn := func() (struct{}, error) {
+		params := db.UpdatePushedAccrualStatusParams{
+			NameStatus: string(o.Status),
+			NameOrder:  o.ID,
+		}
+		if o.Amount.TotalKopecks() != 0 {
+			params.Amount = o.Amount.ToPGNumeric()
+		}
+
+		res, err := db.New(r.pool).UpdatePushedAccrualStatus(ctx, params)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to update status->(%s) for order %s: %w",
+				string(o.Status), o.ID, err)
+		}
+		// заказа нет или он уже в финальном статусе
+		if res.RowsAffected() == 0 {
+			return struct{}{}, fmt.Errorf("pending order %s: %w", o.ID, serviceerrs.ErrNotFound)
+		}
+		return struct{}{}, nil
+	}
+
+	_, err := WithRetry[struct{}](updateFn, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+// RescheduleAccrual откладывает следующий опрос accrual о заказе по b и снимает аренду owner'а.
+// Если accrual так и не узнал о заказе (check.Unknown) за b.Horizon, заказ уходит в REVIEW,
+// а после b.MaxFailures неудачных опросов подряд -- в DEAD_LETTER.
+// Заказ в чужой активной аренде не переносится.
+func (r *OrderRepository) RescheduleAccrual(ctx context.Context,
+	owner, orderID string, check order.Check, b *order.Backoff,
+) (order.Status, error) {
+	reschedule := func(ctx context.Context, tx connectionPool) (any, error) {
+		queries := db.New(tx)
+		schedule, err := queries.GetAccrualSchedule(ctx, db.GetAccrualScheduleParams{
+			NameOrder: orderID,
+			LeasedBy:  owner,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return order.Status(""), fmt.Errorf("pending order %s: %w", orderID, serviceerrs.ErrNotFound)
+		}
+		if err != nil {
+			return order.Status(""), fmt.Errorf(
+				"failed to get accrual schedule for order %s: %w", orderID, err)
+		}
+
+		now := time.Now().UTC()
+		status := order.StatusProcessing
+		failures := schedule.Failures
+		var lastError pgtype.Text
+		switch {
+		case check.Failed():
+			failures++
+			lastError = pgtype.Text{String: check.Err, Valid: true}
+			if b.DeadLetter(failures) {
+				status = order.StatusDeadLetter
+			}
+		case check.Answered || check.Unknown:
+			failures = 0
+			if check.Unknown && b.Expired(schedule.UploadedAt.Time, now) {
+				status = order.StatusReview
+			}
+		}
+		if err = queries.RescheduleAccrual(ctx, db.RescheduleAccrualParams{
+			NameStatus: string(status),
+			Failures:   failures,
+			LastError:  lastError,
+			NextCheckAt: pgtype.Timestamptz{
+				Time:  now.Add(b.Delay(schedule.Attempts)),
+				Valid: true,
+			},
+			NameOrder: orderID,
+		}); err != nil {
+			return order.Status(""), fmt.Errorf(
+				"failed to reschedule order %s: %w", orderID, err)
+		}
+		return status, nil
+	}
+
+	runWithTX := func() (order.Status, error) {
+		return WithTX[order.Status](ctx, r.pool, r.log, reschedule)
+	}
+	return WithRetry[order.Status](runWithTX, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+// ReleaseLeases снимает аренду owner'а с заказов orderIDs. Возвращает число освобождённых заказов.
+func (r *OrderRepository) ReleaseLeases(ctx context.Context, owner string, orderIDs []string) (int64, error) {
+	releaseFn := func() (int64, error) {
+		n, err := db.New(r.pool).ReleaseLeases(ctx, db.ReleaseLeasesParams{
+			LeasedBy: owner,
+			Orders:   orderIDs,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to release leases of %d orders: %w", len(orderIDs), err)
+		}
+		return n, nil
+	}
+	return WithRetry[int64](releaseFn, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+// ClaimPendingHooks возвращает до limit обработанных заказов, начисления по которым ещё не записаны,
+// и откладывает их следующий повтор, чтобы те же заказы не взяла другая реплика.
+func (r *OrderRepository) ClaimPendingHooks(ctx context.Context, limit int32) ([]string, error) {
+	claimFn := func() ([]string, error) {
+		orders, err := db.New(r.pool).ClaimPendingHooks(ctx, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim orders with pending hooks: %w", err)
+		}
+		return orders, nil
+	}
+	return WithRetry[[]string](claimFn, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+// CompleteHooks отмечает, что начисления по обработанному заказу записаны.
+func (r *OrderRepository) CompleteHooks(ctx context.Context, orderID string) error {
+	completeFn := func() (struct{}, error) {
+		if err := db.New(r.pool).CompleteHooks(ctx, orderID); err != nil {
+			return struct{}{}, fmt.Errorf("failed to complete hooks of order %s: %w", orderID, err)
+		}
+		return struct{}{}, nil
+	}
+	_, err := WithRetry[struct{}](completeFn, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *OrderRepository) ListDeadLetters(ctx context.Context) ([]order.DeadLetter, error) {
+	listFn := func() ([]db.ListDeadLettersRow, error) {
+		rows, err := db.New(r.pool).ListDeadLetters(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dead-lettered orders: %w", err)
+		}
+		return rows, nil
+	}
+	rows, err := WithRetry[[]db.ListDeadLettersRow](listFn, 0)
+	if err != nil {
+		return nil, err //nolint: wrapcheck // error from wrapped function
+	}
+
+	orders := make([]order.DeadLetter, len(rows))
+	for i, row := range rows {
+		orders[i] = order.DeadLetter{
+			UploadedAt:     row.UploadedAt.Time,
+			DeadLetteredAt: row.DeadLetteredAt.Time,
+			ID:             row.NameOrder,
+			UserID:         row.IDUser,
+			LastError:      row.LastError,
+			Failures:       row.Failures,
+		}
+	}
+	return orders, nil
+}
+
+// RequeueDeadLetters возвращает заказы из DEAD_LETTER в опрос.
+// Пустой orderIDs -- все заказы в DEAD_LETTER. Возвращает число возвращённых заказов.
+func (r *OrderRepository) RequeueDeadLetters(ctx context.Context, orderIDs []string) (int64, error) {
+	if orderIDs == nil {
+		orderIDs = []string{}
+	}
+	requeueFn := func() (int64, error) {
+		n, err := db.New(r.pool).RequeueDeadLetters(ctx, orderIDs)
+		if err != nil {
+			return 0, fmt.Errorf("failed to requeue dead-lettered orders: %w", err)
+		}
+		return n, nil
+	}
+	return WithRetry[int64](requeueFn, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+// RecheckOrders назначает немедленную проверку заказов, ещё ждущих ответа accrual.
+// Возвращает, сколько заказов будет проверено.
+func (r *OrderRepository) RecheckOrders(ctx context.Context, orderIDs []string) (int64, error) {
+	recheckFn := func() (int64, error) {
+		n, err := db.New(r.pool).RecheckOrders(ctx, orderIDs)
+		if err != nil {
+			return 0, fmt.Errorf("failed to schedule orders recheck: %w", err)
+		}
+		return n, nil
+	}
+	return WithRetry[int64](recheckFn, 0) //nolint: wrapcheck // error from wrapped function
+}
+
 func (r *OrderRepository) GetBalance(ctx context.Context, userID string,
 ) (model.Amount, model.Amount, error) {
 	type Balance struct {
@@ -245,12 +437,24 @@ func (r *OrderRepository) getBalanceTX(ctx context.Context,
 		return model.Amount{}, model.Amount{},
 			fmt.Errorf("failed to get accruals %w", err)
 	}
+	credited, err := getAmount(ctx, queries.GetBonusCreditAmount, userID)
+	if err != nil {
+		return model.Amount{}, model.Amount{},
+			fmt.Errorf("failed to get bonus credits: %w", err)
+	}
+	adjusted, err := getAmount(ctx, queries.GetAdjustmentAmount, userID)
+	if err != nil {
+		return model.Amount{}, model.Amount{},
+			fmt.Errorf("failed to get balance adjustments: %w", err)
+	}
 	withdrawn, err := getAmount(ctx, queries.GetWithdrawnAmount, userID)
 	if err != nil {
 		return model.Amount{}, model.Amount{},
 			fmt.Errorf("failed to get withdrawals: %w", err)
 	}
 
+	accrued = model.NewAmount(0,
+		accrued.TotalKopecks()+credited.TotalKopecks()+adjusted.TotalKopecks())
 	return accrued, withdrawn, nil
 }
 
@@ -267,14 +471,57 @@ func getAmount(ctx context.Context, amountQuery amountQuery, userID string) (mod
 	return model.NewAmount(0, 0), nil
 }
 
-func (r *OrderRepository) SelectOrdersForProcessing(ctx context.Context) ([]string, error) {
-	selectOrders := func() ([]string, error) {
+func (r *OrderRepository) ListHistory(ctx context.Context, userID string,
+) ([]ledger.Entry, error) {
+	listLogic := func() ([]ledger.Entry, error) {
+		queries := db.New(r.pool)
+		rows, err := queries.ListBalanceHistory(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list balance history for user %s: %w", userID, err)
+		}
+
+		entries := make([]ledger.Entry, len(rows))
+		for i, row := range rows {
+			amount, err := model.FromPGNumeric(row.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s amount in history: %w", row.Kind, err)
+			}
+			entries[i] = ledger.Entry{
+				At:        row.HappenedAt.Time,
+				Kind:      ledger.Kind(row.Kind),
+				Reference: row.Reference,
+				Amount:    amount,
+			}
+		}
+		return entries, nil
+	}
+
+	return WithRetry[[]ledger.Entry](listLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+// ClaimOrdersForProcessing захватывает до l.BatchSize заказов, которые пора опросить,
+// и переводит их в PROCESSING. Заказы, захваченные другой репликой, пропускаются.
+func (r *OrderRepository) ClaimOrdersForProcessing(ctx context.Context, l *order.Lease,
+) ([]string, error) {
+	claimOrders := func() ([]string, error) {
 		queries := db.New(r.pool)
-		orderNames, err := queries.SelectOrdersForProcessing(ctx)
+		now := time.Now().UTC()
+		orderNames, err := queries.ClaimOrdersForProcessing(ctx, db.ClaimOrdersForProcessingParams{
+			UploadedBefore: pgtype.Timestamptz{
+				Time:  now.Add(-l.MinAge),
+				Valid: true,
+			},
+			BatchSize: l.BatchSize,
+			LeasedBy:  l.Owner,
+			LeaseUntil: pgtype.Timestamptz{
+				Time:  now.Add(l.Duration),
+				Valid: true,
+			},
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to select orders for accrual calculation: %w", err)
+			return nil, fmt.Errorf("failed to claim orders for accrual calculation: %w", err)
 		}
 		return orderNames, nil
 	}
-	return WithRetry[[]string](selectOrders, 0) //nolint: wrapcheck // error from wrapped function
+	return WithRetry[[]string](claimOrders, 0) //nolint: wrapcheck // error from wrapped function
 }
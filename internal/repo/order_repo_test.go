@@ -274,6 +274,15 @@ func TestOrderRepository_UpdateOrder(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "update already processed order 6 (should error)",
+			order: order.Order{
+				Status: order.StatusProcessed,
+				ID:     "6",
+				Amount: model.NewAmount(0, 700),
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty order ID",
 			order: order.Order{
@@ -294,7 +303,7 @@ func TestOrderRepository_UpdateOrder(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := repo.UpdateAccrualStatus(ctx, &tt.order)
+			err := repo.UpdateAccrualStatus(ctx, "replica-1", &tt.order)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -493,3 +502,204 @@ func TestOrderRepository_GetBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderRepository_RescheduleAccrual(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewOrderRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/order_reschedule.sql"))
+
+	lease := &order.Lease{Owner: "replica-1", Duration: time.Minute, BatchSize: 10}
+	due, err := repo.ClaimOrdersForProcessing(ctx, lease)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backoff-old", "backoff-new"}, due)
+
+	b := order.NewBackoff(time.Hour, time.Hour, 24*time.Hour)
+	owner := lease.Owner
+
+	// опоздавший ответ другой реплики не трогает заказ в чужой аренде
+	_, err = repo.RescheduleAccrual(ctx, "replica-2", "backoff-new", order.Check{Answered: true}, b)
+	require.ErrorIs(t, err, serviceerrs.ErrNotFound)
+	err = repo.UpdateAccrualStatus(ctx, "replica-2", &order.Order{
+		ID:     "backoff-new",
+		Status: order.StatusProcessed,
+		Amount: model.NewAmount(100, 0),
+	})
+	require.ErrorIs(t, err, serviceerrs.ErrNotFound)
+
+	status, err := repo.RescheduleAccrual(ctx, owner, "backoff-new", order.Check{Unknown: true}, b)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusProcessing, status)
+
+	status, err = repo.RescheduleAccrual(ctx, owner, "backoff-old", order.Check{Answered: true}, b)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusProcessing, status)
+
+	due, err = repo.ClaimOrdersForProcessing(ctx, lease)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	status, err = repo.RescheduleAccrual(ctx, owner, "backoff-old", order.Check{Unknown: true}, b)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusReview, status)
+
+	_, err = repo.RescheduleAccrual(ctx, owner, "backoff-done", order.Check{}, b)
+	require.ErrorIs(t, err, serviceerrs.ErrNotFound)
+}
+
+func TestOrderRepository_DeadLetters(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewOrderRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/order_reschedule.sql"))
+
+	b := order.NewBackoff(time.Millisecond, time.Millisecond, 24*time.Hour)
+	b.MaxFailures = 2
+	failed := order.Check{Err: "accrual service error"}
+	const owner = "replica-1"
+
+	status, err := repo.RescheduleAccrual(ctx, owner, "backoff-new", failed, b)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusProcessing, status)
+
+	// удачный опрос сбрасывает счётчик ошибок
+	_, err = repo.RescheduleAccrual(ctx, owner, "backoff-new", order.Check{Answered: true}, b)
+	require.NoError(t, err)
+	status, err = repo.RescheduleAccrual(ctx, owner, "backoff-new", failed, b)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusProcessing, status)
+
+	// 429 или пауза агента -- не ответ accrual: счётчик не сбрасывается
+	_, err = repo.RescheduleAccrual(ctx, owner, "backoff-new", order.Check{}, b)
+	require.NoError(t, err)
+
+	status, err = repo.RescheduleAccrual(ctx, owner, "backoff-new", failed, b)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusDeadLetter, status)
+
+	dead, err := repo.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "backoff-new", dead[0].ID)
+	assert.Equal(t, "1", dead[0].UserID)
+	assert.Equal(t, "accrual service error", dead[0].LastError)
+	assert.Equal(t, int32(2), dead[0].Failures)
+	assert.False(t, dead[0].DeadLetteredAt.IsZero())
+
+	time.Sleep(10 * time.Millisecond)
+	lease := &order.Lease{Owner: "replica-1", Duration: time.Minute, BatchSize: 10}
+	claimed, err := repo.ClaimOrdersForProcessing(ctx, lease)
+	require.NoError(t, err)
+	assert.NotContains(t, claimed, "backoff-new")
+
+	n, err := repo.RequeueDeadLetters(ctx, []string{"backoff-old"})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = repo.RequeueDeadLetters(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	dead, err = repo.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+
+	claimed, err = repo.ClaimOrdersForProcessing(ctx, lease)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backoff-new"}, claimed)
+}
+
+func TestOrderRepository_ClaimOrdersForProcessing(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewOrderRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/order_reschedule.sql"))
+
+	first := &order.Lease{Owner: "replica-1", Duration: time.Minute, BatchSize: 1}
+	second := &order.Lease{Owner: "replica-2", Duration: time.Minute, BatchSize: 10}
+
+	claimed, err := repo.ClaimOrdersForProcessing(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backoff-old"}, claimed)
+
+	t.Run("leased orders are skipped", func(t *testing.T) {
+		claimed, err := repo.ClaimOrdersForProcessing(ctx, second)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"backoff-new"}, claimed)
+	})
+
+	t.Run("concurrent claims do not overlap", func(t *testing.T) {
+		_, err := pool.Exec(ctx, "UPDATE accrued_orders SET lease_until = NULL, leased_by = NULL")
+		require.NoError(t, err)
+
+		results := make(chan []string, 2)
+		for _, l := range []*order.Lease{second, first} {
+			go func() {
+				c, err := repo.ClaimOrdersForProcessing(ctx, l)
+				assert.NoError(t, err)
+				results <- c
+			}()
+		}
+		all := append(<-results, <-results...)
+		assert.ElementsMatch(t, []string{"backoff-old", "backoff-new"}, all)
+	})
+
+	t.Run("expired lease is given back", func(t *testing.T) {
+		_, err := pool.Exec(ctx,
+			"UPDATE accrued_orders SET lease_until = NOW() - INTERVAL '1 second' WHERE name_order = 'backoff-old'")
+		require.NoError(t, err)
+
+		claimed, err := repo.ClaimOrdersForProcessing(ctx, second)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"backoff-old"}, claimed)
+	})
+
+	t.Run("orders younger than min age are skipped", func(t *testing.T) {
+		_, err := pool.Exec(ctx, "UPDATE accrued_orders SET lease_until = NULL, leased_by = NULL")
+		require.NoError(t, err)
+
+		hybrid := &order.Lease{Owner: "replica-3", Duration: time.Minute, BatchSize: 10, MinAge: time.Hour}
+		claimed, err := repo.ClaimOrdersForProcessing(ctx, hybrid)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"backoff-old"}, claimed)
+	})
+	t.Run("released orders are claimed again", func(t *testing.T) {
+		// чужую аренду не снять
+		n, err := repo.ReleaseLeases(ctx, "replica-1", []string{"backoff-old"})
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		n, err = repo.ReleaseLeases(ctx, "replica-3", []string{"backoff-old", "backoff-new"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		claimed, err := repo.ClaimOrdersForProcessing(ctx, second)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"backoff-old", "backoff-new"}, claimed)
+	})
+}
+
+func TestOrderRepository_RecheckOrders(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewOrderRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/order_reschedule.sql"))
+	_, err := pool.Exec(ctx, "UPDATE accrued_orders SET next_check_at = NOW() + INTERVAL '1 hour'")
+	require.NoError(t, err)
+	leased := &order.Lease{Owner: "replica-1", Duration: time.Minute, BatchSize: 10}
+
+	claimed, err := repo.ClaimOrdersForProcessing(ctx, leased)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// завершённый заказ перепроверять нечего
+	n, err := repo.RecheckOrders(ctx, []string{"backoff-old", "backoff-done", "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	claimed, err = repo.ClaimOrdersForProcessing(ctx, leased)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backoff-old"}, claimed)
+
+	t.Run("leased orders are not rechecked", func(t *testing.T) {
+		n, err := repo.RecheckOrders(ctx, []string{"backoff-old"})
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+)
+
+type ReconciliationRepository struct {
+	DB
+}
+
+func NewReconciliationRepository(pool connectionPool, log *slog.Logger) *ReconciliationRepository {
+	return &ReconciliationRepository{
+		DB{
+			pool: pool,
+			log:  log,
+		},
+	}
+}
+
+// ListSettled возвращает до limit заказов PROCESSED и INVALID, дольше всех не сверявшихся
+// с accrual; 0 -- все заказы.
+func (r *ReconciliationRepository) ListSettled(ctx context.Context, limit int32) ([]order.Settled, error) {
+	listLogic := func() ([]order.Settled, error) {
+		queries := db.New(r.pool)
+		rows, err := queries.ListOrdersForReconciliation(ctx, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list orders for reconciliation: %w", err)
+		}
+
+		settled := make([]order.Settled, len(rows))
+		for i, row := range rows {
+			raw, err := model.FromPGNumeric(row.RawAmount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid raw amount of order %s: %w", row.NameOrder, err)
+			}
+			amount, err := model.FromPGNumeric(row.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount of order %s: %w", row.NameOrder, err)
+			}
+			settled[i] = order.Settled{
+				ID:        row.NameOrder,
+				UserID:    row.IDUser,
+				Status:    order.Status(row.NameStatus),
+				RawAmount: raw,
+				Amount:    amount,
+			}
+		}
+		return settled, nil
+	}
+
+	return WithRetry[[]order.Settled](listLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+// MarkReconciled отмечает, что заказ сверен с accrual и расхождений нет.
+func (r *ReconciliationRepository) MarkReconciled(ctx context.Context, orderID string) error {
+	markLogic := func() (any, error) {
+		if err := db.New(r.pool).MarkOrderReconciled(ctx, orderID); err != nil {
+			return nil, fmt.Errorf("failed to mark order %s reconciled: %w", orderID, err)
+		}
+		return nil, nil
+	}
+
+	_, err := WithRetry[any](markLogic, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+// RecordDiscrepancy записывает расхождение в отчёт и отмечает заказ сверенным.
+// Если задана correction, она записывается в журнал корректировок и связывается с расхождением,
+// а статус и начисление accrual в заказе заменяются ответом accrual.
+// Расхождение, уже записанное раньше с тем же ответом accrual, не записывается и не исправляется
+// повторно: тогда возвращается false.
+func (r *ReconciliationRepository) RecordDiscrepancy(ctx context.Context,
+	d *order.Discrepancy, correction *adjustment.Adjustment,
+) (bool, error) {
+	recordLogic := func(ctx context.Context, tx connectionPool) (any, error) {
+		queries := db.New(tx)
+		if err := queries.MarkOrderReconciled(ctx, d.Stored.ID); err != nil {
+			return false, fmt.Errorf("failed to mark order %s reconciled: %w", d.Stored.ID, err)
+		}
+		row, err := queries.CreateDiscrepancy(ctx, db.CreateDiscrepancyParams{
+			NameOrder:       d.Stored.ID,
+			IDUser:          d.Stored.UserID,
+			StoredStatus:    string(d.Stored.Status),
+			StoredRawAmount: d.Stored.RawAmount.ToPGNumeric(),
+			StoredAmount:    d.Stored.Amount.ToPGNumeric(),
+			AccrualStatus:   d.AccrualStatus,
+			AccrualAmount:   d.AccrualAmount.ToPGNumeric(),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to record discrepancy of order %s: %w", d.Stored.ID, err)
+		}
+		d.ID = int64(row.IDDiscrepancy)
+		d.FoundAt = row.FoundAt.Time
+		if correction == nil {
+			return true, nil
+		}
+
+		adj, err := queries.CreateAdjustment(ctx, db.CreateAdjustmentParams{
+			IDUser:     correction.UserID,
+			IDAdmin:    correction.AdminID,
+			Direction:  string(correction.Direction),
+			Amount:     correction.Amount.ToPGNumeric(),
+			ReasonCode: string(correction.Reason),
+			Comment:    correction.Comment,
+			Forced:     correction.Forced,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to record correction of order %s: %w", d.Stored.ID, err)
+		}
+		err = queries.SetDiscrepancyAdjustment(ctx, db.SetDiscrepancyAdjustmentParams{
+			IDDiscrepancy: row.IDDiscrepancy,
+			IDAdjustment:  pgtype.Int4{Int32: adj.IDAdjustment, Valid: true},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to link correction of order %s: %w", d.Stored.ID, err)
+		}
+		// следующая сверка сравнивает accrual уже с исправленным результатом
+		err = queries.ApplyReconciledResult(ctx, db.ApplyReconciledResultParams{
+			NameStatus: d.AccrualStatus,
+			RawAmount:  d.AccrualAmount.ToPGNumeric(),
+			NameOrder:  d.Stored.ID,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to apply accrual result to order %s: %w", d.Stored.ID, err)
+		}
+		correction.ID = int64(adj.IDAdjustment)
+		correction.CreatedAt = adj.CreatedAt.Time
+		d.AdjustmentID = correction.ID
+		return true, nil
+	}
+
+	recordWithTX := func() (bool, error) {
+		return WithTX[bool](ctx, r.pool, r.log, recordLogic)
+	}
+
+	return WithRetry[bool](recordWithTX, 0) //nolint: wrapcheck // error from wrapped function
+}
@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+)
+
+func TestReconciliationRepository(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewReconciliationRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/reconciliation.sql"))
+
+	settled, err := repo.ListSettled(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, settled, 2)
+	assert.Equal(t, "rec-1a", settled[0].ID)
+	assert.Equal(t, order.StatusProcessed, settled[0].Status)
+	assert.Equal(t, int64(10000), settled[0].RawAmount.TotalKopecks())
+	assert.Equal(t, int64(11000), settled[0].Amount.TotalKopecks())
+	assert.Equal(t, "rec-1b", settled[1].ID)
+	assert.Zero(t, settled[1].Amount.TotalKopecks())
+
+	t.Run("reconciled orders go last", func(t *testing.T) {
+		require.NoError(t, repo.MarkReconciled(ctx, "rec-1a"))
+		settled, err := repo.ListSettled(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, settled, 1)
+		assert.Equal(t, "rec-1b", settled[0].ID)
+	})
+
+	t.Run("discrepancy is corrected once", func(t *testing.T) {
+		d, found := settled[0].Compare(order.StatusInvalid, model.NewAmount(0, 0))
+		require.True(t, found)
+		newCorrection := func() *adjustment.Adjustment {
+			return &adjustment.Adjustment{
+				UserID:    "1",
+				AdminID:   "admin",
+				Direction: adjustment.DirectionDebit,
+				Reason:    adjustment.ReasonCorrection,
+				Comment:   "reconciliation of order rec-1a",
+				Amount:    model.NewAmount(110, 0),
+				Forced:    true,
+			}
+		}
+
+		correction := newCorrection()
+		recorded, err := repo.RecordDiscrepancy(ctx, &d, correction)
+		require.NoError(t, err)
+		assert.True(t, recorded)
+		assert.NotZero(t, d.ID)
+		assert.NotZero(t, correction.ID)
+		assert.Equal(t, correction.ID, d.AdjustmentID)
+
+		again := d
+		recorded, err = repo.RecordDiscrepancy(ctx, &again, newCorrection())
+		require.NoError(t, err)
+		assert.False(t, recorded)
+
+		current, _, err := NewOrderRepository(pool, repo.log).GetBalance(ctx, "1")
+		require.NoError(t, err)
+		assert.Zero(t, current.TotalKopecks())
+
+		var count int
+		require.NoError(t, pool.QueryRow(ctx,
+			"SELECT COUNT(*) FROM accrual_discrepancies WHERE id_adjustment IS NOT NULL").Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestReconciliationRepository_RecordDiscrepancy_accrualFlipFlop(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewReconciliationRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/reconciliation.sql"))
+	orders := NewOrderRepository(pool, repo.log)
+
+	stored := func() order.Settled {
+		settled, err := repo.ListSettled(ctx, 0)
+		require.NoError(t, err)
+		for _, s := range settled {
+			if s.ID == "rec-1a" {
+				return s
+			}
+		}
+		require.FailNow(t, "order rec-1a is not listed")
+		return order.Settled{}
+	}
+
+	// accrual пересчитывает rec-1a туда и обратно: каждый раз баланс следует за ответом
+	for _, raw := range []int64{120, 100, 120} {
+		s := stored()
+		d, found := s.Compare(order.StatusProcessed, model.NewAmount(raw, 0))
+		require.True(t, found, "accrual amount %d", raw)
+		correctionAmount := d.Correction()
+		fix := correctionAmount.TotalKopecks()
+		correction := &adjustment.Adjustment{
+			UserID:    "1",
+			AdminID:   "admin",
+			Direction: adjustment.DirectionCredit,
+			Reason:    adjustment.ReasonCorrection,
+			Comment:   "reconciliation of order rec-1a",
+			Amount:    model.NewAmount(0, fix),
+		}
+		if fix < 0 {
+			correction.Direction = adjustment.DirectionDebit
+			correction.Amount = model.NewAmount(0, -fix)
+			correction.Forced = true
+		}
+
+		recorded, err := repo.RecordDiscrepancy(ctx, &d, correction)
+		require.NoError(t, err)
+		require.True(t, recorded, "accrual amount %d", raw)
+
+		s = stored()
+		assert.Equal(t, raw*100, s.RawAmount.TotalKopecks())
+		assert.Equal(t, raw*110, s.Amount.TotalKopecks())
+		current, _, err := orders.GetBalance(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, raw*110, current.TotalKopecks())
+	}
+
+	// accrual отвечает так же, как при прошлой сверке: расхождения нет
+	s := stored()
+	_, found := s.Compare(order.StatusProcessed, model.NewAmount(120, 0))
+	assert.False(t, found)
+}
@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/referral"
+	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type ReferralRepository struct {
+	DB
+}
+
+func NewReferralRepository(pool connectionPool, log *slog.Logger) *ReferralRepository {
+	return &ReferralRepository{
+		DB{
+			pool: pool,
+			log:  log,
+		},
+	}
+}
+
+func (r *ReferralRepository) GetSummary(ctx context.Context, userID string,
+) (referral.Summary, error) {
+	summaryLogic := func() (referral.Summary, error) {
+		queries := db.New(r.pool)
+		code, err := queries.GetReferralCode(ctx, userID)
+		if err != nil && errors.Is(err, pgx.ErrNoRows) {
+			return referral.Summary{}, fmt.Errorf("user %s: %w", userID, serviceerrs.ErrNotFound)
+		}
+		if err != nil {
+			return referral.Summary{}, fmt.Errorf("failed to get referral code: %w", err)
+		}
+
+		rows, err := queries.ListReferralsByReferrer(ctx, userID)
+		if err != nil {
+			return referral.Summary{}, fmt.Errorf("failed to list referrals: %w", err)
+		}
+		invitees := make([]referral.Invitee, len(rows))
+		for i, row := range rows {
+			earned, err := model.FromPGNumeric(row.Earned)
+			if err != nil {
+				return referral.Summary{}, fmt.Errorf("invalid referral bonus amount: %w", err)
+			}
+			invitees[i] = referral.Invitee{
+				InvitedAt:  row.CreatedAt.Time,
+				RewardedAt: fromPGTimestamp(row.RewardedAt),
+				Earned:     earned,
+			}
+		}
+		return referral.Summary{Code: code, Invitees: invitees}, nil
+	}
+
+	return WithRetry[referral.Summary](summaryLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+// ClaimReward начисляет бонусы обеим сторонам, если заказ первый
+// обработанный у приглашённого. Возвращает nil, если начислять нечего.
+func (r *ReferralRepository) ClaimReward(ctx context.Context,
+	orderID string, referrerBonus, refereeBonus model.Amount,
+) (*referral.Reward, error) {
+	claimLogic := func(ctx context.Context, tx connectionPool) (any, error) {
+		queries := db.New(tx)
+		row, err := queries.ClaimReferralReward(ctx, orderID)
+		if err != nil && errors.Is(err, pgx.ErrNoRows) {
+			return (*referral.Reward)(nil), nil
+		}
+		if err != nil {
+			return (*referral.Reward)(nil),
+				fmt.Errorf("failed to claim referral reward for order %s: %w", orderID, err)
+		}
+
+		creditedAt := pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true}
+		credits := []db.CreateReferralCreditParams{
+			{IDReferral: row.IDReferral, IDUser: row.IDReferrer,
+				Amount: referrerBonus.ToPGNumeric(), CreditedAt: creditedAt},
+			{IDReferral: row.IDReferral, IDUser: row.IDReferee,
+				Amount: refereeBonus.ToPGNumeric(), CreditedAt: creditedAt},
+		}
+		for _, c := range credits {
+			if err = queries.CreateReferralCredit(ctx, c); err != nil {
+				return (*referral.Reward)(nil),
+					fmt.Errorf("failed to credit referral bonus to %s: %w", c.IDUser, err)
+			}
+		}
+
+		return &referral.Reward{
+			ReferrerID: row.IDReferrer,
+			RefereeID:  row.IDReferee,
+			ReferralID: int64(row.IDReferral),
+		}, nil
+	}
+
+	claimWithTX := func() (*referral.Reward, error) {
+		return WithTX[*referral.Reward](ctx, r.pool, r.log, claimLogic)
+	}
+
+	return WithRetry[*referral.Reward](claimWithTX, 0) //nolint: wrapcheck // error from wrapped function
+}
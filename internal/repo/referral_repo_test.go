@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func TestReferralRepository_ClaimReward(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewReferralRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/referral_claim.sql"))
+
+	referrerBonus := model.NewAmount(100, 0)
+	refereeBonus := model.NewAmount(50, 0)
+
+	t.Run("not referred user", func(t *testing.T) {
+		reward, err := repo.ClaimReward(ctx, "ref-1a", referrerBonus, refereeBonus)
+		require.NoError(t, err)
+		assert.Nil(t, reward)
+	})
+
+	t.Run("first processed order of referee", func(t *testing.T) {
+		reward, err := repo.ClaimReward(ctx, "ref-2a", referrerBonus, refereeBonus)
+		require.NoError(t, err)
+		require.NotNil(t, reward)
+		assert.Equal(t, "1", reward.ReferrerID)
+		assert.Equal(t, "2", reward.RefereeID)
+	})
+
+	t.Run("reward is paid once", func(t *testing.T) {
+		reward, err := repo.ClaimReward(ctx, "ref-2b", referrerBonus, refereeBonus)
+		require.NoError(t, err)
+		assert.Nil(t, reward)
+	})
+
+	summary, err := repo.GetSummary(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "AAAA2222", summary.Code)
+	require.Len(t, summary.Invitees, 1)
+	assert.NotNil(t, summary.Invitees[0].RewardedAt)
+	assert.Equal(t, referrerBonus, summary.Earned())
+
+	orderRepo := NewOrderRepository(pool, repo.log)
+	accrued, _, err := orderRepo.GetBalance(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, model.NewAmount(550, 0), accrued)
+}
+
+func TestUserRepository_Create_referral(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewUserRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/referral_claim.sql"))
+
+	tests := []struct {
+		name    string
+		id      string
+		code    string
+		wantErr error
+	}{
+		{"invited by referee", "3", "BBBB2222", nil},
+		{"unknown code", "4", "ZZZZ9999", serviceerrs.ErrUnknownReferralCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.Create(ctx, &user.User{
+				ID:           tt.id,
+				LoginHash:    "user" + tt.id + "hash",
+				PasswordHash: "user" + tt.id + "password-hash",
+				ReferralCode: "CODE" + tt.id + "222",
+				ReferrerCode: tt.code,
+			})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
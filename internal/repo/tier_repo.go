@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/tier"
+	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type TierRepository struct {
+	DB
+}
+
+func NewTierRepository(pool connectionPool, log *slog.Logger) *TierRepository {
+	return &TierRepository{
+		DB{
+			pool: pool,
+			log:  log,
+		},
+	}
+}
+
+func (r *TierRepository) ListTiers(ctx context.Context) ([]tier.Tier, error) {
+	listLogic := func() ([]tier.Tier, error) {
+		queries := db.New(r.pool)
+		tiersRaw, err := queries.ListTiers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tiers: %w", err)
+		}
+		if len(tiersRaw) == 0 {
+			return nil, fmt.Errorf("no tiers configured: %w", serviceerrs.ErrNotFound)
+		}
+
+		tiers := make([]tier.Tier, len(tiersRaw))
+		for i, t := range tiersRaw {
+			tiers[i], err = toTier(t.NameTier, t.MinPoints, t.Multiplier)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return tiers, nil
+	}
+
+	return WithRetry[[]tier.Tier](listLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *TierRepository) GetUserTier(ctx context.Context, userID string) (tier.Tier, error) {
+	getLogic := func() (tier.Tier, error) {
+		queries := db.New(r.pool)
+		t, err := queries.GetCurrentUserTier(ctx, userID)
+		if err != nil && errors.Is(err, pgx.ErrNoRows) {
+			return tier.Tier{}, serviceerrs.ErrNotFound
+		}
+		if err != nil {
+			return tier.Tier{}, fmt.Errorf("failed to get tier of user %s: %w", userID, err)
+		}
+		return toTier(t.NameTier, t.MinPoints, t.Multiplier)
+	}
+
+	t, err := WithRetry[tier.Tier](getLogic, 0)
+	if err == nil {
+		return t, nil
+	}
+	if !errors.Is(err, serviceerrs.ErrNotFound) {
+		return tier.Tier{}, err //nolint: wrapcheck // error from wrapped function
+	}
+
+	// пользователь ещё не попадал в пересчёт -- считаем его базовым уровнем
+	tiers, err := r.ListTiers(ctx)
+	if err != nil {
+		return tier.Tier{}, err
+	}
+	return tiers[0], nil
+}
+
+func (r *TierRepository) GetRollingPoints(ctx context.Context, userID string) (model.Amount, error) {
+	getLogic := func() (model.Amount, error) {
+		queries := db.New(r.pool)
+		points, err := queries.GetRollingAccrued(ctx, userID)
+		if err != nil {
+			return model.Amount{},
+				fmt.Errorf("failed to get rolling points of user %s: %w", userID, err)
+		}
+		return model.FromPGNumeric(points) //nolint: wrapcheck // error from wrapped function
+	}
+
+	return WithRetry[model.Amount](getLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+func (r *TierRepository) RecalculateTiers(ctx context.Context) (int64, error) {
+	recalculateLogic := func() (int64, error) {
+		queries := db.New(r.pool)
+		changed, err := queries.RecalculateTiers(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to recalculate tiers: %w", err)
+		}
+		return changed, nil
+	}
+
+	return WithRetry[int64](recalculateLogic, 0) //nolint: wrapcheck // error from wrapped function
+}
+
+func toTier(name string, minPoints, multiplier pgtype.Numeric) (tier.Tier, error) {
+	minAmount, err := model.FromPGNumeric(minPoints)
+	if err != nil {
+		return tier.Tier{}, fmt.Errorf("invalid min points of tier %s: %w", name, err)
+	}
+	multiplierAmount, err := model.FromPGNumeric(multiplier)
+	if err != nil {
+		return tier.Tier{}, fmt.Errorf("invalid multiplier of tier %s: %w", name, err)
+	}
+	return tier.Tier{
+		Name:       name,
+		MinPoints:  minAmount,
+		Multiplier: multiplierAmount,
+	}, nil
+}
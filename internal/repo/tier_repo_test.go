@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+)
+
+func TestTierRepository_RecalculateTiers(t *testing.T) {
+	repo, ctx, cancel, pool := setupRepo(t, NewTierRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/tier_recalculate.sql"))
+
+	tiers, err := repo.ListTiers(ctx)
+	require.NoError(t, err)
+	require.Len(t, tiers, 3)
+	assert.Equal(t, "bronze", tiers[0].Name)
+
+	t.Run("user without history falls back to base tier", func(t *testing.T) {
+		current, err := repo.GetUserTier(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, tiers[0], current)
+	})
+
+	changed, err := repo.RecalculateTiers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), changed)
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantTier   string
+		wantPoints model.Amount
+	}{
+		{"silver by rolling points", "1", "silver", model.NewAmount(1200, 0)},
+		{"old accruals are not counted", "2", "bronze", model.NewAmount(0, 0)},
+		{"exactly gold threshold", "3", "gold", model.NewAmount(5000, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, err := repo.GetUserTier(ctx, tt.userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTier, current.Name)
+
+			points, err := repo.GetRollingPoints(ctx, tt.userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPoints, points)
+		})
+	}
+
+	t.Run("unchanged tiers are not duplicated", func(t *testing.T) {
+		changed, err := repo.RecalculateTiers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), changed)
+	})
+}
+
+func TestOrderRepository_UpdateAccrualStatus_boosted(t *testing.T) {
+	orderRepo, ctx, cancel, pool := setupRepo(t, NewOrderRepository)
+	defer cancel()
+	require.NoError(t, loadFixtureFile(pool, "./fixtures/tier_recalculate.sql"))
+	tierRepo := NewTierRepository(pool, orderRepo.log)
+	_, err := tierRepo.RecalculateTiers(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, orderRepo.CreateOrder(ctx, &order.Order{
+		ID:     "tier-1c",
+		UserID: "1",
+		Status: order.StatusNew,
+		Type:   order.TypeAccrual,
+	}))
+	require.NoError(t, orderRepo.UpdateAccrualStatus(ctx, "replica-1", &order.Order{
+		ID:     "tier-1c",
+		Status: order.StatusProcessed,
+		Amount: model.NewAmount(100, 0),
+	}))
+
+	var raw, boosted string
+	require.NoError(t, pool.QueryRow(ctx,
+		`SELECT raw_amount::text, amount::text FROM accrued_orders WHERE name_order = 'tier-1c'`,
+	).Scan(&raw, &boosted))
+	assert.Equal(t, "100.00", raw)
+	assert.Equal(t, "110.00", boosted)
+}
@@ -2,12 +2,17 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
 	"github.com/talx-hub/gopher-bonus/internal/model"
 	"github.com/talx-hub/gopher-bonus/internal/model/user"
 	"github.com/talx-hub/gopher-bonus/internal/repo/internal/db"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
 )
 
 type UserRepository struct {
@@ -27,8 +32,9 @@ func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
 	createLogic := func(ctx context.Context, tx connectionPool) (any, error) {
 		queries := db.New(tx)
 		id, err := queries.InsertUser(ctx, db.InsertUserParams{
-			IDUser:    u.ID,
-			HashLogin: u.LoginHash,
+			IDUser:       u.ID,
+			HashLogin:    u.LoginHash,
+			ReferralCode: pgtype.Text{String: u.ReferralCode, Valid: u.ReferralCode != ""},
 		})
 		if err != nil {
 			return struct{}{}, fmt.Errorf("failed to insert user login hash: %w", err)
@@ -42,6 +48,12 @@ func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
 			return struct{}{}, fmt.Errorf("failed to insert user password hash: %w", err)
 		}
 
+		if u.ReferrerCode != "" {
+			if err = createReferral(ctx, queries, id, u.ReferrerCode); err != nil {
+				return struct{}{}, err
+			}
+		}
+
 		return struct{}{}, nil
 	}
 
@@ -88,6 +100,8 @@ func (r *UserRepository) FindByLogin(ctx context.Context, loginHash string,
 			ID:           u.IDUser,
 			LoginHash:    u.HashLogin,
 			PasswordHash: u.HashPassword,
+			ReferralCode: u.ReferralCode,
+			Role:         user.Role(u.Role),
 		}, err //nolint: wrapcheck // error from wrapped function
 	}
 
@@ -110,6 +124,8 @@ func (r *UserRepository) FindByID(ctx context.Context, id string,
 			ID:           u.IDUser,
 			LoginHash:    u.HashLogin,
 			PasswordHash: u.HashPassword,
+			ReferralCode: u.ReferralCode,
+			Role:         user.Role(u.Role),
 		}, err //nolint: wrapcheck // error from wrapped function
 	}
 
@@ -120,6 +136,60 @@ func (r *UserRepository) FindByID(ctx context.Context, id string,
 	return u, nil
 }
 
+func (r *UserRepository) SetRole(ctx context.Context, id string, role user.Role) error {
+	setRoleLogic := func() (struct{}, error) {
+		queries := db.New(r.pool)
+		updated, err := queries.SetUserRole(ctx, db.SetUserRoleParams{
+			IDUser: id,
+			Role:   string(role),
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to set role %s for user %s: %w", role, id, err)
+		}
+		if updated == 0 {
+			return struct{}{}, fmt.Errorf("user %s: %w", id, serviceerrs.ErrNotFound)
+		}
+		return struct{}{}, nil
+	}
+
+	_, err := WithRetry[struct{}](setRoleLogic, 0)
+	return err //nolint: wrapcheck // error from wrapped function
+}
+
+func createReferral(ctx context.Context, queries *db.Queries, refereeID, code string) error {
+	referrerID, err := queries.FindUserIDByReferralCode(ctx,
+		pgtype.Text{String: code, Valid: true})
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %s", serviceerrs.ErrUnknownReferralCode, code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find referrer by code: %w", err)
+	}
+	if referrerID == refereeID {
+		return fmt.Errorf("%w: self-referral", serviceerrs.ErrReferralNotAllowed)
+	}
+
+	isLoop, err := queries.IsReferralAncestor(ctx, db.IsReferralAncestorParams{
+		Ancestor:   refereeID,
+		Descendant: referrerID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check referral chain: %w", err)
+	}
+	if isLoop {
+		return fmt.Errorf("%w: referral loop", serviceerrs.ErrReferralNotAllowed)
+	}
+
+	err = queries.CreateReferral(ctx, db.CreateReferralParams{
+		IDReferrer: referrerID,
+		IDReferee:  refereeID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create referral: %w", err)
+	}
+	return nil
+}
+
 func findWrapper[T db.FindUserByIDRow | db.FindUserByLoginRow](ctx context.Context,
 	fn func(context.Context, string) (T, error),
 	key string,
@@ -127,6 +197,10 @@ func findWrapper[T db.FindUserByIDRow | db.FindUserByLoginRow](ctx context.Conte
 	var zero T
 
 	u, err := fn(ctx, key)
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return zero, errors.Join(serviceerrs.ErrNotFound,
+			fmt.Errorf("failed to find user by ID in DB: %w", err))
+	}
 	if err != nil {
 		return zero,
 			fmt.Errorf("failed to find user by ID in DB: %w", err)
@@ -110,7 +110,8 @@ func TestUserRepository_FindByID(t *testing.T) {
 		wantErr bool
 	}{
 		{"existing user", "1", user.User{
-			ID: "1", LoginHash: "user1hash", PasswordHash: "user1password-hash"}, false},
+			ID: "1", LoginHash: "user1hash", PasswordHash: "user1password-hash",
+			Role: user.RoleUser}, false},
 		{"not found", "100500", user.User{}, true},
 		{"bad ID", "not-int", user.User{}, true},
 		{"empty ID", "", user.User{}, true},
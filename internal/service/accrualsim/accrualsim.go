@@ -0,0 +1,213 @@
+package accrualsim
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ShiraazMoollatjie/goluhn"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
+)
+
+type Simulator struct {
+	now       func() time.Time
+	log       *slog.Logger
+	orders    map[string]model.Amount
+	firstSeen map[string]time.Time
+	limiter   *limiter
+	script    []Action
+	calls     int
+	stepDelay time.Duration
+	slowDelay time.Duration
+	mu        sync.Mutex
+}
+
+func New(cfg *Config, rules []Rule, orders []Order, log *slog.Logger) *Simulator {
+	s := &Simulator{
+		now:       time.Now,
+		log:       log,
+		orders:    make(map[string]model.Amount, len(orders)),
+		firstSeen: make(map[string]time.Time),
+		script:    cfg.Script,
+		stepDelay: cfg.StepDelay,
+		slowDelay: cfg.SlowDelay,
+	}
+	if cfg.RPM > 0 {
+		s.limiter = &limiter{rpm: cfg.RPM}
+	}
+	for _, o := range orders {
+		s.orders[o.Number] = Calculate(rules, o.Goods)
+	}
+	return s
+}
+
+func (s *Simulator) Router() *chi.Mux {
+	r := chi.NewRouter()
+	r.Get("/api/orders/{number}", s.GetOrder)
+	return r
+}
+
+func (s *Simulator) GetOrder(w http.ResponseWriter, r *http.Request) {
+	now := s.now()
+	if s.limiter != nil {
+		if retryAfter, ok := s.limiter.allow(now); !ok {
+			w.Header().Set(model.HeaderContentType, "text/plain")
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte("No more than " +
+				strconv.FormatUint(s.limiter.rpm, 10) + " requests per minute allowed"))
+			return
+		}
+	}
+
+	switch s.nextAction() {
+	case ActionFail:
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	case ActionSlow:
+		select {
+		case <-time.After(s.slowDelay):
+		case <-r.Context().Done():
+			return
+		}
+	case ActionOK:
+	}
+
+	number := chi.URLParam(r, "number")
+	info, ok := s.orderInfo(number, now)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set(model.HeaderContentType, "application/json")
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		s.log.LogAttrs(r.Context(),
+			slog.LevelError,
+			"failed to write response",
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+}
+
+func (s *Simulator) nextAction() Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.script) == 0 {
+		return ActionOK
+	}
+	a := s.script[s.calls%len(s.script)]
+	s.calls++
+	return a
+}
+
+// статус заказа определяется временем с момента первого запроса о нём:
+// stepDelay в REGISTERED, ещё stepDelay в PROCESSING, дальше PROCESSED.
+func (s *Simulator) orderInfo(number string, now time.Time) (dto.AccrualInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	amount, ok := s.orders[number]
+	if !ok {
+		return dto.AccrualInfo{}, false
+	}
+	info := dto.AccrualInfo{Order: number}
+	if err := goluhn.Validate(number); err != nil {
+		info.Status = string(dto.StatusCalculatorInvalid)
+		return info, true
+	}
+
+	seen, ok := s.firstSeen[number]
+	if !ok {
+		seen = now
+		s.firstSeen[number] = now
+	}
+	switch elapsed := now.Sub(seen); {
+	case elapsed < s.stepDelay:
+		info.Status = string(dto.StatusCalculatorRegistered)
+	case elapsed < 2*s.stepDelay:
+		info.Status = string(dto.StatusCalculatorProcessing)
+	default:
+		info.Status = string(dto.StatusCalculatorProcessed)
+		if amount.TotalKopecks() > 0 {
+			info.Accrual = json.Number(amount.String())
+		}
+	}
+	return info, true
+}
+
+// limiter -- фиксированное окно в одну минуту, как у настоящего accrual.
+type limiter struct {
+	windowStart time.Time
+	count       uint64
+	rpm         uint64
+	mu          sync.Mutex
+}
+
+func (l *limiter) allow(now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.windowStart) >= time.Minute {
+		l.windowStart = now
+		l.count = 0
+	}
+	if l.count >= l.rpm {
+		retryAfter := l.windowStart.Add(time.Minute).Sub(now)
+		if retryAfter < time.Second {
+			retryAfter = time.Second
+		}
+		return retryAfter.Round(time.Second), false
+	}
+	l.count++
+	return 0, true
+}
+
+func Run() {
+	log := slog.Default()
+	cfg := LoadConfig(log)
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err == nil {
+		log = logger.New(level)
+	}
+
+	ctx := context.Background()
+	rules, err := loadFile(cfg.GoodsPath, LoadRules)
+	if err != nil {
+		log.LogAttrs(ctx, slog.LevelError, "failed to load rules",
+			slog.Any(model.KeyLoggerError, err))
+		return
+	}
+	orders, err := loadFile(cfg.OrdersPath, LoadOrders)
+	if err != nil {
+		log.LogAttrs(ctx, slog.LevelError, "failed to load orders",
+			slog.Any(model.KeyLoggerError, err))
+		return
+	}
+
+	sim := New(cfg, rules, orders, log)
+	log.LogAttrs(ctx,
+		slog.LevelInfo,
+		"starting accrual simulator.....",
+		slog.String("addr", cfg.RunAddr),
+		slog.Int("rules", len(rules)),
+		slog.Int("orders", len(orders)),
+		slog.Uint64("rpm", cfg.RPM),
+	)
+	if err = http.ListenAndServe(cfg.RunAddr, sim.Router()); err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"listen and serve error",
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+}
@@ -0,0 +1,182 @@
+package accrualsim
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+{"match": "Bork", "reward": 4, "reward_type": "%"}
+
+{"match": "Tefal", "reward": 10, "reward_type": "%"}
+
+{"match": "Чайник", "reward": 50, "reward_type": "pt"}
+`
+
+const testOrders = `
+{"order": "9278923470", "goods": [
+	{"description": "Чайник Bork", "price": 7000},
+	{"description": "Чайник Tefal", "price": 9500}
+]}
+
+{"order": "12345678903", "goods": [{"description": "Ariston", "price": 37000}]}
+
+{"order": "1234", "goods": []}
+`
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestSimulator(t *testing.T, cfg *Config) (*Simulator, *fakeClock) {
+	t.Helper()
+	rules, err := LoadRules(strings.NewReader(testRules))
+	require.NoError(t, err)
+	orders, err := LoadOrders(strings.NewReader(testOrders))
+	require.NoError(t, err)
+
+	clock := &fakeClock{t: time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)}
+	s := New(cfg, rules, orders, slog.Default())
+	s.now = clock.now
+	return s, clock
+}
+
+func get(t *testing.T, s *Simulator, number string) (*http.Response, string) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	s.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/orders/"+number, http.NoBody))
+	res := rr.Result()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	return res, strings.TrimSpace(string(body))
+}
+
+func TestLoadFiles(t *testing.T) {
+	for _, tt := range []struct {
+		path string
+		load func(path string) (int, error)
+	}{
+		{"../../../other/accrualgoods.txt", func(p string) (int, error) {
+			r, err := loadFile(p, LoadRules)
+			return len(r), err
+		}},
+		{"../../../other/accrualorders.json", func(p string) (int, error) {
+			o, err := loadFile(p, LoadOrders)
+			return len(o), err
+		}},
+	} {
+		_, err := os.Stat(tt.path)
+		require.NoError(t, err)
+		n, err := tt.load(tt.path)
+		require.NoError(t, err)
+		assert.Positive(t, n, tt.path)
+	}
+}
+
+func TestLoadRules_invalid(t *testing.T) {
+	_, err := LoadRules(strings.NewReader(`{"match": "Bork", "reward": 4, "reward_type": "$"}`))
+	require.Error(t, err)
+	_, err = LoadRules(strings.NewReader(`{"match": "Bork", `))
+	require.Error(t, err)
+}
+
+func TestCalculate(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(testRules))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		want  string
+		goods []Good
+	}{
+		{"percent", "280", []Good{{"Чайник Bork", 7000}}},
+		{"first rule wins", "1230", []Good{{"Чайник Bork", 7000}, {"Чайник Tefal", 9500}}},
+		{"points", "50", []Good{{"Чайник Vitek", 1}}},
+		{"kopecks", "0.41", []Good{{"Bork", 10.25}}},
+		{"no match", "0", []Good{{"Ariston", 37000}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Calculate(rules, tt.goods)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestSimulator_progression(t *testing.T) {
+	s, clock := newTestSimulator(t, &Config{StepDelay: time.Second})
+
+	res, body := get(t, s, "9278923470")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"order":"9278923470","status":"REGISTERED"}`, body)
+
+	clock.t = clock.t.Add(time.Second)
+	_, body = get(t, s, "9278923470")
+	assert.JSONEq(t, `{"order":"9278923470","status":"PROCESSING"}`, body)
+
+	clock.t = clock.t.Add(time.Second)
+	_, body = get(t, s, "9278923470")
+	assert.JSONEq(t, `{"order":"9278923470","status":"PROCESSED","accrual":1230}`, body)
+
+	// отсчёт у каждого заказа свой
+	_, body = get(t, s, "12345678903")
+	assert.JSONEq(t, `{"order":"12345678903","status":"REGISTERED"}`, body)
+	clock.t = clock.t.Add(2 * time.Second)
+	_, body = get(t, s, "12345678903")
+	assert.JSONEq(t, `{"order":"12345678903","status":"PROCESSED"}`, body)
+
+	_, body = get(t, s, "1234")
+	assert.JSONEq(t, `{"order":"1234","status":"INVALID"}`, body)
+
+	res, _ = get(t, s, "79927398713")
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestSimulator_rateLimit(t *testing.T) {
+	s, clock := newTestSimulator(t, &Config{RPM: 2})
+
+	for range 2 {
+		res, _ := get(t, s, "9278923470")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+	clock.t = clock.t.Add(15 * time.Second)
+	res, body := get(t, s, "9278923470")
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "45", res.Header.Get("Retry-After"))
+	assert.Equal(t, "No more than 2 requests per minute allowed", body)
+
+	clock.t = clock.t.Add(45 * time.Second)
+	res, _ = get(t, s, "9278923470")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestSimulator_script(t *testing.T) {
+	script, err := ParseScript("ok, fail,slow")
+	require.NoError(t, err)
+	s, _ := newTestSimulator(t, &Config{Script: script, SlowDelay: 10 * time.Millisecond})
+
+	res, _ := get(t, s, "9278923470")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = get(t, s, "9278923470")
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	start := time.Now()
+	res, _ = get(t, s, "9278923470")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	res, _ = get(t, s, "9278923470")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	_, err = ParseScript("ok,teapot")
+	require.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package accrualsim
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+type Config struct {
+	RunAddr    string `env:"RUN_ADDRESS"  envDefault:"localhost:8081"`
+	GoodsPath  string `env:"ACCRUAL_SIM_GOODS"  envDefault:"other/accrualgoods.txt"`
+	OrdersPath string `env:"ACCRUAL_SIM_ORDERS" envDefault:"other/accrualorders.json"`
+	LogLevel   string `env:"LOG_LEVEL"    envDefault:"info"`
+
+	// 0 -- без ограничения
+	RPM       uint64        `env:"ACCRUAL_SIM_RPM"        envDefault:"0"`
+	StepDelay time.Duration `env:"ACCRUAL_SIM_STEP_DELAY" envDefault:"2s"`
+	SlowDelay time.Duration `env:"ACCRUAL_SIM_SLOW_DELAY" envDefault:"1s"`
+	Script    []Action      `env:"ACCRUAL_SIM_SCRIPT"     envSeparator:","`
+}
+
+func LoadConfig(log *slog.Logger) *Config {
+	cfg := &Config{}
+	if err := env.Parse(cfg); err != nil {
+		log.LogAttrs(context.Background(),
+			slog.LevelError, "Failed to parse config", slog.Any(model.KeyLoggerError, err))
+	}
+
+	flag.StringVar(&cfg.RunAddr, "a", cfg.RunAddr, "Run address")
+	flag.StringVar(&cfg.GoodsPath, "goods", cfg.GoodsPath, "Reward rules file")
+	flag.StringVar(&cfg.OrdersPath, "orders", cfg.OrdersPath, "Orders file")
+	flag.StringVar(&cfg.LogLevel, "l", cfg.LogLevel, "Log level")
+	flag.Uint64Var(&cfg.RPM, "rpm", cfg.RPM, "Requests per minute limit, 0 disables the limit")
+	flag.DurationVar(&cfg.StepDelay, "step", cfg.StepDelay,
+		"Time an order spends in REGISTERED and then in PROCESSING")
+	flag.DurationVar(&cfg.SlowDelay, "slow-delay", cfg.SlowDelay, "Delay of the scripted slow response")
+	flag.Func("script", "Comma-separated cycle of responses: ok, fail, slow", func(s string) error {
+		script, err := ParseScript(s)
+		if err != nil {
+			return err
+		}
+		cfg.Script = script
+		return nil
+	})
+
+	flag.Parse()
+	return cfg
+}
+
+type Action string
+
+const (
+	ActionOK   Action = "ok"
+	ActionFail Action = "fail"
+	ActionSlow Action = "slow"
+)
+
+func (a *Action) UnmarshalText(text []byte) error {
+	switch act := Action(strings.TrimSpace(string(text))); act {
+	case ActionOK, ActionFail, ActionSlow:
+		*a = act
+		return nil
+	default:
+		return fmt.Errorf("unknown script action %q", act)
+	}
+}
+
+func ParseScript(s string) ([]Action, error) {
+	var script []Action
+	for _, part := range strings.Split(s, ",") {
+		var a Action
+		if err := a.UnmarshalText([]byte(part)); err != nil {
+			return nil, err
+		}
+		script = append(script, a)
+	}
+	return script, nil
+}
@@ -0,0 +1,121 @@
+package accrualsim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+type RewardType string
+
+const (
+	RewardPercent RewardType = "%"
+	RewardPoints  RewardType = "pt"
+)
+
+type Rule struct {
+	Match      string     `json:"match"`
+	RewardType RewardType `json:"reward_type"`
+	Reward     float64    `json:"reward"`
+}
+
+type Good struct {
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+}
+
+type Order struct {
+	Number string `json:"order"`
+	Goods  []Good `json:"goods"`
+}
+
+// файлы в other/ -- это поток JSON-объектов, разделённых пустыми строками,
+// поэтому читаем их декодером по одному объекту
+func decodeStream[T any](r io.Reader) ([]T, error) {
+	dec := json.NewDecoder(r)
+	var items []T
+	for {
+		var item T
+		err := dec.Decode(&item)
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode item #%d: %w", len(items)+1, err)
+		}
+		items = append(items, item)
+	}
+}
+
+func LoadRules(r io.Reader) ([]Rule, error) {
+	rules, err := decodeStream[Rule](r)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.Match == "" {
+			return nil, errors.New("rule with empty match")
+		}
+		if rule.RewardType != RewardPercent && rule.RewardType != RewardPoints {
+			return nil, fmt.Errorf("rule %q: unknown reward type %q", rule.Match, rule.RewardType)
+		}
+	}
+	return rules, nil
+}
+
+func LoadOrders(r io.Reader) ([]Order, error) {
+	orders, err := decodeStream[Order](r)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range orders {
+		if o.Number == "" {
+			return nil, errors.New("order with empty number")
+		}
+	}
+	return orders, nil
+}
+
+func loadFile[T any](path string, load func(io.Reader) ([]T, error)) ([]T, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	items, err := load(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return items, nil
+}
+
+// Calculate применяет к каждому товару первое подходящее правило.
+func Calculate(rules []Rule, goods []Good) model.Amount {
+	const kopInRub = 100
+	var total float64
+	for _, g := range goods {
+		for _, rule := range rules {
+			if !strings.Contains(g.Description, rule.Match) {
+				continue
+			}
+			switch rule.RewardType {
+			case RewardPercent:
+				total += g.Price * rule.Reward / 100
+			case RewardPoints:
+				total += rule.Reward
+			}
+			break
+		}
+	}
+	return model.NewAmount(0, int64(math.Round(total*kopInRub)))
+}
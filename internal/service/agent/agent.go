@@ -2,34 +2,125 @@ package agent
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"runtime"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/accrual"
 	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/httpclient"
-	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/requestwatcher"
 	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/workerpool"
 	"github.com/talx-hub/gopher-bonus/internal/service/dto"
 	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+	"github.com/talx-hub/gopher-bonus/internal/utils/breaker"
 	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
-	"github.com/talx-hub/gopher-bonus/internal/utils/semaphore"
 )
 
+// DefaultProvider -- имя провайдера по адресу из agent.New.
+const DefaultProvider = "default"
+
+// Metrics получает исходы запросов к accrual, текущую ёмкость семафора, темп запросов и размер пула
+// отдельно по каждому провайдеру.
+type Metrics interface {
+	ObserveAccrualResponse(provider string, code int)
+	SetAccrualCapacity(provider string, n uint64)
+	SetAccrualRateLimit(provider string, rpm uint64)
+	SetAccrualWorkers(provider string, n int)
+}
+
+// Scaling -- границы и период подстройки пула воркеров под очередь заказов.
+type Scaling struct {
+	Interval   time.Duration
+	MaxLatency time.Duration
+	Min        int
+	Max        int
+	DownAfter  int
+}
+
+// ClientConfig -- TLS, авторизация, прокси и таймауты клиента accrual.
+type ClientConfig = httpclient.Config
+
+// Provider -- ещё одна система accrual со своими адресом, лимитом и правилами выбора заказов.
+type Provider struct {
+	Breaker *breaker.Breaker
+	Name    string
+	Address string
+	Client  ClientConfig
+	// заказ достаётся провайдеру, если номер начинается с одного из Prefixes
+	// и его длина -- одна из Lengths; пустой список не ограничивает
+	Prefixes []string
+	Lengths  []int
+	// RPM -- темп запросов в минуту до первого 429 и число одновременных запросов,
+	// 0 -- как у провайдера по умолчанию
+	RPM uint64
+}
+
+func (p *Provider) matches(orderID string) bool {
+	prefixOK := len(p.Prefixes) == 0
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(orderID, prefix) {
+			prefixOK = true
+			break
+		}
+	}
+	lengthOK := len(p.Lengths) == 0
+	for _, l := range p.Lengths {
+		if len(orderID) == l {
+			lengthOK = true
+			break
+		}
+	}
+	return prefixOK && lengthOK
+}
+
+// ProviderState -- то, что видно оператору о провайдере.
+type ProviderState = accrual.ProviderState
+
+// State -- состояние агента для оператора.
+type State struct {
+	Providers []ProviderState
+	// Paused -- опрос приостановлен оператором
+	Paused  bool
+	Running bool
+}
+
 type Agent struct {
 	ordersCh       chan string
 	responsesCh    chan<- dto.AccrualInfo
+	breaker        *breaker.Breaker
+	metrics        Metrics
+	budget         Budget
+	controls       Controls
+	scaling        *Scaling
+	clients        map[string]*httpclient.HTTPClient
 	accrualAddress string
-	workerCount    int
+	instanceID     string
+	providers      []Provider
+	// lanes запущенного Run, nil -- агент не работает
+	lanes []*lane
+	// idle -- lane для Lookup, пока Run не запущен
+	idle        map[string]*lane
+	client      ClientConfig
+	workerCount int
+	mu          sync.Mutex
+	paused      bool
 }
 
 func New(
 	ordersCh chan string,
 	responsesCh chan<- dto.AccrualInfo,
 	accrualAddress string,
+	b *breaker.Breaker,
+	m Metrics,
 ) *Agent {
 	return &Agent{
+		breaker:        b,
+		metrics:        m,
 		accrualAddress: accrualAddress,
 		ordersCh:       ordersCh,
 		responsesCh:    responsesCh,
@@ -37,70 +128,345 @@ func New(
 	}
 }
 
+// WithScaling включает подстройку числа воркеров у каждого провайдера.
+// Без неё пулы работают с фиксированным числом воркеров.
+func (a *Agent) WithScaling(s Scaling) *Agent {
+	a.scaling = &s
+	return a
+}
+
+// WithProviders добавляет провайдеров accrual. Заказ уходит первому подходящему,
+// а не подошедший ни одному -- провайдеру по умолчанию.
+// У каждого провайдера свои клиент, семафор и учёт 429, так что пауза одного не останавливает других.
+func (a *Agent) WithProviders(providers ...Provider) *Agent {
+	a.providers = append(a.providers, providers...)
+	return a
+}
+
+// WithBudget делит темп запросов к каждому провайдеру со всеми репликами через общий бюджет:
+// перед каждым запросом берётся токен, а 429, полученный любой репликой, ставит на паузу все.
+func (a *Agent) WithBudget(b Budget) *Agent {
+	a.budget = b
+	return a
+}
+
+// WithClient задаёт настройки клиента провайдера по умолчанию.
+func (a *Agent) WithClient(cfg ClientConfig) *Agent {
+	a.client = cfg
+	return a
+}
+
+// Validate проверяет адреса и настройки клиентов всех провайдеров и собирает клиентов,
+// чтобы ошибка конфигурации обнаружилась при старте, а не на первом заказе.
+func (a *Agent) Validate() error {
+	providers := make([]Provider, 0, len(a.providers)+1)
+	providers = append(providers, a.providers...)
+	providers = append(providers, a.defaultProvider(0))
+	clients := make(map[string]*httpclient.HTTPClient, len(providers))
+	for _, p := range providers {
+		client, err := httpclient.New(p.Address, p.Client)
+		if err != nil {
+			return fmt.Errorf("accrual provider %s: %w", p.Name, err)
+		}
+		clients[p.Name] = client
+	}
+	a.clients = clients
+	return nil
+}
+
+// Lookup запрашивает заказ у подходящего провайдера в обход очередей и пулов, но с тем же темпом,
+// общим бюджетом и breaker'ом, что и опрос: 429 в ответ на Lookup снижает темп и опроса.
+// Нужен для редких запросов вне основного потока, например сверки. Требует Validate.
+func (a *Agent) Lookup(ctx context.Context, orderID string) (dto.AccrualInfo, error) {
+	if a.clients == nil {
+		return dto.AccrualInfo{}, errors.New("accrual clients are not validated")
+	}
+	p := a.defaultProvider(model.DefaultRequestCount)
+	for _, candidate := range a.providers {
+		if candidate.matches(orderID) {
+			p = candidate
+			break
+		}
+	}
+	log := logger.FromContext(ctx).With("service", "agent", "provider", p.Name)
+	return a.laneFor(ctx, log, p).lookup(ctx, log, orderID)
+}
+
+// laneFor возвращает lane провайдера из запущенного Run, а если опрос не запущен, например в режиме push, --
+// lane без воркеров, который хранит темп провайдера между вызовами Lookup.
+func (a *Agent) laneFor(ctx context.Context, log *slog.Logger, p Provider) *lane {
+	a.mu.Lock()
+	for _, l := range a.lanes {
+		if l.provider.Name == p.Name {
+			a.mu.Unlock()
+			return l
+		}
+	}
+	l, ok := a.idle[p.Name]
+	if !ok {
+		if p.RPM == 0 {
+			p.RPM = model.DefaultRequestCount
+		}
+		l = a.newLane(log, p)
+		if a.idle == nil {
+			a.idle = make(map[string]*lane)
+		}
+		a.idle[p.Name] = l
+	}
+	a.mu.Unlock()
+	if !ok {
+		l.ensureBudget(ctx, log)
+	}
+	return l
+}
+
+func (a *Agent) defaultProvider(maxRequestCount uint64) Provider {
+	return Provider{
+		Breaker: a.breaker,
+		Name:    DefaultProvider,
+		Address: a.accrualAddress,
+		Client:  a.client,
+		RPM:     maxRequestCount,
+	}
+}
+
 func (a *Agent) Run(ctx context.Context, maxRequestCount uint64) {
 	log := logger.FromContext(ctx).With("service", "agent")
 	log.LogAttrs(ctx, slog.LevelInfo, "running")
 
-	requestsCh := make(chan struct{}, runtime.NumCPU()*model.DefaultWorkerCountMultiplier)
-	rpmWatcher := requestwatcher.New(requestsCh, log)
-	rpmWatcher.Start()
+	if a.clients == nil {
+		if err := a.Validate(); err != nil {
+			log.LogAttrs(ctx,
+				slog.LevelError,
+				"invalid accrual client config",
+				slog.Any(model.KeyLoggerError, err))
+			close(a.responsesCh)
+			return
+		}
+	}
+
+	if a.controls != nil {
+		a.loadHold(ctx, log)
+	}
+	// под a.mu: Pause, вызванный во время запуска, не должен пройти мимо новых lane
+	a.mu.Lock()
+	lanes := make([]*lane, 0, len(a.providers)+1)
+	for _, p := range a.providers {
+		if p.RPM == 0 {
+			p.RPM = maxRequestCount
+		}
+		lanes = append(lanes, a.newLane(log.With("provider", p.Name), p))
+	}
+	fallback := a.newLane(log.With("provider", DefaultProvider), a.defaultProvider(maxRequestCount))
+	lanes = append(lanes, fallback)
+	a.lanes = lanes
+	a.mu.Unlock()
 
 	wg := &sync.WaitGroup{}
-	rateDataCh := make(chan serviceerrs.TooManyRequestsError)
-	pool := workerpool.New(
-		httpclient.New(a.accrualAddress),
-		semaphore.New(maxRequestCount),
-		wg,
-		a.ordersCh,
-		rateDataCh,
-		requestsCh,
-		a.responsesCh,
-	)
-	log.LogAttrs(ctx, slog.LevelInfo, "starting worker pool")
-	poolCancel := pool.Start(ctx, a.workerCount)
-
-	timer := time.NewTimer(model.DefaultTimeout)
-	timer.Stop()
-	defer func() {
-		if timer != nil {
-			timer.Stop()
-		}
-	}()
-
-	rateData := serviceerrs.TooManyRequestsError{}
+	for _, l := range lanes {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			l.run(ctx, log.With("provider", l.provider.Name))
+		}()
+		go func() {
+			defer wg.Done()
+			l.forward(a.responsesCh)
+		}()
+	}
+
+	if a.controls != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.follow(ctx, log)
+		}()
+	}
+
+	a.dispatch(ctx, log, lanes[:len(lanes)-1], fallback)
+	// новых заказов не будет: оставшиеся в очередях lane вернёт как неотправленные
+	for _, l := range lanes {
+		close(l.jobs)
+	}
+
+	wg.Wait()
+	a.mu.Lock()
+	a.lanes = nil
+	a.mu.Unlock()
+	close(a.responsesCh)
+	log.LogAttrs(ctx, slog.LevelInfo, "stopped")
+}
+
+// Pause приостанавливает опрос всех провайдеров: воркеры доделывают начатые запросы,
+// а новые и ждущие в очередях заказы сразу возвращаются с serviceerrs.ErrAgentPaused.
+// Пауза сохраняется и для Run, запущенного позже, а с WithControls действует на все реплики.
+func (a *Agent) Pause(ctx context.Context) error {
+	return a.hold(ctx, true)
+}
+
+// Resume возобновляет опрос после Pause. Пауза после 429 при этом не снимается.
+func (a *Agent) Resume(ctx context.Context) error {
+	return a.hold(ctx, false)
+}
+
+func (a *Agent) hold(ctx context.Context, held bool) error {
+	if a.controls != nil {
+		if err := a.controls.SetHold(ctx, a.providerNames(), held); err != nil {
+			return fmt.Errorf("failed to save accrual hold: %w", err)
+		}
+	}
+	return a.setPaused(ctx, held)
+}
+
+func (a *Agent) setPaused(ctx context.Context, paused bool) error {
+	a.mu.Lock()
+	a.paused = paused
+	lanes := a.lanes
+	a.mu.Unlock()
+	for _, l := range lanes {
+		if _, err := send(ctx, l, l.hold, paused); err != nil {
+			return fmt.Errorf("failed to pause provider %s: %w", l.provider.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *Agent) isPaused() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.paused
+}
+
+// SetMaxRequests меняет число одновременных запросов к провайдеру.
+// Темп запросов от этого не меняется: он подстраивается под лимит accrual сам.
+// С WithControls число действует на все реплики, в том числе ещё не запущенные.
+func (a *Agent) SetMaxRequests(ctx context.Context, provider string, n uint64) error {
+	if a.controls != nil {
+		if !slices.Contains(a.providerNames(), provider) {
+			return fmt.Errorf("accrual provider %s: %w", provider, serviceerrs.ErrNotFound)
+		}
+		if err := a.controls.SetMaxRequests(ctx, provider, n); err != nil {
+			return fmt.Errorf("failed to save max requests of provider %s: %w", provider, err)
+		}
+	}
+	a.mu.Lock()
+	lanes := a.lanes
+	a.mu.Unlock()
+	// остановленная реплика возьмёт число из хранилища при запуске
+	stopped := serviceerrs.ErrAgentNotRunning
+	if a.controls != nil {
+		stopped = nil
+	}
+	if lanes == nil {
+		return stopped
+	}
+	for _, l := range lanes {
+		if l.provider.Name != provider {
+			continue
+		}
+		sent, err := send(ctx, l, l.limit, n)
+		if err != nil {
+			return fmt.Errorf("failed to change max requests of provider %s: %w", provider, err)
+		}
+		if !sent {
+			return stopped
+		}
+		return nil
+	}
+	return fmt.Errorf("accrual provider %s: %w", provider, serviceerrs.ErrNotFound)
+}
+
+// State возвращает состояние агента и его провайдеров.
+func (a *Agent) State() State {
+	a.mu.Lock()
+	state := State{
+		Paused:  a.paused,
+		Running: a.lanes != nil,
+	}
+	lanes := a.lanes
+	a.mu.Unlock()
+	for _, l := range lanes {
+		state.Providers = append(state.Providers, l.snapshot())
+	}
+	return state
+}
+
+func (a *Agent) newLane(log *slog.Logger, p Provider) *lane {
+	workerCount := a.workerCount
+	var scaler *workerpool.Scaler
+	if a.scaling != nil {
+		scaler = workerpool.NewScaler(workerpool.ScaleConfig{
+			Interval:   a.scaling.Interval,
+			MaxLatency: a.scaling.MaxLatency,
+			Min:        a.scaling.Min,
+			Max:        a.scaling.Max,
+			DownAfter:  a.scaling.DownAfter,
+		})
+		cfg := scaler.Config()
+		workerCount = min(max(workerCount, cfg.Min), cfg.Max)
+	}
+	l := newLane(p, a.clients[p.Name], a.metrics, scaler, workerCount, a.paused)
+	l.limiter = l.rate
+	if a.budget != nil {
+		l.budget = a.budget
+		l.shared = &sharedLimiter{local: l.rate, budget: a.budget, log: log, provider: p.Name}
+		l.limiter = l.shared
+	}
+	return l
+}
+
+// dispatch раздаёт заказы провайдерам, пока не отменён ctx.
+func (a *Agent) dispatch(ctx context.Context, log *slog.Logger, lanes []*lane, fallback *lane) {
 	for {
 		select {
 		case <-ctx.Done():
-			poolCancel()
-			wg.Wait()
-			close(requestsCh)
-			close(rateDataCh)
-			close(a.responsesCh)
-			log.LogAttrs(ctx, slog.LevelInfo, "stopped")
 			return
-		case rateData = <-rateDataCh:
-			wg.Wait()
-			rpmWatcher.Stop()
-			timer = time.NewTimer(rateData.RetryAfter)
-			log.LogAttrs(ctx,
-				slog.LevelInfo,
-				"paused requesting",
-				slog.Duration("retry_after", rateData.RetryAfter))
-		case <-timer.C:
-			currRPM := rpmWatcher.GetRPM()
-			newMaxRequestCount := maxRequestCount
-			if currRPM != 0 {
-				newMaxRequestCount = rateData.RPM / currRPM
+		case orderID, ok := <-a.ordersCh:
+			if !ok {
+				<-ctx.Done()
+				return
 			}
-
-			rpmWatcher.Start()
-			pool.ChangeMaxRequests(newMaxRequestCount)
-			poolCancel = pool.Start(ctx, a.workerCount)
+			if a.isPaused() {
+				if !a.reject(ctx, orderID, serviceerrs.ErrAgentPaused) {
+					return
+				}
+				continue
+			}
+			l := fallback
+			for _, candidate := range lanes {
+				if candidate.provider.matches(orderID) {
+					l = candidate
+					break
+				}
+			}
+			if l.offer(orderID) {
+				continue
+			}
+			// очередь провайдера полна, пока он на паузе после 429:
+			// отвечаем сразу, чтобы не держать заказы других провайдеров
 			log.LogAttrs(ctx,
-				slog.LevelInfo,
-				"restarted requesting",
-				slog.Int("old_rpm", int(maxRequestCount)),
-				slog.Int("new_rpm", int(newMaxRequestCount)))
+				slog.LevelDebug,
+				"accrual provider is busy, order postponed",
+				slog.String("provider", l.provider.Name),
+				slog.String("order_no", orderID),
+			)
+			if !a.reject(ctx, orderID, serviceerrs.ErrProviderBusy) {
+				return
+			}
 		}
 	}
 }
+
+// reject сразу отвечает, что заказ не отправлен в accrual; false -- ctx отменён.
+func (a *Agent) reject(ctx context.Context, orderID string, reason error) bool {
+	select {
+	case a.responsesCh <- dto.AccrualInfo{
+		Order:  orderID,
+		Status: string(dto.StatusAgentFailed),
+		Err:    reason,
+	}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
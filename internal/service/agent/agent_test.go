@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func TestProvider_matches(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		orderID  string
+		want     bool
+	}{
+		{"prefix", Provider{Prefixes: []string{"9", "42"}}, "4200", true},
+		{"other prefix", Provider{Prefixes: []string{"9", "42"}}, "4300", false},
+		{"length", Provider{Lengths: []int{16}}, "4561261212345467", true},
+		{"other length", Provider{Lengths: []int{16}}, "12345678903", false},
+		{"prefix and length", Provider{Prefixes: []string{"4"}, Lengths: []int{16}}, "4561261212345467", true},
+		{"prefix without length", Provider{Prefixes: []string{"4"}, Lengths: []int{16}}, "4561", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.provider.matches(tt.orderID))
+		})
+	}
+}
+
+func TestAgent_Run_providerThrottlingIsIsolated(t *testing.T) {
+	throttled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("No more than 1 requests per minute allowed"))
+	}))
+	defer throttled.Close()
+
+	var authorization string
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		orderID := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+		w.Header().Set(model.HeaderContentType, "application/json")
+		_, _ = w.Write([]byte(`{"order":"` + orderID + `","status":"PROCESSED","accrual":10}`))
+	}))
+	defer partner.Close()
+
+	ordersCh := make(chan string)
+	responsesCh := make(chan dto.AccrualInfo)
+	a := New(ordersCh, responsesCh, throttled.URL, nil, nil).
+		WithProviders(Provider{Name: "partner", Address: partner.URL,
+			Client: ClientConfig{Token: "secret"}, Prefixes: []string{"9"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx, model.DefaultRequestCount)
+		close(done)
+	}()
+
+	receive := func() dto.AccrualInfo {
+		select {
+		case resp := <-responsesCh:
+			return resp
+		case <-time.After(time.Second):
+			require.FailNow(t, "no accrual response")
+			return dto.AccrualInfo{}
+		}
+	}
+
+	ordersCh <- "1111"
+	resp := receive()
+	assert.Equal(t, "1111", resp.Order)
+	var tmrErr *serviceerrs.TooManyRequestsError
+	assert.ErrorAs(t, resp.Err, &tmrErr)
+
+	// провайдер по умолчанию на паузе на минуту, а партнёр продолжает отвечать
+	for _, orderID := range []string{"9001", "9002"} {
+		ordersCh <- orderID
+		resp = receive()
+		assert.Equal(t, orderID, resp.Order)
+		assert.Equal(t, string(dto.StatusCalculatorProcessed), resp.Status)
+	}
+	assert.Equal(t, "Bearer secret", authorization)
+
+	// темп провайдера по умолчанию снижен до лимита из ответа 429
+	state := a.State()
+	require.Len(t, state.Providers, 2)
+	assert.Equal(t, ProviderState{
+		RetryAfter:  state.Providers[1].RetryAfter,
+		Name:        DefaultProvider,
+		MaxRequests: model.DefaultRequestCount,
+		RateLimit:   1,
+		RPM:         1,
+		Throttled:   true,
+	}, state.Providers[1])
+	assert.Equal(t, uint64(2), state.Providers[0].RPM)
+
+	cancel()
+	<-done
+	_, ok := <-responsesCh
+	assert.False(t, ok)
+}
+
+func TestAgent_Run_finishesRequestsOnStop(t *testing.T) {
+	inFlight := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(inFlight)
+		<-release
+		orderID := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+		w.Header().Set(model.HeaderContentType, "application/json")
+		_, _ = w.Write([]byte(`{"order":"` + orderID + `","status":"PROCESSED","accrual":10}`))
+	}))
+	defer srv.Close()
+
+	ordersCh := make(chan string)
+	responsesCh := make(chan dto.AccrualInfo)
+	a := New(ordersCh, responsesCh, srv.URL, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go a.Run(ctx, model.DefaultRequestCount)
+
+	ordersCh <- "1111"
+	<-inFlight
+	cancel()
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+
+	// запрос, начатый до остановки, не отменяется, и его ответ доходит
+	select {
+	case resp := <-responsesCh:
+		assert.Equal(t, "1111", resp.Order)
+		assert.Equal(t, string(dto.StatusCalculatorProcessed), resp.Status)
+	case <-time.After(time.Second):
+		require.FailNow(t, "in-flight request was lost")
+	}
+	select {
+	case _, ok := <-responsesCh:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		require.FailNow(t, "agent did not stop")
+	}
+}
+
+func TestAgent_Lookup(t *testing.T) {
+	reply := func(status string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			orderID := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+			w.Header().Set(model.HeaderContentType, "application/json")
+			_, _ = w.Write([]byte(`{"order":"` + orderID + `","status":"` + status + `"}`))
+		}))
+	}
+	fallback := reply("INVALID")
+	defer fallback.Close()
+	partner := reply("PROCESSING")
+	defer partner.Close()
+
+	a := New(nil, nil, fallback.URL, nil, nil).
+		WithProviders(Provider{Name: "partner", Address: partner.URL, Prefixes: []string{"9"}})
+	_, err := a.Lookup(context.Background(), "1111")
+	require.Error(t, err)
+
+	require.NoError(t, a.Validate())
+	info, err := a.Lookup(context.Background(), "9001")
+	require.NoError(t, err)
+	assert.Equal(t, dto.AccrualInfo{Order: "9001", Status: "PROCESSING"}, info)
+	info, err = a.Lookup(context.Background(), "1111")
+	require.NoError(t, err)
+	assert.Equal(t, dto.AccrualInfo{Order: "1111", Status: "INVALID"}, info)
+}
+
+func TestAgent_control(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orderID := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+		w.Header().Set(model.HeaderContentType, "application/json")
+		_, _ = w.Write([]byte(`{"order":"` + orderID + `","status":"PROCESSED","accrual":10}`))
+	}))
+	defer srv.Close()
+
+	ordersCh := make(chan string)
+	responsesCh := make(chan dto.AccrualInfo)
+	a := New(ordersCh, responsesCh, srv.URL, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx, model.DefaultRequestCount)
+		close(done)
+	}()
+
+	check := func(orderID string) dto.AccrualInfo {
+		ordersCh <- orderID
+		select {
+		case resp := <-responsesCh:
+			assert.Equal(t, orderID, resp.Order)
+			return resp
+		case <-time.After(time.Second):
+			require.FailNow(t, "no accrual response")
+			return dto.AccrualInfo{}
+		}
+	}
+	provider := func() ProviderState {
+		state := a.State()
+		require.True(t, state.Running)
+		require.Len(t, state.Providers, 1)
+		return state.Providers[0]
+	}
+
+	assert.Equal(t, string(dto.StatusCalculatorProcessed), check("1").Status)
+
+	require.NoError(t, a.Pause(ctx))
+	assert.True(t, a.State().Paused)
+	resp := check("2")
+	assert.Equal(t, string(dto.StatusAgentFailed), resp.Status)
+	require.ErrorIs(t, resp.Err, serviceerrs.ErrAgentPaused)
+	assert.Eventually(t, func() bool { return provider().Workers == 0 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, a.SetMaxRequests(ctx, DefaultProvider, 5))
+	assert.Eventually(t, func() bool { return provider().MaxRequests == 5 }, time.Second, 10*time.Millisecond)
+	require.ErrorIs(t, a.SetMaxRequests(ctx, "unknown", 5), serviceerrs.ErrNotFound)
+
+	require.NoError(t, a.Resume(ctx))
+	assert.Equal(t, string(dto.StatusCalculatorProcessed), check("3").Status)
+	assert.Positive(t, provider().Workers)
+	assert.Zero(t, provider().InFlight)
+
+	cancel()
+	<-done
+	assert.False(t, a.State().Running)
+	require.ErrorIs(t, a.SetMaxRequests(context.Background(), DefaultProvider, 5), serviceerrs.ErrAgentNotRunning)
+}
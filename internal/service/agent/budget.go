@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/ratelimit"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+// Budget -- общий для всех реплик бюджет запросов к провайдеру: accrual ограничивает темп
+// всех наших экземпляров вместе.
+type Budget interface {
+	// Ensure заводит бюджет с темпом rpm, если его ещё нет.
+	Ensure(ctx context.Context, provider string, rpm uint64) error
+	// Take берёт токен и возвращает, сколько ждать до его использования;
+	// paused -- токен не взят из-за паузы после 429, Take нужно повторить после ожидания.
+	Take(ctx context.Context, provider string) (wait time.Duration, paused bool, err error)
+	// Throttle ставит на паузу все реплики; rpm -- новый темп, 0 -- снизить текущий вдвое.
+	Throttle(ctx context.Context, provider string, rpm uint64, retryAfter time.Duration) error
+	// Raise поднимает темп, если accrual не отказывал дольше quiet; raised -- темп поднят этим вызовом.
+	Raise(ctx context.Context, provider string, quiet time.Duration) (rpm uint64, raised bool, err error)
+}
+
+// sharedQuietPeriod -- сколько общий темп держится после 429 или прошлого подъёма.
+const sharedQuietPeriod = time.Minute
+
+// sharedLimiter пропускает запрос, когда его разрешили и свой темп реплики, и общий бюджет.
+// Если бюджет недоступен, запросы ограничивает только свой темп: опрос не должен вставать из-за БД.
+type sharedLimiter struct {
+	local    *ratelimit.Controller
+	budget   Budget
+	log      *slog.Logger
+	provider string
+}
+
+func (s *sharedLimiter) Wait(ctx context.Context) error {
+	if err := s.local.Wait(ctx); err != nil {
+		return err //nolint: wrapcheck // only ctx errors
+	}
+	for {
+		wait, paused, err := s.budget.Take(ctx, s.provider)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err() //nolint: wrapcheck // caller's ctx error
+			}
+			s.log.LogAttrs(ctx,
+				slog.LevelWarn,
+				"shared accrual budget is unavailable, using local rate only",
+				slog.Any(model.KeyLoggerError, err))
+			return nil
+		}
+		if err = sleep(ctx, wait); err != nil {
+			return err
+		}
+		if !paused {
+			return nil
+		}
+	}
+}
+
+// throttle сообщает о 429 остальным репликам.
+func (s *sharedLimiter) throttle(ctx context.Context, rateData serviceerrs.TooManyRequestsError) {
+	var rpm uint64
+	if rateData.RPM != 0 {
+		rpm = max(1, uint64(float64(rateData.RPM)*ratelimit.DefaultHeadroom))
+	}
+	if err := s.budget.Throttle(ctx, s.provider, rpm, rateData.RetryAfter); err != nil {
+		s.log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to pause other replicas",
+			slog.Any(model.KeyLoggerError, err))
+	}
+}
+
+// probe поднимает общий темп, снижённый после 429, когда accrual долго не отказывал.
+func (s *sharedLimiter) probe(ctx context.Context) {
+	rpm, raised, err := s.budget.Raise(ctx, s.provider, sharedQuietPeriod)
+	if err != nil {
+		s.log.LogAttrs(ctx,
+			slog.LevelWarn,
+			"failed to raise shared accrual budget",
+			slog.Any(model.KeyLoggerError, err))
+		return
+	}
+	if raised {
+		s.log.LogAttrs(ctx, slog.LevelInfo, "shared request rate raised", slog.Uint64("new_rpm", rpm))
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err() //nolint: wrapcheck // caller's ctx error
+	}
+}
@@ -0,0 +1,256 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/ratelimit"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+// fakeBudget -- бюджет в памяти вместо таблицы в БД.
+type fakeBudget struct {
+	err         error
+	rpm         map[string]uint64
+	pausedUntil map[string]time.Time
+	ceiling     uint64
+	takes       int
+	mu          sync.Mutex
+}
+
+func newFakeBudget() *fakeBudget {
+	return &fakeBudget{rpm: map[string]uint64{}, pausedUntil: map[string]time.Time{}}
+}
+
+func (b *fakeBudget) Ensure(_ context.Context, provider string, rpm uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.rpm[provider]; !ok {
+		b.rpm[provider] = rpm
+	}
+	return nil
+}
+
+func (b *fakeBudget) Take(_ context.Context, provider string) (time.Duration, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return 0, false, b.err
+	}
+	if wait := time.Until(b.pausedUntil[provider]); wait > 0 {
+		return wait, true, nil
+	}
+	b.takes++
+	return 0, false, nil
+}
+
+func (b *fakeBudget) Throttle(_ context.Context, provider string, rpm uint64, retryAfter time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rpm != 0 {
+		b.rpm[provider] = rpm
+	}
+	b.pausedUntil[provider] = time.Now().Add(retryAfter)
+	return nil
+}
+
+func (b *fakeBudget) Raise(_ context.Context, provider string, quiet time.Duration) (uint64, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Since(b.pausedUntil[provider]) < quiet || b.rpm[provider] >= b.ceiling {
+		return 0, false, nil
+	}
+	b.rpm[provider] = min(b.ceiling, b.rpm[provider]+max(1, b.rpm[provider]/20))
+	return b.rpm[provider], true, nil
+}
+
+func (b *fakeBudget) snapshot(provider string) (uint64, bool, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rpm[provider], time.Now().Before(b.pausedUntil[provider]), b.takes
+}
+
+func TestSharedLimiter_Wait(t *testing.T) {
+	budget := newFakeBudget()
+	s := &sharedLimiter{
+		local:    ratelimit.New(ratelimit.Config{}),
+		budget:   budget,
+		log:      slog.Default(),
+		provider: DefaultProvider,
+	}
+	ctx := context.Background()
+
+	require.NoError(t, s.Wait(ctx))
+	_, _, takes := budget.snapshot(DefaultProvider)
+	assert.Equal(t, 1, takes)
+
+	// пауза, объявленная другой репликой, задерживает запрос
+	require.NoError(t, budget.Throttle(ctx, DefaultProvider, 0, 50*time.Millisecond))
+	started := time.Now()
+	require.NoError(t, s.Wait(ctx))
+	assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+	_, _, takes = budget.snapshot(DefaultProvider)
+	assert.Equal(t, 2, takes)
+
+	require.NoError(t, budget.Throttle(ctx, DefaultProvider, 0, time.Hour))
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, s.Wait(cancelled), context.DeadlineExceeded)
+
+	// недоступный бюджет не останавливает опрос
+	budget.err = errors.New("connection refused")
+	require.NoError(t, s.Wait(ctx))
+}
+
+func TestSharedLimiter_probe(t *testing.T) {
+	budget := newFakeBudget()
+	budget.ceiling = 60
+	s := &sharedLimiter{
+		local:    ratelimit.New(ratelimit.Config{}),
+		budget:   budget,
+		log:      slog.Default(),
+		provider: DefaultProvider,
+	}
+	ctx := context.Background()
+	require.NoError(t, budget.Ensure(ctx, DefaultProvider, 30))
+
+	// сразу после 429 темп не растёт
+	require.NoError(t, budget.Throttle(ctx, DefaultProvider, 0, 0))
+	s.probe(ctx)
+	rpm, _, _ := budget.snapshot(DefaultProvider)
+	assert.Equal(t, uint64(30), rpm)
+
+	// после тихого периода растёт понемногу до потолка
+	budget.pausedUntil[DefaultProvider] = time.Now().Add(-sharedQuietPeriod)
+	s.probe(ctx)
+	rpm, _, _ = budget.snapshot(DefaultProvider)
+	assert.Equal(t, uint64(31), rpm)
+	for range 100 {
+		s.probe(ctx)
+	}
+	rpm, _, _ = budget.snapshot(DefaultProvider)
+	assert.Equal(t, uint64(60), rpm)
+}
+
+func TestAgent_Run_sharedBudget(t *testing.T) {
+	throttled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("No more than 10 requests per minute allowed"))
+	}))
+	defer throttled.Close()
+	var requests atomic.Int64
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		orderID := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+		w.Header().Set(model.HeaderContentType, "application/json")
+		_, _ = w.Write([]byte(`{"order":"` + orderID + `","status":"PROCESSED","accrual":10}`))
+	}))
+	defer healthy.Close()
+
+	budget := newFakeBudget()
+	run := func(address string) (chan<- string, <-chan dto.AccrualInfo, context.CancelFunc) {
+		ordersCh := make(chan string)
+		responsesCh := make(chan dto.AccrualInfo)
+		a := New(ordersCh, responsesCh, address, nil, nil).WithBudget(budget)
+		ctx, cancel := context.WithCancel(context.Background())
+		go a.Run(ctx, model.DefaultRequestCount)
+		return ordersCh, responsesCh, cancel
+	}
+	receive := func(responsesCh <-chan dto.AccrualInfo) dto.AccrualInfo {
+		select {
+		case resp := <-responsesCh:
+			return resp
+		case <-time.After(time.Second):
+			require.FailNow(t, "no accrual response")
+			return dto.AccrualInfo{}
+		}
+	}
+
+	// первая реплика получает 429
+	ordersA, responsesA, cancelA := run(throttled.URL)
+	defer cancelA()
+	ordersA <- "1"
+	var tmrErr *serviceerrs.TooManyRequestsError
+	require.ErrorAs(t, receive(responsesA).Err, &tmrErr)
+	require.Eventually(t, func() bool {
+		_, paused, _ := budget.snapshot(DefaultProvider)
+		return paused
+	}, time.Second, 10*time.Millisecond)
+	rpm, _, _ := budget.snapshot(DefaultProvider)
+	assert.Equal(t, uint64(9), rpm)
+
+	// вторая реплика ждёт конца паузы и не обращается к accrual
+	ordersB, responsesB, cancelB := run(healthy.URL)
+	ordersB <- "2"
+	time.Sleep(100 * time.Millisecond)
+	assert.Zero(t, requests.Load())
+
+	// при остановке ждущий заказ возвращается неотправленным
+	cancelB()
+	resp := receive(responsesB)
+	assert.Equal(t, "2", resp.Order)
+	require.ErrorIs(t, resp.Err, serviceerrs.ErrAgentStopped)
+	_, ok := <-responsesB
+	assert.False(t, ok)
+}
+
+func TestAgent_Lookup_throttles(t *testing.T) {
+	var requests atomic.Int64
+	throttled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("No more than 10 requests per minute allowed"))
+	}))
+	defer throttled.Close()
+
+	t.Run("agent is not running", func(t *testing.T) {
+		requests.Store(0)
+		budget := newFakeBudget()
+		a := New(nil, nil, throttled.URL, nil, nil).WithBudget(budget)
+		require.NoError(t, a.Validate())
+
+		_, err := a.Lookup(context.Background(), "1")
+		var tmrErr *serviceerrs.TooManyRequestsError
+		require.ErrorAs(t, err, &tmrErr)
+		rpm, paused, _ := budget.snapshot(DefaultProvider)
+		assert.Equal(t, uint64(9), rpm)
+		assert.True(t, paused)
+
+		// следующий запрос ждёт конца паузы, объявленной accrual
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = a.Lookup(ctx, "2")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int64(1), requests.Load())
+	})
+
+	t.Run("agent is running", func(t *testing.T) {
+		a := New(make(chan string), make(chan dto.AccrualInfo), throttled.URL, nil, nil)
+		require.NoError(t, a.Validate())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go a.Run(ctx, model.DefaultRequestCount)
+		require.Eventually(t, func() bool { return a.State().Running }, time.Second, 10*time.Millisecond)
+
+		_, err := a.Lookup(ctx, "1")
+		var tmrErr *serviceerrs.TooManyRequestsError
+		require.ErrorAs(t, err, &tmrErr)
+		// 429 на сверке снижает темп опроса
+		assert.Equal(t, uint64(9), a.State().Providers[0].RateLimit)
+	})
+}
@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/accrual"
+)
+
+const (
+	// controlsInterval -- как часто реплика сверяется с командами оператора и сообщает своё состояние
+	controlsInterval = 5 * time.Second
+	// replicaStaleAfter -- реплика, так долго не сообщавшая состояние, считается остановленной
+	replicaStaleAfter = 3 * controlsInterval
+)
+
+// Controls -- общее для всех реплик хранилище команд оператора и состояний реплик:
+// пауза и число одновременных запросов, заданные через любую реплику, действуют на все.
+type Controls interface {
+	// SetHold приостанавливает опрос providers или, если held ложно, возобновляет опрос всех провайдеров.
+	SetHold(ctx context.Context, providers []string, held bool) error
+	SetMaxRequests(ctx context.Context, provider string, n uint64) error
+	// Controls возвращает команды по провайдерам; провайдеров без команд в ответе нет.
+	Controls(ctx context.Context) (map[string]accrual.Control, error)
+	// Report заменяет состояние реплики и удаляет состояния, не обновлявшиеся с staleBefore.
+	Report(ctx context.Context, replica accrual.Replica, staleBefore time.Time) error
+	Replicas(ctx context.Context, since time.Time) ([]accrual.Replica, error)
+}
+
+// WithControls хранит паузу и число одновременных запросов в общем хранилище, так что команды
+// оператора действуют на все реплики, а состояние каждой реплики видно через любую.
+func (a *Agent) WithControls(c Controls, instanceID string) *Agent {
+	a.controls = c
+	a.instanceID = instanceID
+	return a
+}
+
+// Replicas возвращает состояния работающих реплик; без WithControls -- nil.
+func (a *Agent) Replicas(ctx context.Context) ([]accrual.Replica, error) {
+	if a.controls == nil {
+		return nil, nil
+	}
+	replicas, err := a.controls.Replicas(ctx, time.Now().Add(-replicaStaleAfter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accrual replicas: %w", err)
+	}
+	return replicas, nil
+}
+
+// providerNames -- имена всех провайдеров, включая провайдера по умолчанию.
+func (a *Agent) providerNames() []string {
+	names := make([]string, 0, len(a.providers)+1)
+	for _, p := range a.providers {
+		names = append(names, p.Name)
+	}
+	return append(names, DefaultProvider)
+}
+
+// heldBy -- опрос приостановлен, если оператор приостановил любого из провайдеров реплики.
+func (a *Agent) heldBy(controls map[string]accrual.Control) bool {
+	for _, name := range a.providerNames() {
+		if controls[name].Held {
+			return true
+		}
+	}
+	return false
+}
+
+// loadHold берёт паузу из хранилища до запуска lane, чтобы перезапущенная реплика
+// не начала опрос, приостановленный оператором.
+func (a *Agent) loadHold(ctx context.Context, log *slog.Logger) {
+	controls, err := a.controls.Controls(ctx)
+	if err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelWarn,
+			"failed to load accrual controls",
+			slog.Any(model.KeyLoggerError, err))
+		return
+	}
+	a.mu.Lock()
+	a.paused = a.heldBy(controls)
+	a.mu.Unlock()
+}
+
+// follow применяет команды оператора, отданные через другие реплики, и сообщает им своё состояние,
+// пока не отменён ctx.
+func (a *Agent) follow(ctx context.Context, log *slog.Logger) {
+	ticker := time.NewTicker(controlsInterval)
+	defer ticker.Stop()
+	for {
+		a.syncControls(ctx, log)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Agent) syncControls(ctx context.Context, log *slog.Logger) {
+	controls, err := a.controls.Controls(ctx)
+	if err == nil {
+		err = a.applyControls(ctx, controls)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.LogAttrs(ctx,
+			slog.LevelWarn,
+			"failed to apply accrual controls",
+			slog.Any(model.KeyLoggerError, err))
+	}
+
+	state := a.State()
+	err = a.controls.Report(ctx, accrual.Replica{
+		InstanceID: a.instanceID,
+		Providers:  state.Providers,
+		Paused:     state.Paused,
+	}, time.Now().Add(-replicaStaleAfter))
+	if err != nil && ctx.Err() == nil {
+		log.LogAttrs(ctx,
+			slog.LevelWarn,
+			"failed to report accrual state",
+			slog.Any(model.KeyLoggerError, err))
+	}
+}
+
+func (a *Agent) applyControls(ctx context.Context, controls map[string]accrual.Control) error {
+	if paused := a.heldBy(controls); paused != a.isPaused() {
+		if err := a.setPaused(ctx, paused); err != nil {
+			return err
+		}
+	}
+	a.mu.Lock()
+	lanes := a.lanes
+	a.mu.Unlock()
+	for _, l := range lanes {
+		n := controls[l.provider.Name].MaxRequests
+		if n == 0 || n == l.snapshot().MaxRequests {
+			continue
+		}
+		if _, err := send(ctx, l, l.limit, n); err != nil {
+			return fmt.Errorf("failed to change max requests of provider %s: %w", l.provider.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/accrual"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+// fakeControls -- хранилище команд в памяти вместо таблиц в БД.
+type fakeControls struct {
+	controls map[string]accrual.Control
+	replicas map[string]accrual.Replica
+	mu       sync.Mutex
+}
+
+func newFakeControls() *fakeControls {
+	return &fakeControls{controls: map[string]accrual.Control{}, replicas: map[string]accrual.Replica{}}
+}
+
+func (c *fakeControls) SetHold(_ context.Context, providers []string, held bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !held {
+		providers = providers[:0]
+		for name := range c.controls {
+			providers = append(providers, name)
+		}
+	}
+	for _, name := range providers {
+		control := c.controls[name]
+		control.Held = held
+		c.controls[name] = control
+	}
+	return nil
+}
+
+func (c *fakeControls) SetMaxRequests(_ context.Context, provider string, n uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	control := c.controls[provider]
+	control.MaxRequests = n
+	c.controls[provider] = control
+	return nil
+}
+
+func (c *fakeControls) Controls(context.Context) (map[string]accrual.Control, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	controls := make(map[string]accrual.Control, len(c.controls))
+	for name, control := range c.controls {
+		controls[name] = control
+	}
+	return controls, nil
+}
+
+func (c *fakeControls) Report(_ context.Context, replica accrual.Replica, _ time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	replica.ReportedAt = time.Now()
+	c.replicas[replica.InstanceID] = replica
+	return nil
+}
+
+func (c *fakeControls) Replicas(_ context.Context, since time.Time) ([]accrual.Replica, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var replicas []accrual.Replica
+	for _, replica := range c.replicas {
+		if !replica.ReportedAt.Before(since) {
+			replicas = append(replicas, replica)
+		}
+	}
+	return replicas, nil
+}
+
+func TestAgent_controlsAreShared(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orderID := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+		w.Header().Set(model.HeaderContentType, "application/json")
+		_, _ = w.Write([]byte(`{"order":"` + orderID + `","status":"PROCESSED","accrual":10}`))
+	}))
+	defer srv.Close()
+
+	shared := newFakeControls()
+	ordersCh := make(chan string)
+	responsesCh := make(chan dto.AccrualInfo)
+	a := New(ordersCh, responsesCh, srv.URL, nil, nil).WithControls(shared, "replica-1")
+	// реплика, принявшая запрос оператора; опрос на ней не запущен
+	b := New(nil, nil, srv.URL, nil, nil).WithControls(shared, "replica-2")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx, model.DefaultRequestCount)
+		close(done)
+	}()
+
+	check := func(orderID string) dto.AccrualInfo {
+		ordersCh <- orderID
+		select {
+		case resp := <-responsesCh:
+			assert.Equal(t, orderID, resp.Order)
+			return resp
+		case <-time.After(time.Second):
+			require.FailNow(t, "no accrual response")
+			return dto.AccrualInfo{}
+		}
+	}
+	log := slog.Default()
+
+	assert.Equal(t, string(dto.StatusCalculatorProcessed), check("1").Status)
+
+	require.NoError(t, b.Pause(ctx))
+	a.syncControls(ctx, log)
+	assert.True(t, a.State().Paused)
+	resp := check("2")
+	assert.Equal(t, string(dto.StatusAgentFailed), resp.Status)
+	require.ErrorIs(t, resp.Err, serviceerrs.ErrAgentPaused)
+
+	require.NoError(t, b.SetMaxRequests(ctx, DefaultProvider, 5))
+	require.ErrorIs(t, b.SetMaxRequests(ctx, "unknown", 5), serviceerrs.ErrNotFound)
+	a.syncControls(ctx, log)
+	assert.Eventually(t, func() bool {
+		return a.State().Providers[0].MaxRequests == 5
+	}, time.Second, 10*time.Millisecond)
+
+	a.syncControls(ctx, log)
+	replicas, err := b.Replicas(ctx)
+	require.NoError(t, err)
+	require.Len(t, replicas, 1)
+	assert.Equal(t, "replica-1", replicas[0].InstanceID)
+	assert.True(t, replicas[0].Paused)
+	require.Len(t, replicas[0].Providers, 1)
+	assert.Equal(t, uint64(5), replicas[0].Providers[0].MaxRequests)
+
+	t.Run("started replica follows hold", func(t *testing.T) {
+		c := New(make(chan string), make(chan dto.AccrualInfo), srv.URL, nil, nil).WithControls(shared, "replica-3")
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go c.Run(ctx, model.DefaultRequestCount)
+		require.Eventually(t, func() bool { return c.State().Running }, time.Second, 10*time.Millisecond)
+		assert.True(t, c.State().Paused)
+	})
+
+	require.NoError(t, b.Resume(ctx))
+	a.syncControls(ctx, log)
+	assert.False(t, a.State().Paused)
+	assert.Equal(t, string(dto.StatusCalculatorProcessed), check("4").Status)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,142 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+// replay отвечает на любой запрос ответом accrual, записанным в testdata/accrual.
+func replay(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "accrual", name))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	recorded, err := http.ReadResponse(bufio.NewReader(f), nil)
+	require.NoError(t, err)
+	body, err := io.ReadAll(recorded.Body)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for key, values := range recorded.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(recorded.StatusCode)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestHTTPClient_GetOrderInfo_contract фиксирует, как клиент понимает ответы accrual.
+// Если accrual начнёт отвечать иначе, новый ответ стоит записать сюда же.
+func TestHTTPClient_GetOrderInfo_contract(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		recorded    string
+		want        dto.AccrualInfo
+		wantErr     error
+		wantErrText string
+		want429     *serviceerrs.TooManyRequestsError
+	}{
+		{
+			recorded: "200_registered.http",
+			want:     dto.AccrualInfo{Order: "9278923470", Status: string(dto.StatusCalculatorRegistered)},
+		},
+		{
+			recorded: "200_processing.http",
+			want:     dto.AccrualInfo{Order: "9278923470", Status: string(dto.StatusCalculatorProcessing)},
+		},
+		{
+			recorded: "200_invalid.http",
+			want:     dto.AccrualInfo{Order: "9278923470", Status: string(dto.StatusCalculatorInvalid)},
+		},
+		{
+			recorded: "200_processed.http",
+			want: dto.AccrualInfo{
+				Order: "9278923470", Status: string(dto.StatusCalculatorProcessed), Accrual: "729.98"},
+		},
+		{
+			recorded: "200_processed_charset.http",
+			want: dto.AccrualInfo{
+				Order: "9278923470", Status: string(dto.StatusCalculatorProcessed), Accrual: "500"},
+		},
+		{
+			recorded: "200_processed_extra_fields.http",
+			want: dto.AccrualInfo{
+				Order: "9278923470", Status: string(dto.StatusCalculatorProcessed), Accrual: "500"},
+		},
+		{recorded: "200_text_plain.http", wantErrText: "unexpected content type text/plain; charset=utf-8"},
+		{recorded: "200_malformed.http", wantErrText: "request decoding error"},
+		{recorded: "204_no_content.http", wantErr: serviceerrs.ErrNoContent},
+		{
+			recorded: "429_retry_after_seconds.http",
+			want429:  &serviceerrs.TooManyRequestsError{RetryAfter: time.Minute, RPM: 60},
+		},
+		{
+			recorded: "429_retry_after_date.http",
+			want429:  &serviceerrs.TooManyRequestsError{RetryAfter: 90 * time.Second, RPM: 60},
+		},
+		{
+			recorded: "429_no_retry_after.http",
+			want429:  &serviceerrs.TooManyRequestsError{RetryAfter: defaultRetryAfter, RPM: 100},
+		},
+		{
+			recorded: "429_json_body.http",
+			want429:  &serviceerrs.TooManyRequestsError{RetryAfter: 30 * time.Second, RPM: 120},
+		},
+		{
+			recorded: "429_unknown_body.http",
+			want429:  &serviceerrs.TooManyRequestsError{RetryAfter: 30 * time.Second},
+		},
+		{
+			recorded: "429_empty_body.http",
+			want429:  &serviceerrs.TooManyRequestsError{RetryAfter: 30 * time.Second},
+		},
+		{recorded: "500_internal.http", wantErrText: "accrual service error Body: internal error"},
+		{recorded: "503_unavailable.http", wantErrText: "unexpected status: 503"},
+	}
+	// каждый записанный ответ должен быть в таблице
+	files, err := filepath.Glob(filepath.Join("testdata", "accrual", "*.http"))
+	require.NoError(t, err)
+	recorded := make([]string, 0, len(tests))
+	for _, tt := range tests {
+		recorded = append(recorded, filepath.Join("testdata", "accrual", tt.recorded))
+	}
+	require.ElementsMatch(t, files, recorded)
+
+	for _, tt := range tests {
+		t.Run(tt.recorded, func(t *testing.T) {
+			c, err := New(replay(t, tt.recorded).URL, Config{})
+			require.NoError(t, err)
+			c.now = func() time.Time { return now }
+
+			info, err := c.GetOrderInfo(context.Background(), "9278923470")
+			switch {
+			case tt.want429 != nil:
+				var tooMany *serviceerrs.TooManyRequestsError
+				require.ErrorAs(t, err, &tooMany)
+				assert.Equal(t, tt.want429, tooMany)
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+			case tt.wantErrText != "":
+				require.ErrorContains(t, err, tt.wantErrText)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, info)
+			}
+		})
+	}
+}
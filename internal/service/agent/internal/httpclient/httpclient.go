@@ -7,53 +7,113 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/talx-hub/gopher-bonus/internal/model"
 	"github.com/talx-hub/gopher-bonus/internal/service/dto"
 	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
 	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
 )
 
+const tracerName = "github.com/talx-hub/gopher-bonus/internal/service/agent"
+
+// defaultRetryAfter -- пауза после 429 без Retry-After: accrual считает лимит за минуту.
+const defaultRetryAfter = time.Minute
+
+var rpmPattern = regexp.MustCompile(`(?i)(\d+)\s+requests?\s+per\s+minute`)
+
 type HTTPClient struct {
-	client         http.Client
-	accrualAddress string
+	// если задан, вызывается с кодом каждого ответа accrual, 0 -- ответа не было
+	OnResponse func(code int)
+	// добавляется к каждому запросу, например Authorization
+	Header http.Header
+	tracer trace.Tracer
+	base   *url.URL
+	now    func() time.Time
+	client http.Client
 }
 
-func New(accrualAddress string) *HTTPClient {
-	return &HTTPClient{
-		client:         http.Client{},
-		accrualAddress: accrualAddress,
+// New проверяет адрес accrual и собирает клиент с транспортом, TLS и заголовками из cfg.
+func New(accrualAddress string, cfg Config) (*HTTPClient, error) {
+	base, err := ParseAddress(accrualAddress)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(&cfg)
+	if err != nil {
+		return nil, err
 	}
+	return &HTTPClient{
+		Header: cfg.headers(),
+		tracer: otel.Tracer(tracerName),
+		base:   base,
+		now:    time.Now,
+		client: http.Client{Transport: transport},
+	}, nil
 }
 
 func (c *HTTPClient) GetOrderInfo(ctx context.Context, orderID string,
-) (dto.AccrualInfo, error) {
-	tCtx, cancel := context.WithTimeout(ctx, model.DefaultTimeout)
-	defer cancel()
+) (info dto.AccrualInfo, err error) {
+	ctx, span := c.tracer.Start(ctx, "GET /api/orders/{number}",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodGet,
+			attribute.String("order_no", orderID),
+		),
+	)
+	defer func() {
+		// 204 и 429 -- штатные ответы accrual, а не ошибки вызова
+		var tmrErr *serviceerrs.TooManyRequestsError
+		if err != nil && !errors.Is(err, serviceerrs.ErrNoContent) && !errors.As(err, &tmrErr) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	request, err := http.NewRequestWithContext(
-		tCtx, http.MethodGet, c.accrualAddress+"/api/orders/"+orderID, http.NoBody)
+		ctx, http.MethodGet, c.base.JoinPath("api", "orders", orderID).String(), http.NoBody)
 	if err != nil {
 		return dto.AccrualInfo{},
 			fmt.Errorf("failed to create the request: %w", err)
 	}
+	for key, values := range c.Header {
+		request.Header[key] = values
+	}
+	span.SetAttributes(semconv.URLFull(request.URL.String()))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
 	resp, err := c.client.Do(request)
 	if err != nil {
+		if ctx.Err() == nil {
+			c.observe(0)
+		}
 		return dto.AccrualInfo{},
 			fmt.Errorf("failed to send request to Accrual: %w", err)
 	}
+	c.observe(resp.StatusCode)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 	body, err := io.ReadAll(resp.Body)
 	defer func() {
-		if err = resp.Body.Close(); err != nil {
+		if closeErr := resp.Body.Close(); closeErr != nil {
 			log := logger.FromContext(ctx)
 			log.LogAttrs(
 				ctx,
 				slog.LevelError,
 				"failed to close the response body",
-				slog.Any(model.KeyLoggerError, err),
+				slog.Any(model.KeyLoggerError, closeErr),
 			)
 		}
 	}()
@@ -64,7 +124,7 @@ func (c *HTTPClient) GetOrderInfo(ctx context.Context, orderID string,
 
 	data, err := c.handleRequestData(resp, body)
 	if err == nil ||
-		errors.Is(err, &serviceerrs.TooManyRequestsError{}) ||
+		errors.As(err, new(*serviceerrs.TooManyRequestsError)) ||
 		errors.Is(err, serviceerrs.ErrNoContent) {
 		return data, err
 	}
@@ -72,11 +132,17 @@ func (c *HTTPClient) GetOrderInfo(ctx context.Context, orderID string,
 	return data, fmt.Errorf("request accrual failed: %w", err)
 }
 
+func (c *HTTPClient) observe(code int) {
+	if c.OnResponse != nil {
+		c.OnResponse(code)
+	}
+}
+
 func (c *HTTPClient) handleRequestData(resp *http.Response, body []byte,
 ) (dto.AccrualInfo, error) {
 	switch resp.StatusCode {
 	case http.StatusOK:
-		if ct := resp.Header.Get(model.HeaderContentType); ct != "application/json" {
+		if ct := resp.Header.Get(model.HeaderContentType); !isJSON(ct) {
 			return dto.AccrualInfo{},
 				fmt.Errorf("unexpected content type %s", ct)
 		}
@@ -89,27 +155,10 @@ func (c *HTTPClient) handleRequestData(resp *http.Response, body []byte,
 	case http.StatusNoContent:
 		return dto.AccrualInfo{}, serviceerrs.ErrNoContent
 	case http.StatusTooManyRequests:
-		retryAfter := resp.Header.Get("Retry-After")
-		if retryAfter == "" {
-			return dto.AccrualInfo{},
-				errors.New("empty retry-after value")
-		}
-		ra, err := strconv.Atoi(retryAfter)
-		if err != nil {
-			return dto.AccrualInfo{},
-				fmt.Errorf("retry after atoi failed: %w", err)
-		}
-
-		rpm, err := c.parseBody(body)
-		if err != nil {
-			return dto.AccrualInfo{},
-				fmt.Errorf("failed to parse the body: %w", err)
-		}
-
 		return dto.AccrualInfo{},
 			&serviceerrs.TooManyRequestsError{
-				RetryAfter: time.Duration(ra) * time.Second,
-				RPM:        rpm,
+				RetryAfter: c.retryAfter(resp.Header.Get("Retry-After")),
+				RPM:        parseRPM(body),
 			}
 	case http.StatusInternalServerError:
 		return dto.AccrualInfo{},
@@ -121,22 +170,39 @@ func (c *HTTPClient) handleRequestData(resp *http.Response, body []byte,
 			resp.StatusCode, string(body))
 }
 
-func (c *HTTPClient) parseBody(b []byte) (uint64, error) {
-	msg := string(b)
-	const prefix = "No more than "
-	const suffix = " requests per minute allowed"
-
-	if !strings.HasPrefix(msg, prefix) || !strings.HasSuffix(msg, suffix) {
-		return 0, fmt.Errorf("unexpected message format: %s", msg)
+// isJSON принимает application/json с параметрами, например charset, и типы вида application/*+json.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
 	}
+	return mediaType == "application/json" ||
+		strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json")
+}
 
-	numStr := strings.TrimSuffix(strings.TrimPrefix(msg, prefix), suffix)
+// retryAfter разбирает Retry-After в секундах или в виде HTTP-даты.
+// Без заголовка или с непонятным значением ждём defaultRetryAfter.
+func (c *HTTPClient) retryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(c.now()), 0)
+	}
+	return defaultRetryAfter
+}
 
-	var n uint64
-	_, err := fmt.Sscanf(numStr, "%d", &n)
+// parseRPM ищет лимит в теле 429, например "No more than 60 requests per minute allowed".
+// 0 -- лимит неизвестен.
+func parseRPM(body []byte) uint64 {
+	match := rpmPattern.FindSubmatch(body)
+	if match == nil {
+		return 0
+	}
+	rpm, err := strconv.ParseUint(string(match[1]), 10, 64)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse number: %w", err)
+		return 0
 	}
-
-	return n, nil
+	return rpm
 }
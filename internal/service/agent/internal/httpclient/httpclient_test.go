@@ -0,0 +1,111 @@
+package httpclient
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/talx-hub/gopher-bonus/internal/service/accrualsim"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func startSimulator(t *testing.T, cfg *accrualsim.Config) *HTTPClient {
+	t.Helper()
+	rules, err := accrualsim.LoadRules(strings.NewReader(
+		`{"match": "Bork", "reward": 4, "reward_type": "%"}`))
+	require.NoError(t, err)
+	orders, err := accrualsim.LoadOrders(strings.NewReader(
+		`{"order": "9278923470", "goods": [{"description": "Чайник Bork", "price": 7000}]}`))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(accrualsim.New(cfg, rules, orders, slog.Default()).Router())
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL, Config{})
+	require.NoError(t, err)
+	return c
+}
+
+func TestHTTPClient_GetOrderInfo_simulator(t *testing.T) {
+	c := startSimulator(t, &accrualsim.Config{})
+
+	info, err := c.GetOrderInfo(context.Background(), "9278923470")
+	require.NoError(t, err)
+	assert.Equal(t, dto.AccrualInfo{
+		Order:   "9278923470",
+		Status:  string(dto.StatusCalculatorProcessed),
+		Accrual: "280",
+	}, info)
+
+	_, err = c.GetOrderInfo(context.Background(), "79927398713")
+	require.ErrorIs(t, err, serviceerrs.ErrNoContent)
+}
+
+func TestHTTPClient_GetOrderInfo_simulatorRateLimit(t *testing.T) {
+	c := startSimulator(t, &accrualsim.Config{RPM: 1})
+
+	_, err := c.GetOrderInfo(context.Background(), "9278923470")
+	require.NoError(t, err)
+
+	_, err = c.GetOrderInfo(context.Background(), "9278923470")
+	var tooMany *serviceerrs.TooManyRequestsError
+	require.ErrorAs(t, err, &tooMany)
+	assert.Equal(t, uint64(1), tooMany.RPM)
+	assert.Positive(t, tooMany.RetryAfter)
+}
+
+func TestHTTPClient_GetOrderInfo_simulatorFaults(t *testing.T) {
+	c := startSimulator(t, &accrualsim.Config{
+		Script:    []accrualsim.Action{accrualsim.ActionFail, accrualsim.ActionSlow},
+		SlowDelay: 2 * time.Second,
+	})
+
+	_, err := c.GetOrderInfo(context.Background(), "9278923470")
+	require.ErrorContains(t, err, "accrual service error")
+
+	// медленный ответ не укладывается в model.DefaultTimeout
+	_, err = c.GetOrderInfo(context.Background(), "9278923470")
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.ErrorContains(t, err, "timeout awaiting response headers")
+}
+
+func TestHTTPClient_GetOrderInfo_traceparent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Config{})
+	require.NoError(t, err)
+	_, err = c.GetOrderInfo(context.Background(), "79927398713")
+	require.ErrorIs(t, err, serviceerrs.ErrNoContent)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	sc := spans[0].SpanContext()
+	assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", traceparent)
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
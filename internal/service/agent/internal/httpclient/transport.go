@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+const defaultAPIKeyHeader = "X-API-Key"
+
+// Config -- как клиент подключается к accrual. Нулевое значение -- обычный http.Client
+// с прокси из окружения и таймаутом ожидания ответа model.DefaultTimeout.
+type Config struct {
+	// Proxy -- URL прокси; пусто -- HTTP_PROXY/HTTPS_PROXY из окружения
+	Proxy string
+	// CAFile -- PEM с корневыми сертификатами accrual вместо системных
+	CAFile string
+	// CertFile и KeyFile -- клиентский сертификат для mTLS, задаются вместе
+	CertFile string
+	KeyFile  string
+	// Token отправляется в заголовке Authorization: Bearer
+	Token string
+	// APIKey отправляется в заголовке APIKeyHeader, по умолчанию X-API-Key
+	APIKey       string
+	APIKeyHeader string
+	// ConnectTimeout ограничивает установку TCP и TLS соединения, 0 -- без ограничения
+	ConnectTimeout time.Duration
+	// ResponseTimeout ограничивает ожидание заголовков ответа после отправки запроса,
+	// 0 -- model.DefaultTimeout
+	ResponseTimeout time.Duration
+	IdleConnTimeout time.Duration
+	// MaxIdleConns -- сколько соединений с accrual держать открытыми, 0 -- без ограничения
+	MaxIdleConns int
+	// MaxConnsPerHost -- сколько соединений с accrual открывать одновременно, 0 -- без ограничения
+	MaxConnsPerHost int
+}
+
+// ParseAddress проверяет адрес accrual: нужны схема http или https и хост.
+func ParseAddress(address string) (*url.URL, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accrual address %q: %w", address, err)
+	}
+	// "localhost:8081" разбирается как схема localhost, поэтому схему проверяем явно
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf(
+			"accrual address %q must start with http:// or https://, e.g. http://%s", address, address)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("accrual address %q has no host", address)
+	}
+	return u, nil
+}
+
+func newTransport(cfg *Config) (*http.Transport, error) {
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid accrual proxy %q: %w", cfg.Proxy, err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	responseTimeout := cfg.ResponseTimeout
+	if responseTimeout <= 0 {
+		responseTimeout = model.DefaultTimeout
+	}
+	dialer := &net.Dialer{
+		Timeout:   cfg.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:               proxy,
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: cfg.ConnectTimeout,
+		// время до заголовков ответа, чтение тела ограничивает ctx запроса
+		ResponseHeaderTimeout: responseTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		// клиент ходит в один хост, иначе простаивающих соединений было бы всего 2
+		MaxIdleConnsPerHost: cfg.MaxIdleConns,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		IdleConnTimeout:     cfg.IdleConnTimeout,
+	}, nil
+}
+
+func newTLSConfig(cfg *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read accrual CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in accrual CA bundle %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("accrual client certificate and key must be set together")
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load accrual client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func (cfg *Config) headers() http.Header {
+	header := http.Header{}
+	if cfg.Token != "" {
+		header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+	if cfg.APIKey != "" {
+		name := cfg.APIKeyHeader
+		if name == "" {
+			name = defaultAPIKeyHeader
+		}
+		header.Set(name, cfg.APIKey)
+	}
+	return header
+}
@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr string
+	}{
+		{"http", "http://localhost:8081", ""},
+		{"https with path", "https://accrual.example.com/v1", ""},
+		{"no scheme", "localhost:8081", "must start with http:// or https://"},
+		{"other scheme", "ftp://localhost:8081", "must start with http:// or https://"},
+		{"no host", "http://", "has no host"},
+		{"empty", "", "must start with http:// or https://"},
+		{"malformed", "http://[::1", "missing ']' in host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAddress(tt.address)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"missing CA", Config{CAFile: missing}, "failed to read accrual CA bundle"},
+		{"cert without key", Config{CertFile: missing}, "must be set together"},
+		{"missing cert", Config{CertFile: missing, KeyFile: missing}, "failed to load accrual client certificate"},
+		{"bad proxy", Config{Proxy: "http://[::1"}, "invalid accrual proxy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New("http://localhost:8081", tt.cfg)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestHTTPClient_GetOrderInfo_auth(t *testing.T) {
+	var header http.Header
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		path = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL+"/accrual/", Config{
+		Token:        "secret",
+		APIKey:       "key",
+		APIKeyHeader: "X-Partner-Key",
+	})
+	require.NoError(t, err)
+	_, err = c.GetOrderInfo(context.Background(), "79927398713")
+	require.ErrorIs(t, err, serviceerrs.ErrNoContent)
+
+	assert.Equal(t, "/accrual/api/orders/79927398713", path)
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+	assert.Equal(t, "key", header.Get("X-Partner-Key"))
+}
+
+func TestHTTPClient_GetOrderInfo_customCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// без CA сертификат тестового сервера не проходит проверку
+	c, err := New(srv.URL, Config{})
+	require.NoError(t, err)
+	_, err = c.GetOrderInfo(context.Background(), "79927398713")
+	require.ErrorContains(t, err, "certificate")
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, ca, 0o600))
+
+	c, err = New(srv.URL, Config{CAFile: caFile})
+	require.NoError(t, err)
+	_, err = c.GetOrderInfo(context.Background(), "79927398713")
+	require.ErrorIs(t, err, serviceerrs.ErrNoContent)
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// bucket -- token bucket с темпом rate запросов в минуту и запасом в секунду этого темпа.
+// Токены можно брать в долг: каждый следующий запрос просто ждёт дольше,
+// так что ожидающие проходят по очереди и темп не превышается.
+type bucket struct {
+	now  func() time.Time
+	last time.Time
+	// tokens < 0 -- долг перед уже выданными разрешениями
+	tokens float64
+	// rate == 0 -- без ограничения
+	rate uint64
+	mu   sync.Mutex
+}
+
+func newBucket(rate uint64, now func() time.Time) *bucket {
+	b := &bucket{now: now, last: now(), rate: rate}
+	b.tokens = b.burst()
+	return b
+}
+
+func (b *bucket) burst() float64 {
+	return max(1, float64(b.rate)/perMinute)
+}
+
+// advance начисляет токены за время с прошлого обращения. Вызывается под b.mu.
+func (b *bucket) advance(now time.Time) {
+	if b.rate != 0 && now.After(b.last) {
+		b.tokens = min(b.burst(), b.tokens+now.Sub(b.last).Minutes()*float64(b.rate))
+	}
+	b.last = now
+}
+
+// reserve забирает токен и возвращает, сколько ждать, пока он станет действительным.
+func (b *bucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate == 0 {
+		return 0
+	}
+	b.advance(b.now())
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / float64(b.rate) * float64(time.Minute))
+}
+
+// cancel возвращает токен, который так и не понадобился.
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate == 0 {
+		return
+	}
+	b.advance(b.now())
+	b.tokens = min(b.burst(), b.tokens+1)
+}
+
+func (b *bucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err() //nolint: wrapcheck // caller's ctx error
+	}
+}
+
+func (b *bucket) setRate(rate uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.now())
+	unlimited := b.rate == 0
+	b.rate = rate
+	if unlimited {
+		b.tokens = b.burst()
+		return
+	}
+	b.tokens = min(b.tokens, b.burst())
+}
+
+func (b *bucket) getRate() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	t  time.Time
+	mu sync.Mutex
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func TestBucket_reserve(t *testing.T) {
+	clock := newFakeClock()
+	b := newBucket(60, clock.now)
+
+	// запас -- секунда темпа, дальше по токену в секунду
+	assert.Zero(t, b.reserve())
+	assert.Equal(t, time.Second, b.reserve())
+	assert.Equal(t, 2*time.Second, b.reserve())
+
+	clock.advance(3 * time.Second)
+	assert.Zero(t, b.reserve())
+
+	// простой не копит токенов больше запаса
+	clock.advance(time.Hour)
+	assert.Zero(t, b.reserve())
+	assert.Equal(t, time.Second, b.reserve())
+
+	b.cancel()
+	assert.Equal(t, time.Second, b.reserve())
+}
+
+func TestBucket_unlimited(t *testing.T) {
+	b := newBucket(0, newFakeClock().now)
+	for range 1000 {
+		require.Zero(t, b.reserve())
+	}
+
+	b.setRate(120)
+	assert.Zero(t, b.reserve())
+	assert.Zero(t, b.reserve())
+	assert.Equal(t, 500*time.Millisecond, b.reserve())
+}
+
+func TestBucket_setRate(t *testing.T) {
+	clock := newFakeClock()
+	b := newBucket(600, clock.now)
+	for range 10 {
+		require.Zero(t, b.reserve())
+	}
+
+	// долг остаётся, но отдаётся уже в новом темпе
+	b.setRate(60)
+	assert.Equal(t, time.Second, b.reserve())
+	assert.Equal(t, uint64(60), b.getRate())
+}
+
+func TestBucket_wait(t *testing.T) {
+	b := newBucket(60, time.Now)
+	require.NoError(t, b.wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, b.wait(ctx), context.DeadlineExceeded)
+	// отменённое ожидание вернуло токен: следующему ждать не дольше секунды
+	assert.LessOrEqual(t, b.reserve(), time.Second)
+}
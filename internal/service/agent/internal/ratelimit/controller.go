@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultHeadroom -- доля объявленного accrual лимита, до которой поднимается темп по умолчанию.
+const DefaultHeadroom = 0.9
+
+const (
+	defaultBackoff     = 0.5
+	defaultQuietPeriod = time.Minute
+	// defaultStepShare -- доля текущего темпа, на которую он растёт за Probe, если Step не задан
+	defaultStepShare = 20
+	// темп поднимается, только если его расходуют хотя бы на demandNum/demandDen
+	demandNum, demandDen = 3, 4
+)
+
+type Config struct {
+	// Initial -- темп до первого 429, запросов в минуту; 0 -- без ограничения
+	Initial uint64
+	// Step -- прибавка темпа за Probe, запросов в минуту; 0 -- 5% текущего темпа
+	Step uint64
+	// Headroom -- доля объявленного accrual лимита, выше которой темп не поднимается
+	Headroom float64
+	// Backoff -- во сколько раз снижается темп после 429
+	Backoff float64
+	// QuietPeriod -- сколько темп держится после изменения, прежде чем его снова поднимать
+	QuietPeriod time.Duration
+}
+
+// Controller ограничивает темп запросов к accrual token bucket'ом и подстраивает его по AIMD:
+// после 429 темп сразу падает до доли объявленного лимита или вдвое,
+// а после спокойного периода понемногу растёт, замедляясь у лимита.
+type Controller struct {
+	now    func() time.Time
+	bucket *bucket
+	meter  *meter
+	// changed -- когда темп менялся в последний раз
+	changed time.Time
+	cfg     Config
+	// ceiling -- лимит из последнего 429, 0 -- неизвестен
+	ceiling uint64
+	mu      sync.Mutex
+}
+
+func New(cfg Config) *Controller {
+	return newController(cfg, time.Now)
+}
+
+func newController(cfg Config, now func() time.Time) *Controller {
+	if cfg.Headroom <= 0 || cfg.Headroom > 1 {
+		cfg.Headroom = DefaultHeadroom
+	}
+	if cfg.Backoff <= 0 || cfg.Backoff >= 1 {
+		cfg.Backoff = defaultBackoff
+	}
+	if cfg.QuietPeriod <= 0 {
+		cfg.QuietPeriod = defaultQuietPeriod
+	}
+	return &Controller{
+		now:     now,
+		bucket:  newBucket(cfg.Initial, now),
+		meter:   newMeter(now),
+		changed: now(),
+		cfg:     cfg,
+	}
+}
+
+// Wait ждёт разрешения на запрос; ошибка -- только ctx.Err().
+func (c *Controller) Wait(ctx context.Context) error {
+	return c.bucket.wait(ctx)
+}
+
+// Observe учитывает отправленный запрос.
+func (c *Controller) Observe() {
+	c.meter.add()
+}
+
+// Rate -- текущий темп, запросов в минуту; 0 -- без ограничения.
+func (c *Controller) Rate() uint64 {
+	return c.bucket.getRate()
+}
+
+// RPM -- сколько запросов отправлено за последнюю минуту.
+func (c *Controller) RPM() uint64 {
+	return c.meter.rpm()
+}
+
+// Throttle снижает темп после 429. advertised -- лимит из ответа accrual, 0 -- неизвестен:
+// тогда за основу берётся измеренный темп, при котором accrual начал отказывать.
+// Возвращает новый темп.
+func (c *Controller) Throttle(advertised uint64) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := c.bucket.getRate()
+	next := current
+	switch measured := c.meter.rpm(); {
+	case advertised != 0:
+		c.ceiling = advertised
+		next = c.capacity()
+		// уже шли ниже лимита, а 429 всё равно пришёл: лимит делят с кем-то ещё
+		if current != 0 && next >= current {
+			next = c.backoff(current)
+		}
+	case measured != 0:
+		if current != 0 {
+			measured = min(measured, current)
+		}
+		next = c.backoff(measured)
+	case current != 0:
+		next = c.backoff(current)
+	}
+	c.set(next)
+	return next
+}
+
+// Probe поднимает темп, если accrual давно не отказывал и темпа не хватает;
+// если лимит accrual известен, шаг уменьшается по мере приближения к нему.
+// Если отправлено больше, чем позволяет лимит, темп снижается заранее, не дожидаясь 429.
+// Возвращает новый темп и изменился ли он.
+func (c *Controller) Probe() (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := c.bucket.getRate()
+	if current == 0 || c.now().Sub(c.changed) < c.cfg.QuietPeriod {
+		return current, false
+	}
+	measured := c.meter.rpm()
+	limit := c.capacity()
+	if c.ceiling != 0 && measured > limit {
+		next := min(current, limit)
+		next -= min(c.step(next), next-1)
+		if next == current {
+			return current, false
+		}
+		c.set(next)
+		return next, true
+	}
+	if measured*demandDen < current*demandNum {
+		return current, false
+	}
+	step := c.step(current)
+	if c.ceiling != 0 {
+		if current >= limit {
+			return current, false
+		}
+		step = min(step, (limit-current+1)/2)
+	}
+	next := current + step
+	c.set(next)
+	return next, true
+}
+
+// capacity -- до какого темпа можно подниматься при известном лимите. Вызывается под c.mu.
+func (c *Controller) capacity() uint64 {
+	return max(1, uint64(float64(c.ceiling)*c.cfg.Headroom))
+}
+
+func (c *Controller) backoff(rate uint64) uint64 {
+	return max(1, uint64(float64(rate)*c.cfg.Backoff))
+}
+
+func (c *Controller) step(rate uint64) uint64 {
+	if c.cfg.Step != 0 {
+		return c.cfg.Step
+	}
+	return max(1, rate/defaultStepShare)
+}
+
+// set вызывается под c.mu.
+func (c *Controller) set(rate uint64) {
+	c.bucket.setRate(rate)
+	c.changed = c.now()
+}
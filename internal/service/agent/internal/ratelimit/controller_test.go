@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func observe(c *Controller, n int) {
+	for range n {
+		c.Observe()
+	}
+}
+
+func TestController_Throttle(t *testing.T) {
+	t.Run("advertised limit", func(t *testing.T) {
+		c := newController(Config{Initial: 600}, newFakeClock().now)
+		assert.Equal(t, uint64(90), c.Throttle(100))
+		// 429 пришёл, хотя шли ниже лимита: дальше снижаем вдвое
+		assert.Equal(t, uint64(45), c.Throttle(100))
+		assert.Equal(t, uint64(45), c.Rate())
+	})
+
+	t.Run("measured rate", func(t *testing.T) {
+		clock := newFakeClock()
+		c := newController(Config{}, clock.now)
+		assert.Zero(t, c.Rate())
+		observe(c, 120)
+		clock.advance(30 * time.Second)
+		assert.Equal(t, uint64(120), c.RPM())
+
+		assert.Equal(t, uint64(60), c.Throttle(0))
+		assert.Equal(t, uint64(30), c.Throttle(0))
+	})
+
+	t.Run("nothing to measure", func(t *testing.T) {
+		c := newController(Config{}, newFakeClock().now)
+		assert.Zero(t, c.Throttle(0))
+		c = newController(Config{Initial: 10}, newFakeClock().now)
+		assert.Equal(t, uint64(5), c.Throttle(0))
+		assert.Equal(t, uint64(2), c.Throttle(0))
+		assert.Equal(t, uint64(1), c.Throttle(0))
+		assert.Equal(t, uint64(1), c.Throttle(0))
+	})
+}
+
+func TestController_Probe(t *testing.T) {
+	clock := newFakeClock()
+	c := newController(Config{Initial: 600, Step: 20}, clock.now)
+	c.Throttle(100)
+	c.Throttle(100)
+
+	// сразу после 429 темп не растёт
+	observe(c, 45)
+	rate, changed := c.Probe()
+	assert.False(t, changed)
+	assert.Equal(t, uint64(45), rate)
+
+	// без спроса тоже не растёт
+	clock.advance(time.Minute)
+	_, changed = c.Probe()
+	assert.False(t, changed)
+
+	// к лимиту 90 (доля от 100) темп подходит всё меньшими шагами
+	var rates []uint64
+	for range 8 {
+		observe(c, int(c.Rate()))
+		if rate, changed = c.Probe(); changed {
+			rates = append(rates, rate)
+		}
+		clock.advance(time.Minute)
+	}
+	assert.Equal(t, []uint64{65, 78, 84, 87, 89, 90}, rates)
+}
+
+func TestController_Probe_slowsDownBeforeLimit(t *testing.T) {
+	clock := newFakeClock()
+	c := newController(Config{Initial: 600, Step: 20}, clock.now)
+	c.Throttle(100)
+
+	// отправлено больше доли лимита: снижаем, не дожидаясь 429
+	clock.advance(time.Minute)
+	observe(c, 95)
+	rate, changed := c.Probe()
+	assert.True(t, changed)
+	assert.Equal(t, uint64(70), rate)
+}
+
+func TestController_Probe_unknownLimit(t *testing.T) {
+	clock := newFakeClock()
+	c := newController(Config{}, clock.now)
+	_, changed := c.Probe()
+	assert.False(t, changed)
+
+	c = newController(Config{Initial: 100}, clock.now)
+	clock.advance(time.Minute)
+	observe(c, 80)
+	rate, changed := c.Probe()
+	assert.True(t, changed)
+	assert.Equal(t, uint64(105), rate)
+}
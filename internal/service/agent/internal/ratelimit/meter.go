@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	perMinute = 60
+	// meterSlots -- окно meter в секундах
+	meterSlots = perMinute
+)
+
+// meter считает запросы за последнюю минуту по ячейкам в одну секунду.
+// Первую минуту после старта окно неполное, и RPM занижен.
+type meter struct {
+	now    func() time.Time
+	counts [meterSlots]uint64
+	// seconds -- к какой секунде Unix относится ячейка
+	seconds [meterSlots]int64
+	mu      sync.Mutex
+}
+
+func newMeter(now func() time.Time) *meter {
+	return &meter{now: now}
+}
+
+func (m *meter) add() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sec := m.now().Unix()
+	i := slot(sec)
+	if m.seconds[i] != sec {
+		m.seconds[i] = sec
+		m.counts[i] = 0
+	}
+	m.counts[i]++
+}
+
+// rpm -- запросов за последние 60 секунд, включая текущую.
+func (m *meter) rpm() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sec := m.now().Unix()
+	var total uint64
+	for i := range m.counts {
+		if age := sec - m.seconds[i]; age >= 0 && age < meterSlots {
+			total += m.counts[i]
+		}
+	}
+	return total
+}
+
+func slot(sec int64) int {
+	i := sec % meterSlots
+	if i < 0 {
+		i += meterSlots
+	}
+	return int(i)
+}
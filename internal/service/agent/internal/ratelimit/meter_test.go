@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeter_rpm(t *testing.T) {
+	clock := newFakeClock()
+	m := newMeter(clock.now)
+	assert.Zero(t, m.rpm())
+
+	for range 30 {
+		m.add()
+		m.add()
+		clock.advance(time.Second)
+	}
+	assert.Equal(t, uint64(60), m.rpm())
+
+	// окно скользит: через минуту после первых запросов они из него выпадают
+	clock.advance(30 * time.Second)
+	assert.Equal(t, uint64(58), m.rpm())
+
+	clock.advance(28 * time.Second)
+	assert.Equal(t, uint64(2), m.rpm())
+
+	clock.advance(time.Second)
+	assert.Zero(t, m.rpm())
+
+	// ячейка, оставшаяся с прошлого круга, не суммируется с новой
+	clock.advance(time.Hour)
+	m.add()
+	assert.Equal(t, uint64(1), m.rpm())
+}
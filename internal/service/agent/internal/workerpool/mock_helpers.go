@@ -68,8 +68,8 @@ func ConfigureMockAlwaysTimeoutExceedSemaphore(t *testing.T) AccrualSemaphore {
 	mockSema := mocks.NewMockAccrualSemaphore(t)
 	mockSema.
 		EXPECT().
-		AcquireWithTimeout(mock.Anything).
-		RunAndReturn(func(_ time.Duration) error {
+		Acquire(mock.Anything).
+		RunAndReturn(func(_ context.Context) error {
 			return serviceerrs.ErrSemaphoreTimeoutExceeded
 		})
 
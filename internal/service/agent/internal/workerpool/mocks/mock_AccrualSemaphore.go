@@ -5,7 +5,7 @@
 package mocks
 
 import (
-	"time"
+	"context"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -37,39 +37,39 @@ func (_m *MockAccrualSemaphore) EXPECT() *MockAccrualSemaphore_Expecter {
 	return &MockAccrualSemaphore_Expecter{mock: &_m.Mock}
 }
 
-// AcquireWithTimeout provides a mock function for the type MockAccrualSemaphore
-func (_mock *MockAccrualSemaphore) AcquireWithTimeout(timeout time.Duration) error {
-	ret := _mock.Called(timeout)
+// Acquire provides a mock function for the type MockAccrualSemaphore
+func (_mock *MockAccrualSemaphore) Acquire(ctx context.Context) error {
+	ret := _mock.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for AcquireWithTimeout")
+		panic("no return value specified for Acquire")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(time.Duration) error); ok {
-		r0 = returnFunc(timeout)
+	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = returnFunc(ctx)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockAccrualSemaphore_AcquireWithTimeout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcquireWithTimeout'
-type MockAccrualSemaphore_AcquireWithTimeout_Call struct {
+// MockAccrualSemaphore_Acquire_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Acquire'
+type MockAccrualSemaphore_Acquire_Call struct {
 	*mock.Call
 }
 
-// AcquireWithTimeout is a helper method to define mock.On call
-//   - timeout time.Duration
-func (_e *MockAccrualSemaphore_Expecter) AcquireWithTimeout(timeout interface{}) *MockAccrualSemaphore_AcquireWithTimeout_Call {
-	return &MockAccrualSemaphore_AcquireWithTimeout_Call{Call: _e.mock.On("AcquireWithTimeout", timeout)}
+// Acquire is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAccrualSemaphore_Expecter) Acquire(ctx interface{}) *MockAccrualSemaphore_Acquire_Call {
+	return &MockAccrualSemaphore_Acquire_Call{Call: _e.mock.On("Acquire", ctx)}
 }
 
-func (_c *MockAccrualSemaphore_AcquireWithTimeout_Call) Run(run func(timeout time.Duration)) *MockAccrualSemaphore_AcquireWithTimeout_Call {
+func (_c *MockAccrualSemaphore_Acquire_Call) Run(run func(ctx context.Context)) *MockAccrualSemaphore_Acquire_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 time.Duration
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(time.Duration)
+			arg0 = args[0].(context.Context)
 		}
 		run(
 			arg0,
@@ -78,12 +78,12 @@ func (_c *MockAccrualSemaphore_AcquireWithTimeout_Call) Run(run func(timeout tim
 	return _c
 }
 
-func (_c *MockAccrualSemaphore_AcquireWithTimeout_Call) Return(err error) *MockAccrualSemaphore_AcquireWithTimeout_Call {
+func (_c *MockAccrualSemaphore_Acquire_Call) Return(err error) *MockAccrualSemaphore_Acquire_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockAccrualSemaphore_AcquireWithTimeout_Call) RunAndReturn(run func(timeout time.Duration) error) *MockAccrualSemaphore_AcquireWithTimeout_Call {
+func (_c *MockAccrualSemaphore_Acquire_Call) RunAndReturn(run func(ctx context.Context) error) *MockAccrualSemaphore_Acquire_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,64 @@
+package workerpool
+
+import "time"
+
+type ScaleConfig struct {
+	// Interval -- как часто пересчитывается размер пула; за это время пул должен разобрать очередь
+	Interval time.Duration
+	// MaxLatency -- если accrual отвечает медленнее, пул не растёт: лишние запросы его только добьют
+	MaxLatency time.Duration
+	Min        int
+	Max        int
+	// DownAfter -- сколько пересчётов подряд нужно хотеть меньше воркеров, чтобы пул уменьшился
+	DownAfter int
+}
+
+// Scaler выбирает размер пула по закону Литтла: чтобы за Interval разобрать depth заказов
+// при задержке latency, нужно depth*latency/Interval воркеров.
+// Растёт пул сразу, а уменьшается только после DownAfter пересчётов подряд и не ниже чем вдвое,
+// и только если нужно меньше 3/4 текущих воркеров -- иначе размер дёргался бы на каждом тике.
+type Scaler struct {
+	cfg  ScaleConfig
+	idle int
+}
+
+func NewScaler(cfg ScaleConfig) *Scaler {
+	cfg.Min = max(cfg.Min, 1)
+	cfg.Max = max(cfg.Max, cfg.Min)
+	cfg.DownAfter = max(cfg.DownAfter, 1)
+	return &Scaler{cfg: cfg}
+}
+
+func (s *Scaler) Config() ScaleConfig {
+	return s.cfg
+}
+
+// Next возвращает новый размер пула из current воркеров.
+// latency == 0 -- задержка ещё не измерена, тогда размер меняется только до границ Min/Max.
+func (s *Scaler) Next(current, depth int, latency time.Duration) int {
+	want := current
+	if latency > 0 && s.cfg.Interval > 0 {
+		perWorker := float64(s.cfg.Interval) / float64(latency)
+		want = int(float64(depth)/perWorker + 0.999)
+	}
+	if s.cfg.MaxLatency > 0 && latency > s.cfg.MaxLatency {
+		want = min(want, current)
+	}
+	want = min(max(want, s.cfg.Min), s.cfg.Max)
+
+	switch {
+	case current < s.cfg.Min || current > s.cfg.Max || want > current:
+		s.idle = 0
+		return want
+	case want*4 < current*3:
+		s.idle++
+		if s.idle < s.cfg.DownAfter {
+			return current
+		}
+		s.idle = 0
+		return max(want, current/2, s.cfg.Min)
+	default:
+		s.idle = 0
+		return current
+	}
+}
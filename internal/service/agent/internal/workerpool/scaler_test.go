@@ -0,0 +1,63 @@
+package workerpool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaler_Next(t *testing.T) {
+	cfg := ScaleConfig{
+		Interval:   time.Second,
+		MaxLatency: 500 * time.Millisecond,
+		Min:        2,
+		Max:        16,
+		DownAfter:  3,
+	}
+
+	tests := []struct {
+		name    string
+		current int
+		depth   int
+		latency time.Duration
+		want    int
+	}{
+		{"no latency yet", 4, 100, 0, 4},
+		{"below min", 1, 0, 0, 2},
+		{"above max", 20, 1000, 100 * time.Millisecond, 16},
+		{"grows to drain backlog", 2, 80, 100 * time.Millisecond, 8},
+		{"grows up to max", 4, 1000, 100 * time.Millisecond, 16},
+		{"accrual too slow to grow", 4, 1000, time.Second, 4},
+		{"within hysteresis band", 8, 70, 100 * time.Millisecond, 8},
+		{"single low round keeps size", 8, 10, 100 * time.Millisecond, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScaler(cfg)
+			assert.Equal(t, tt.want, s.Next(tt.current, tt.depth, tt.latency))
+		})
+	}
+}
+
+func TestScaler_Next_scaleDown(t *testing.T) {
+	s := NewScaler(ScaleConfig{Interval: time.Second, Min: 2, Max: 16, DownAfter: 3})
+	latency := 100 * time.Millisecond
+
+	assert.Equal(t, 16, s.Next(16, 0, latency))
+	assert.Equal(t, 16, s.Next(16, 0, latency))
+	// не больше чем вдвое за раз
+	assert.Equal(t, 8, s.Next(16, 0, latency))
+
+	// всплеск очереди сбрасывает счётчик
+	assert.Equal(t, 8, s.Next(8, 0, latency))
+	assert.Equal(t, 8, s.Next(8, 80, latency))
+	assert.Equal(t, 8, s.Next(8, 0, latency))
+	assert.Equal(t, 8, s.Next(8, 0, latency))
+	assert.Equal(t, 4, s.Next(8, 0, latency))
+
+	assert.Equal(t, 4, s.Next(4, 0, latency))
+	assert.Equal(t, 4, s.Next(4, 0, latency))
+	assert.Equal(t, 2, s.Next(4, 0, latency))
+	assert.Equal(t, 2, s.Next(2, 0, latency))
+}
@@ -99,7 +99,7 @@ func TestWorker(t *testing.T,
 
 	pool.WaitGroup.Add(1)
 	go func() {
-		pool.worker(ctx, cancel)
+		pool.worker(ctx, cancel, nil)
 	}()
 	pool.WaitGroup.Wait()
 	close(rateDataCh)
@@ -10,6 +10,7 @@ import (
 	"github.com/talx-hub/gopher-bonus/internal/model"
 	"github.com/talx-hub/gopher-bonus/internal/service/dto"
 	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+	"github.com/talx-hub/gopher-bonus/internal/utils/breaker"
 	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
 )
 
@@ -18,11 +19,23 @@ type AccrualClient interface {
 }
 
 type AccrualSemaphore interface {
-	AcquireWithTimeout(timeout time.Duration) error
+	Acquire(ctx context.Context) error
 	ChangeMaxRequests(newMaxRequests uint64)
 	Release()
 }
 
+// AccrualLimiter задаёт темп запросов к accrual; Wait возвращает только ошибки ctx.
+type AccrualLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// AccrualBreaker размыкает цепь, когда accrual массово отвечает ошибками.
+type AccrualBreaker interface {
+	Acquire(ctx context.Context) (breaker.Permit, error)
+	Done(p breaker.Permit, failed bool)
+	Cancel(p breaker.Permit)
+}
+
 type WorkerPool struct {
 	Client         AccrualClient
 	Sema           AccrualSemaphore
@@ -32,6 +45,18 @@ type WorkerPool struct {
 	RequestCounter chan<- struct{}
 	Results        chan<- dto.AccrualInfo
 	OnWorkerStart  func()
+	// если задан, воркер не берёт заказы, пока цепь разомкнута
+	Breaker AccrualBreaker
+	// если задан, воркер ждёт его разрешения перед каждым запросом
+	Limiter AccrualLimiter
+
+	// ctx и cancel последнего Start: с ними Resize запускает новых воркеров
+	ctx    context.Context //nolint: containedctx // воркеры Resize должны отменяться вместе с воркерами Start
+	cancel context.CancelFunc
+	// quits -- по каналу на каждого живого воркера, закрытый канал останавливает воркера
+	quits   []chan struct{}
+	latency time.Duration
+	mu      sync.Mutex
 }
 
 func New(
@@ -56,10 +81,11 @@ func New(
 
 func (pool *WorkerPool) Start(ctx context.Context, workerCount int) context.CancelFunc {
 	workerCtx, workerCancel := context.WithCancel(ctx)
-	for range workerCount {
-		pool.WaitGroup.Add(1)
-		go pool.worker(workerCtx, workerCancel)
-	}
+	pool.mu.Lock()
+	pool.ctx, pool.cancel = workerCtx, workerCancel
+	pool.quits = nil
+	pool.spawn(workerCount)
+	pool.mu.Unlock()
 	log := logger.FromContext(workerCtx).With("module", "worker_pool")
 	log.LogAttrs(ctx, slog.LevelInfo,
 		"all workers started", slog.Int("count", workerCount))
@@ -67,11 +93,68 @@ func (pool *WorkerPool) Start(ctx context.Context, workerCount int) context.Canc
 	return workerCancel
 }
 
+// Resize меняет число воркеров, запущенных последним Start.
+// Лишние воркеры доделывают текущий заказ и выходят, не забирая новых, так что заказы не теряются.
+// Если пул остановлен, ничего не делает. Возвращает прежний размер.
+func (pool *WorkerPool) Resize(workerCount int) int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	old := len(pool.quits)
+	if pool.ctx == nil || pool.ctx.Err() != nil {
+		return old
+	}
+	switch {
+	case workerCount > old:
+		pool.spawn(workerCount - old)
+	case workerCount < old:
+		for _, quit := range pool.quits[workerCount:] {
+			close(quit)
+		}
+		pool.quits = pool.quits[:workerCount]
+	}
+	return old
+}
+
+// Size возвращает число воркеров, запущенных последним Start с учётом Resize.
+func (pool *WorkerPool) Size() int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return len(pool.quits)
+}
+
+// Latency возвращает сглаженную задержку ответов accrual, 0 -- ответов ещё не было.
+func (pool *WorkerPool) Latency() time.Duration {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.latency
+}
+
+func (pool *WorkerPool) observeLatency(d time.Duration) {
+	const weight = 5 // новое значение даёт 1/5 среднего
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if pool.latency == 0 {
+		pool.latency = d
+		return
+	}
+	pool.latency += (d - pool.latency) / weight
+}
+
+// spawn вызывается под pool.mu.
+func (pool *WorkerPool) spawn(workerCount int) {
+	for range workerCount {
+		quit := make(chan struct{})
+		pool.quits = append(pool.quits, quit)
+		pool.WaitGroup.Add(1)
+		go pool.worker(pool.ctx, pool.cancel, quit)
+	}
+}
+
 func (pool *WorkerPool) ChangeMaxRequests(newMaxRequests uint64) {
 	pool.Sema.ChangeMaxRequests(newMaxRequests)
 }
 
-func (pool *WorkerPool) worker(ctx context.Context, cancelAll context.CancelFunc) {
+func (pool *WorkerPool) worker(ctx context.Context, cancelAll context.CancelFunc, quit <-chan struct{}) {
 	if pool.OnWorkerStart != nil {
 		pool.OnWorkerStart()
 	}
@@ -81,44 +164,76 @@ func (pool *WorkerPool) worker(ctx context.Context, cancelAll context.CancelFunc
 	defer log.LogAttrs(ctx, slog.LevelInfo, "worker stopped")
 
 	for {
+		// остановленный Resize'ом воркер не должен брать новый заказ, даже если тот уже ждёт
+		select {
+		case <-quit:
+			return
+		default:
+		}
+		permit, err := pool.acquireBreaker(ctx, quit)
+		if err != nil {
+			return
+		}
 		select {
 		case <-ctx.Done():
+			pool.cancelBreaker(permit)
+			return
+		case <-quit:
+			pool.cancelBreaker(permit)
 			return
 		case orderID, ok := <-pool.Jobs:
 			if !ok {
+				pool.cancelBreaker(permit)
 				return
 			}
 
-			if err := pool.Sema.AcquireWithTimeout(model.DefaultTimeout); err != nil {
+			if err := pool.waitLimiter(ctx, quit); err != nil {
+				pool.cancelBreaker(permit)
+				pool.Results <- pool.dummy(orderID, dto.StatusAgentFailed, err)
+				return
+			}
+			if err := pool.acquireSema(ctx); err != nil {
+				pool.cancelBreaker(permit)
 				log.With("unit", "semaphore").LogAttrs(
 					ctx,
 					slog.LevelWarn,
 					err.Error(),
 				)
-				pool.Results <- pool.dummy(orderID, dto.StatusAgentFailed)
+				pool.Results <- pool.dummy(orderID, dto.StatusAgentFailed, err)
+				if ctx.Err() != nil {
+					return
+				}
 				continue
 			}
 			log.With("unit", "semaphore").
 				LogAttrs(ctx, slog.LevelDebug, "acquire")
 			pool.RequestCounter <- struct{}{}
 
+			started := time.Now()
 			data, err := pool.Client.GetOrderInfo(
 				ctx,
 				orderID)
+			if ctx.Err() == nil {
+				pool.observeLatency(time.Since(started))
+			}
 			pool.Sema.Release()
 			log.With("unit", "semaphore").
 				LogAttrs(ctx, slog.LevelDebug, "release")
+			pool.doneBreaker(ctx, permit, err)
 
 			if err != nil {
 				if errors.Is(err, serviceerrs.ErrNoContent) {
-					pool.Results <- pool.dummy(orderID, dto.StatusCalculatorNoContent)
+					pool.Results <- pool.dummy(orderID, dto.StatusCalculatorNoContent, nil)
 					continue
 				}
-
-				pool.Results <- pool.dummy(orderID, dto.StatusCalculatorFailed)
+				// запрос прерван остановкой воркеров, в том числе после 429 другого воркера:
+				// это не отказ accrual
 				if ctx.Err() != nil {
+					pool.Results <- pool.dummy(orderID, dto.StatusAgentFailed, serviceerrs.ErrAgentStopped)
 					return
 				}
+
+				pool.Results <- pool.dummy(orderID, dto.StatusCalculatorFailed, err)
 				log.LogAttrs(ctx, slog.LevelError,
 					"failed to get order info", slog.Any(model.KeyLoggerError, err))
 				var tmrErr *serviceerrs.TooManyRequestsError
@@ -134,9 +249,89 @@ func (pool *WorkerPool) worker(ctx context.Context, cancelAll context.CancelFunc
 	}
 }
 
-func (pool *WorkerPool) dummy(orderID string, status dto.AccrualStatus) dto.AccrualInfo {
+// acquireBreaker ждёт замкнутой цепи, пока воркер не остановлен через ctx или quit.
+func (pool *WorkerPool) acquireBreaker(ctx context.Context, quit <-chan struct{}) (breaker.Permit, error) {
+	if pool.Breaker == nil {
+		return breaker.Permit{}, nil
+	}
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-waitCtx.Done():
+		}
+	}()
+	return pool.Breaker.Acquire(waitCtx) //nolint: wrapcheck // only ctx errors
+}
+
+// waitLimiter ждёт разрешения Limiter. Остановленный воркер перестаёт ждать сразу,
+// а заказ возвращается с serviceerrs.ErrAgentStopped: при низком темпе ожидание может длиться минуты.
+// Так же возвращается заказ воркера, отменённого через ctx, например после 429 другого воркера.
+func (pool *WorkerPool) waitLimiter(ctx context.Context, quit <-chan struct{}) error {
+	if pool.Limiter == nil {
+		return nil
+	}
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-waitCtx.Done():
+		}
+	}()
+	if err := pool.Limiter.Wait(waitCtx); err != nil {
+		return serviceerrs.ErrAgentStopped
+	}
+	return nil
+}
+
+// acquireSema ждёт разрешения на запрос не дольше model.DefaultTimeout.
+// Если воркер отменён через ctx, заказ возвращается с serviceerrs.ErrAgentStopped.
+func (pool *WorkerPool) acquireSema(ctx context.Context) error {
+	acquireCtx, cancel := context.WithTimeout(ctx, model.DefaultTimeout)
+	defer cancel()
+	err := pool.Sema.Acquire(acquireCtx)
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return serviceerrs.ErrAgentStopped
+	case errors.Is(err, context.DeadlineExceeded):
+		return serviceerrs.ErrSemaphoreTimeoutExceeded
+	}
+	return err //nolint: wrapcheck // only ctx errors
+}
+
+func (pool *WorkerPool) cancelBreaker(p breaker.Permit) {
+	if pool.Breaker != nil {
+		pool.Breaker.Cancel(p)
+	}
+}
+
+// doneBreaker считает отказом accrual только ошибки сервера и сети:
+// 204 и 429 -- штатные ответы, а отмена ctx -- наша остановка.
+func (pool *WorkerPool) doneBreaker(ctx context.Context, p breaker.Permit, err error) {
+	if pool.Breaker == nil {
+		return
+	}
+	var tmrErr *serviceerrs.TooManyRequestsError
+	if ctx.Err() != nil {
+		pool.Breaker.Cancel(p)
+		return
+	}
+	failed := err != nil &&
+		!errors.Is(err, serviceerrs.ErrNoContent) &&
+		!errors.As(err, &tmrErr)
+	pool.Breaker.Done(p, failed)
+}
+
+func (pool *WorkerPool) dummy(orderID string, status dto.AccrualStatus, err error) dto.AccrualInfo {
 	return dto.AccrualInfo{
 		Order:  orderID,
 		Status: string(status),
+		Err:    err,
 	}
 }
@@ -0,0 +1,202 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/workerpool/mocks"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+	"github.com/talx-hub/gopher-bonus/internal/utils/breaker"
+	"github.com/talx-hub/gopher-bonus/internal/utils/semaphore"
+)
+
+func setupResizePool(t *testing.T, client AccrualClient,
+) (*WorkerPool, chan string, chan dto.AccrualInfo, *sync.WaitGroup) {
+	t.Helper()
+
+	jobs := make(chan string)
+	results := make(chan dto.AccrualInfo)
+	requests := make(chan struct{}, 1024)
+	wg := &sync.WaitGroup{}
+	pool := New(client, semaphore.New(model.DefaultRequestCount), wg,
+		jobs, make(chan serviceerrs.TooManyRequestsError), requests, results)
+	return pool, jobs, results, wg
+}
+
+func TestWorkerPool_Resize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := mocks.NewMockAccrualClient(t)
+	client.EXPECT().
+		GetOrderInfo(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, orderID string) (dto.AccrualInfo, error) {
+			return dto.AccrualInfo{Order: orderID, Status: string(dto.StatusCalculatorProcessed)}, nil
+		})
+	pool, jobs, results, wg := setupResizePool(t, client)
+	var mu sync.Mutex
+	started := 0
+	pool.OnWorkerStart = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		started++
+	}
+
+	poolCancel := pool.Start(ctx, 4)
+	assert.Equal(t, 4, pool.Resize(1))
+	assert.Equal(t, 1, pool.Size())
+
+	orders := []string{"1", "2", "3", "4", "5", "6", "7", "8"}
+	go func() {
+		for _, o := range orders {
+			jobs <- o
+		}
+	}()
+	got := make([]string, 0, len(orders))
+	for range orders {
+		got = append(got, (<-results).Order)
+	}
+	assert.ElementsMatch(t, orders, got)
+
+	assert.Equal(t, 1, pool.Resize(3))
+	assert.Equal(t, 3, pool.Size())
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return started == 6
+	}, time.Second, 10*time.Millisecond)
+	assert.Positive(t, pool.Latency())
+
+	poolCancel()
+	wg.Wait()
+	// остановленный пул не запускает новых воркеров
+	assert.Equal(t, 3, pool.Resize(10))
+	assert.Equal(t, 3, pool.Size())
+}
+
+func TestWorkerPool_Resize_finishesJobInProgress(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inFlight := make(chan struct{})
+	release := make(chan struct{})
+	client := mocks.NewMockAccrualClient(t)
+	client.EXPECT().
+		GetOrderInfo(mock.Anything, "slow").
+		RunAndReturn(func(_ context.Context, orderID string) (dto.AccrualInfo, error) {
+			close(inFlight)
+			<-release
+			return dto.AccrualInfo{Order: orderID, Status: string(dto.StatusCalculatorProcessed)}, nil
+		}).
+		Once()
+	pool, jobs, results, wg := setupResizePool(t, client)
+
+	pool.Start(ctx, 1)
+	jobs <- "slow"
+	<-inFlight
+	pool.Resize(0)
+	close(release)
+
+	select {
+	case res := <-results:
+		assert.Equal(t, "slow", res.Order)
+	case <-time.After(time.Second):
+		require.FailNow(t, "job in progress was lost")
+	}
+
+	// воркер вышел сам, без отмены ctx
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "retired worker did not stop")
+	}
+}
+
+func TestWorkerPool_Resize_stopsWorkerWaitingForBreaker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool, _, _, wg := setupResizePool(t, mocks.NewMockAccrualClient(t))
+	pool.Breaker = breaker.New(breaker.Config{
+		FailureRate: 1,
+		Window:      1,
+		MinRequests: 1,
+		CoolDown:    time.Hour,
+	}, nil)
+	permit, err := pool.Breaker.Acquire(ctx)
+	require.NoError(t, err)
+	pool.Breaker.Done(permit, true)
+
+	pool.Start(ctx, 1)
+	pool.Resize(0)
+
+	// воркер не ждёт конца cool down, хотя ctx не отменён
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "worker waiting for the breaker did not stop")
+	}
+}
+
+// blockingLimiter сообщает, что воркер ждёт разрешения, и не даёт его до отмены.
+type blockingLimiter struct {
+	waiting chan struct{}
+}
+
+func (l *blockingLimiter) Wait(ctx context.Context) error {
+	close(l.waiting)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWorkerPool_Resize_stopsWorkerWaitingForLimiter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool, jobs, results, wg := setupResizePool(t, mocks.NewMockAccrualClient(t))
+	limiter := &blockingLimiter{waiting: make(chan struct{})}
+	pool.Limiter = limiter
+
+	pool.Start(ctx, 1)
+	jobs <- "1"
+	<-limiter.waiting
+	pool.Resize(0)
+
+	// заказ возвращается, а воркер выходит, не дожидаясь разрешения
+	select {
+	case res := <-results:
+		assert.Equal(t, "1", res.Order)
+		assert.Equal(t, string(dto.StatusAgentFailed), res.Status)
+		require.ErrorIs(t, res.Err, serviceerrs.ErrAgentStopped)
+	case <-time.After(time.Second):
+		require.FailNow(t, "job waiting for the limiter was lost")
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "worker waiting for the limiter did not stop")
+	}
+}
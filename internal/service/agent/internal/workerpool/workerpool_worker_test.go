@@ -2,20 +2,25 @@ package workerpool
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/talx-hub/gopher-bonus/internal/model"
 	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/workerpool/mocks"
 	"github.com/talx-hub/gopher-bonus/internal/service/dto"
 	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+	"github.com/talx-hub/gopher-bonus/internal/utils/breaker"
 	"github.com/talx-hub/gopher-bonus/internal/utils/semaphore"
 )
 
 func TestWorkerPool_worker_general(t *testing.T) {
+	throttled := &serviceerrs.TooManyRequestsError{RetryAfter: model.DefaultTimeout, RPM: 1}
 	tests := []struct {
 		name         string
 		jobs         []string
@@ -84,7 +89,7 @@ func TestWorkerPool_worker_general(t *testing.T) {
 			name: "too many requests #1",
 			jobs: []string{"429"},
 			results: []dto.AccrualInfo{
-				{Order: "429", Status: "CALCULATOR_FAILED"},
+				{Order: "429", Status: "CALCULATOR_FAILED", Err: throttled},
 			},
 			requestCount: 1,
 			rateData: []serviceerrs.TooManyRequestsError{
@@ -95,7 +100,7 @@ func TestWorkerPool_worker_general(t *testing.T) {
 			name: "too many requests #2",
 			jobs: []string{"429", "200", "201", "202"},
 			results: []dto.AccrualInfo{
-				{Order: "429", Status: "CALCULATOR_FAILED"},
+				{Order: "429", Status: "CALCULATOR_FAILED", Err: throttled},
 			},
 			requestCount: 1,
 			rateData: []serviceerrs.TooManyRequestsError{
@@ -108,7 +113,7 @@ func TestWorkerPool_worker_general(t *testing.T) {
 			results: []dto.AccrualInfo{
 				{Order: "201", Status: "PROCESSED", Accrual: "201"},
 				{Order: "202", Status: "PROCESSED", Accrual: "202"},
-				{Order: "429", Status: "CALCULATOR_FAILED"},
+				{Order: "429", Status: "CALCULATOR_FAILED", Err: throttled},
 			},
 			requestCount: 3,
 			rateData: []serviceerrs.TooManyRequestsError{
@@ -120,7 +125,7 @@ func TestWorkerPool_worker_general(t *testing.T) {
 			jobs: []string{"200", "429", "429", "429"},
 			results: []dto.AccrualInfo{
 				{Order: "200", Status: "PROCESSED", Accrual: "200"},
-				{Order: "429", Status: "CALCULATOR_FAILED"},
+				{Order: "429", Status: "CALCULATOR_FAILED", Err: throttled},
 			},
 			requestCount: 2,
 			rateData: []serviceerrs.TooManyRequestsError{
@@ -236,13 +241,13 @@ func TestWorkerPool_worker_semaphoreError(t *testing.T) {
 		ctx, cancel, rateDataCh, requestCountCh, resultCh, pool)
 
 	wantResults := []dto.AccrualInfo{
-		{Order: "429", Status: string(dto.StatusAgentFailed)},
-		{Order: "200", Status: string(dto.StatusAgentFailed)},
-		{Order: "201", Status: string(dto.StatusAgentFailed)},
-		{Order: "500", Status: string(dto.StatusAgentFailed)},
-		{Order: "202", Status: string(dto.StatusAgentFailed)},
-		{Order: "501", Status: string(dto.StatusAgentFailed)},
-		{Order: "203", Status: string(dto.StatusAgentFailed)},
+		{Order: "429", Status: string(dto.StatusAgentFailed), Err: serviceerrs.ErrSemaphoreTimeoutExceeded},
+		{Order: "200", Status: string(dto.StatusAgentFailed), Err: serviceerrs.ErrSemaphoreTimeoutExceeded},
+		{Order: "201", Status: string(dto.StatusAgentFailed), Err: serviceerrs.ErrSemaphoreTimeoutExceeded},
+		{Order: "500", Status: string(dto.StatusAgentFailed), Err: serviceerrs.ErrSemaphoreTimeoutExceeded},
+		{Order: "202", Status: string(dto.StatusAgentFailed), Err: serviceerrs.ErrSemaphoreTimeoutExceeded},
+		{Order: "501", Status: string(dto.StatusAgentFailed), Err: serviceerrs.ErrSemaphoreTimeoutExceeded},
+		{Order: "203", Status: string(dto.StatusAgentFailed), Err: serviceerrs.ErrSemaphoreTimeoutExceeded},
 	}
 
 	assert.Equal(t, wantResults, results)
@@ -250,3 +255,152 @@ func TestWorkerPool_worker_semaphoreError(t *testing.T) {
 	assert.Equal(t, []serviceerrs.TooManyRequestsError{}, errs)
 	mockClientNoExpectations.AssertNotCalled(t, "GetOrderInfo")
 }
+
+func TestWorkerPool_worker_breakerOpen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jobs := GenerateJobs(t, ctx, []string{"500", "501", "200", "201"})
+	client := mocks.NewMockAccrualClient(t)
+	client.EXPECT().
+		GetOrderInfo(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, orderID string) (dto.AccrualInfo, error) {
+			return dto.AccrualInfo{}, errors.New("accrual service error Body: " + orderID)
+		}).
+		Times(2)
+	pool, rateDataCh, requestCountCh, resultCh :=
+		SetupWorkerPool(t,
+			&sync.WaitGroup{},
+			client,
+			semaphore.New(model.DefaultRequestCount),
+			func() chan string { return jobs })
+	pool.Breaker = breaker.New(breaker.Config{
+		FailureRate: 1,
+		Window:      2,
+		MinRequests: 2,
+		CoolDown:    time.Hour,
+	}, nil)
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	results, requests, errs := TestWorker(t,
+		ctx, cancel, rateDataCh, requestCountCh, resultCh, pool)
+
+	assert.Equal(t, []dto.AccrualInfo{
+		{
+			Order:  "500",
+			Status: string(dto.StatusCalculatorFailed),
+			Err:    errors.New("accrual service error Body: 500"),
+		},
+		{
+			Order:  "501",
+			Status: string(dto.StatusCalculatorFailed),
+			Err:    errors.New("accrual service error Body: 501"),
+		},
+	}, results)
+	assert.Equal(t, 2, len(requests))
+	assert.Equal(t, []serviceerrs.TooManyRequestsError{}, errs)
+	// пока цепь разомкнута, оставшиеся заказы не забираются из очереди
+	assert.Len(t, jobs, 2)
+}
+
+// stingyLimiter пропускает allowed запросов, а дальше ждёт отмены.
+type stingyLimiter struct {
+	allowed int
+}
+
+func (l *stingyLimiter) Wait(ctx context.Context) error {
+	if l.allowed > 0 {
+		l.allowed--
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWorkerPool_worker_limiter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jobs := GenerateJobs(t, ctx, []string{"200", "201", "202", "203"})
+	pool, rateDataCh, requestCountCh, resultCh :=
+		SetupWorkerPool(t,
+			&sync.WaitGroup{},
+			ConfigureMockAccrualClient(t),
+			semaphore.New(model.DefaultRequestCount),
+			func() chan string { return jobs })
+	pool.Limiter = &stingyLimiter{allowed: 2}
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	results, requests, _ := TestWorker(t,
+		ctx, cancel, rateDataCh, requestCountCh, resultCh, pool)
+
+	// заказ, не дождавшийся разрешения, возвращается, а не теряется
+	assert.Equal(t, []dto.AccrualInfo{
+		{Order: "200", Status: "PROCESSED", Accrual: "200"},
+		{Order: "201", Status: "PROCESSED", Accrual: "201"},
+		{Order: "202", Status: string(dto.StatusAgentFailed), Err: serviceerrs.ErrAgentStopped},
+	}, results)
+	assert.Equal(t, 2, len(requests))
+}
+
+// heldClient отвечает 429, когда тест закроет release.
+type heldClient struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *heldClient) GetOrderInfo(context.Context, string) (dto.AccrualInfo, error) {
+	close(c.started)
+	<-c.release
+	return dto.AccrualInfo{}, &serviceerrs.TooManyRequestsError{RPM: 60, RetryAfter: time.Second}
+}
+
+// firstLimiter пропускает первый запрос, а следующие держит до отмены и сообщает о них в waiting.
+type firstLimiter struct {
+	waiting chan struct{}
+	allowed bool
+}
+
+func (l *firstLimiter) Wait(ctx context.Context) error {
+	if !l.allowed {
+		l.allowed = true
+		return nil
+	}
+	close(l.waiting)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWorkerPool_worker_tooManyRequestsStopsOtherWorkers(t *testing.T) {
+	client := &heldClient{started: make(chan struct{}), release: make(chan struct{})}
+	jobs := make(chan string)
+	results := make(chan dto.AccrualInfo)
+	rateDataCh := make(chan serviceerrs.TooManyRequestsError, 1)
+	pool := New(client, semaphore.New(model.DefaultRequestCount), &sync.WaitGroup{},
+		jobs, rateDataCh, make(chan struct{}, 1), results)
+	limiter := &firstLimiter{waiting: make(chan struct{})}
+	pool.Limiter = limiter
+
+	cancel := pool.Start(context.Background(), 2)
+	defer cancel()
+	jobs <- "429"
+	<-client.started
+	jobs <- "200"
+	<-limiter.waiting
+	close(client.release)
+
+	got := map[string]dto.AccrualInfo{}
+	for range 2 {
+		select {
+		case res := <-results:
+			got[res.Order] = res
+		case <-time.After(time.Second):
+			require.FailNow(t, "no result from worker")
+		}
+	}
+	assert.Equal(t, string(dto.StatusCalculatorFailed), got["429"].Status)
+	// заказ, ждавший разрешения, не отправлен из-за чужого 429, и это не отказ accrual
+	assert.Equal(t, string(dto.StatusAgentFailed), got["200"].Status)
+	require.ErrorIs(t, got["200"].Err, serviceerrs.ErrAgentStopped)
+	assert.Equal(t, uint64(60), (<-rateDataCh).RPM)
+}
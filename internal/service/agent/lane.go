@@ -0,0 +1,439 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/httpclient"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/ratelimit"
+	"github.com/talx-hub/gopher-bonus/internal/service/agent/internal/workerpool"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+	"github.com/talx-hub/gopher-bonus/internal/utils/breaker"
+	"github.com/talx-hub/gopher-bonus/internal/utils/semaphore"
+)
+
+const (
+	// laneQueueSize -- сколько заказов провайдера может ждать свободного воркера.
+	laneQueueSize = 256
+	// rateProbeInterval -- как часто проверяется, не пора ли поднять темп запросов
+	rateProbeInterval = 15 * time.Second
+)
+
+// lane -- всё, что агент держит для одного провайдера: очередь, пул воркеров и учёт 429.
+type lane struct {
+	client  *httpclient.HTTPClient
+	metrics Metrics
+	scaler  *workerpool.Scaler
+	rate    *ratelimit.Controller
+	// budget -- общий с другими репликами бюджет запросов, nil -- темп только свой
+	budget Budget
+	// shared -- rate вместе с budget, nil без budget
+	shared *sharedLimiter
+	// limiter ограничивает темп и воркеров, и Lookup: shared, а без бюджета -- rate
+	limiter workerpool.AccrualLimiter
+	jobs    chan string
+	results chan dto.AccrualInfo
+	// команды оператора, их читает run
+	hold  chan bool
+	limit chan uint64
+	// закрывается, когда run вернулся и команды больше не читаются
+	stopped  chan struct{}
+	provider Provider
+	// state -- снимок для State, его пишет только run
+	state ProviderState
+	// заказы, отданные провайдеру и ещё не получившие ответа
+	outstanding atomic.Int64
+	mu          sync.Mutex
+	workerCount int
+	// held -- оператор приостановил опрос; читает и пишет только run
+	held bool
+}
+
+func newLane(
+	p Provider, client *httpclient.HTTPClient, m Metrics, scaler *workerpool.Scaler, workerCount int, held bool,
+) *lane {
+	return &lane{
+		client:      client,
+		metrics:     m,
+		scaler:      scaler,
+		rate:        ratelimit.New(ratelimit.Config{Initial: p.RPM}),
+		jobs:        make(chan string, laneQueueSize),
+		results:     make(chan dto.AccrualInfo),
+		hold:        make(chan bool),
+		limit:       make(chan uint64),
+		stopped:     make(chan struct{}),
+		provider:    p,
+		state:       ProviderState{Name: p.Name, MaxRequests: p.RPM, RateLimit: p.RPM},
+		workerCount: workerCount,
+		held:        held,
+	}
+}
+
+// send передаёт команду оператора в run; false -- lane уже остановлен.
+func send[T any](ctx context.Context, l *lane, ch chan<- T, v T) (bool, error) {
+	select {
+	case ch <- v:
+		return true, nil
+	case <-l.stopped:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err() //nolint: wrapcheck // caller's deadline
+	}
+}
+
+func (l *lane) snapshot() ProviderState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.state
+	s.InFlight = l.outstanding.Load()
+	s.RPM = l.rate.RPM()
+	return s
+}
+
+func (l *lane) report(update func(s *ProviderState)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	update(&l.state)
+}
+
+// offer ставит заказ в очередь провайдера; false -- очередь полна.
+func (l *lane) offer(orderID string) bool {
+	l.outstanding.Add(1)
+	select {
+	case l.jobs <- orderID:
+		return true
+	default:
+		l.outstanding.Add(-1)
+		return false
+	}
+}
+
+// forward пересылает ответы провайдера агенту, пока run не закроет results.
+func (l *lane) forward(responsesCh chan<- dto.AccrualInfo) {
+	for resp := range l.results {
+		l.outstanding.Add(-1)
+		responsesCh <- resp
+	}
+}
+
+func (l *lane) run(ctx context.Context, log *slog.Logger) {
+	maxRequestCount := l.provider.RPM
+	requestsCh := make(chan struct{}, runtime.NumCPU()*model.DefaultWorkerCountMultiplier)
+	go func() {
+		for range requestsCh {
+			l.rate.Observe()
+		}
+	}()
+
+	wg := &sync.WaitGroup{}
+	rateDataCh := make(chan serviceerrs.TooManyRequestsError)
+	client := l.client
+	if l.metrics != nil {
+		client.OnResponse = func(code int) {
+			l.metrics.ObserveAccrualResponse(l.provider.Name, code)
+		}
+		l.metrics.SetAccrualCapacity(l.provider.Name, maxRequestCount)
+		l.metrics.SetAccrualRateLimit(l.provider.Name, l.rate.Rate())
+	}
+	pool := workerpool.New(
+		client,
+		semaphore.New(maxRequestCount),
+		wg,
+		l.jobs,
+		rateDataCh,
+		requestsCh,
+		l.results,
+	)
+	if l.provider.Breaker != nil {
+		pool.Breaker = l.provider.Breaker
+	}
+	pool.Limiter = l.limiter
+	l.ensureBudget(ctx, log)
+	// воркеры не отменяются вместе с ctx: при остановке они доделывают начатые запросы
+	poolCtx := context.WithoutCancel(ctx)
+	log.LogAttrs(ctx, slog.LevelInfo, "starting worker pool")
+	defer close(l.stopped)
+	poolCancel := pool.Start(poolCtx, l.activeWorkers())
+	l.setWorkers(l.activeWorkers())
+
+	timer := time.NewTimer(model.DefaultTimeout)
+	timer.Stop()
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	var scaleCh <-chan time.Time
+	if l.scaler != nil && l.scaler.Config().Interval > 0 {
+		ticker := time.NewTicker(l.scaler.Config().Interval)
+		defer ticker.Stop()
+		scaleCh = ticker.C
+	}
+	probe := time.NewTicker(rateProbeInterval)
+	defer probe.Stop()
+	// пока accrual просит подождать после 429, воркеры остановлены и пул не подстраивается
+	paused := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.drain(ctx, log, pool, wg, rateDataCh)
+			poolCancel()
+			close(requestsCh)
+			close(rateDataCh)
+			l.rejectQueued()
+			close(l.results)
+			log.LogAttrs(ctx, slog.LevelInfo, "stopped")
+			return
+		case rateData := <-rateDataCh:
+			paused = true
+			wg.Wait()
+			oldRate := l.rate.Rate()
+			newRate := l.throttle(ctx, rateData)
+			timer = time.NewTimer(rateData.RetryAfter)
+			l.report(func(s *ProviderState) {
+				s.Throttled = true
+				s.RetryAfter = time.Now().Add(rateData.RetryAfter)
+				s.Workers = 0
+			})
+			log.LogAttrs(ctx,
+				slog.LevelInfo,
+				"paused requesting",
+				slog.Duration("retry_after", rateData.RetryAfter),
+				slog.Uint64("advertised_rpm", rateData.RPM),
+				slog.Uint64("old_rpm", oldRate),
+				slog.Uint64("new_rpm", newRate))
+		case <-timer.C:
+			poolCancel = pool.Start(poolCtx, l.activeWorkers())
+			paused = false
+			l.report(func(s *ProviderState) {
+				s.Throttled = false
+				s.RetryAfter = time.Time{}
+				s.Workers = l.activeWorkers()
+			})
+			log.LogAttrs(ctx,
+				slog.LevelInfo,
+				"restarted requesting",
+				slog.Uint64("rpm", l.rate.Rate()))
+		case <-probe.C:
+			if paused || l.held {
+				continue
+			}
+			if l.shared != nil {
+				l.shared.probe(ctx)
+			}
+			oldRate := l.rate.Rate()
+			if newRate, changed := l.rate.Probe(); changed {
+				l.setRate(newRate)
+				log.LogAttrs(ctx,
+					slog.LevelInfo,
+					"request rate adjusted",
+					slog.Uint64("old_rpm", oldRate),
+					slog.Uint64("new_rpm", newRate),
+					slog.Uint64("measured_rpm", l.rate.RPM()))
+			}
+		case <-scaleCh:
+			if !paused && !l.held {
+				l.scale(ctx, log, pool)
+			}
+		case held := <-l.hold:
+			if held == l.held {
+				continue
+			}
+			l.held = held
+			// после 429 воркеры уже остановлены и запустятся по таймеру с учётом held
+			if !paused {
+				pool.Resize(l.activeWorkers())
+				l.setWorkers(l.activeWorkers())
+			}
+			if held {
+				l.rejectHeld()
+			}
+			log.LogAttrs(ctx, slog.LevelInfo, "requesting held by operator", slog.Bool("held", held))
+		case n := <-l.limit:
+			log.LogAttrs(ctx,
+				slog.LevelInfo,
+				"max requests changed by operator",
+				slog.Uint64("old_max_requests", maxRequestCount),
+				slog.Uint64("new_max_requests", n))
+			maxRequestCount = n
+			pool.ChangeMaxRequests(n)
+			if l.metrics != nil {
+				l.metrics.SetAccrualCapacity(l.provider.Name, n)
+			}
+			l.report(func(s *ProviderState) { s.MaxRequests = n })
+		}
+	}
+}
+
+// ensureBudget заводит общий бюджет провайдера, если он задан.
+func (l *lane) ensureBudget(ctx context.Context, log *slog.Logger) {
+	if l.budget == nil {
+		return
+	}
+	if err := l.budget.Ensure(ctx, l.provider.Name, l.provider.RPM); err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to create shared accrual budget",
+			slog.Any(model.KeyLoggerError, err))
+	}
+}
+
+// throttle снижает темп после 429 у себя и, если есть общий бюджет, у всех реплик. Возвращает новый темп.
+func (l *lane) throttle(ctx context.Context, rateData serviceerrs.TooManyRequestsError) uint64 {
+	rate := l.rate.Throttle(rateData.RPM)
+	l.setRate(rate)
+	if l.shared != nil {
+		l.shared.throttle(ctx, rateData)
+	}
+	return rate
+}
+
+// lookup отправляет запрос в обход пула, но через тот же темп, бюджет и breaker, что и воркеры.
+// Воркеров 429 в ответ на lookup не останавливает: их задержит сниженный темп или пауза бюджета.
+func (l *lane) lookup(ctx context.Context, log *slog.Logger, orderID string) (dto.AccrualInfo, error) {
+	var permit breaker.Permit
+	if l.provider.Breaker != nil {
+		var err error
+		if permit, err = l.provider.Breaker.Acquire(ctx); err != nil {
+			return dto.AccrualInfo{}, err //nolint: wrapcheck // only ctx errors
+		}
+	}
+	if err := l.limiter.Wait(ctx); err != nil {
+		if l.provider.Breaker != nil {
+			l.provider.Breaker.Cancel(permit)
+		}
+		return dto.AccrualInfo{}, err //nolint: wrapcheck // only ctx errors
+	}
+	l.rate.Observe()
+
+	info, err := l.client.GetOrderInfo(ctx, orderID)
+	var tmrErr *serviceerrs.TooManyRequestsError
+	tooMany := errors.As(err, &tmrErr)
+	if l.provider.Breaker != nil {
+		// как у воркеров: 204 и 429 -- штатные ответы, а отмена ctx -- не отказ accrual
+		if ctx.Err() != nil {
+			l.provider.Breaker.Cancel(permit)
+		} else {
+			l.provider.Breaker.Done(permit, err != nil && !errors.Is(err, serviceerrs.ErrNoContent) && !tooMany)
+		}
+	}
+	if tooMany {
+		oldRate := l.rate.Rate()
+		newRate := l.throttle(ctx, *tmrErr)
+		log.LogAttrs(ctx,
+			slog.LevelInfo,
+			"request rate lowered after lookup",
+			slog.Duration("retry_after", tmrErr.RetryAfter),
+			slog.Uint64("advertised_rpm", tmrErr.RPM),
+			slog.Uint64("old_rpm", oldRate),
+			slog.Uint64("new_rpm", newRate))
+	}
+	return info, err //nolint: wrapcheck // error from wrapped function
+}
+
+// activeWorkers -- сколько воркеров должно работать, пока нет паузы после 429.
+func (l *lane) activeWorkers() int {
+	if l.held {
+		return 0
+	}
+	return l.workerCount
+}
+
+// rejectHeld возвращает заказы, ждущие в очереди приостановленного провайдера,
+// чтобы они не держались до возобновления.
+func (l *lane) rejectHeld() {
+	for {
+		select {
+		case orderID, ok := <-l.jobs:
+			if !ok {
+				return
+			}
+			l.results <- dto.AccrualInfo{
+				Order:  orderID,
+				Status: string(dto.StatusAgentFailed),
+				Err:    serviceerrs.ErrAgentPaused,
+			}
+		default:
+			return
+		}
+	}
+}
+
+// drain останавливает воркеров и ждёт, пока они доделают начатые запросы к accrual.
+func (l *lane) drain(
+	ctx context.Context,
+	log *slog.Logger,
+	pool *workerpool.WorkerPool,
+	wg *sync.WaitGroup,
+	rateDataCh <-chan serviceerrs.TooManyRequestsError,
+) {
+	log.LogAttrs(ctx, slog.LevelInfo, "waiting for requests in progress")
+	pool.Resize(0)
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	for {
+		select {
+		case <-stopped:
+			return
+		// воркер, получивший 429, ждёт, пока его прочитают
+		case <-rateDataCh:
+		}
+	}
+}
+
+// rejectQueued возвращает заказы, так и не отданные воркерам, пока агент не закроет очередь.
+func (l *lane) rejectQueued() {
+	for orderID := range l.jobs {
+		l.results <- dto.AccrualInfo{
+			Order:  orderID,
+			Status: string(dto.StatusAgentFailed),
+			Err:    serviceerrs.ErrAgentStopped,
+		}
+	}
+}
+
+func (l *lane) scale(ctx context.Context, log *slog.Logger, pool *workerpool.WorkerPool) {
+	depth := int(l.outstanding.Load())
+	latency := pool.Latency()
+	next := l.scaler.Next(l.workerCount, depth, latency)
+	if next == l.workerCount {
+		return
+	}
+	pool.Resize(next)
+	log.LogAttrs(ctx,
+		slog.LevelInfo,
+		"worker pool resized",
+		slog.Int("old_workers", l.workerCount),
+		slog.Int("new_workers", next),
+		slog.Int("backlog", depth),
+		slog.Duration("latency", latency),
+	)
+	l.workerCount = next
+	l.setWorkers(next)
+}
+
+func (l *lane) setRate(rpm uint64) {
+	l.report(func(s *ProviderState) { s.RateLimit = rpm })
+	if l.metrics != nil {
+		l.metrics.SetAccrualRateLimit(l.provider.Name, rpm)
+	}
+}
+
+func (l *lane) setWorkers(n int) {
+	l.report(func(s *ProviderState) { s.Workers = n })
+	if l.metrics != nil {
+		l.metrics.SetAccrualWorkers(l.provider.Name, n)
+	}
+}
@@ -3,9 +3,15 @@ package config
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v6"
+	"github.com/google/uuid"
 
 	"github.com/talx-hub/gopher-bonus/internal/model"
 )
@@ -13,10 +19,94 @@ import (
 type Config struct {
 	RunAddr       string `env:"RUN_ADDRESS"   envDefault:"localhost:8080"`
 	DatabaseURI   string `env:"DATABASE_URI"   envDefault:""`
-	AccrualAddr   string `env:"ACCRUAL_SYSTEM_ADDRESS"   envDefault:"localhost:8081"`
+	AccrualAddr   string `env:"ACCRUAL_SYSTEM_ADDRESS"   envDefault:"http://localhost:8081"`
 	SecretKey     string `env:"SECRET_KEY"     envDefault:""`
 	LogLevel      string `env:"LOG_LEVEL"      envDefault:"info"`
 	UsePagination bool   `env:"USE_PAGINATION" envDefault:"false"`
+
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"15s"`
+
+	TierRecalcInterval time.Duration `env:"TIER_RECALC_INTERVAL" envDefault:"24h"`
+	AdminIDs           []string      `env:"ADMIN_USER_IDS" envSeparator:","`
+	SuperAdminIDs      []string      `env:"SUPERADMIN_USER_IDS" envSeparator:","`
+	ReferrerBonus      string        `env:"REFERRAL_REFERRER_BONUS" envDefault:"100"`
+	RefereeBonus       string        `env:"REFERRAL_REFEREE_BONUS" envDefault:"50"`
+
+	ReconcileInterval  time.Duration `env:"RECONCILE_INTERVAL"   envDefault:"24h"`
+	ReconcileBatchSize int           `env:"RECONCILE_BATCH_SIZE" envDefault:"100"`
+	ReconcileAdminID   string        `env:"RECONCILE_ADMIN_ID"`
+	ReconcileAutoApply bool          `env:"RECONCILE_AUTO_APPLY" envDefault:"false"`
+
+	AccrualBackoffBase   time.Duration `env:"ACCRUAL_BACKOFF_BASE"   envDefault:"5s"`
+	AccrualBackoffMax    time.Duration `env:"ACCRUAL_BACKOFF_MAX"    envDefault:"30m"`
+	AccrualReviewHorizon time.Duration `env:"ACCRUAL_REVIEW_HORIZON" envDefault:"24h"`
+	AccrualMaxFailures   int           `env:"ACCRUAL_MAX_FAILURES"   envDefault:"10"`
+	AccrualLease         time.Duration `env:"ACCRUAL_LEASE"          envDefault:"2m"`
+	AccrualBatchSize     int           `env:"ACCRUAL_BATCH_SIZE"     envDefault:"100"`
+	InstanceID           string        `env:"INSTANCE_ID"`
+	LeaderCheckInterval  time.Duration `env:"LEADER_CHECK_INTERVAL"  envDefault:"5s"`
+	AccrualSharedBudget  bool          `env:"ACCRUAL_SHARED_BUDGET"  envDefault:"false"`
+
+	AccrualWorkersMin      int           `env:"ACCRUAL_WORKERS_MIN"       envDefault:"2"`
+	AccrualWorkersMax      int           `env:"ACCRUAL_WORKERS_MAX"       envDefault:"64"`
+	AccrualScaleInterval   time.Duration `env:"ACCRUAL_SCALE_INTERVAL"    envDefault:"5s"`
+	AccrualScaleMaxLatency time.Duration `env:"ACCRUAL_SCALE_MAX_LATENCY" envDefault:"2s"`
+	AccrualScaleDownAfter  int           `env:"ACCRUAL_SCALE_DOWN_AFTER"  envDefault:"3"`
+
+	AccrualClient
+	AccrualConnectTimeout  time.Duration `env:"ACCRUAL_CONNECT_TIMEOUT"    envDefault:"2s"`
+	AccrualResponseTimeout time.Duration `env:"ACCRUAL_RESPONSE_TIMEOUT"   envDefault:"500ms"`
+	AccrualIdleConnTimeout time.Duration `env:"ACCRUAL_IDLE_CONN_TIMEOUT"  envDefault:"90s"`
+	AccrualMaxIdleConns    int           `env:"ACCRUAL_MAX_IDLE_CONNS"     envDefault:"100"`
+	AccrualMaxConnsPerHost int           `env:"ACCRUAL_MAX_CONNS_PER_HOST" envDefault:"0"`
+
+	BreakerFailureRate float64       `env:"ACCRUAL_BREAKER_FAILURE_RATE" envDefault:"0.5"`
+	BreakerWindow      int           `env:"ACCRUAL_BREAKER_WINDOW"       envDefault:"20"`
+	BreakerMinRequests int           `env:"ACCRUAL_BREAKER_MIN_REQUESTS" envDefault:"10"`
+	BreakerCoolDown    time.Duration `env:"ACCRUAL_BREAKER_COOLDOWN"     envDefault:"30s"`
+	BreakerProbes      int           `env:"ACCRUAL_BREAKER_PROBES"       envDefault:"1"`
+
+	AccrualMode              AccrualMode      `env:"ACCRUAL_MODE"               envDefault:"poll"`
+	AccrualCallbackSecret    string           `env:"ACCRUAL_CALLBACK_SECRET"`
+	AccrualCallbackTolerance time.Duration    `env:"ACCRUAL_CALLBACK_TOLERANCE" envDefault:"5m"`
+	AccrualPushGrace         time.Duration    `env:"ACCRUAL_PUSH_GRACE"         envDefault:"5m"`
+	AccrualProviders         AccrualProviders `env:"ACCRUAL_PROVIDERS"`
+
+	TraceExporter    string  `env:"TRACE_EXPORTER"     envDefault:"none"`
+	TraceEndpoint    string  `env:"TRACE_ENDPOINT"`
+	TraceSampleRatio float64 `env:"TRACE_SAMPLE_RATIO" envDefault:"1"`
+}
+
+// AccrualMode -- как узнаём о результатах расчёта accrual:
+// опросом, push callback'ами или и так, и так.
+type AccrualMode string
+
+const (
+	AccrualModePoll   AccrualMode = "poll"
+	AccrualModePush   AccrualMode = "push"
+	AccrualModeHybrid AccrualMode = "hybrid"
+)
+
+func (m *AccrualMode) UnmarshalText(text []byte) error {
+	switch mode := AccrualMode(strings.ToLower(strings.TrimSpace(string(text)))); mode {
+	case AccrualModePoll, AccrualModePush, AccrualModeHybrid:
+		*m = mode
+		return nil
+	default:
+		return fmt.Errorf("unknown accrual mode %q", mode)
+	}
+}
+
+func (m AccrualMode) MarshalText() ([]byte, error) {
+	return []byte(m), nil
+}
+
+func (m AccrualMode) Polls() bool {
+	return m != AccrualModePush
+}
+
+func (m AccrualMode) AcceptsCallbacks() bool {
+	return m == AccrualModePush || m == AccrualModeHybrid
 }
 
 type Builder struct {
@@ -33,6 +123,66 @@ func NewBuilder(log *slog.Logger) *Builder {
 			SecretKey:     "",
 			LogLevel:      "",
 			UsePagination: false,
+
+			ShutdownTimeout: 0,
+
+			TierRecalcInterval: 0,
+			AdminIDs:           nil,
+			SuperAdminIDs:      nil,
+			ReferrerBonus:      "",
+			RefereeBonus:       "",
+
+			ReconcileInterval:  0,
+			ReconcileBatchSize: 0,
+			ReconcileAdminID:   "",
+			ReconcileAutoApply: false,
+
+			AccrualBackoffBase:   0,
+			AccrualBackoffMax:    0,
+			AccrualReviewHorizon: 0,
+			AccrualMaxFailures:   0,
+			AccrualLease:         0,
+			AccrualBatchSize:     0,
+			InstanceID:           "",
+			LeaderCheckInterval:  0,
+			AccrualSharedBudget:  false,
+
+			AccrualWorkersMin:      0,
+			AccrualWorkersMax:      0,
+			AccrualScaleInterval:   0,
+			AccrualScaleMaxLatency: 0,
+			AccrualScaleDownAfter:  0,
+
+			AccrualClient: AccrualClient{
+				CAFile:       "",
+				CertFile:     "",
+				KeyFile:      "",
+				Token:        "",
+				APIKey:       "",
+				APIKeyHeader: "",
+				Proxy:        "",
+			},
+			AccrualConnectTimeout:  0,
+			AccrualResponseTimeout: 0,
+			AccrualIdleConnTimeout: 0,
+			AccrualMaxIdleConns:    0,
+			AccrualMaxConnsPerHost: 0,
+
+			BreakerFailureRate: 0,
+			BreakerWindow:      0,
+			BreakerMinRequests: 0,
+			BreakerCoolDown:    0,
+			BreakerProbes:      0,
+
+			AccrualMode:              "",
+			AccrualCallbackSecret:    "",
+			AccrualCallbackTolerance: 0,
+			AccrualPushGrace:         0,
+			AccrualProviders:         nil,
+
+			TraceExporter:    "",
+			TraceEndpoint:    "",
+			TraceSampleRatio: 0,
 		},
 		log: log,
 	}
@@ -49,15 +199,147 @@ func (b *Builder) FromEnv() *Builder {
 func (b *Builder) FromFlags() *Builder {
 	flag.StringVar(&b.cfg.RunAddr, "a", b.cfg.RunAddr, "Run address")
 	flag.StringVar(&b.cfg.DatabaseURI, "d", b.cfg.DatabaseURI, "Database URI")
-	flag.StringVar(&b.cfg.AccrualAddr, "r", b.cfg.AccrualAddr, "Accrual address")
+	flag.StringVar(&b.cfg.AccrualAddr, "r", b.cfg.AccrualAddr, "Accrual address, e.g. http://localhost:8081")
 	flag.StringVar(&b.cfg.SecretKey, "k", b.cfg.SecretKey, "Secret key")
 	flag.StringVar(&b.cfg.LogLevel, "l", b.cfg.LogLevel, "Log level")
 	flag.BoolVar(&b.cfg.UsePagination, "p", b.cfg.UsePagination, "Use pagination")
+	flag.DurationVar(&b.cfg.ShutdownTimeout, "shutdown-timeout", b.cfg.ShutdownTimeout,
+		"How long in-flight HTTP requests and accrual calls may finish on shutdown")
+	flag.DurationVar(&b.cfg.TierRecalcInterval,
+		"tier-recalc-interval", b.cfg.TierRecalcInterval, "Loyalty tier recalculation interval")
+	flag.Func("admin-ids", "Comma-separated user IDs granted the admin role on start", func(s string) error {
+		b.cfg.AdminIDs = strings.Split(s, ",")
+		return nil
+	})
+	flag.Func("superadmin-ids", "Comma-separated user IDs granted the superadmin role on start",
+		func(s string) error {
+			b.cfg.SuperAdminIDs = strings.Split(s, ",")
+			return nil
+		})
+	flag.StringVar(&b.cfg.ReferrerBonus,
+		"referrer-bonus", b.cfg.ReferrerBonus, "Bonus credited to the referrer")
+	flag.StringVar(&b.cfg.RefereeBonus,
+		"referee-bonus", b.cfg.RefereeBonus, "Bonus credited to the invited user")
+	flag.DurationVar(&b.cfg.ReconcileInterval, "reconcile-interval", b.cfg.ReconcileInterval,
+		"How often settled orders are re-checked with accrual, 0 disables reconciliation")
+	flag.IntVar(&b.cfg.ReconcileBatchSize, "reconcile-batch-size", b.cfg.ReconcileBatchSize,
+		"How many settled orders are re-checked per run, 0 checks all of them")
+	flag.StringVar(&b.cfg.ReconcileAdminID, "reconcile-admin-id", b.cfg.ReconcileAdminID,
+		"Admin user ID recorded on automatic reconciliation corrections")
+	flag.BoolVar(&b.cfg.ReconcileAutoApply, "reconcile-auto-apply", b.cfg.ReconcileAutoApply,
+		"Correct balances with adjustments when accrual results changed")
+	flag.DurationVar(&b.cfg.AccrualBackoffBase,
+		"accrual-backoff-base", b.cfg.AccrualBackoffBase, "First delay between accrual checks of an order")
+	flag.DurationVar(&b.cfg.AccrualBackoffMax,
+		"accrual-backoff-max", b.cfg.AccrualBackoffMax, "Max delay between accrual checks of an order")
+	flag.DurationVar(&b.cfg.AccrualReviewHorizon,
+		"accrual-review-horizon", b.cfg.AccrualReviewHorizon,
+		"Time after upload when an order unknown to accrual goes to review")
+	flag.IntVar(&b.cfg.AccrualMaxFailures,
+		"accrual-max-failures", b.cfg.AccrualMaxFailures,
+		"Failed accrual checks in a row before an order goes to dead letter, 0 to retry forever")
+	flag.DurationVar(&b.cfg.AccrualLease,
+		"accrual-lease", b.cfg.AccrualLease, "How long a claimed order stays with this instance")
+	flag.IntVar(&b.cfg.AccrualBatchSize,
+		"accrual-batch-size", b.cfg.AccrualBatchSize, "Max orders claimed per watcher tick")
+	flag.StringVar(&b.cfg.InstanceID, "instance-id", b.cfg.InstanceID,
+		"Instance ID used for order leases, random by default")
+	flag.DurationVar(&b.cfg.LeaderCheckInterval, "leader-check-interval", b.cfg.LeaderCheckInterval,
+		"How often leadership for background jobs is acquired and confirmed")
+	flag.BoolVar(&b.cfg.AccrualSharedBudget, "accrual-shared-budget", b.cfg.AccrualSharedBudget,
+		"Share the accrual request rate between all instances through the database")
+	flag.IntVar(&b.cfg.AccrualWorkersMin, "accrual-workers-min", b.cfg.AccrualWorkersMin,
+		"Min number of workers polling accrual")
+	flag.IntVar(&b.cfg.AccrualWorkersMax, "accrual-workers-max", b.cfg.AccrualWorkersMax,
+		"Max number of workers polling accrual")
+	flag.DurationVar(&b.cfg.AccrualScaleInterval, "accrual-scale-interval", b.cfg.AccrualScaleInterval,
+		"How often the accrual worker pool is resized, 0 keeps it fixed")
+	flag.DurationVar(&b.cfg.AccrualScaleMaxLatency, "accrual-scale-max-latency", b.cfg.AccrualScaleMaxLatency,
+		"Accrual latency above which the worker pool stops growing")
+	flag.IntVar(&b.cfg.AccrualScaleDownAfter, "accrual-scale-down-after", b.cfg.AccrualScaleDownAfter,
+		"Resize rounds in a row wanting fewer workers before the pool shrinks")
+	flag.StringVar(&b.cfg.CAFile, "accrual-ca-file", b.cfg.CAFile,
+		"PEM bundle of CAs trusted for accrual instead of the system ones")
+	flag.StringVar(&b.cfg.CertFile, "accrual-cert-file", b.cfg.CertFile,
+		"Client certificate for mutual TLS with accrual")
+	flag.StringVar(&b.cfg.KeyFile, "accrual-key-file", b.cfg.KeyFile,
+		"Client certificate key for mutual TLS with accrual")
+	flag.StringVar(&b.cfg.Token, "accrual-token", b.cfg.Token,
+		"Bearer token sent to accrual")
+	flag.StringVar(&b.cfg.APIKey, "accrual-api-key", b.cfg.APIKey,
+		"API key sent to accrual")
+	flag.StringVar(&b.cfg.APIKeyHeader, "accrual-api-key-header", b.cfg.APIKeyHeader,
+		"Header carrying the accrual API key, X-API-Key by default")
+	flag.StringVar(&b.cfg.Proxy, "accrual-proxy", b.cfg.Proxy,
+		"Proxy URL for accrual requests, HTTP_PROXY and HTTPS_PROXY by default")
+	flag.DurationVar(&b.cfg.AccrualConnectTimeout, "accrual-connect-timeout", b.cfg.AccrualConnectTimeout,
+		"Max time to establish a TCP and TLS connection to accrual, 0 for no limit")
+	flag.DurationVar(&b.cfg.AccrualResponseTimeout, "accrual-response-timeout", b.cfg.AccrualResponseTimeout,
+		"Max time to wait for accrual response headers after sending a request")
+	flag.DurationVar(&b.cfg.AccrualIdleConnTimeout, "accrual-idle-conn-timeout", b.cfg.AccrualIdleConnTimeout,
+		"How long an idle connection to accrual is kept open")
+	flag.IntVar(&b.cfg.AccrualMaxIdleConns, "accrual-max-idle-conns", b.cfg.AccrualMaxIdleConns,
+		"Max idle connections kept per accrual provider, 0 for no limit")
+	flag.IntVar(&b.cfg.AccrualMaxConnsPerHost, "accrual-max-conns-per-host", b.cfg.AccrualMaxConnsPerHost,
+		"Max connections opened to one accrual provider, 0 for no limit")
+	flag.Float64Var(&b.cfg.BreakerFailureRate, "breaker-failure-rate", b.cfg.BreakerFailureRate,
+		"Share of failed accrual calls that opens the circuit, 0 disables the breaker")
+	flag.IntVar(&b.cfg.BreakerWindow, "breaker-window", b.cfg.BreakerWindow,
+		"Number of recent accrual calls the failure rate is computed over")
+	flag.IntVar(&b.cfg.BreakerMinRequests, "breaker-min-requests", b.cfg.BreakerMinRequests,
+		"Min accrual calls in the window before the circuit may open")
+	flag.DurationVar(&b.cfg.BreakerCoolDown, "breaker-cooldown", b.cfg.BreakerCoolDown,
+		"How long the circuit stays open before probing accrual again")
+	flag.IntVar(&b.cfg.BreakerProbes, "breaker-probes", b.cfg.BreakerProbes,
+		"Successful probes needed to close the circuit")
+	flag.TextVar(&b.cfg.AccrualMode, "accrual-mode", b.cfg.AccrualMode,
+		"How accrual results arrive: poll, push or hybrid")
+	flag.StringVar(&b.cfg.AccrualCallbackSecret, "accrual-callback-secret", b.cfg.AccrualCallbackSecret,
+		"HMAC secret of accrual push callbacks, required in push and hybrid modes")
+	flag.DurationVar(&b.cfg.AccrualCallbackTolerance,
+		"accrual-callback-tolerance", b.cfg.AccrualCallbackTolerance,
+		"Max clock difference of a signed accrual callback")
+	flag.DurationVar(&b.cfg.AccrualPushGrace, "accrual-push-grace", b.cfg.AccrualPushGrace,
+		"In hybrid mode orders are polled only after waiting this long for a callback")
+	flag.TextVar(&b.cfg.AccrualProviders, "accrual-providers", b.cfg.AccrualProviders,
+		"JSON list of extra accrual providers with routing rules by order number prefix and length")
+	flag.StringVar(&b.cfg.TraceExporter, "trace-exporter", b.cfg.TraceExporter,
+		"Trace exporter: none, stdout or otlp")
+	flag.StringVar(&b.cfg.TraceEndpoint, "trace-endpoint", b.cfg.TraceEndpoint,
+		"OTLP/HTTP endpoint URL, e.g. http://localhost:4318")
+	flag.Float64Var(&b.cfg.TraceSampleRatio, "trace-sample-ratio", b.cfg.TraceSampleRatio,
+		"Share of new traces that are sampled")
 
 	flag.Parse()
+	if b.cfg.InstanceID == "" {
+		b.cfg.InstanceID = defaultInstanceID()
+	}
 	return b
 }
 
+func defaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "gophermart"
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
+
 func (b *Builder) GetConfig() *Config {
 	return b.cfg
 }
+
+// Validate проверяет значения, с которыми сервис не сможет работать.
+func (c *Config) Validate() error {
+	if c.AccrualLease <= 0 {
+		return fmt.Errorf("accrual lease must be positive, got %s", c.AccrualLease)
+	}
+	if c.AccrualBatchSize <= 0 || c.AccrualBatchSize > math.MaxInt32 {
+		return fmt.Errorf("accrual batch size must be in [1, %d], got %d", math.MaxInt32, c.AccrualBatchSize)
+	}
+	// 0 -- сверять все заказы за раз
+	if c.ReconcileBatchSize < 0 || c.ReconcileBatchSize > math.MaxInt32 {
+		return fmt.Errorf("reconcile batch size must be in [0, %d], got %d", math.MaxInt32, c.ReconcileBatchSize)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// AccrualClient -- авторизация, TLS и прокси клиента accrual. У провайдера по умолчанию
+// задаётся переменными окружения, у остальных -- полями того же JSON-объекта.
+type AccrualClient struct {
+	// CAFile -- PEM с корневыми сертификатами accrual вместо системных
+	CAFile string `env:"ACCRUAL_CA_FILE" json:"ca_file,omitempty"`
+	// CertFile и KeyFile -- клиентский сертификат для mTLS
+	CertFile string `env:"ACCRUAL_CERT_FILE" json:"cert_file,omitempty"`
+	KeyFile  string `env:"ACCRUAL_KEY_FILE"  json:"key_file,omitempty"`
+	// Token отправляется в заголовке Authorization: Bearer
+	Token string `env:"ACCRUAL_TOKEN" json:"token,omitempty"`
+	// APIKey отправляется в заголовке APIKeyHeader, по умолчанию X-API-Key
+	APIKey       string `env:"ACCRUAL_API_KEY"        json:"api_key,omitempty"`
+	APIKeyHeader string `env:"ACCRUAL_API_KEY_HEADER" json:"api_key_header,omitempty"`
+	// Proxy -- URL прокси; пусто -- HTTP_PROXY/HTTPS_PROXY из окружения
+	Proxy string `env:"ACCRUAL_PROXY" json:"proxy,omitempty"`
+}
+
+// AccrualProvider -- дополнительная система расчёта баллов партнёра.
+// Заказы, не подошедшие ни под одного провайдера, уходят в ACCRUAL_SYSTEM_ADDRESS.
+type AccrualProvider struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	AccrualClient
+	// заказ достаётся провайдеру, если номер начинается с одного из Prefixes
+	// и его длина -- одна из Lengths; пустой список не ограничивает
+	Prefixes []string `json:"prefixes,omitempty"`
+	Lengths  []int    `json:"lengths,omitempty"`
+	// RPM -- стартовый бюджет запросов, пока accrual сам не сообщит лимит в 429
+	RPM uint64 `json:"rpm,omitempty"`
+}
+
+// DefaultAccrualProvider -- имя провайдера по ACCRUAL_SYSTEM_ADDRESS.
+const DefaultAccrualProvider = "default"
+
+// AccrualProviders задаётся JSON-массивом, например
+// [{"name":"partner","address":"https://partner:8080","token":"...","prefixes":["9"],"rpm":600}].
+type AccrualProviders []AccrualProvider
+
+func (p *AccrualProviders) UnmarshalText(text []byte) error {
+	var providers []AccrualProvider
+	if err := json.Unmarshal(text, &providers); err != nil {
+		return fmt.Errorf("failed to parse accrual providers: %w", err)
+	}
+	names := make(map[string]struct{}, len(providers))
+	for _, provider := range providers {
+		if provider.Name == "" || provider.Address == "" {
+			return errors.New("accrual provider needs a name and an address")
+		}
+		if provider.Name == DefaultAccrualProvider {
+			return fmt.Errorf("accrual provider name %q is reserved", provider.Name)
+		}
+		if len(provider.Prefixes) == 0 && len(provider.Lengths) == 0 {
+			return fmt.Errorf("accrual provider %q has no routing rules", provider.Name)
+		}
+		if _, ok := names[provider.Name]; ok {
+			return fmt.Errorf("duplicate accrual provider %q", provider.Name)
+		}
+		names[provider.Name] = struct{}{}
+	}
+	*p = providers
+	return nil
+}
+
+func (p AccrualProviders) MarshalText() ([]byte, error) {
+	if p == nil {
+		return []byte{}, nil
+	}
+	text, err := json.Marshal([]AccrualProvider(p))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode accrual providers: %w", err)
+	}
+	return text, nil
+}
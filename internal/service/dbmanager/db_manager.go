@@ -53,6 +53,15 @@ func (m *DBManager) GetPool(ctx context.Context) (*pgxpool.Pool, error) {
 	return p, nil
 }
 
+// Stat возвращает статистику пула или nil, если пул ещё не создан.
+func (m *DBManager) Stat() *pgxpool.Stat {
+	p, ok := m.pool.(*pgxpool.Pool)
+	if !ok {
+		return nil
+	}
+	return p.Stat()
+}
+
 func (m *DBManager) Connect(ctx context.Context) *DBManager {
 	if m.err != nil {
 		return m
@@ -71,7 +80,7 @@ func (m *DBManager) Connect(ctx context.Context) *DBManager {
 	}
 	cfg.MinConns = 1
 	cfg.MaxConns = 10
-	cfg.ConnConfig.Tracer = &queryTracer{m.log}
+	cfg.ConnConfig.Tracer = newQueryTracer(m.log)
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
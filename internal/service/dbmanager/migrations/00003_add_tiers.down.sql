@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+    DROP TABLE user_tiers;
+    DROP TABLE tiers;
+    ALTER TABLE accrued_orders DROP COLUMN processed_at;
+    ALTER TABLE accrued_orders DROP COLUMN raw_amount;
+
+COMMIT;
@@ -0,0 +1,42 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrued_orders ADD COLUMN raw_amount DECIMAL(12, 2) DEFAULT 0.0;
+    ALTER TABLE accrued_orders ADD COLUMN processed_at timestamp with time zone;
+
+    CREATE TABLE tiers(
+        id_tier INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+        name_tier VARCHAR(30) NOT NULL,
+        min_points DECIMAL(12, 2) NOT NULL,
+        multiplier DECIMAL(4, 2) NOT NULL);
+
+    CREATE TABLE user_tiers(
+        id_user_tier INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+        id_user TEXT REFERENCES user_hashes(id_user) NOT NULL,
+        id_tier INT REFERENCES tiers(id_tier) NOT NULL,
+        rolling_points DECIMAL(12, 2) NOT NULL,
+        assigned_at timestamp with time zone NOT NULL);
+
+ALTER TABLE accrued_orders ADD CONSTRAINT non_negative_raw_amount CHECK (raw_amount::numeric >= 0);
+
+ALTER TABLE tiers ADD CONSTRAINT unique_name_tier UNIQUE (name_tier);
+ALTER TABLE tiers ADD CONSTRAINT unique_min_points UNIQUE (min_points);
+ALTER TABLE tiers ADD CONSTRAINT non_negative_min_points CHECK (min_points::numeric >= 0);
+ALTER TABLE tiers ADD CONSTRAINT positive_multiplier CHECK (multiplier::numeric > 0);
+
+CREATE INDEX idx_user_tiers_user_assigned ON user_tiers (id_user, assigned_at DESC);
+
+    INSERT INTO tiers(name_tier, min_points, multiplier)
+    VALUES
+        ('bronze', 0, 1.00),
+        ('silver', 1000, 1.10),
+        ('gold', 5000, 1.25);
+
+    UPDATE accrued_orders
+    SET raw_amount = amount,
+        processed_at = uploaded_at
+    WHERE id_status = (
+        SELECT id_status
+        FROM statuses
+        WHERE name_status = 'PROCESSED');
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+    DROP TABLE bonus_credits;
+    DROP TABLE campaigns;
+    ALTER TABLE user_hashes DROP COLUMN registered_at;
+
+COMMIT;
@@ -0,0 +1,38 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE user_hashes ADD COLUMN registered_at timestamp with time zone NOT NULL DEFAULT now();
+
+    CREATE TABLE campaigns(
+        id_campaign INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+        name_campaign VARCHAR(100) NOT NULL,
+        starts_at timestamp with time zone NOT NULL,
+        ends_at timestamp with time zone NOT NULL,
+        first_order_only BOOLEAN NOT NULL DEFAULT false,
+        id_min_tier INT REFERENCES tiers(id_tier),
+        registered_after timestamp with time zone,
+        registered_before timestamp with time zone,
+        bonus_type VARCHAR(20) NOT NULL,
+        bonus_value DECIMAL(12, 2) NOT NULL,
+        archived_at timestamp with time zone);
+
+    CREATE TABLE bonus_credits(
+        id_bonus_credit INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+        id_user TEXT REFERENCES user_hashes(id_user) NOT NULL,
+        name_order VARCHAR(36) REFERENCES accrued_orders(name_order) NOT NULL,
+        id_campaign INT REFERENCES campaigns(id_campaign) NOT NULL,
+        amount DECIMAL(12, 2) NOT NULL,
+        credited_at timestamp with time zone NOT NULL);
+
+ALTER TABLE campaigns ADD CONSTRAINT check_name_campaign_not_empty
+    CHECK (length(trim(name_campaign)) > 0);
+ALTER TABLE campaigns ADD CONSTRAINT check_campaign_window CHECK (starts_at < ends_at);
+ALTER TABLE campaigns ADD CONSTRAINT check_bonus_type CHECK (bonus_type IN ('multiplier', 'fixed'));
+ALTER TABLE campaigns ADD CONSTRAINT positive_bonus_value CHECK (bonus_value::numeric > 0);
+
+ALTER TABLE bonus_credits ADD CONSTRAINT unique_order_campaign UNIQUE (name_order, id_campaign);
+ALTER TABLE bonus_credits ADD CONSTRAINT non_negative_bonus_amount CHECK (amount::numeric >= 0);
+
+CREATE INDEX idx_campaigns_window ON campaigns (starts_at, ends_at) WHERE archived_at IS NULL;
+CREATE INDEX idx_bonus_credits_user ON bonus_credits (id_user);
+
+COMMIT;
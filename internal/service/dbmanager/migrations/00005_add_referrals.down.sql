@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+    DROP TABLE referral_credits;
+    DROP TABLE referrals;
+    ALTER TABLE user_hashes DROP COLUMN referral_code;
+
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE user_hashes ADD COLUMN referral_code VARCHAR(16);
+
+    CREATE TABLE referrals(
+        id_referral INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+        id_referrer TEXT REFERENCES user_hashes(id_user) NOT NULL,
+        id_referee TEXT REFERENCES user_hashes(id_user) NOT NULL,
+        created_at timestamp with time zone NOT NULL DEFAULT now(),
+        name_order VARCHAR(36) REFERENCES accrued_orders(name_order),
+        rewarded_at timestamp with time zone);
+
+    CREATE TABLE referral_credits(
+        id_referral_credit INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+        id_referral INT REFERENCES referrals(id_referral) NOT NULL,
+        id_user TEXT REFERENCES user_hashes(id_user) NOT NULL,
+        amount DECIMAL(12, 2) NOT NULL,
+        credited_at timestamp with time zone NOT NULL);
+
+UPDATE user_hashes SET referral_code = upper(substr(md5(id_user), 1, 8));
+
+ALTER TABLE user_hashes ADD CONSTRAINT unique_referral_code UNIQUE (referral_code);
+
+ALTER TABLE referrals ADD CONSTRAINT unique_referee UNIQUE (id_referee);
+ALTER TABLE referrals ADD CONSTRAINT check_no_self_referral CHECK (id_referrer <> id_referee);
+
+ALTER TABLE referral_credits ADD CONSTRAINT unique_referral_user UNIQUE (id_referral, id_user);
+ALTER TABLE referral_credits ADD CONSTRAINT non_negative_bonus_amount CHECK (amount::numeric >= 0);
+
+CREATE INDEX idx_referrals_referrer ON referrals (id_referrer);
+CREATE INDEX idx_referral_credits_user ON referral_credits (id_user);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+    DROP TRIGGER balance_adjustments_immutable ON balance_adjustments;
+    DROP FUNCTION forbid_balance_adjustment_change();
+    DROP TABLE balance_adjustments;
+
+COMMIT;
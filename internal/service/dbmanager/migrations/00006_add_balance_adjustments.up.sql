@@ -0,0 +1,31 @@
+BEGIN TRANSACTION;
+
+    CREATE TABLE balance_adjustments(
+        id_adjustment INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+        id_user TEXT REFERENCES user_hashes(id_user) NOT NULL,
+        id_admin TEXT REFERENCES user_hashes(id_user) NOT NULL,
+        direction VARCHAR(10) NOT NULL,
+        amount DECIMAL(12, 2) NOT NULL,
+        reason_code VARCHAR(30) NOT NULL,
+        comment TEXT NOT NULL,
+        forced BOOLEAN NOT NULL DEFAULT false,
+        created_at timestamp with time zone NOT NULL DEFAULT now());
+
+ALTER TABLE balance_adjustments ADD CONSTRAINT check_direction CHECK (direction IN ('credit', 'debit'));
+ALTER TABLE balance_adjustments ADD CONSTRAINT positive_adjustment_amount CHECK (amount::numeric > 0);
+ALTER TABLE balance_adjustments ADD CONSTRAINT check_comment_not_empty CHECK (length(trim(comment)) > 0);
+
+CREATE INDEX idx_balance_adjustments_user ON balance_adjustments (id_user);
+
+-- журнал корректировок только дописывается
+CREATE FUNCTION forbid_balance_adjustment_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'balance adjustments are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER balance_adjustments_immutable
+    BEFORE UPDATE OR DELETE ON balance_adjustments
+    FOR EACH ROW EXECUTE FUNCTION forbid_balance_adjustment_change();
+
+COMMIT;
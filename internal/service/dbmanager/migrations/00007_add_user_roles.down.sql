@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE user_hashes DROP COLUMN role;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE user_hashes ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE user_hashes ADD CONSTRAINT check_user_role CHECK (role IN ('user', 'support', 'admin'));
+
+COMMIT;
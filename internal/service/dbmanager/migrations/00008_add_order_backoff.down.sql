@@ -0,0 +1,15 @@
+BEGIN TRANSACTION;
+
+    UPDATE accrued_orders
+    SET id_status = (SELECT id_status FROM statuses WHERE name_status = 'PROCESSING')
+    WHERE id_status = (SELECT id_status FROM statuses WHERE name_status = 'REVIEW');
+
+    DELETE FROM statuses WHERE name_status = 'REVIEW';
+
+    DROP INDEX idx_accrued_orders_next_check_at;
+
+    ALTER TABLE accrued_orders
+        DROP COLUMN attempts,
+        DROP COLUMN next_check_at;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrued_orders
+        ADD COLUMN next_check_at timestamp with time zone NOT NULL DEFAULT now(),
+        ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+
+    ALTER TABLE accrued_orders ADD CONSTRAINT non_negative_attempts CHECK (attempts >= 0);
+
+    CREATE INDEX idx_accrued_orders_next_check_at ON accrued_orders(next_check_at);
+
+    INSERT INTO statuses(name_status) VALUES ('REVIEW');
+
+COMMIT;
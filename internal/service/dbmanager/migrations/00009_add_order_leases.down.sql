@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrued_orders
+        DROP CONSTRAINT check_lease_complete,
+        DROP COLUMN lease_until,
+        DROP COLUMN leased_by;
+
+COMMIT;
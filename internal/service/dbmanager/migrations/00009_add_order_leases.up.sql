@@ -0,0 +1,10 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrued_orders
+        ADD COLUMN leased_by TEXT,
+        ADD COLUMN lease_until timestamp with time zone;
+
+    ALTER TABLE accrued_orders ADD CONSTRAINT check_lease_complete
+        CHECK ((leased_by IS NULL) = (lease_until IS NULL));
+
+COMMIT;
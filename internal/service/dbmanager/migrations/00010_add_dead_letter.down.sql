@@ -0,0 +1,15 @@
+BEGIN TRANSACTION;
+
+    UPDATE accrued_orders
+    SET id_status = (SELECT id_status FROM statuses WHERE name_status = 'PROCESSING')
+    WHERE id_status = (SELECT id_status FROM statuses WHERE name_status = 'DEAD_LETTER');
+
+    DELETE FROM statuses WHERE name_status = 'DEAD_LETTER';
+
+    ALTER TABLE accrued_orders
+        DROP CONSTRAINT non_negative_failures,
+        DROP COLUMN dead_lettered_at,
+        DROP COLUMN last_error,
+        DROP COLUMN failures;
+
+COMMIT;
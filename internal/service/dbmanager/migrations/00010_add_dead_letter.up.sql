@@ -0,0 +1,12 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrued_orders
+        ADD COLUMN failures INT NOT NULL DEFAULT 0,
+        ADD COLUMN last_error TEXT,
+        ADD COLUMN dead_lettered_at timestamp with time zone;
+
+    ALTER TABLE accrued_orders ADD CONSTRAINT non_negative_failures CHECK (failures >= 0);
+
+    INSERT INTO statuses(name_status) VALUES ('DEAD_LETTER');
+
+COMMIT;
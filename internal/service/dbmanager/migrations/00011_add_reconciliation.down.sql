@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+    DROP TABLE accrual_discrepancies;
+
+    ALTER TABLE accrued_orders DROP COLUMN reconciled_at;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrued_orders ADD COLUMN reconciled_at timestamp with time zone;
+
+    CREATE TABLE accrual_discrepancies(
+        id_discrepancy INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+        name_order VARCHAR(36) REFERENCES accrued_orders(name_order) NOT NULL,
+        id_user TEXT REFERENCES user_hashes(id_user) NOT NULL,
+        stored_status VARCHAR(30) NOT NULL,
+        stored_raw_amount DECIMAL(12, 2) NOT NULL,
+        stored_amount DECIMAL(12, 2) NOT NULL,
+        accrual_status VARCHAR(30) NOT NULL,
+        accrual_amount DECIMAL(12, 2) NOT NULL,
+        id_adjustment INT REFERENCES balance_adjustments(id_adjustment),
+        found_at timestamp with time zone NOT NULL DEFAULT now());
+
+    CREATE INDEX idx_accrual_discrepancies_order ON accrual_discrepancies (name_order, found_at DESC);
+
+COMMIT;
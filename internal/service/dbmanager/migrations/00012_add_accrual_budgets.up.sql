@@ -0,0 +1,10 @@
+BEGIN TRANSACTION;
+
+    CREATE TABLE accrual_budgets(
+        name_provider TEXT PRIMARY KEY,
+        rate_rpm BIGINT NOT NULL DEFAULT 0,
+        tokens DOUBLE PRECISION NOT NULL DEFAULT 0,
+        refilled_at timestamp with time zone NOT NULL DEFAULT now(),
+        paused_until timestamp with time zone);
+
+COMMIT;
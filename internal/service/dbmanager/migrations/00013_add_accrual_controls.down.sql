@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+    DROP TABLE accrual_replicas;
+    DROP TABLE accrual_controls;
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN TRANSACTION;
+
+    CREATE TABLE accrual_controls(
+        name_provider TEXT PRIMARY KEY,
+        held BOOLEAN NOT NULL DEFAULT false,
+        max_requests BIGINT NOT NULL DEFAULT 0);
+
+    CREATE TABLE accrual_replicas(
+        id_instance TEXT NOT NULL,
+        name_provider TEXT NOT NULL,
+        paused BOOLEAN NOT NULL,
+        max_requests BIGINT NOT NULL,
+        rate_limit_rpm BIGINT NOT NULL,
+        rpm BIGINT NOT NULL,
+        in_flight BIGINT NOT NULL,
+        workers INTEGER NOT NULL,
+        throttled BOOLEAN NOT NULL,
+        retry_after timestamp with time zone,
+        reported_at timestamp with time zone NOT NULL DEFAULT now(),
+        PRIMARY KEY (id_instance, name_provider));
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrual_budgets
+        DROP COLUMN changed_at,
+        DROP COLUMN ceiling_rpm;
+
+COMMIT;
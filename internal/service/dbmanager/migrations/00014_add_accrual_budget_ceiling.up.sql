@@ -0,0 +1,10 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrual_budgets
+        ADD COLUMN ceiling_rpm BIGINT NOT NULL DEFAULT 0,
+        ADD COLUMN changed_at timestamp with time zone NOT NULL DEFAULT now();
+
+    UPDATE accrual_budgets
+    SET ceiling_rpm = rate_rpm;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+    DROP INDEX idx_accrued_orders_hooks_due_at;
+
+    ALTER TABLE accrued_orders
+        DROP COLUMN hooks_due_at;
+
+COMMIT;
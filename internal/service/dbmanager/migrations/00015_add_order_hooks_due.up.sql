@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE accrued_orders
+        ADD COLUMN hooks_due_at timestamp with time zone;
+
+    CREATE INDEX idx_accrued_orders_hooks_due_at ON accrued_orders(hooks_due_at)
+        WHERE hooks_due_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN TRANSACTION;
+
+    UPDATE user_hashes
+    SET role = 'admin'
+    WHERE role = 'superadmin';
+
+    ALTER TABLE user_hashes DROP CONSTRAINT check_user_role;
+
+    ALTER TABLE user_hashes
+        ADD CONSTRAINT check_user_role CHECK (role IN ('user', 'support', 'admin'));
+
+COMMIT;
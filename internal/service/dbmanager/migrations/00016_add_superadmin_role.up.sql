@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+    ALTER TABLE user_hashes DROP CONSTRAINT check_user_role;
+
+    ALTER TABLE user_hashes
+        ADD CONSTRAINT check_user_role CHECK (role IN ('user', 'support', 'admin', 'superadmin'));
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+    DROP TABLE accrual_callback_replays;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+    CREATE TABLE accrual_callback_replays(
+        signature TEXT PRIMARY KEY,
+        expires_at timestamp with time zone NOT NULL);
+
+    CREATE INDEX idx_accrual_callback_replays_expires_at ON accrual_callback_replays(expires_at);
+
+COMMIT;
@@ -3,12 +3,27 @@ package dbmanager
 import (
 	"context"
 	"log/slog"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/talx-hub/gopher-bonus/internal/service/dbmanager"
+
 type queryTracer struct {
-	log *slog.Logger
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func newQueryTracer(log *slog.Logger) *queryTracer {
+	return &queryTracer{
+		log:    log,
+		tracer: otel.Tracer(tracerName),
+	}
 }
 
 func (t *queryTracer) TraceQueryStart(
@@ -16,6 +31,13 @@ func (t *queryTracer) TraceQueryStart(
 	_ *pgx.Conn,
 	data pgx.TraceQueryStartData,
 ) context.Context {
+	ctx, _ = t.tracer.Start(ctx, queryName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBQueryText(data.SQL),
+		),
+	)
 	t.log.LogAttrs(ctx,
 		slog.LevelDebug,
 		"Running query",
@@ -26,8 +48,23 @@ func (t *queryTracer) TraceQueryStart(
 }
 
 func (t *queryTracer) TraceQueryEnd(
-	_ context.Context,
+	ctx context.Context,
 	_ *pgx.Conn,
-	_ pgx.TraceQueryEndData,
+	data pgx.TraceQueryEndData,
 ) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
+
+// queryName берёт имя из комментария sqlc "-- name: X :one", иначе "db.query".
+func queryName(sql string) string {
+	name, ok := strings.CutPrefix(sql, "-- name: ")
+	if fields := strings.Fields(name); ok && len(fields) > 0 {
+		return fields[0]
+	}
+	return "db.query"
 }
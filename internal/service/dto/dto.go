@@ -18,4 +18,6 @@ type AccrualInfo struct {
 	Order   string      `json:"order"`
 	Status  string      `json:"status"`
 	Accrual json.Number `json:"accrual,omitempty"`
+	// Err -- причина AGENT_FAILED/CALCULATOR_FAILED, в accrual не передаётся
+	Err error `json:"-"`
 }
@@ -0,0 +1,174 @@
+package leader
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
+)
+
+// Session -- выделенное соединение, в котором держится advisory lock.
+// Lock живёт, пока живёт сессия, поэтому потеря соединения означает потерю лидерства.
+type Session interface {
+	TryLock(ctx context.Context, key int64) (bool, error)
+	Holds(ctx context.Context, key int64) (bool, error)
+	Close(ctx context.Context, key int64, broken bool)
+}
+
+type Connector interface {
+	Open(ctx context.Context) (Session, error)
+}
+
+// Job -- фоновая задача, которая должна работать ровно на одной реплике.
+// Run обязан вернуться после отмены ctx.
+type Job interface {
+	Run(ctx context.Context)
+}
+
+type JobFunc func(ctx context.Context)
+
+func (f JobFunc) Run(ctx context.Context) { f(ctx) }
+
+type Elector struct {
+	connector Connector
+	name      string
+	jobs      []Job
+	key       int64
+	interval  time.Duration
+	isLeader  atomic.Bool
+	// term растёт при каждом получении лидерства, чтобы отличать старые запуски задач
+	term atomic.Int64
+}
+
+func New(connector Connector, name string, interval time.Duration, jobs ...Job) *Elector {
+	return &Elector{
+		connector: connector,
+		name:      name,
+		jobs:      jobs,
+		key:       LockKey(name),
+		interval:  interval,
+	}
+}
+
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64()) //nolint: gosec // only bits matter for advisory lock key
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.isLeader.Load()
+}
+
+func (e *Elector) Term() int64 {
+	return e.term.Load()
+}
+
+func (e *Elector) Run(ctx context.Context) {
+	log := logger.FromContext(ctx).With("service", "leader_election", "lock", e.name)
+	if e.interval <= 0 {
+		log.LogAttrs(ctx, slog.LevelWarn, "non-positive interval, background jobs disabled")
+		return
+	}
+	log.LogAttrs(ctx, slog.LevelInfo, "running", slog.Duration("interval", e.interval))
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		e.campaign(ctx, log, ticker.C)
+
+		select {
+		case <-ctx.Done():
+			log.LogAttrs(ctx, slog.LevelInfo, "stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign пытается взять lock и, если получилось, держит лидерство,
+// пока lock подтверждается на каждом тике.
+func (e *Elector) campaign(ctx context.Context, log *slog.Logger, tick <-chan time.Time) {
+	session, err := e.connector.Open(ctx)
+	if err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelWarn,
+			"failed to open leader election session",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return
+	}
+
+	locked, err := session.TryLock(ctx, e.key)
+	if err != nil || !locked {
+		if err != nil {
+			log.LogAttrs(ctx,
+				slog.LevelWarn,
+				"failed to try advisory lock",
+				slog.Any(model.KeyLoggerError, err),
+			)
+		}
+		session.Close(context.WithoutCancel(ctx), e.key, err != nil)
+		return
+	}
+
+	term := e.term.Add(1)
+	e.isLeader.Store(true)
+	log.LogAttrs(ctx, slog.LevelInfo, "leadership acquired", slog.Int64("term", term))
+	stopJobs := e.startJobs(ctx)
+
+	broken := e.hold(ctx, log, session, tick, term)
+
+	// fencing: сначала останавливаем задачи и только потом отпускаем lock.
+	// Если соединение уже потеряно, сервер снял lock сам, и задачи
+	// могут пересечься с новым лидером не дольше одного interval.
+	stopJobs()
+	e.isLeader.Store(false)
+	session.Close(context.WithoutCancel(ctx), e.key, broken)
+	log.LogAttrs(ctx, slog.LevelInfo, "leadership released", slog.Int64("term", term))
+}
+
+// hold подтверждает lock на каждом тике. Возвращает true, если lock потерян.
+func (e *Elector) hold(ctx context.Context, log *slog.Logger,
+	session Session, tick <-chan time.Time, term int64,
+) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-tick:
+			holds, err := session.Holds(ctx, e.key)
+			if err == nil && holds {
+				continue
+			}
+			log.LogAttrs(ctx,
+				slog.LevelWarn,
+				"leadership lost",
+				slog.Int64("term", term),
+				slog.Any(model.KeyLoggerError, err),
+			)
+			return true
+		}
+	}
+}
+
+func (e *Elector) startJobs(ctx context.Context) func() {
+	jobCtx, cancel := context.WithCancel(ctx)
+	wg := &sync.WaitGroup{}
+	for _, j := range e.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j.Run(jobCtx)
+		}()
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
@@ -0,0 +1,137 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB имитирует advisory lock: держит его не больше одна сессия.
+type fakeDB struct {
+	owner  *fakeSession
+	closed []bool
+	mu     sync.Mutex
+}
+
+func (db *fakeDB) Open(context.Context) (Session, error) {
+	return &fakeSession{db: db}, nil
+}
+
+// drop имитирует обрыв соединения лидера: сервер снимает lock.
+func (db *fakeDB) drop() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.owner != nil {
+		db.owner.lost = true
+		db.owner = nil
+	}
+}
+
+func (db *fakeDB) closedBroken() bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, broken := range db.closed {
+		if broken {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeSession struct {
+	db   *fakeDB
+	lost bool
+}
+
+func (s *fakeSession) TryLock(context.Context, int64) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if s.db.owner != nil {
+		return false, nil
+	}
+	s.db.owner = s
+	return true, nil
+}
+
+func (s *fakeSession) Holds(context.Context, int64) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if s.lost {
+		return false, errors.New("connection lost")
+	}
+	return s.db.owner == s, nil
+}
+
+func (s *fakeSession) Close(_ context.Context, _ int64, broken bool) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if s.db.owner == s {
+		s.db.owner = nil
+	}
+	s.db.closed = append(s.db.closed, broken)
+}
+
+type countingJob struct {
+	running atomic.Int32
+	starts  atomic.Int32
+}
+
+func (j *countingJob) Run(ctx context.Context) {
+	j.starts.Add(1)
+	j.running.Add(1)
+	defer j.running.Add(-1)
+	<-ctx.Done()
+}
+
+func TestElector_singleLeader(t *testing.T) {
+	db := &fakeDB{}
+	job := &countingJob{}
+	const interval = 5 * time.Millisecond
+	a := New(db, "jobs", interval, job)
+	b := New(db, "jobs", interval, job)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	for _, e := range []*Elector{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.Run(ctx)
+		}()
+	}
+
+	require.Eventually(t, func() bool { return a.IsLeader() != b.IsLeader() },
+		time.Second, interval)
+	for range 10 {
+		time.Sleep(interval)
+		assert.LessOrEqual(t, job.running.Load(), int32(1))
+		assert.False(t, a.IsLeader() && b.IsLeader())
+	}
+
+	t.Run("re-election on connection loss", func(t *testing.T) {
+		db.drop()
+		require.Eventually(t, func() bool {
+			return job.starts.Load() == 2 && a.IsLeader() != b.IsLeader()
+		}, time.Second, interval)
+		assert.Equal(t, int64(2), a.Term()+b.Term())
+		assert.LessOrEqual(t, job.running.Load(), int32(1))
+		assert.True(t, db.closedBroken())
+	})
+
+	cancel()
+	wg.Wait()
+	assert.Equal(t, int32(0), job.running.Load())
+	assert.False(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+}
+
+func TestLockKey(t *testing.T) {
+	assert.Equal(t, LockKey("jobs"), LockKey("jobs"))
+	assert.NotEqual(t, LockKey("jobs"), LockKey("reports"))
+}
@@ -0,0 +1,68 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PGConnector struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGConnector(pool *pgxpool.Pool) *PGConnector {
+	return &PGConnector{pool: pool}
+}
+
+func (c *PGConnector) Open(ctx context.Context) (Session, error) {
+	conn, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	return &pgSession{conn: conn}, nil
+}
+
+type pgSession struct {
+	conn *pgxpool.Conn
+}
+
+func (s *pgSession) TryLock(ctx context.Context, key int64) (bool, error) {
+	var locked bool
+	if err := s.conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		return false, fmt.Errorf("pg_try_advisory_lock failed: %w", err)
+	}
+	return locked, nil
+}
+
+// Holds проверяет, что сессия жива и lock всё ещё за ней.
+func (s *pgSession) Holds(ctx context.Context, key int64) (bool, error) {
+	const query = `
+SELECT EXISTS (
+    SELECT 1 FROM pg_locks
+    WHERE locktype = 'advisory'
+      AND pid = pg_backend_pid()
+      AND granted
+      AND objsubid = 1
+      AND classid = (($1::bigint >> 32) & 4294967295)::oid
+      AND objid = ($1::bigint & 4294967295)::oid
+)`
+	var holds bool
+	if err := s.conn.QueryRow(ctx, query, key).Scan(&holds); err != nil {
+		return false, fmt.Errorf("failed to check advisory lock: %w", err)
+	}
+	return holds, nil
+}
+
+func (s *pgSession) Close(ctx context.Context, key int64, broken bool) {
+	if !broken {
+		if _, err := s.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", key); err == nil {
+			s.conn.Release()
+			return
+		}
+	}
+	// соединение в непонятном состоянии: закрываем его, и lock снимется вместе с сессией,
+	// а пул при Release выбросит закрытое соединение
+	_ = s.conn.Conn().Close(ctx)
+	s.conn.Release()
+}
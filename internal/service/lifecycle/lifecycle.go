@@ -0,0 +1,173 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
+)
+
+// DefaultStopTimeout -- сколько ждать остановки компонента, если StopTimeout не задан.
+const DefaultStopTimeout = 10 * time.Second
+
+// Component -- часть сервиса под управлением Supervisor.
+type Component struct {
+	// Start запускает компонент и не блокируется; ошибка отменяет запуск следующих компонентов
+	Start func(ctx context.Context) error
+	// Stop останавливает компонент, дав доделать начатое, но не дольше ctx
+	Stop func(ctx context.Context) error
+	Name string
+	// StopTimeout -- сколько ждать Stop, 0 -- DefaultStopTimeout
+	StopTimeout time.Duration
+}
+
+// Supervisor запускает компоненты в порядке добавления и останавливает в обратном,
+// когда отменён ctx Run или компонент сообщил об ошибке через Fail.
+type Supervisor struct {
+	log        *slog.Logger
+	failed     chan error
+	components []Component
+}
+
+func New(log *slog.Logger) *Supervisor {
+	return &Supervisor{
+		log:    log,
+		failed: make(chan error, 1),
+	}
+}
+
+func (s *Supervisor) Add(c Component) *Supervisor {
+	s.components = append(s.components, c)
+	return s
+}
+
+// Go добавляет компонент, работающий в горутине. run должен вернуться после отмены ctx,
+// доделав начатую работу; Stop ждёт этого не дольше stopTimeout.
+func (s *Supervisor) Go(name string, stopTimeout time.Duration, run func(ctx context.Context)) *Supervisor {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	return s.Add(Component{
+		Name:        name,
+		StopTimeout: stopTimeout,
+		Start: func(ctx context.Context) error {
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(ctx)
+			go func() {
+				defer close(done)
+				run(runCtx)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err() //nolint: wrapcheck // deadline of the stop
+			}
+		},
+	})
+}
+
+// Serve добавляет HTTP-сервер. Адрес занимается сразу в Start, а остановка
+// через http.Server.Shutdown дожидается уже принятых запросов.
+func (s *Supervisor) Serve(srv *http.Server, stopTimeout time.Duration) *Supervisor {
+	return s.Add(Component{
+		Name:        "http server",
+		StopTimeout: stopTimeout,
+		Start: func(ctx context.Context) error {
+			ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", srv.Addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen %s: %w", srv.Addr, err)
+			}
+			s.log.LogAttrs(ctx, slog.LevelInfo, "starting server.....", slog.String("addr", srv.Addr))
+			go func() {
+				if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+					s.Fail(fmt.Errorf("http server failed: %w", err))
+				}
+			}()
+			return nil
+		},
+		Stop: srv.Shutdown,
+	})
+}
+
+// OnStop добавляет действие, которое выполняется при остановке, например закрытие пула БД.
+// Добавленное раньше выполняется позже.
+func (s *Supervisor) OnStop(name string, stop func(ctx context.Context) error) *Supervisor {
+	return s.Add(Component{
+		Name: name,
+		Stop: stop,
+	})
+}
+
+// Fail останавливает сервис из-за ошибки компонента. Run вернёт первую такую ошибку.
+func (s *Supervisor) Fail(err error) {
+	select {
+	case s.failed <- err:
+	default:
+	}
+}
+
+// Run запускает компоненты и блокируется до отмены ctx или Fail, после чего останавливает
+// запущенные компоненты. Компоненты получают ctx без отмены и с логгером Supervisor:
+// их останавливает Stop.
+func (s *Supervisor) Run(ctx context.Context) error {
+	runCtx := logger.WithContext(context.WithoutCancel(ctx), s.log)
+	started := 0
+	var err error
+	for _, c := range s.components {
+		if c.Start != nil {
+			if err = c.Start(runCtx); err != nil {
+				err = fmt.Errorf("failed to start %s: %w", c.Name, err)
+				break
+			}
+			s.log.LogAttrs(ctx, slog.LevelInfo, "component started", slog.String("component", c.Name))
+		}
+		started++
+	}
+
+	if err == nil {
+		select {
+		case <-ctx.Done():
+			s.log.LogAttrs(runCtx, slog.LevelInfo, "stop signal received, shutting down")
+		case err = <-s.failed:
+		}
+	}
+	if err != nil {
+		s.log.LogAttrs(runCtx, slog.LevelError, "shutting down", slog.Any(model.KeyLoggerError, err))
+	}
+	return errors.Join(err, s.stop(runCtx, started))
+}
+
+func (s *Supervisor) stop(ctx context.Context, started int) error {
+	var errs []error
+	for i := started - 1; i >= 0; i-- {
+		c := s.components[i]
+		if c.Stop == nil {
+			continue
+		}
+		timeout := c.StopTimeout
+		if timeout <= 0 {
+			timeout = DefaultStopTimeout
+		}
+		stopCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := c.Stop(stopCtx)
+		cancel()
+		if err != nil {
+			err = fmt.Errorf("failed to stop %s: %w", c.Name, err)
+			s.log.LogAttrs(ctx, slog.LevelError, "component not stopped", slog.Any(model.KeyLoggerError, err))
+			errs = append(errs, err)
+			continue
+		}
+		s.log.LogAttrs(ctx, slog.LevelInfo, "component stopped", slog.String("component", c.Name))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,152 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type journal struct {
+	events []string
+	mu     sync.Mutex
+}
+
+func (j *journal) add(event string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.events = append(j.events, event)
+}
+
+func (j *journal) component(name string, startErr error) Component {
+	return Component{
+		Name: name,
+		Start: func(context.Context) error {
+			j.add("start " + name)
+			return startErr
+		},
+		Stop: func(context.Context) error {
+			j.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestSupervisor_Run_order(t *testing.T) {
+	j := &journal{}
+	s := New(discard()).
+		OnStop("db", func(context.Context) error {
+			j.add("close db")
+			return nil
+		}).
+		Add(j.component("agent", nil)).
+		Add(j.component("http", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, s.Run(ctx))
+	assert.Equal(t, []string{"start agent", "start http", "stop http", "stop agent", "close db"}, j.events)
+}
+
+func TestSupervisor_Run_startFailure(t *testing.T) {
+	j := &journal{}
+	failure := errors.New("port is busy")
+	s := New(discard()).
+		Add(j.component("agent", nil)).
+		Add(j.component("http", failure)).
+		Add(j.component("never", nil))
+
+	err := s.Run(context.Background())
+	require.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"start agent", "start http", "stop agent"}, j.events)
+}
+
+func TestSupervisor_Run_fail(t *testing.T) {
+	j := &journal{}
+	s := New(discard()).Add(j.component("agent", nil))
+	failure := errors.New("broken")
+	time.AfterFunc(10*time.Millisecond, func() { s.Fail(failure) })
+
+	err := s.Run(context.Background())
+	require.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"start agent", "stop agent"}, j.events)
+}
+
+func TestSupervisor_Go_stopTimeout(t *testing.T) {
+	var finished bool
+	stuck := make(chan struct{})
+	defer close(stuck)
+	s := New(discard()).
+		Go("finishing", time.Second, func(ctx context.Context) {
+			<-ctx.Done()
+			finished = true
+		}).
+		Go("stuck", 10*time.Millisecond, func(context.Context) {
+			<-stuck
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := s.Run(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "failed to stop stuck")
+	// зависший компонент не мешает остановить остальные
+	assert.True(t, finished)
+}
+
+func TestSupervisor_Serve_drainsRequests(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	inFlight := make(chan struct{})
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(inFlight)
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := New(discard()).Serve(srv, time.Second)
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			_ = conn.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	respCh := make(chan error)
+	go func() {
+		var err error
+		resp, err = http.Get("http://" + addr) //nolint: noctx // test request
+		respCh <- err
+	}()
+	<-inFlight
+	cancel()
+
+	// начатый запрос доделывается, хотя остановка уже началась
+	require.NoError(t, <-respCh)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.NoError(t, <-done)
+}
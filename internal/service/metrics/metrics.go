@@ -0,0 +1,234 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+const namespace = "gophermart"
+
+// Metrics держит собственный Registry, а не глобальный prometheus.DefaultRegisterer:
+// так в тестах можно создать чистый экземпляр и проверить значения через Gather.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	accrualResponses *prometheus.CounterVec
+	accrualThrottled *prometheus.CounterVec
+	accrualCapacity  *prometheus.GaugeVec
+	accrualRateLimit *prometheus.GaugeVec
+	accrualWorkers   *prometheus.GaugeVec
+
+	ordersSkipped    prometheus.Counter
+	responsesDropped prometheus.Counter
+
+	ordersUploaded  prometheus.Counter
+	pointsAccrued   prometheus.Counter
+	pointsWithdrawn prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route pattern, method and status code.",
+		}, []string{"route", "method", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route pattern and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+
+		accrualResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "accrual",
+			Name:      "responses_total",
+			Help:      "Accrual responses by provider and status code, \"error\" when no response was received.",
+		}, []string{"provider", "code"}),
+		accrualThrottled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "accrual",
+			Name:      "throttled_total",
+			Help:      "Accrual responses with 429 Too Many Requests by provider.",
+		}, []string{"provider"}),
+		accrualCapacity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "accrual",
+			Name:      "semaphore_capacity",
+			Help:      "Current number of concurrent accrual requests allowed by provider.",
+		}, []string{"provider"}),
+		accrualRateLimit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "accrual",
+			Name:      "rate_limit_rpm",
+			Help:      "Current accrual requests per minute allowed by provider, 0 when unlimited.",
+		}, []string{"provider"}),
+		accrualWorkers: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "accrual",
+			Name:      "workers",
+			Help:      "Current number of accrual worker pool goroutines by provider.",
+		}, []string{"provider"}),
+
+		ordersSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "watcher",
+			Name:      "orders_skipped_total",
+			Help:      "Claimed orders not sent to accrual because a request for them is still in flight.",
+		}),
+		responsesDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "watcher",
+			Name:      "responses_dropped_total",
+			Help:      "Late or duplicate accrual responses dropped by the watcher.",
+		}),
+
+		ordersUploaded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_uploaded_total",
+			Help:      "Orders uploaded by users for accrual.",
+		}),
+		pointsAccrued: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "points_accrued_total",
+			Help:      "Points reported by accrual for processed orders.",
+		}),
+		pointsWithdrawn: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "points_withdrawn_total",
+			Help:      "Points withdrawn by users.",
+		}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.accrualResponses,
+		m.accrualThrottled,
+		m.accrualCapacity,
+		m.accrualRateLimit,
+		m.accrualWorkers,
+		m.ordersSkipped,
+		m.responsesDropped,
+		m.ordersUploaded,
+		m.pointsAccrued,
+		m.pointsWithdrawn,
+	)
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+func (m *Metrics) ObserveHTTPRequest(route, method string, code int, d time.Duration) {
+	m.httpRequests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(d.Seconds())
+}
+
+// ObserveAccrualResponse учитывает ответ accrual; code == 0 -- ответа не было.
+func (m *Metrics) ObserveAccrualResponse(provider string, code int) {
+	label := "error"
+	if code != 0 {
+		label = strconv.Itoa(code)
+	}
+	m.accrualResponses.WithLabelValues(provider, label).Inc()
+	if code == http.StatusTooManyRequests {
+		m.accrualThrottled.WithLabelValues(provider).Inc()
+	}
+}
+
+func (m *Metrics) SetAccrualCapacity(provider string, n uint64) {
+	m.accrualCapacity.WithLabelValues(provider).Set(float64(n))
+}
+
+func (m *Metrics) SetAccrualRateLimit(provider string, rpm uint64) {
+	m.accrualRateLimit.WithLabelValues(provider).Set(float64(rpm))
+}
+
+func (m *Metrics) SetAccrualWorkers(provider string, n int) {
+	m.accrualWorkers.WithLabelValues(provider).Set(float64(n))
+}
+
+func (m *Metrics) OrdersSkipped(n int) {
+	m.ordersSkipped.Add(float64(n))
+}
+
+func (m *Metrics) ResponseDropped() {
+	m.responsesDropped.Inc()
+}
+
+func (m *Metrics) OrderUploaded() {
+	m.ordersUploaded.Inc()
+}
+
+func (m *Metrics) PointsAccrued(a model.Amount) {
+	m.pointsAccrued.Add(points(a))
+}
+
+func (m *Metrics) PointsWithdrawn(a model.Amount) {
+	m.pointsWithdrawn.Add(points(a))
+}
+
+func points(a model.Amount) float64 {
+	const kopecksInPoint = 100
+	return float64(a.TotalKopecks()) / kopecksInPoint
+}
+
+// WatchBacklog публикует размер очереди watcher, значение читается при каждом сборе.
+func (m *Metrics) WatchBacklog(backlog func() int64) {
+	m.Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "watcher",
+		Name:      "backlog",
+		Help:      "Orders claimed by the watcher and still waiting for an accrual answer.",
+	}, func() float64 { return float64(backlog()) }))
+}
+
+// WatchLeader публикует, держит ли реплика lock и каким по счёту получением лидерства.
+func (m *Metrics) WatchLeader(lock string, isLeader func() bool, term func() int64) {
+	labels := prometheus.Labels{"lock": lock}
+	m.Registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "leader",
+			Name:        "is_leader",
+			Help:        "1 when this instance holds the leader election lock, 0 otherwise.",
+			ConstLabels: labels,
+		}, func() float64 {
+			if isLeader() {
+				return 1
+			}
+			return 0
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "leader",
+			Name:        "term",
+			Help:        "How many times this instance has acquired the leader election lock.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(term()) }),
+	)
+}
+
+// WatchPool публикует статистику pgxpool; stat может вернуть nil, пока пула нет.
+func (m *Metrics) WatchPool(stat func() *pgxpool.Stat) {
+	m.Registry.MustRegister(newPoolCollector(stat))
+}
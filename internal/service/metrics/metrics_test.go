@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+)
+
+func TestMetrics_counters(t *testing.T) {
+	m := New()
+
+	m.ObserveHTTPRequest("/api/user/orders", http.MethodPost, http.StatusAccepted, 10*time.Millisecond)
+	m.ObserveHTTPRequest("/api/user/orders", http.MethodPost, http.StatusAccepted, 20*time.Millisecond)
+	m.ObserveAccrualResponse("default", http.StatusOK)
+	m.ObserveAccrualResponse("default", http.StatusTooManyRequests)
+	m.ObserveAccrualResponse("partner", 0)
+	m.SetAccrualCapacity("default", 7)
+	m.SetAccrualRateLimit("default", 540)
+	m.SetAccrualWorkers("partner", 4)
+	m.OrdersSkipped(3)
+	m.ResponseDropped()
+	m.OrderUploaded()
+	m.PointsAccrued(model.NewAmount(500, 50))
+	m.PointsWithdrawn(model.NewAmount(100, 0))
+
+	assert.InDelta(t, 2, testutil.ToFloat64(
+		m.httpRequests.WithLabelValues("/api/user/orders", http.MethodPost, "202")), 0)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.httpDuration))
+	assert.InDelta(t, 1, testutil.ToFloat64(m.accrualResponses.WithLabelValues("default", "200")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.accrualResponses.WithLabelValues("default", "429")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.accrualResponses.WithLabelValues("partner", "error")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.accrualThrottled.WithLabelValues("default")), 0)
+	assert.InDelta(t, 7, testutil.ToFloat64(m.accrualCapacity.WithLabelValues("default")), 0)
+	assert.InDelta(t, 540, testutil.ToFloat64(m.accrualRateLimit.WithLabelValues("default")), 0)
+	assert.InDelta(t, 4, testutil.ToFloat64(m.accrualWorkers.WithLabelValues("partner")), 0)
+	assert.InDelta(t, 3, testutil.ToFloat64(m.ordersSkipped), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.responsesDropped), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.ordersUploaded), 0)
+	assert.InDelta(t, 500.5, testutil.ToFloat64(m.pointsAccrued), 1e-9)
+	assert.InDelta(t, 100, testutil.ToFloat64(m.pointsWithdrawn), 0)
+}
+
+func TestMetrics_gauges(t *testing.T) {
+	m := New()
+	var backlog int64 = 3
+	m.WatchBacklog(func() int64 { return backlog })
+	m.WatchPool(func() *pgxpool.Stat { return nil })
+
+	err := testutil.GatherAndCompare(m.Registry, strings.NewReader(`
+# HELP gophermart_watcher_backlog Orders claimed by the watcher and still waiting for an accrual answer.
+# TYPE gophermart_watcher_backlog gauge
+gophermart_watcher_backlog 3
+`), "gophermart_watcher_backlog", "gophermart_db_pool_total_conns")
+	require.NoError(t, err)
+
+	// значение читается заново при каждом сборе
+	backlog = 0
+	err = testutil.GatherAndCompare(m.Registry, strings.NewReader(`
+# HELP gophermart_watcher_backlog Orders claimed by the watcher and still waiting for an accrual answer.
+# TYPE gophermart_watcher_backlog gauge
+gophermart_watcher_backlog 0
+`), "gophermart_watcher_backlog")
+	require.NoError(t, err)
+}
+
+func TestMetrics_WatchLeader(t *testing.T) {
+	m := New()
+	var leader atomic.Bool
+	var term atomic.Int64
+	m.WatchLeader("jobs", leader.Load, term.Load)
+
+	leader.Store(true)
+	term.Store(2)
+	err := testutil.GatherAndCompare(m.Registry, strings.NewReader(`
+# HELP gophermart_leader_is_leader 1 when this instance holds the leader election lock, 0 otherwise.
+# TYPE gophermart_leader_is_leader gauge
+gophermart_leader_is_leader{lock="jobs"} 1
+# HELP gophermart_leader_term How many times this instance has acquired the leader election lock.
+# TYPE gophermart_leader_term gauge
+gophermart_leader_term{lock="jobs"} 2
+`), "gophermart_leader_is_leader", "gophermart_leader_term")
+	require.NoError(t, err)
+
+	leader.Store(false)
+	err = testutil.GatherAndCompare(m.Registry, strings.NewReader(`
+# HELP gophermart_leader_is_leader 1 when this instance holds the leader election lock, 0 otherwise.
+# TYPE gophermart_leader_is_leader gauge
+gophermart_leader_is_leader{lock="jobs"} 0
+`), "gophermart_leader_is_leader")
+	require.NoError(t, err)
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.OrderUploaded()
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "gophermart_orders_uploaded_total 1")
+	assert.Contains(t, rr.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector снимает pgxpool.Stat на каждый сбор, а не копит значения сам.
+type poolCollector struct {
+	stat func() *pgxpool.Stat
+
+	acquired      *prometheus.Desc
+	idle          *prometheus.Desc
+	constructing  *prometheus.Desc
+	total         *prometheus.Desc
+	max           *prometheus.Desc
+	acquires      *prometheus.Desc
+	acquireTime   *prometheus.Desc
+	emptyAcquires *prometheus.Desc
+	canceled      *prometheus.Desc
+}
+
+func newPoolCollector(stat func() *pgxpool.Stat) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		stat: stat,
+
+		acquired:      desc("acquired_conns", "Connections currently in use."),
+		idle:          desc("idle_conns", "Idle connections in the pool."),
+		constructing:  desc("constructing_conns", "Connections being established."),
+		total:         desc("total_conns", "All connections in the pool."),
+		max:           desc("max_conns", "Max size of the pool."),
+		acquires:      desc("acquires_total", "Successful connection acquires."),
+		acquireTime:   desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquires: desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceled:      desc("canceled_acquires_total", "Acquires canceled by the context."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stat()
+	if s == nil {
+		return
+	}
+	gauge := func(d *prometheus.Desc, v int32) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, float64(v))
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+	gauge(c.acquired, s.AcquiredConns())
+	gauge(c.idle, s.IdleConns())
+	gauge(c.constructing, s.ConstructingConns())
+	gauge(c.total, s.TotalConns())
+	gauge(c.max, s.MaxConns())
+	counter(c.acquires, float64(s.AcquireCount()))
+	counter(c.acquireTime, s.AcquireDuration().Seconds())
+	counter(c.emptyAcquires, float64(s.EmptyAcquireCount()))
+	counter(c.canceled, float64(s.CanceledAcquireCount()))
+}
@@ -0,0 +1,75 @@
+package promo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model/campaign"
+	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
+)
+
+type campaignRepo interface {
+	ListActive(ctx context.Context, at time.Time) ([]campaign.Campaign, error)
+	GetOrderFacts(ctx context.Context, orderID string) (campaign.Facts, error)
+	CreateCredit(ctx context.Context, c *campaign.Credit) (bool, error)
+}
+
+type Evaluator struct {
+	repo campaignRepo
+}
+
+func New(repo campaignRepo) *Evaluator {
+	return &Evaluator{repo: repo}
+}
+
+func (e *Evaluator) OnProcessed(ctx context.Context, orderID string) error {
+	log := logger.FromContext(ctx).With("service", "promo")
+
+	facts, err := e.repo.GetOrderFacts(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to collect campaign facts: %w", err)
+	}
+	campaigns, err := e.repo.ListActive(ctx, facts.ProcessedAt)
+	if err != nil {
+		return fmt.Errorf("failed to list active campaigns: %w", err)
+	}
+
+	// бонусы остальных кампаний записываются, даже если один не записался;
+	// при повторе записанные пропускаются
+	var errs []error
+	for i := range campaigns {
+		c := &campaigns[i]
+		if !c.IsEligible(&facts) {
+			continue
+		}
+		bonus := c.Bonus(facts.Accrual)
+		if bonus.TotalKopecks() == 0 {
+			continue
+		}
+
+		credited, err := e.repo.CreateCredit(ctx, &campaign.Credit{
+			CreditedAt: time.Now().UTC(),
+			UserID:     facts.UserID,
+			OrderID:    orderID,
+			Amount:     bonus,
+			CampaignID: c.ID,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to credit bonus of campaign %d: %w", c.ID, err))
+			continue
+		}
+		if credited {
+			log.LogAttrs(ctx,
+				slog.LevelInfo,
+				"campaign bonus credited",
+				slog.String("order_no", orderID),
+				slog.Int64("campaign_id", c.ID),
+				slog.String("amount", bonus.String()),
+			)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,212 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
+)
+
+type reconciliationRepo interface {
+	ListSettled(ctx context.Context, limit int32) ([]order.Settled, error)
+	MarkReconciled(ctx context.Context, orderID string) error
+	RecordDiscrepancy(ctx context.Context, d *order.Discrepancy, correction *adjustment.Adjustment) (bool, error)
+}
+
+type accrual interface {
+	Lookup(ctx context.Context, orderID string) (dto.AccrualInfo, error)
+}
+
+type Config struct {
+	// AdminID -- от чьего имени записываются автоматические корректировки
+	AdminID  string
+	Interval time.Duration
+	// BatchSize -- сколько заказов сверять за раз, 0 -- все
+	BatchSize int32
+	// AutoApply -- исправлять баланс корректировкой, а не только записывать расхождение
+	AutoApply bool
+}
+
+// Reconciler периодически заново запрашивает у accrual заказы PROCESSED и INVALID
+// и записывает расхождения с сохранённым результатом.
+type Reconciler struct {
+	repo    reconciliationRepo
+	accrual accrual
+	cfg     Config
+}
+
+func New(repo reconciliationRepo, accrual accrual, cfg Config) *Reconciler {
+	return &Reconciler{
+		repo:    repo,
+		accrual: accrual,
+		cfg:     cfg,
+	}
+}
+
+type summary struct {
+	checked   int
+	found     int
+	corrected int
+	skipped   int
+}
+
+func (r *Reconciler) Run(ctx context.Context) {
+	log := logger.FromContext(ctx).With("service", "reconciler")
+	if r.cfg.Interval <= 0 {
+		log.LogAttrs(ctx, slog.LevelWarn, "non-positive interval, reconciliation disabled")
+		return
+	}
+	log.LogAttrs(ctx, slog.LevelInfo, "running",
+		slog.Duration("interval", r.cfg.Interval),
+		slog.Bool("auto_apply", r.cfg.AutoApply),
+	)
+
+	r.reconcile(ctx, log)
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.LogAttrs(ctx, slog.LevelInfo, "stopped")
+			return
+		case <-ticker.C:
+			r.reconcile(ctx, log)
+		}
+	}
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, log *slog.Logger) {
+	settled, err := r.repo.ListSettled(ctx, r.cfg.BatchSize)
+	if err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to list orders for reconciliation",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return
+	}
+
+	var s summary
+	for i := range settled {
+		if ctx.Err() != nil {
+			break
+		}
+		err = r.check(ctx, log, &settled[i], &s)
+		var tmrErr *serviceerrs.TooManyRequestsError
+		if errors.As(err, &tmrErr) {
+			// сверка не срочная: не отнимаем у основного потока бюджет accrual
+			log.LogAttrs(ctx,
+				slog.LevelWarn,
+				"accrual is throttling, reconciliation postponed",
+				slog.Duration("retry_after", tmrErr.RetryAfter),
+			)
+			break
+		}
+		if err != nil {
+			s.skipped++
+			log.LogAttrs(ctx,
+				slog.LevelError,
+				"failed to reconcile order",
+				slog.String("order_no", settled[i].ID),
+				slog.Any(model.KeyLoggerError, err),
+			)
+		}
+	}
+	log.LogAttrs(ctx,
+		slog.LevelInfo,
+		"orders reconciled",
+		slog.Int("checked", s.checked),
+		slog.Int("discrepancies", s.found),
+		slog.Int("corrected", s.corrected),
+		slog.Int("skipped", s.skipped),
+	)
+}
+
+func (r *Reconciler) check(ctx context.Context, log *slog.Logger, o *order.Settled, s *summary) error {
+	info, err := r.accrual.Lookup(ctx, o.ID)
+	switch {
+	case errors.Is(err, serviceerrs.ErrNoContent):
+		info = dto.AccrualInfo{Order: o.ID, Status: string(dto.StatusCalculatorNoContent)}
+	case err != nil:
+		return fmt.Errorf("failed to query accrual: %w", err)
+	}
+
+	var amount model.Amount
+	switch dto.AccrualStatus(info.Status) {
+	case dto.StatusCalculatorProcessed:
+		if amount, err = model.FromString(string(info.Accrual)); err != nil {
+			return fmt.Errorf("failed to convert amount %q: %w", info.Accrual, err)
+		}
+	case dto.StatusCalculatorInvalid, dto.StatusCalculatorNoContent:
+		// начисления нет
+	default:
+		// accrual пересчитывает заказ: сверим, когда ответ снова станет окончательным
+		s.skipped++
+		return nil
+	}
+	s.checked++
+
+	d, found := o.Compare(order.Status(info.Status), amount)
+	if !found {
+		return r.repo.MarkReconciled(ctx, o.ID) //nolint: wrapcheck // error from wrapped function
+	}
+	correction := r.correction(&d)
+	recorded, err := r.repo.RecordDiscrepancy(ctx, &d, correction)
+	if err != nil {
+		return err //nolint: wrapcheck // error from wrapped function
+	}
+	if !recorded {
+		return nil
+	}
+	s.found++
+	attrs := []slog.Attr{
+		slog.String("order_no", o.ID),
+		slog.String("stored_status", string(o.Status)),
+		slog.String("stored_amount", o.RawAmount.String()),
+		slog.String("accrual_status", d.AccrualStatus),
+		slog.String("accrual_amount", d.AccrualAmount.String()),
+	}
+	if correction != nil {
+		s.corrected++
+		attrs = append(attrs, slog.Int64("adjustment_id", correction.ID))
+	}
+	log.LogAttrs(ctx, slog.LevelWarn, "accrual discrepancy found", attrs...)
+	return nil
+}
+
+// correction -- корректировка баланса по расхождению или nil, если исправлять нечего или не нужно.
+func (r *Reconciler) correction(d *order.Discrepancy) *adjustment.Adjustment {
+	if !r.cfg.AutoApply {
+		return nil
+	}
+	fix := d.Correction()
+	kopecks := fix.TotalKopecks()
+	if kopecks == 0 {
+		return nil
+	}
+	a := &adjustment.Adjustment{
+		UserID:    d.Stored.UserID,
+		AdminID:   r.cfg.AdminID,
+		Direction: adjustment.DirectionCredit,
+		Reason:    adjustment.ReasonCorrection,
+		Comment:   "reconciliation of order " + d.Stored.ID,
+	}
+	if kopecks < 0 {
+		// начисленное уже могли потратить: списываем без проверки остатка
+		a.Direction = adjustment.DirectionDebit
+		a.Forced = true
+		kopecks = -kopecks
+	}
+	a.Amount = model.NewAmount(0, kopecks)
+	return a
+}
@@ -0,0 +1,207 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/adjustment"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type fakeRepo struct {
+	recorded    map[string]*adjustment.Adjustment
+	settled     []order.Settled
+	reconciled  []string
+	discrepancy []order.Discrepancy
+}
+
+func (f *fakeRepo) ListSettled(_ context.Context, limit int32) ([]order.Settled, error) {
+	if limit > 0 && int(limit) < len(f.settled) {
+		return f.settled[:limit], nil
+	}
+	return f.settled, nil
+}
+
+func (f *fakeRepo) MarkReconciled(_ context.Context, orderID string) error {
+	f.reconciled = append(f.reconciled, orderID)
+	return nil
+}
+
+// RecordDiscrepancy повторяет ReconciliationRepository: расхождение, совпадающее с последним
+// по заказу, не записывается, а исправленный заказ получает ответ accrual.
+func (f *fakeRepo) RecordDiscrepancy(_ context.Context,
+	d *order.Discrepancy, correction *adjustment.Adjustment,
+) (bool, error) {
+	f.reconciled = append(f.reconciled, d.Stored.ID)
+	for i := len(f.discrepancy) - 1; i >= 0; i-- {
+		last := f.discrepancy[i]
+		if last.Stored.ID != d.Stored.ID {
+			continue
+		}
+		if last.AccrualStatus == d.AccrualStatus &&
+			last.AccrualAmount.TotalKopecks() == d.AccrualAmount.TotalKopecks() {
+			return false, nil
+		}
+		break
+	}
+	f.recorded[d.Stored.ID+d.AccrualStatus] = correction
+	f.discrepancy = append(f.discrepancy, *d)
+	if correction == nil {
+		return true, nil
+	}
+	for i := range f.settled {
+		s := &f.settled[i]
+		if s.ID != d.Stored.ID {
+			continue
+		}
+		fix := correction.Amount.TotalKopecks()
+		if correction.Direction == adjustment.DirectionDebit {
+			fix = -fix
+		}
+		s.Status = order.Status(d.AccrualStatus)
+		s.RawAmount = d.AccrualAmount
+		s.Amount = model.NewAmount(0, s.Amount.TotalKopecks()+fix)
+	}
+	return true, nil
+}
+
+type fakeAccrual map[string]dto.AccrualInfo
+
+var errBroken = errors.New("broken")
+
+func (f fakeAccrual) Lookup(_ context.Context, orderID string) (dto.AccrualInfo, error) {
+	info, ok := f[orderID]
+	if !ok {
+		return dto.AccrualInfo{}, serviceerrs.ErrNoContent
+	}
+	if info.Err != nil {
+		return dto.AccrualInfo{}, info.Err
+	}
+	return info, nil
+}
+
+func processed(id string, raw, amount int64) order.Settled {
+	return order.Settled{
+		ID:        id,
+		UserID:    "user-" + id,
+		Status:    order.StatusProcessed,
+		RawAmount: model.NewAmount(raw, 0),
+		Amount:    model.NewAmount(amount, 0),
+	}
+}
+
+func TestReconciler_reconcile(t *testing.T) {
+	repo := &fakeRepo{
+		recorded: map[string]*adjustment.Adjustment{},
+		settled: []order.Settled{
+			processed("1", 100, 110),
+			processed("2", 100, 110),
+			processed("3", 100, 110),
+			processed("4", 100, 100),
+			processed("5", 100, 100),
+			{ID: "6", UserID: "user-6", Status: order.StatusInvalid},
+		},
+	}
+	accrual := fakeAccrual{
+		"1": {Order: "1", Status: "PROCESSED", Accrual: "100"},
+		"2": {Order: "2", Status: "PROCESSED", Accrual: "120"},
+		"3": {Order: "3", Status: "INVALID"},
+		"4": {Order: "4", Status: "PROCESSING"},
+		// "5" accrual не знает
+		"6": {Err: errBroken},
+	}
+	r := New(repo, accrual, Config{AdminID: "admin", AutoApply: true})
+
+	r.reconcile(context.Background(), slog.Default())
+
+	assert.Equal(t, []string{"1", "2", "3", "5"}, repo.reconciled)
+	require.Len(t, repo.discrepancy, 3)
+	assert.Equal(t, "NO_CONTENT", repo.discrepancy[2].AccrualStatus)
+
+	raised := repo.recorded["2PROCESSED"]
+	require.NotNil(t, raised)
+	assert.Equal(t, adjustment.DirectionCredit, raised.Direction)
+	assert.Equal(t, int64(2200), raised.Amount.TotalKopecks())
+	assert.Equal(t, "user-2", raised.UserID)
+	assert.Equal(t, "admin", raised.AdminID)
+	assert.Equal(t, adjustment.ReasonCorrection, raised.Reason)
+	assert.NoError(t, raised.Validate())
+
+	cancelled := repo.recorded["3INVALID"]
+	require.NotNil(t, cancelled)
+	assert.Equal(t, adjustment.DirectionDebit, cancelled.Direction)
+	assert.True(t, cancelled.Forced)
+	assert.Equal(t, int64(11000), cancelled.Amount.TotalKopecks())
+	assert.NoError(t, cancelled.Validate())
+
+	// accrual не знает о заказе: расхождение записано, но баланс не трогаем
+	assert.Nil(t, repo.recorded["5NO_CONTENT"])
+
+	// повторная сверка не записывает те же расхождения заново
+	repo.reconciled = nil
+	r.reconcile(context.Background(), slog.Default())
+	assert.Len(t, repo.discrepancy, 3)
+}
+
+func TestReconciler_reconcile_accrualFlipFlop(t *testing.T) {
+	repo := &fakeRepo{
+		recorded: map[string]*adjustment.Adjustment{},
+		settled:  []order.Settled{processed("1", 100, 110)},
+	}
+	accrual := fakeAccrual{}
+	r := New(repo, accrual, Config{AdminID: "admin", AutoApply: true})
+
+	// accrual пересчитывает заказ туда и обратно: каждый раз баланс следует за ответом
+	for i, raw := range []string{"120", "100", "120", "100"} {
+		accrual["1"] = dto.AccrualInfo{Order: "1", Status: "PROCESSED", Accrual: json.Number(raw)}
+		r.reconcile(context.Background(), slog.Default())
+
+		require.Len(t, repo.discrepancy, i+1)
+		want, err := model.FromString(raw)
+		require.NoError(t, err)
+		assert.Equal(t, want.TotalKopecks(), repo.settled[0].RawAmount.TotalKopecks())
+		assert.Equal(t, want.TotalKopecks()*11/10, repo.settled[0].Amount.TotalKopecks())
+	}
+
+	// ответ не менялся с прошлой сверки: расхождения нет
+	repo.reconciled = nil
+	r.reconcile(context.Background(), slog.Default())
+	assert.Len(t, repo.discrepancy, 4)
+	assert.Equal(t, []string{"1"}, repo.reconciled)
+}
+
+func TestReconciler_reconcile_reportOnly(t *testing.T) {
+	repo := &fakeRepo{
+		recorded: map[string]*adjustment.Adjustment{},
+		settled:  []order.Settled{processed("1", 100, 110)},
+	}
+	accrual := fakeAccrual{"1": {Order: "1", Status: "INVALID"}}
+	New(repo, accrual, Config{}).reconcile(context.Background(), slog.Default())
+
+	require.Len(t, repo.discrepancy, 1)
+	assert.Nil(t, repo.recorded["1INVALID"])
+}
+
+func TestReconciler_reconcile_stopsOnTooManyRequests(t *testing.T) {
+	repo := &fakeRepo{
+		recorded: map[string]*adjustment.Adjustment{},
+		settled:  []order.Settled{processed("1", 100, 100), processed("2", 100, 100)},
+	}
+	accrual := fakeAccrual{
+		"1": {Err: &serviceerrs.TooManyRequestsError{RetryAfter: time.Minute}},
+		"2": {Order: "2", Status: "PROCESSED", Accrual: "100"},
+	}
+	New(repo, accrual, Config{}).reconcile(context.Background(), slog.Default())
+
+	assert.Empty(t, repo.reconciled)
+}
@@ -0,0 +1,52 @@
+package referrals
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/referral"
+	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
+)
+
+type referralRepo interface {
+	ClaimReward(ctx context.Context,
+		orderID string, referrerBonus, refereeBonus model.Amount) (*referral.Reward, error)
+}
+
+type Rewarder struct {
+	repo          referralRepo
+	referrerBonus model.Amount
+	refereeBonus  model.Amount
+}
+
+func New(repo referralRepo, referrerBonus, refereeBonus model.Amount) *Rewarder {
+	return &Rewarder{
+		repo:          repo,
+		referrerBonus: referrerBonus,
+		refereeBonus:  refereeBonus,
+	}
+}
+
+// OnProcessed начисляет бонусы за первый обработанный заказ приглашённого.
+// Ошибка оставляет заказ в ожидании повтора; повтор безопасен: награда выдаётся
+// в одной транзакции с отметкой о ней и только один раз на приглашение.
+func (r *Rewarder) OnProcessed(ctx context.Context, orderID string) error {
+	reward, err := r.repo.ClaimReward(ctx, orderID, r.referrerBonus, r.refereeBonus)
+	if err != nil {
+		return fmt.Errorf("failed to reward referral: %w", err)
+	}
+	if reward == nil {
+		return nil
+	}
+
+	logger.FromContext(ctx).With("service", "referrals").LogAttrs(ctx,
+		slog.LevelInfo,
+		"referral bonus credited",
+		slog.String("order_no", orderID),
+		slog.String("referrer_id", reward.ReferrerID),
+		slog.String("referee_id", reward.RefereeID),
+	)
+	return nil
+}
@@ -9,13 +9,17 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 
 	"github.com/talx-hub/gopher-bonus/internal/api/middlewares"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
 	"github.com/talx-hub/gopher-bonus/internal/service/config"
 )
 
 type CustomRouter struct {
-	router *chi.Mux
-	logger *slog.Logger
-	cfg    *config.Config
+	router  *chi.Mux
+	logger  *slog.Logger
+	cfg     *config.Config
+	metrics Metrics
+	roles   middlewares.RoleSource
+	replays middlewares.ReplayStore
 }
 
 func New(cfg *config.Config, log *slog.Logger) *CustomRouter {
@@ -28,6 +32,31 @@ func New(cfg *config.Config, log *slog.Logger) *CustomRouter {
 	return router
 }
 
+type Metrics interface {
+	middlewares.HTTPMetrics
+	Handler() http.Handler
+}
+
+// WithMetrics включает учёт запросов и /metrics; вызывать до SetRouter.
+func (cr *CustomRouter) WithMetrics(m Metrics) *CustomRouter {
+	cr.metrics = m
+	return cr
+}
+
+// WithRoles проверяет роль на /api/admin по хранилищу пользователей, а не по токену;
+// вызывать до SetRouter.
+func (cr *CustomRouter) WithRoles(users middlewares.RoleSource) *CustomRouter {
+	cr.roles = users
+	return cr
+}
+
+// WithReplays хранит подписи принятых callback'ов accrual в общем для реплик хранилище,
+// чтобы повтор отклоняла любая реплика; вызывать до SetRouter.
+func (cr *CustomRouter) WithReplays(replays middlewares.ReplayStore) *CustomRouter {
+	cr.replays = replays
+	return cr
+}
+
 type AuthHandler interface {
 	Register(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
@@ -39,19 +68,81 @@ type OrdersHandler interface {
 	Withdraw(w http.ResponseWriter, r *http.Request)
 	GetBalance(w http.ResponseWriter, r *http.Request)
 	GetWithdrawals(w http.ResponseWriter, r *http.Request)
+	GetBalanceHistory(w http.ResponseWriter, r *http.Request)
+}
+
+type TierHandler interface {
+	GetTier(w http.ResponseWriter, r *http.Request)
+}
+
+type ReferralHandler interface {
+	GetReferrals(w http.ResponseWriter, r *http.Request)
+}
+
+type CampaignHandler interface {
+	CreateCampaign(w http.ResponseWriter, r *http.Request)
+	ListCampaigns(w http.ResponseWriter, r *http.Request)
+	GetCampaign(w http.ResponseWriter, r *http.Request)
+	UpdateCampaign(w http.ResponseWriter, r *http.Request)
+	DeleteCampaign(w http.ResponseWriter, r *http.Request)
+}
+
+type AdjustmentHandler interface {
+	CreateAdjustment(w http.ResponseWriter, r *http.Request)
+	ListAdjustments(w http.ResponseWriter, r *http.Request)
+}
+
+type AdminHandler interface {
+	FindUser(w http.ResponseWriter, r *http.Request)
+	GetUser(w http.ResponseWriter, r *http.Request)
+	GetUserOrders(w http.ResponseWriter, r *http.Request)
+	GetUserBalance(w http.ResponseWriter, r *http.Request)
+	SetUserRole(w http.ResponseWriter, r *http.Request)
+}
+
+type DeadLetterHandler interface {
+	ListDeadLetters(w http.ResponseWriter, r *http.Request)
+	RequeueDeadLetter(w http.ResponseWriter, r *http.Request)
+	RequeueDeadLetters(w http.ResponseWriter, r *http.Request)
+}
+
+type AgentHandler interface {
+	GetAgentState(w http.ResponseWriter, r *http.Request)
+	PauseAgent(w http.ResponseWriter, r *http.Request)
+	ResumeAgent(w http.ResponseWriter, r *http.Request)
+	SetMaxRequests(w http.ResponseWriter, r *http.Request)
+	RecheckOrders(w http.ResponseWriter, r *http.Request)
 }
 
 type HealthHandler interface {
 	Ping(w http.ResponseWriter, r *http.Request)
+	Health(w http.ResponseWriter, r *http.Request)
+}
+
+type AccrualCallbackHandler interface {
+	AccrualCallback(w http.ResponseWriter, r *http.Request)
 }
 
 type Handler interface {
 	AuthHandler
 	OrdersHandler
+	TierHandler
+	ReferralHandler
+	CampaignHandler
+	AdjustmentHandler
+	AdminHandler
+	DeadLetterHandler
+	AgentHandler
 	HealthHandler
+	AccrualCallbackHandler
 }
 
 func (cr *CustomRouter) SetRouter(h Handler) {
+	cr.router.Use(middlewares.Tracing())
+	if cr.metrics != nil {
+		cr.router.Use(middlewares.Metrics(cr.metrics))
+		cr.router.Method(http.MethodGet, "/metrics", cr.metrics.Handler())
+	}
 	cr.router.Route("/api/user", func(r chi.Router) {
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.Compress(gzip.DefaultCompression))
@@ -73,16 +164,81 @@ func (cr *CustomRouter) SetRouter(h Handler) {
 
 				r.Route("/balance", func(r chi.Router) {
 					r.Get("/", h.GetBalance)
+					r.Get("/history", h.GetBalanceHistory)
 					r.Route("/withdraw", func(r chi.Router) {
 						r.With(middleware.AllowContentType("application/json")).
 							Post("/", h.Withdraw)
 					})
 				})
 				r.Get("/withdrawals", h.GetWithdrawals)
+				r.Get("/tier", h.GetTier)
+				r.Get("/referrals", h.GetReferrals)
+			})
+		})
+	})
+	cr.router.Route("/api/admin", func(r chi.Router) {
+		r.Use(middleware.Compress(gzip.DefaultCompression))
+		r.Use(middlewares.Authentication([]byte(cr.cfg.SecretKey), cr.logger))
+		if cr.roles != nil {
+			r.Use(middlewares.StoredRole(cr.roles, cr.logger))
+		}
+		r.Use(middlewares.Audit(cr.logger))
+		r.Use(middlewares.RequireRole(cr.logger, user.RoleAdmin, user.RoleSuperAdmin))
+
+		r.Route("/users", func(r chi.Router) {
+			r.Get("/", h.FindUser)
+			r.Route("/{userID}", func(r chi.Router) {
+				r.Get("/", h.GetUser)
+				r.Get("/orders", h.GetUserOrders)
+				r.Get("/balance", h.GetUserBalance)
+				r.With(middleware.AllowContentType("application/json")).
+					Put("/role", h.SetUserRole)
+
+				r.Route("/adjustments", func(r chi.Router) {
+					r.With(middleware.AllowContentType("application/json")).
+						Post("/", h.CreateAdjustment)
+					r.Get("/", h.ListAdjustments)
+				})
 			})
 		})
+
+		r.Route("/orders/dead-letter", func(r chi.Router) {
+			r.Get("/", h.ListDeadLetters)
+			r.Post("/requeue", h.RequeueDeadLetters)
+			r.Post("/{number}/requeue", h.RequeueDeadLetter)
+		})
+
+		r.Route("/accrual", func(r chi.Router) {
+			r.Get("/", h.GetAgentState)
+			r.Post("/pause", h.PauseAgent)
+			r.Post("/resume", h.ResumeAgent)
+			r.With(middleware.AllowContentType("application/json")).
+				Post("/recheck", h.RecheckOrders)
+			r.With(middleware.AllowContentType("application/json")).
+				Put("/providers/{provider}/max-requests", h.SetMaxRequests)
+		})
+
+		r.Route("/campaigns", func(r chi.Router) {
+			r.With(middleware.AllowContentType("application/json")).
+				Post("/", h.CreateCampaign)
+			r.Get("/", h.ListCampaigns)
+			r.Get("/{id}", h.GetCampaign)
+			r.With(middleware.AllowContentType("application/json")).
+				Put("/{id}", h.UpdateCampaign)
+			r.Delete("/{id}", h.DeleteCampaign)
+		})
 	})
+	// accrual присылает результаты сам только в режимах push и hybrid
+	if cr.cfg.AccrualMode.AcceptsCallbacks() {
+		cr.router.Route("/api/internal/accrual", func(r chi.Router) {
+			r.Use(middleware.AllowContentType("application/json"))
+			r.Use(middlewares.VerifyAccrualSignature([]byte(cr.cfg.AccrualCallbackSecret),
+				cr.cfg.AccrualCallbackTolerance, cr.replays, cr.logger))
+			r.Post("/callback", h.AccrualCallback)
+		})
+	}
 	cr.router.Get("/ping", h.Ping)
+	cr.router.Get("/health", h.Health)
 
 	cr.router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w,
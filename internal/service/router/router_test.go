@@ -1,15 +1,31 @@
 package router
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 
+	"github.com/talx-hub/gopher-bonus/internal/api/middlewares"
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
 	"github.com/talx-hub/gopher-bonus/internal/service/config"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
 	"github.com/talx-hub/gopher-bonus/internal/utils/auth"
 )
 
@@ -46,9 +62,84 @@ func (h) Withdraw(w http.ResponseWriter, r *http.Request) {
 func (h) GetWithdrawals(w http.ResponseWriter, r *http.Request) {
 	stubHandler{name: "get_withdrawals"}.ServeHTTP(w, r)
 }
+func (h) GetTier(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "get_tier"}.ServeHTTP(w, r)
+}
+func (h) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "get_balance_history"}.ServeHTTP(w, r)
+}
+func (h) CreateAdjustment(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "create_adjustment"}.ServeHTTP(w, r)
+}
+func (h) ListAdjustments(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "list_adjustments"}.ServeHTTP(w, r)
+}
+func (h) FindUser(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "find_user"}.ServeHTTP(w, r)
+}
+func (h) GetUser(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "get_user"}.ServeHTTP(w, r)
+}
+func (h) GetUserOrders(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "get_user_orders"}.ServeHTTP(w, r)
+}
+func (h) GetUserBalance(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "get_user_balance"}.ServeHTTP(w, r)
+}
+func (h) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "set_user_role"}.ServeHTTP(w, r)
+}
+func (h) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "list_dead_letters"}.ServeHTTP(w, r)
+}
+func (h) RequeueDeadLetter(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "requeue_dead_letter"}.ServeHTTP(w, r)
+}
+func (h) RequeueDeadLetters(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "requeue_dead_letters"}.ServeHTTP(w, r)
+}
+func (h) GetReferrals(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "get_referrals"}.ServeHTTP(w, r)
+}
+func (h) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "create_campaign"}.ServeHTTP(w, r)
+}
+func (h) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "list_campaigns"}.ServeHTTP(w, r)
+}
+func (h) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "get_campaign"}.ServeHTTP(w, r)
+}
+func (h) UpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "update_campaign"}.ServeHTTP(w, r)
+}
+func (h) DeleteCampaign(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "delete_campaign"}.ServeHTTP(w, r)
+}
+func (h) GetAgentState(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "get_agent_state"}.ServeHTTP(w, r)
+}
+func (h) PauseAgent(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "pause_agent"}.ServeHTTP(w, r)
+}
+func (h) ResumeAgent(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "resume_agent"}.ServeHTTP(w, r)
+}
+func (h) SetMaxRequests(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "set_max_requests"}.ServeHTTP(w, r)
+}
+func (h) RecheckOrders(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "recheck_orders"}.ServeHTTP(w, r)
+}
 func (h) Ping(w http.ResponseWriter, r *http.Request) {
 	stubHandler{name: "ping"}.ServeHTTP(w, r)
 }
+func (h) Health(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "health"}.ServeHTTP(w, r)
+}
+func (h) AccrualCallback(w http.ResponseWriter, r *http.Request) {
+	stubHandler{name: "accrual_callback"}.ServeHTTP(w, r)
+}
 
 func TestCustomRouter_Route_happyTests(t *testing.T) {
 	tests := []struct {
@@ -63,8 +154,32 @@ func TestCustomRouter_Route_happyTests(t *testing.T) {
 		{http.MethodPost, "/api/user/orders", "post_order", http.StatusTeapot},
 		{http.MethodGet, "/api/user/balance", "get_balance", http.StatusTeapot},
 		{http.MethodPost, "/api/user/balance/withdraw", "withdraw", http.StatusTeapot},
+		{http.MethodGet, "/api/user/balance/history", "get_balance_history", http.StatusTeapot},
 		{http.MethodGet, "/api/user/withdrawals", "get_withdrawals", http.StatusTeapot},
+		{http.MethodGet, "/api/user/tier", "get_tier", http.StatusTeapot},
+		{http.MethodGet, "/api/user/referrals", "get_referrals", http.StatusTeapot},
+		{http.MethodPost, "/api/admin/campaigns", "create_campaign", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/campaigns", "list_campaigns", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/campaigns/1", "get_campaign", http.StatusTeapot},
+		{http.MethodPut, "/api/admin/campaigns/1", "update_campaign", http.StatusTeapot},
+		{http.MethodDelete, "/api/admin/campaigns/1", "delete_campaign", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/users?login=x", "find_user", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/users/u1", "get_user", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/users/u1/orders", "get_user_orders", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/users/u1/balance", "get_user_balance", http.StatusTeapot},
+		{http.MethodPut, "/api/admin/users/u1/role", "set_user_role", http.StatusTeapot},
+		{http.MethodPost, "/api/admin/users/u1/adjustments", "create_adjustment", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/users/u1/adjustments", "list_adjustments", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/orders/dead-letter", "list_dead_letters", http.StatusTeapot},
+		{http.MethodPost, "/api/admin/orders/dead-letter/requeue", "requeue_dead_letters", http.StatusTeapot},
+		{http.MethodPost, "/api/admin/orders/dead-letter/1/requeue", "requeue_dead_letter", http.StatusTeapot},
+		{http.MethodGet, "/api/admin/accrual", "get_agent_state", http.StatusTeapot},
+		{http.MethodPost, "/api/admin/accrual/pause", "pause_agent", http.StatusTeapot},
+		{http.MethodPost, "/api/admin/accrual/resume", "resume_agent", http.StatusTeapot},
+		{http.MethodPost, "/api/admin/accrual/recheck", "recheck_orders", http.StatusTeapot},
+		{http.MethodPut, "/api/admin/accrual/providers/default/max-requests", "set_max_requests", http.StatusTeapot},
 		{http.MethodGet, "/ping", "ping", http.StatusTeapot},
+		{http.MethodGet, "/health", "health", http.StatusTeapot},
 	}
 
 	r := New(&config.Config{}, slog.Default())
@@ -75,7 +190,7 @@ func TestCustomRouter_Route_happyTests(t *testing.T) {
 	for _, tt := range tests {
 		req, err := http.NewRequest(tt.method, srv.URL+tt.path, http.NoBody)
 		require.NoError(t, err)
-		jwtCookie, err := auth.Authenticate("id", []byte(""))
+		jwtCookie, err := auth.Authenticate("id", user.RoleAdmin, []byte(""))
 		require.NoError(t, err)
 		req.AddCookie(&jwtCookie)
 
@@ -116,6 +231,12 @@ func TestCustomRouter_Route_wrong_routes(t *testing.T) {
 		{http.MethodPost, "/api/user/balance", http.StatusMethodNotAllowed},
 		{http.MethodGet, "/api/user/balance/withdraw", http.StatusMethodNotAllowed},
 		{http.MethodPost, "/api/user/withdrawals", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/user/tier", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/user/referrals", http.StatusMethodNotAllowed},
+		{http.MethodPatch, "/api/admin/campaigns/1", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/api/admin/users/u1/adjustments", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/admin/accrual/pause", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/user/balance/history", http.StatusMethodNotAllowed},
 		{http.MethodPost, "/ping?x=true", http.StatusMethodNotAllowed},
 	}
 
@@ -123,7 +244,63 @@ func TestCustomRouter_Route_wrong_routes(t *testing.T) {
 		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
 			req, err := http.NewRequest(tt.method, srv.URL+tt.path, http.NoBody)
 			require.NoError(t, err)
-			jwtCookie, err := auth.Authenticate("id", []byte(""))
+			jwtCookie, err := auth.Authenticate("id", user.RoleAdmin, []byte(""))
+			require.NoError(t, err)
+			req.AddCookie(&jwtCookie)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestCustomRouter_Route_roles(t *testing.T) {
+	r := New(&config.Config{}, slog.Default())
+	r.SetRouter(h{})
+	srv := httptest.NewServer(r.GetRouter())
+	defer srv.Close()
+
+	tests := []struct {
+		role     user.Role
+		method   string
+		path     string
+		wantCode int
+	}{
+		{user.RoleUser, http.MethodGet, "/api/admin/users/u1", http.StatusForbidden},
+		{user.RoleUser, http.MethodGet, "/api/admin/campaigns", http.StatusForbidden},
+		{user.RoleUser, http.MethodGet, "/api/user/tier", http.StatusTeapot},
+		{user.RoleSupport, http.MethodGet, "/api/admin/users/u1", http.StatusForbidden},
+		{user.RoleSupport, http.MethodGet, "/api/admin/users/u1/balance", http.StatusForbidden},
+		{user.RoleSupport, http.MethodGet, "/api/admin/users/u1/adjustments", http.StatusForbidden},
+		{user.RoleSupport, http.MethodPost, "/api/admin/users/u1/adjustments", http.StatusForbidden},
+		{user.RoleAdmin, http.MethodGet, "/api/admin/users/u1", http.StatusTeapot},
+		{user.RoleAdmin, http.MethodPost, "/api/admin/users/u1/adjustments", http.StatusTeapot},
+		{user.RoleSuperAdmin, http.MethodPost, "/api/admin/users/u1/adjustments", http.StatusTeapot},
+		{user.RoleSupport, http.MethodPut, "/api/admin/users/u1/role", http.StatusForbidden},
+		{user.RoleSupport, http.MethodGet, "/api/admin/campaigns", http.StatusForbidden},
+		{user.RoleAdmin, http.MethodPut, "/api/admin/users/u1/role", http.StatusTeapot},
+		{user.RoleAdmin, http.MethodGet, "/api/admin/campaigns", http.StatusTeapot},
+		{user.RoleUser, http.MethodGet, "/api/admin/orders/dead-letter", http.StatusForbidden},
+		{user.RoleSupport, http.MethodGet, "/api/admin/orders/dead-letter", http.StatusForbidden},
+		{user.RoleSupport, http.MethodPost, "/api/admin/orders/dead-letter/1/requeue", http.StatusForbidden},
+		{user.RoleAdmin, http.MethodGet, "/api/admin/orders/dead-letter", http.StatusTeapot},
+		{user.RoleAdmin, http.MethodPost, "/api/admin/orders/dead-letter/1/requeue", http.StatusTeapot},
+		{user.RoleUser, http.MethodGet, "/api/admin/accrual", http.StatusForbidden},
+		{user.RoleSupport, http.MethodGet, "/api/admin/accrual", http.StatusForbidden},
+		{user.RoleSupport, http.MethodPost, "/api/admin/accrual/pause", http.StatusForbidden},
+		{user.RoleAdmin, http.MethodGet, "/api/admin/accrual", http.StatusTeapot},
+		{user.RoleAdmin, http.MethodPost, "/api/admin/accrual/resume", http.StatusTeapot},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, http.NoBody)
+			require.NoError(t, err)
+			jwtCookie, err := auth.Authenticate("id", tt.role, []byte(""))
 			require.NoError(t, err)
 			req.AddCookie(&jwtCookie)
 
@@ -136,3 +313,225 @@ func TestCustomRouter_Route_wrong_routes(t *testing.T) {
 		})
 	}
 }
+
+type roleSource map[string]user.Role
+
+func (s roleSource) FindByID(_ context.Context, id string) (user.User, error) {
+	role, ok := s[id]
+	if !ok {
+		return user.User{}, serviceerrs.ErrNotFound
+	}
+	return user.User{ID: id, Role: role}, nil
+}
+
+func TestCustomRouter_Route_storedRole(t *testing.T) {
+	r := New(&config.Config{}, slog.Default()).WithRoles(roleSource{
+		"demoted":  user.RoleUser,
+		"promoted": user.RoleAdmin,
+	})
+	r.SetRouter(h{})
+	srv := httptest.NewServer(r.GetRouter())
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		userID   string
+		role     user.Role
+		wantCode int
+	}{
+		{"role revoked after login", "demoted", user.RoleAdmin, http.StatusForbidden},
+		{"role granted after login", "promoted", user.RoleUser, http.StatusTeapot},
+		{"deleted user", "deleted", user.RoleAdmin, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/admin/users/u1", http.NoBody)
+			require.NoError(t, err)
+			jwtCookie, err := auth.Authenticate(tt.userID, tt.role, []byte(""))
+			require.NoError(t, err)
+			req.AddCookie(&jwtCookie)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestCustomRouter_Route_accrualCallback(t *testing.T) {
+	secret := []byte("callback-secret")
+	body := `{"order":"12345678903","status":"PROCESSED","accrual":500}`
+	signed := func(ts time.Time, key []byte) *http.Request {
+		timestamp := strconv.FormatInt(ts.Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/api/internal/accrual/callback",
+			strings.NewReader(body))
+		req.Header.Set(model.HeaderContentType, "application/json")
+		req.Header.Set(middlewares.HeaderAccrualTimestamp, timestamp)
+		req.Header.Set(middlewares.HeaderAccrualSignature,
+			middlewares.SignAccrualCallback(key, timestamp, []byte(body)))
+		return req
+	}
+	serve := func(r *CustomRouter, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.GetRouter().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("poll mode has no callback route", func(t *testing.T) {
+		r := New(&config.Config{AccrualMode: config.AccrualModePoll}, slog.Default())
+		r.SetRouter(h{})
+		assert.Equal(t, http.StatusNotFound, serve(r, signed(time.Now(), secret)).Code)
+	})
+
+	r := New(&config.Config{
+		AccrualMode:              config.AccrualModeHybrid,
+		AccrualCallbackSecret:    string(secret),
+		AccrualCallbackTolerance: time.Minute,
+	}, slog.Default())
+	r.SetRouter(h{})
+
+	t.Run("signed", func(t *testing.T) {
+		now := time.Now()
+		rr := serve(r, signed(now, secret))
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+		assert.Equal(t, "accrual_callback", rr.Header().Get("X-Handler"))
+
+		// тот же callback второй раз -- replay
+		assert.Equal(t, http.StatusUnauthorized, serve(r, signed(now, secret)).Code)
+	})
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{"wrong secret", signed(time.Now().Add(time.Second), []byte("other"))},
+		{"stale timestamp", signed(time.Now().Add(-2*time.Minute), secret)},
+		{"timestamp from the future", signed(time.Now().Add(2*time.Minute), secret)},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/api/internal/accrual/callback",
+			strings.NewReader(body))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Header.Set(model.HeaderContentType, "application/json")
+			assert.Equal(t, http.StatusUnauthorized, serve(r, tt.req).Code)
+		})
+	}
+
+	t.Run("tampered body", func(t *testing.T) {
+		req := signed(time.Now().Add(2*time.Second), secret)
+		req.Body = io.NopCloser(strings.NewReader(strings.Replace(body, "500", "5000", 1)))
+		assert.Equal(t, http.StatusUnauthorized, serve(r, req).Code)
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		req := signed(time.Now().Add(3*time.Second), secret)
+		req.Body = io.NopCloser(strings.NewReader(strings.Repeat(" ", middlewares.MaxAccrualCallbackBody+1)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, serve(r, req).Code)
+	})
+
+	t.Run("replay to another replica", func(t *testing.T) {
+		replays := &replayStore{seen: map[string]bool{}}
+		replica := func() *CustomRouter {
+			r := New(&config.Config{
+				AccrualMode:              config.AccrualModePush,
+				AccrualCallbackSecret:    string(secret),
+				AccrualCallbackTolerance: time.Minute,
+			}, slog.Default()).WithReplays(replays)
+			r.SetRouter(h{})
+			return r
+		}
+		first, second := replica(), replica()
+
+		now := time.Now().Add(4 * time.Second)
+		assert.Equal(t, http.StatusTeapot, serve(first, signed(now, secret)).Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(second, signed(now, secret)).Code)
+
+		replays.err = errors.New("db is down")
+		assert.Equal(t, http.StatusServiceUnavailable,
+			serve(second, signed(now.Add(time.Second), secret)).Code)
+	})
+}
+
+// replayStore -- общее для реплик хранилище подписей в памяти вместо таблицы в БД.
+type replayStore struct {
+	err  error
+	seen map[string]bool
+	mu   sync.Mutex
+}
+
+func (s *replayStore) Remember(_ context.Context, signature string, _ time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, s.err
+	}
+	if s.seen[signature] {
+		return false, nil
+	}
+	s.seen[signature] = true
+	return true, nil
+}
+
+type recordedRequest struct {
+	route  string
+	method string
+	code   int
+}
+
+type fakeMetrics struct {
+	requests []recordedRequest
+}
+
+func (m *fakeMetrics) ObserveHTTPRequest(route, method string, code int, _ time.Duration) {
+	m.requests = append(m.requests, recordedRequest{route, method, code})
+}
+
+func (m *fakeMetrics) Handler() http.Handler {
+	return stubHandler{name: "metrics"}
+}
+
+func TestCustomRouter_Route_metrics(t *testing.T) {
+	m := &fakeMetrics{}
+	r := New(&config.Config{}, slog.Default()).WithMetrics(m)
+	r.SetRouter(h{})
+
+	jwtCookie, err := auth.Authenticate("id", user.RoleAdmin, []byte(""))
+	require.NoError(t, err)
+	for _, path := range []string{"/api/admin/users/u1", "/api/admin/users/u2", "/metrics", "/nowhere"} {
+		req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+		req.AddCookie(&jwtCookie)
+		r.GetRouter().ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, []recordedRequest{
+		{"/api/admin/users/{userID}", http.MethodGet, http.StatusTeapot},
+		{"/api/admin/users/{userID}", http.MethodGet, http.StatusTeapot},
+		{"/metrics", http.MethodGet, http.StatusTeapot},
+		{"unmatched", http.MethodGet, http.StatusNotFound},
+	}, m.requests)
+}
+
+func TestCustomRouter_Route_tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	r := New(&config.Config{}, slog.Default())
+	r.SetRouter(h{})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/users/u1/balance", http.NoBody)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	jwtCookie, err := auth.Authenticate("id", user.RoleAdmin, []byte(""))
+	require.NoError(t, err)
+	req.AddCookie(&jwtCookie)
+	r.GetRouter().ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /api/admin/users/{userID}/balance", spans[0].Name())
+	assert.Equal(t, traceID, spans[0].SpanContext().TraceID().String())
+	assert.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(http.StatusTeapot))
+}
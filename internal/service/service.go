@@ -4,31 +4,67 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/go-chi/chi/v5"
-
 	"github.com/talx-hub/gopher-bonus/internal/api/handlers"
 	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
 	"github.com/talx-hub/gopher-bonus/internal/repo"
 	"github.com/talx-hub/gopher-bonus/internal/service/agent"
 	"github.com/talx-hub/gopher-bonus/internal/service/config"
 	"github.com/talx-hub/gopher-bonus/internal/service/dbmanager"
 	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/service/leader"
+	"github.com/talx-hub/gopher-bonus/internal/service/lifecycle"
+	"github.com/talx-hub/gopher-bonus/internal/service/metrics"
+	"github.com/talx-hub/gopher-bonus/internal/service/promo"
+	"github.com/talx-hub/gopher-bonus/internal/service/reconcile"
+	"github.com/talx-hub/gopher-bonus/internal/service/referrals"
 	"github.com/talx-hub/gopher-bonus/internal/service/router"
+	"github.com/talx-hub/gopher-bonus/internal/service/tiercalc"
+	"github.com/talx-hub/gopher-bonus/internal/service/tracing"
 	"github.com/talx-hub/gopher-bonus/internal/service/watcher"
+	"github.com/talx-hub/gopher-bonus/internal/utils/breaker"
 	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
 )
 
-func initService(log *slog.Logger) (*chi.Mux, context.CancelFunc, string) {
+func initService(log *slog.Logger) *lifecycle.Supervisor {
 	cfg := config.NewBuilder(log).
 		FromEnv().
 		FromFlags().
 		GetConfig()
+	if err := cfg.Validate(); err != nil {
+		log.LogAttrs(context.Background(),
+			slog.LevelError,
+			"failed to start service: invalid config",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return nil
+	}
 
 	const connectTO = 2 * time.Second
 	ctx, cancel := context.WithTimeout(context.Background(), connectTO)
 	defer cancel()
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:    tracing.Exporter(cfg.TraceExporter),
+		Endpoint:    cfg.TraceEndpoint,
+		InstanceID:  cfg.InstanceID,
+		SampleRatio: cfg.TraceSampleRatio,
+	}, os.Stdout)
+	if err != nil {
+		log.LogAttrs(context.Background(),
+			slog.LevelError,
+			"failed to start service: tracing setup error",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return nil
+	}
+	log = slog.New(logger.WithTraceIDs(log.Handler()))
 	dbManager := dbmanager.New(cfg.DatabaseURI, log).
 		Connect(ctx).
 		ApplyMigrations(ctx).
@@ -39,7 +75,7 @@ func initService(log *slog.Logger) (*chi.Mux, context.CancelFunc, string) {
 			"failed to start service: db connection error",
 			slog.Any(model.KeyLoggerError, err),
 		)
-		return nil, nil, ""
+		return nil
 	}
 
 	db, err := dbManager.GetPool(ctx)
@@ -49,63 +85,265 @@ func initService(log *slog.Logger) (*chi.Mux, context.CancelFunc, string) {
 			"failed to start service: failed to get DB pool",
 			slog.Any(model.KeyLoggerError, err),
 		)
-		return nil, nil, ""
+		return nil
 	}
 
+	m := metrics.New()
+	m.WatchPool(dbManager.Stat)
+
 	usersRepo := repo.NewUserRepository(db, log)
 	orderRepo := repo.NewOrderRepository(db, log)
+	tierRepo := repo.NewTierRepository(db, log)
+	campaignRepo := repo.NewCampaignRepository(db, log)
+	referralRepo := repo.NewReferralRepository(db, log)
+	adjustmentRepo := repo.NewAdjustmentRepository(db, log)
 
-	ctx, cancel = context.WithCancel(context.Background())
-	loggerCtx := logger.WithContext(ctx, log)
+	grantRole := func(ids []string, role user.Role) {
+		for _, id := range ids {
+			if err = usersRepo.SetRole(ctx, id, role); err != nil {
+				log.LogAttrs(ctx,
+					slog.LevelWarn,
+					"failed to grant role",
+					slog.String("user_id", id),
+					slog.String("role", string(role)),
+					slog.Any(model.KeyLoggerError, err),
+				)
+			}
+		}
+	}
+	grantRole(cfg.AdminIDs, user.RoleAdmin)
+	grantRole(cfg.SuperAdminIDs, user.RoleSuperAdmin)
+
+	referrerBonus, err := model.FromString(cfg.ReferrerBonus)
+	if err != nil {
+		log.LogAttrs(context.Background(),
+			slog.LevelError,
+			"failed to start service: invalid referrer bonus",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return nil
+	}
+	refereeBonus, err := model.FromString(cfg.RefereeBonus)
+	if err != nil {
+		log.LogAttrs(context.Background(),
+			slog.LevelError,
+			"failed to start service: invalid referee bonus",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return nil
+	}
+
+	if cfg.AccrualMode.AcceptsCallbacks() && cfg.AccrualCallbackSecret == "" {
+		log.LogAttrs(context.Background(),
+			slog.LevelError,
+			"failed to start service: accrual callback secret is required",
+			slog.String("accrual_mode", string(cfg.AccrualMode)),
+		)
+		return nil
+	}
+
+	if cfg.ReconcileAutoApply && cfg.ReconcileAdminID == "" {
+		log.LogAttrs(context.Background(),
+			slog.LevelError,
+			"failed to start service: reconciliation admin ID is required to apply corrections",
+		)
+		return nil
+	}
+
+	ctx = context.Background()
+	supervisor := lifecycle.New(log).
+		OnStop("tracing", shutdownTracing).
+		OnStop("database", func(context.Context) error {
+			dbManager.Close()
+			return nil
+		})
 
 	inputCh := make(chan string)
 	outputCh := make(chan dto.AccrualInfo)
-	w := watcher.New(orderRepo, inputCh, outputCh)
-	go w.Run(loggerCtx)
+	backoff := order.NewBackoff(cfg.AccrualBackoffBase, cfg.AccrualBackoffMax, cfg.AccrualReviewHorizon)
+	backoff.MaxFailures = int32(cfg.AccrualMaxFailures) //nolint: gosec // max failures is small
+	lease := &order.Lease{
+		Owner:     cfg.InstanceID,
+		Duration:  cfg.AccrualLease,
+		BatchSize: int32(cfg.AccrualBatchSize), //nolint: gosec // batch size is small
+	}
+	if cfg.AccrualMode == config.AccrualModeHybrid {
+		lease.MinAge = cfg.AccrualPushGrace
+	}
+	w := watcher.New(orderRepo, lease, backoff, inputCh, outputCh,
+		promo.New(campaignRepo),
+		referrals.New(referralRepo, referrerBonus, refereeBonus),
+	).WithMetrics(m)
+	m.WatchBacklog(w.Backlog)
 	log.LogAttrs(ctx,
 		slog.LevelInfo,
 		"accrual addr",
 		slog.String("addr", cfg.AccrualAddr),
+		slog.String("accrual_mode", string(cfg.AccrualMode)),
+		slog.String("instance_id", cfg.InstanceID),
+	)
+	newBreaker := func(provider string) *breaker.Breaker {
+		return breaker.New(breaker.Config{
+			FailureRate:    cfg.BreakerFailureRate,
+			Window:         cfg.BreakerWindow,
+			MinRequests:    cfg.BreakerMinRequests,
+			CoolDown:       cfg.BreakerCoolDown,
+			HalfOpenProbes: cfg.BreakerProbes,
+		}, func(from, to breaker.State) {
+			level := slog.LevelInfo
+			if to == breaker.StateOpen {
+				level = slog.LevelWarn
+			}
+			log.LogAttrs(ctx, level,
+				"accrual circuit breaker state changed",
+				slog.String("provider", provider),
+				slog.String("from", string(from)),
+				slog.String("to", string(to)),
+			)
+		})
+	}
+	accrualBreaker := newBreaker(agent.DefaultProvider)
+	breakers := map[string]handlers.AccrualBreaker{agent.DefaultProvider: accrualBreaker}
+	// таймауты и пул соединений общие, авторизация и TLS -- свои у каждого провайдера
+	clientConfig := func(c config.AccrualClient) agent.ClientConfig {
+		return agent.ClientConfig{
+			Proxy:           c.Proxy,
+			CAFile:          c.CAFile,
+			CertFile:        c.CertFile,
+			KeyFile:         c.KeyFile,
+			Token:           c.Token,
+			APIKey:          c.APIKey,
+			APIKeyHeader:    c.APIKeyHeader,
+			ConnectTimeout:  cfg.AccrualConnectTimeout,
+			ResponseTimeout: cfg.AccrualResponseTimeout,
+			IdleConnTimeout: cfg.AccrualIdleConnTimeout,
+			MaxIdleConns:    cfg.AccrualMaxIdleConns,
+			MaxConnsPerHost: cfg.AccrualMaxConnsPerHost,
+		}
+	}
+	providers := make([]agent.Provider, len(cfg.AccrualProviders))
+	for i, p := range cfg.AccrualProviders {
+		b := newBreaker(p.Name)
+		breakers[p.Name] = b
+		providers[i] = agent.Provider{
+			Breaker:  b,
+			Name:     p.Name,
+			Address:  p.Address,
+			Client:   clientConfig(p.AccrualClient),
+			Prefixes: p.Prefixes,
+			Lengths:  p.Lengths,
+			RPM:      p.RPM,
+		}
+		log.LogAttrs(ctx,
+			slog.LevelInfo,
+			"accrual provider",
+			slog.String("provider", p.Name),
+			slog.String("addr", p.Address),
+		)
+	}
+	a := agent.New(inputCh, outputCh, cfg.AccrualAddr, accrualBreaker, m).
+		WithClient(clientConfig(cfg.AccrualClient)).
+		WithScaling(agent.Scaling{
+			Interval:   cfg.AccrualScaleInterval,
+			MaxLatency: cfg.AccrualScaleMaxLatency,
+			Min:        cfg.AccrualWorkersMin,
+			Max:        cfg.AccrualWorkersMax,
+			DownAfter:  cfg.AccrualScaleDownAfter,
+		}).
+		WithProviders(providers...).
+		WithControls(repo.NewAccrualControlRepository(db, log), cfg.InstanceID)
+	if cfg.AccrualSharedBudget {
+		a.WithBudget(repo.NewAccrualBudgetRepository(db, log))
+	}
+	if err = a.Validate(); err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to start service: invalid accrual client config",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return nil
+	}
+	// в режиме push опрос не нужен: результаты приходят через callback,
+	// а агент нужен только для сверки
+	if cfg.AccrualMode.Polls() {
+		// watcher возвращается, когда агент доделал начатые запросы и их ответы сохранены
+		supervisor.Go("accrual", cfg.ShutdownTimeout, func(ctx context.Context) {
+			go a.Run(ctx, model.DefaultRequestCount)
+			w.Run(ctx)
+		})
+	}
+
+	// фоновые задачи-одиночки работают только на реплике-лидере
+	const leaderLock = "gophermart-background-jobs"
+	elector := leader.New(leader.NewPGConnector(db), leaderLock,
+		cfg.LeaderCheckInterval,
+		tiercalc.New(tierRepo, cfg.TierRecalcInterval),
+		reconcile.New(repo.NewReconciliationRepository(db, log), a, reconcile.Config{
+			AdminID:   cfg.ReconcileAdminID,
+			Interval:  cfg.ReconcileInterval,
+			BatchSize: int32(cfg.ReconcileBatchSize), //nolint: gosec // batch size is small
+			AutoApply: cfg.ReconcileAutoApply,
+		}),
 	)
-	a := agent.New(inputCh, outputCh, cfg.AccrualAddr)
-	go a.Run(loggerCtx, model.DefaultRequestCount)
+	m.WatchLeader(leaderLock, elector.IsLeader, elector.Term)
 
-	rr := router.New(cfg, log)
+	rr := router.New(cfg, log).WithMetrics(m).WithRoles(usersRepo).
+		WithReplays(repo.NewCallbackReplayRepository(db, log))
 	rr.SetRouter(&struct {
 		*handlers.AuthHandler
 		*handlers.OrderHandler
+		*handlers.TierHandler
+		*handlers.ReferralHandler
+		*handlers.CampaignHandler
+		*handlers.AdjustmentHandler
+		*handlers.AdminHandler
+		*handlers.DeadLetterHandler
+		*handlers.AgentHandler
 		*handlers.HealthHandler
+		*handlers.AccrualCallbackHandler
 	}{
-		AuthHandler:   handlers.NewAuthHandler(usersRepo, log, cfg.SecretKey),
-		OrderHandler:  handlers.NewOrderHandler(usersRepo, orderRepo, log),
-		HealthHandler: handlers.NewHealthHandler(dbManager),
+		AuthHandler:            handlers.NewAuthHandler(usersRepo, log, cfg.SecretKey),
+		OrderHandler:           handlers.NewOrderHandler(usersRepo, orderRepo, log, m),
+		TierHandler:            handlers.NewTierHandler(usersRepo, tierRepo, log),
+		ReferralHandler:        handlers.NewReferralHandler(usersRepo, referralRepo, log),
+		CampaignHandler:        handlers.NewCampaignHandler(campaignRepo, log),
+		AdjustmentHandler:      handlers.NewAdjustmentHandler(adjustmentRepo, log),
+		AdminHandler:           handlers.NewAdminHandler(usersRepo, orderRepo, log),
+		DeadLetterHandler:      handlers.NewDeadLetterHandler(orderRepo, log),
+		AgentHandler:           handlers.NewAgentHandler(a, orderRepo, log),
+		HealthHandler:          handlers.NewHealthHandler(dbManager, elector, breakers, cfg.InstanceID),
+		AccrualCallbackHandler: handlers.NewAccrualCallbackHandler(w, log),
 	})
 
-	return rr.GetRouter(), cancel, cfg.RunAddr
+	const readHeaderTO = 5 * time.Second
+	return supervisor.
+		Go("leader election", 0, elector.Run).
+		Serve(&http.Server{
+			Addr:              cfg.RunAddr,
+			Handler:           rr.GetRouter(),
+			ReadHeaderTimeout: readHeaderTO,
+		}, cfg.ShutdownTimeout)
 }
 
 func RunServer() {
 	log := slog.Default()
-	mux, cancel, addr := initService(log)
-	if mux == nil {
+	supervisor := initService(log)
+	if supervisor == nil {
 		log.LogAttrs(context.TODO(),
 			slog.LevelError,
 			"failed to init service",
 		)
 		return
 	}
-	defer cancel()
 
-	log.LogAttrs(context.Background(),
-		slog.LevelInfo,
-		"starting server.....",
-		slog.String("addr", addr),
-	)
-	err := http.ListenAndServe(addr, mux)
-	if err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// повторный сигнал завершает процесс сразу, не дожидаясь остановки
+	context.AfterFunc(ctx, stop)
+	if err := supervisor.Run(ctx); err != nil {
 		log.LogAttrs(context.TODO(),
 			slog.LevelError,
-			"listen and serve error",
+			"service stopped with error",
 			slog.Any(model.KeyLoggerError, err),
 		)
 	}
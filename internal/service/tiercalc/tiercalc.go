@@ -0,0 +1,67 @@
+package tiercalc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
+)
+
+type tierRepo interface {
+	RecalculateTiers(context.Context) (int64, error)
+}
+
+type Recalculator struct {
+	tierRepo tierRepo
+	interval time.Duration
+}
+
+func New(tierRepo tierRepo, interval time.Duration) *Recalculator {
+	return &Recalculator{
+		tierRepo: tierRepo,
+		interval: interval,
+	}
+}
+
+func (r *Recalculator) Run(ctx context.Context) {
+	log := logger.FromContext(ctx).With("service", "tier_recalculator")
+	if r.interval <= 0 {
+		log.LogAttrs(ctx, slog.LevelWarn, "non-positive interval, tier recalculation disabled")
+		return
+	}
+	log.LogAttrs(ctx, slog.LevelInfo, "running", slog.Duration("interval", r.interval))
+
+	r.recalculate(ctx, log)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.LogAttrs(ctx, slog.LevelInfo, "stopped")
+			return
+		case <-ticker.C:
+			r.recalculate(ctx, log)
+		}
+	}
+}
+
+func (r *Recalculator) recalculate(ctx context.Context, log *slog.Logger) {
+	changed, err := r.tierRepo.RecalculateTiers(ctx)
+	if err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to recalculate tiers",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return
+	}
+	log.LogAttrs(ctx,
+		slog.LevelInfo,
+		"tiers recalculated",
+		slog.Int64("changed", changed),
+	)
+}
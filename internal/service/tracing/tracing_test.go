@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestSetup_stdout(t *testing.T) {
+	out := &bytes.Buffer{}
+	shutdown, err := Setup(context.Background(), Config{
+		Exporter:    ExporterStdout,
+		InstanceID:  "replica-1",
+		SampleRatio: 1,
+	}, out)
+	require.NoError(t, err)
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "GET /api/user/balance")
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	assert.Contains(t, carrier.Get("traceparent"), span.SpanContext().TraceID().String())
+	assert.Contains(t, out.String(), `"Name":"GET /api/user/balance"`)
+	assert.Contains(t, out.String(), "replica-1")
+}
+
+func TestSetup_exporters(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone}, nil)
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), Config{Exporter: "jaeger"}, nil)
+	require.Error(t, err)
+}
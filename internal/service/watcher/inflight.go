@@ -0,0 +1,80 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+)
+
+// inflight -- заказы, отданные агенту и ещё не получившие ответа.
+// Так у каждого заказа не больше одного запроса к accrual, даже если тики накладываются.
+type inflight struct {
+	orders map[string]time.Time
+	// ttl -- после этого запрос считается потерянным и заказ можно отдать агенту снова
+	ttl time.Duration
+	mu  sync.Mutex
+}
+
+func newInflight(ttl time.Duration) *inflight {
+	return &inflight{
+		orders: make(map[string]time.Time),
+		ttl:    ttl,
+	}
+}
+
+// add отмечает заказ отданным агенту; false -- по заказу уже ждём ответа.
+func (f *inflight) add(orderID string, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.waitingLocked(orderID, now) {
+		return false
+	}
+	f.orders[orderID] = now
+	return true
+}
+
+// waiting -- ждём ли ответа по заказу.
+func (f *inflight) waiting(orderID string, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.waitingLocked(orderID, now)
+}
+
+func (f *inflight) waitingLocked(orderID string, now time.Time) bool {
+	sentAt, ok := f.orders[orderID]
+	return ok && (f.ttl <= 0 || now.Sub(sentAt) < f.ttl)
+}
+
+// done снимает отметку; false -- ответа не ждали: он опоздал или повторный.
+func (f *inflight) done(orderID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.orders[orderID]; !ok {
+		return false
+	}
+	delete(f.orders, orderID)
+	return true
+}
+
+// prune забывает заказы, ответ по которым не пришёл за ttl: запрос считается потерянным.
+func (f *inflight) prune(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for orderID := range f.orders {
+		if !f.waitingLocked(orderID, now) {
+			delete(f.orders, orderID)
+		}
+	}
+}
+
+// len -- число заказов, по которым ещё ждём ответа.
+func (f *inflight) len(now time.Time) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for orderID := range f.orders {
+		if f.waitingLocked(orderID, now) {
+			n++
+		}
+	}
+	return n
+}
@@ -2,124 +2,407 @@ package watcher
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/talx-hub/gopher-bonus/internal/model"
 	"github.com/talx-hub/gopher-bonus/internal/model/order"
 	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
 	"github.com/talx-hub/gopher-bonus/internal/utils/logger"
 )
 
 type orderRepo interface {
-	SelectOrdersForProcessing(context.Context) ([]string, error)
-	UpdateAccrualStatus(context.Context, *order.Order) error
+	ClaimOrdersForProcessing(ctx context.Context, l *order.Lease) ([]string, error)
+	ReleaseLeases(ctx context.Context, owner string, orderIDs []string) (int64, error)
+	UpdateAccrualStatus(ctx context.Context, owner string, o *order.Order) error
+	UpdatePushedAccrualStatus(ctx context.Context, o *order.Order) error
+	RescheduleAccrual(ctx context.Context, owner, orderID string, check order.Check, b *order.Backoff,
+	) (order.Status, error)
+	ClaimPendingHooks(ctx context.Context, limit int32) ([]string, error)
+	CompleteHooks(ctx context.Context, orderID string) error
+}
+
+// ProcessedHook is called after an order has been stored as PROCESSED.
+// A failed hook is called again for the same order, so it must be idempotent.
+type ProcessedHook interface {
+	OnProcessed(ctx context.Context, orderID string) error
+}
+
+type Metrics interface {
+	PointsAccrued(a model.Amount)
+	// OrdersSkipped -- заказы, не отданные агенту: по ним уже ждём ответа accrual
+	OrdersSkipped(n int)
+	// ResponseDropped -- ответ accrual, которого не ждали
+	ResponseDropped()
 }
 
 type Watcher struct {
+	metrics     Metrics
+	tracer      trace.Tracer
 	orderRepo   orderRepo
+	lease       *order.Lease
+	backoff     *order.Backoff
 	ordersCh    chan<- string
 	responsesCh <-chan dto.AccrualInfo
+	hooks       []ProcessedHook
+	inflight    *inflight
 }
 
 func New(
 	orderRepo orderRepo,
+	lease *order.Lease,
+	backoff *order.Backoff,
 	ordersCh chan string,
 	responsesCh chan dto.AccrualInfo,
+	hooks ...ProcessedHook,
 ) *Watcher {
 	return &Watcher{
+		tracer:      otel.Tracer("github.com/talx-hub/gopher-bonus/internal/service/watcher"),
 		orderRepo:   orderRepo,
+		lease:       lease,
+		backoff:     backoff,
 		ordersCh:    ordersCh,
 		responsesCh: responsesCh,
+		hooks:       hooks,
+		inflight:    newInflight(lease.Duration),
 	}
 }
 
+func (w *Watcher) WithMetrics(m Metrics) *Watcher {
+	w.metrics = m
+	return w
+}
+
+// Backlog возвращает число заказов, отданных агенту и ещё не получивших ответа.
+func (w *Watcher) Backlog() int64 {
+	return int64(w.inflight.len(time.Now()))
+}
+
+// Run отдаёт агенту заказы, пока не отменён ctx, и сохраняет ответы, пока агент не закроет responsesCh.
+// Так ответы на запросы, начатые до остановки, тоже сохраняются.
 func (w *Watcher) Run(ctx context.Context) {
 	log := logger.FromContext(ctx).With("service", "watcher")
 	log.LogAttrs(ctx, slog.LevelInfo, "running")
 
 	selectTicker := time.NewTicker(model.WatcherTickTimeout)
 	defer selectTicker.Stop()
+	tickCh := selectTicker.C
+	stopCh := ctx.Done()
+	// ответы сохраняются и после отмены ctx
+	saveCtx := context.WithoutCancel(ctx)
+	// агент закрывает responsesCh, когда заказы уже не читает: тики прерываются
+	tickCtx, stopTicks := context.WithCancel(ctx)
+	defer stopTicks()
+	ticks := &sync.WaitGroup{}
 
 	for {
 		select {
-		case <-ctx.Done():
-			log.LogAttrs(ctx, slog.LevelInfo, "stop signal received, exiting...")
+		case <-stopCh:
+			log.LogAttrs(ctx, slog.LevelInfo, "stop signal received, waiting for accrual responses")
 			selectTicker.Stop()
+			tickCh, stopCh = nil, nil
 
-		case <-selectTicker.C:
+		case <-tickCh:
+			ticks.Add(1)
 			go func() {
-				orders, err := w.orderRepo.SelectOrdersForProcessing(ctx)
-				if err != nil {
-					log.LogAttrs(ctx,
-						slog.LevelError,
-						"failed to select order numbers for accrual",
-						slog.Any(model.KeyLoggerError, err),
-					)
-					return
-				}
-				for _, o := range orders {
-					w.ordersCh <- o
-					err = w.orderRepo.UpdateAccrualStatus(ctx,
-						&order.Order{
-							ID:     o,
-							Status: order.StatusProcessing,
-						})
-					if err != nil {
-						log.LogAttrs(ctx,
-							slog.LevelError,
-							`failed to update order status to "PROCESSING"`,
-							slog.String("order_no", o),
-							slog.Any(model.KeyLoggerError, err),
-						)
-					}
-				}
+				defer ticks.Done()
+				w.tick(tickCtx, log)
 			}()
 
 		case resp, ok := <-w.responsesCh:
 			if !ok {
+				stopTicks()
+				ticks.Wait()
 				close(w.ordersCh)
 				log.LogAttrs(ctx, slog.LevelInfo, "stopped")
 				return
 			}
-			var realStatus order.Status
-			switch dto.AccrualStatus(resp.Status) {
-			case dto.StatusAgentFailed,
-				dto.StatusCalculatorFailed,
-				dto.StatusCalculatorProcessing,
-				dto.StatusCalculatorRegistered:
-				realStatus = order.StatusProcessing
-
-			case dto.StatusCalculatorInvalid:
-				realStatus = order.StatusInvalid
-
-			case dto.StatusCalculatorProcessed,
-				dto.StatusCalculatorNoContent:
-				realStatus = order.StatusProcessed
+			if !w.inflight.done(resp.Order) {
+				w.dropResponse(saveCtx, log, resp)
+				continue
 			}
+			w.handleResponse(saveCtx, log, resp)
+		}
+	}
+}
 
-			a, err := model.FromString(string(resp.Accrual))
-			if err != nil {
-				log.LogAttrs(ctx,
-					slog.LevelError,
-					"failed to convert amount from string",
-					slog.String("amount", string(resp.Accrual)),
-					slog.Any(model.KeyLoggerError, err),
-				)
-			}
+func (w *Watcher) tick(ctx context.Context, log *slog.Logger) {
+	ctx, span := w.tracer.Start(ctx, "watcher.tick")
+	defer span.End()
 
-			o := order.Order{
-				ID:     resp.Order,
-				Status: realStatus,
-				Amount: a,
-			}
-			if err := w.orderRepo.UpdateAccrualStatus(ctx, &o); err != nil {
-				log.LogAttrs(ctx,
-					slog.LevelError,
-					"failed to update accrual info",
-					slog.Any(model.KeyLoggerError, err),
-				)
-			}
+	w.inflight.prune(time.Now())
+	w.retryHooks(ctx, log)
+
+	orders, err := w.orderRepo.ClaimOrdersForProcessing(ctx, w.lease)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to claim order numbers for accrual",
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return
+	}
+	// заказы уже в PROCESSING и за нами до конца аренды
+	skipped := 0
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("orders.claimed", len(orders)),
+			attribute.Int("orders.skipped", skipped),
+		)
+		if skipped == 0 {
+			return
+		}
+		if w.metrics != nil {
+			w.metrics.OrdersSkipped(skipped)
+		}
+		log.LogAttrs(ctx,
+			slog.LevelDebug,
+			"orders still waiting for accrual skipped",
+			slog.Int("skipped", skipped),
+			slog.Int("claimed", len(orders)),
+		)
+	}()
+	for i, o := range orders {
+		if !w.inflight.add(o, time.Now()) {
+			skipped++
+			continue
+		}
+		select {
+		case w.ordersCh <- o:
+		case <-ctx.Done():
+			w.inflight.done(o)
+			w.release(ctx, log, orders[i:])
+			return
+		}
+	}
+}
+
+// release снимает аренду с захваченных, но не отданных агенту заказов:
+// иначе до конца аренды их не опросит ни одна реплика.
+// Заказы, по которым ещё ждём ответа, остаются за нами.
+func (w *Watcher) release(ctx context.Context, log *slog.Logger, orders []string) {
+	now := time.Now()
+	unsent := make([]string, 0, len(orders))
+	for _, o := range orders {
+		if !w.inflight.waiting(o, now) {
+			unsent = append(unsent, o)
+		}
+	}
+	if len(unsent) == 0 {
+		return
+	}
+	// тик прерван остановкой, но аренду снять нужно
+	ctx = context.WithoutCancel(ctx)
+	n, err := w.orderRepo.ReleaseLeases(ctx, w.lease.Owner, unsent)
+	if err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to release leases of unsent orders",
+			slog.Int("orders", len(unsent)),
+			slog.Any(model.KeyLoggerError, err),
+		)
+		return
+	}
+	log.LogAttrs(ctx,
+		slog.LevelDebug,
+		"leases of unsent orders released",
+		slog.Int64("released", n),
+	)
+}
+
+func (w *Watcher) dropResponse(ctx context.Context, log *slog.Logger, resp dto.AccrualInfo) {
+	if w.metrics != nil {
+		w.metrics.ResponseDropped()
+	}
+	log.LogAttrs(ctx,
+		slog.LevelDebug,
+		"late or duplicate accrual response dropped",
+		slog.String("order_no", resp.Order),
+		slog.String("status", resp.Status),
+	)
+}
+
+func (w *Watcher) handleResponse(ctx context.Context, log *slog.Logger, resp dto.AccrualInfo) {
+	if err := w.Apply(ctx, resp); err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to apply accrual info",
+			slog.String("order_no", resp.Order),
+			slog.String("status", resp.Status),
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+}
+
+// Apply сохраняет ответ accrual на опрос этой реплики: финальные статусы записываются,
+// остальные откладывают следующий опрос.
+func (w *Watcher) Apply(ctx context.Context, resp dto.AccrualInfo) error {
+	return w.apply(ctx, resp, func(ctx context.Context, o *order.Order) error {
+		return w.orderRepo.UpdateAccrualStatus(ctx, w.lease.Owner, o)
+	})
+}
+
+// ApplyCallback сохраняет результат, который accrual прислал сам. В отличие от Apply финальный
+// статус записывается и для заказа, который сейчас опрашивает другая реплика: иначе результат потерялся бы.
+func (w *Watcher) ApplyCallback(ctx context.Context, resp dto.AccrualInfo) error {
+	return w.apply(ctx, resp, w.orderRepo.UpdatePushedAccrualStatus)
+}
+
+func (w *Watcher) apply(ctx context.Context, resp dto.AccrualInfo,
+	update func(ctx context.Context, o *order.Order) error,
+) error {
+	var realStatus order.Status
+	switch dto.AccrualStatus(resp.Status) {
+	case dto.StatusCalculatorInvalid:
+		realStatus = order.StatusInvalid
+
+	case dto.StatusCalculatorProcessed:
+		realStatus = order.StatusProcessed
+
+	case dto.StatusAgentFailed,
+		dto.StatusCalculatorFailed,
+		dto.StatusCalculatorProcessing,
+		dto.StatusCalculatorRegistered,
+		dto.StatusCalculatorNoContent:
+		return w.reschedule(ctx, resp)
+
+	default:
+		return fmt.Errorf("%q: %w", resp.Status, serviceerrs.ErrUnknownAccrualStatus)
+	}
+
+	var a model.Amount
+	if resp.Accrual != "" {
+		var err error
+		if a, err = model.FromString(string(resp.Accrual)); err != nil {
+			return fmt.Errorf("failed to convert amount %q: %w", resp.Accrual, err)
+		}
+	}
+
+	o := order.Order{
+		ID:     resp.Order,
+		Status: realStatus,
+		Amount: a,
+	}
+	if err := update(ctx, &o); err != nil {
+		return fmt.Errorf("failed to update accrual info: %w", err)
+	}
+	if realStatus == order.StatusProcessed {
+		if w.metrics != nil {
+			w.metrics.PointsAccrued(a)
+		}
+		w.runHooks(ctx, logger.FromContext(ctx).With("service", "watcher"), o.ID)
+	}
+	return nil
+}
+
+func (w *Watcher) reschedule(ctx context.Context, resp dto.AccrualInfo) error {
+	check := checkOf(resp)
+	status, err := w.orderRepo.RescheduleAccrual(ctx, w.lease.Owner, resp.Order, check, w.backoff)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule accrual check: %w", err)
+	}
+	switch status {
+	case order.StatusReview:
+		logger.FromContext(ctx).LogAttrs(ctx,
+			slog.LevelWarn,
+			"order is unknown to accrual, moved to review",
+			slog.String("service", "watcher"),
+			slog.String("order_no", resp.Order),
+		)
+	case order.StatusDeadLetter:
+		logger.FromContext(ctx).LogAttrs(ctx,
+			slog.LevelWarn,
+			"accrual check keeps failing, order moved to dead letter",
+			slog.String("service", "watcher"),
+			slog.String("order_no", resp.Order),
+			slog.String(model.KeyLoggerError, check.Err),
+		)
+	}
+	return nil
+}
+
+// checkOf описывает результат опроса для RescheduleAccrual.
+// 429, занятость провайдера и остановка агента -- не вина заказа, поэтому в счётчик отказов не идут.
+func checkOf(resp dto.AccrualInfo) order.Check {
+	switch dto.AccrualStatus(resp.Status) {
+	case dto.StatusCalculatorNoContent:
+		return order.Check{Unknown: true}
+	case dto.StatusAgentFailed, dto.StatusCalculatorFailed:
+		var tmrErr *serviceerrs.TooManyRequestsError
+		if errors.As(resp.Err, &tmrErr) ||
+			errors.Is(resp.Err, serviceerrs.ErrProviderBusy) ||
+			errors.Is(resp.Err, serviceerrs.ErrAgentStopped) ||
+			errors.Is(resp.Err, serviceerrs.ErrAgentPaused) {
+			return order.Check{}
+		}
+		if resp.Err == nil {
+			return order.Check{Err: resp.Status}
+		}
+		return order.Check{Err: resp.Err.Error()}
+	default:
+		return order.Check{Answered: true}
+	}
+}
+
+// runHooks записывает начисления по обработанному заказу. Если какое-то не записалось,
+// отметка в БД остаётся, и retryHooks повторит все хуки заказа.
+func (w *Watcher) runHooks(ctx context.Context, log *slog.Logger, orderID string) {
+	failed := false
+	for _, h := range w.hooks {
+		if err := h.OnProcessed(ctx, orderID); err != nil {
+			failed = true
+			log.LogAttrs(ctx,
+				slog.LevelError,
+				"processed order hook failed, will retry",
+				slog.String("order_no", orderID),
+				slog.Any(model.KeyLoggerError, err),
+			)
+		}
+	}
+	if failed {
+		return
+	}
+	if err := w.orderRepo.CompleteHooks(ctx, orderID); err != nil {
+		log.LogAttrs(ctx,
+			slog.LevelError,
+			"failed to complete processed order hooks",
+			slog.String("order_no", orderID),
+			slog.Any(model.KeyLoggerError, err),
+		)
+	}
+}
+
+// retryHooks повторяет хуки обработанных заказов, начисления по которым не записались.
+func (w *Watcher) retryHooks(ctx context.Context, log *slog.Logger) {
+	orders, err := w.orderRepo.ClaimPendingHooks(ctx, w.lease.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.LogAttrs(ctx,
+				slog.LevelError,
+				"failed to claim orders with pending hooks",
+				slog.Any(model.KeyLoggerError, err),
+			)
+		}
+		return
+	}
+	for _, o := range orders {
+		if ctx.Err() != nil {
+			return
 		}
+		w.runHooks(ctx, log, o)
 	}
 }
@@ -1 +1,376 @@
 package watcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/talx-hub/gopher-bonus/internal/model"
+	"github.com/talx-hub/gopher-bonus/internal/model/order"
+	"github.com/talx-hub/gopher-bonus/internal/service/dto"
+	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
+)
+
+type rescheduled struct {
+	orderID string
+	check   order.Check
+}
+
+type fakeRepo struct {
+	claimed     []string
+	released    []string
+	updated     []order.Order
+	rescheduled []rescheduled
+	// pendingHooks -- обработанные заказы, хуки которых ещё не завершены
+	pendingHooks []string
+	// leasedBy -- заказы в чужой активной аренде
+	leasedBy map[string]string
+	mu       sync.Mutex
+}
+
+func (r *fakeRepo) ClaimOrdersForProcessing(context.Context, *order.Lease) ([]string, error) {
+	return r.claimed, nil
+}
+
+func (r *fakeRepo) ReleaseLeases(ctx context.Context, _ string, orderIDs []string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.released = append(r.released, orderIDs...)
+	return int64(len(orderIDs)), nil
+}
+
+func (r *fakeRepo) UpdateAccrualStatus(ctx context.Context, owner string, o *order.Order) error {
+	if leasedBy, ok := r.leasedBy[o.ID]; ok && leasedBy != owner {
+		return serviceerrs.ErrNotFound
+	}
+	return r.UpdatePushedAccrualStatus(ctx, o)
+}
+
+func (r *fakeRepo) UpdatePushedAccrualStatus(ctx context.Context, o *order.Order) error {
+	// как и pgx, отменённый ctx не даёт записать
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updated = append(r.updated, *o)
+	if o.Status == order.StatusProcessed {
+		r.pendingHooks = append(r.pendingHooks, o.ID)
+	}
+	return nil
+}
+
+func (r *fakeRepo) RescheduleAccrual(_ context.Context, _, orderID string, check order.Check, _ *order.Backoff,
+) (order.Status, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rescheduled = append(r.rescheduled, rescheduled{orderID, check})
+	return order.StatusProcessing, nil
+}
+
+func (r *fakeRepo) ClaimPendingHooks(context.Context, int32) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.pendingHooks), nil
+}
+
+func (r *fakeRepo) CompleteHooks(_ context.Context, orderID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pendingHooks = slices.DeleteFunc(r.pendingHooks, func(o string) bool { return o == orderID })
+	return nil
+}
+
+type hookFunc func(ctx context.Context, orderID string) error
+
+func (f hookFunc) OnProcessed(ctx context.Context, orderID string) error { return f(ctx, orderID) }
+
+func TestWatcher_Run_responses(t *testing.T) {
+	repo := &fakeRepo{}
+	var processed []string
+	hook := hookFunc(func(_ context.Context, orderID string) error {
+		processed = append(processed, orderID)
+		return nil
+	})
+
+	ordersCh := make(chan string)
+	responsesCh := make(chan dto.AccrualInfo)
+	w := New(repo, &order.Lease{Owner: "test", Duration: time.Minute, BatchSize: 10},
+		order.NewBackoff(time.Second, time.Minute, time.Hour),
+		ordersCh, responsesCh, hook)
+	done := make(chan struct{})
+	go func() {
+		w.Run(context.Background())
+		close(done)
+	}()
+
+	for _, resp := range []dto.AccrualInfo{
+		{Order: "1", Status: string(dto.StatusCalculatorProcessed), Accrual: "500"},
+		{Order: "2", Status: string(dto.StatusCalculatorInvalid)},
+		{Order: "3", Status: string(dto.StatusCalculatorRegistered)},
+		{Order: "4", Status: string(dto.StatusCalculatorNoContent)},
+		{Order: "5", Status: string(dto.StatusAgentFailed)},
+		{Order: "6", Status: string(dto.StatusCalculatorFailed), Err: errors.New("accrual service error")},
+		{Order: "7", Status: string(dto.StatusCalculatorFailed), Err: &serviceerrs.TooManyRequestsError{}},
+	} {
+		require.True(t, w.inflight.add(resp.Order, time.Now()))
+		responsesCh <- resp
+	}
+	close(responsesCh)
+	<-done
+
+	assert.Equal(t, []order.Order{
+		{ID: "1", Status: order.StatusProcessed, Amount: model.NewAmount(500, 0)},
+		{ID: "2", Status: order.StatusInvalid, Amount: model.NewAmount(0, 0)},
+	}, repo.updated)
+	assert.Equal(t, []rescheduled{
+		{"3", order.Check{Answered: true}},
+		{"4", order.Check{Unknown: true}},
+		{"5", order.Check{Err: string(dto.StatusAgentFailed)}},
+		{"6", order.Check{Err: "accrual service error"}},
+		{"7", order.Check{}},
+	}, repo.rescheduled)
+	assert.Equal(t, []string{"1"}, processed)
+}
+
+func TestWatcher_Apply_errors(t *testing.T) {
+	repo := &fakeRepo{}
+	w := New(repo, &order.Lease{}, order.NewBackoff(time.Second, time.Minute, time.Hour),
+		make(chan string), make(chan dto.AccrualInfo))
+
+	err := w.Apply(context.Background(), dto.AccrualInfo{Order: "1", Status: "DONE"})
+	require.ErrorIs(t, err, serviceerrs.ErrUnknownAccrualStatus)
+
+	err = w.Apply(context.Background(), dto.AccrualInfo{
+		Order: "2", Status: string(dto.StatusCalculatorProcessed), Accrual: "1.234"})
+	require.ErrorIs(t, err, model.ErrFromString)
+
+	assert.Empty(t, repo.updated)
+	assert.Empty(t, repo.rescheduled)
+}
+
+func TestWatcher_ApplyCallback_leasedByOtherReplica(t *testing.T) {
+	repo := &fakeRepo{leasedBy: map[string]string{"1": "other", "2": "other"}}
+	var processed []string
+	hook := hookFunc(func(_ context.Context, orderID string) error {
+		processed = append(processed, orderID)
+		return nil
+	})
+	w := New(repo, &order.Lease{Owner: "test"}, order.NewBackoff(time.Second, time.Minute, time.Hour),
+		make(chan string), make(chan dto.AccrualInfo), hook)
+	ctx := context.Background()
+
+	// ответ на опрос не трогает заказ, который опрашивает другая реплика
+	err := w.Apply(ctx, dto.AccrualInfo{Order: "1", Status: string(dto.StatusCalculatorProcessed), Accrual: "500"})
+	require.ErrorIs(t, err, serviceerrs.ErrNotFound)
+	assert.Empty(t, repo.updated)
+
+	// а результат, присланный accrual, записывается
+	require.NoError(t, w.ApplyCallback(ctx,
+		dto.AccrualInfo{Order: "1", Status: string(dto.StatusCalculatorProcessed), Accrual: "500"}))
+	require.NoError(t, w.ApplyCallback(ctx,
+		dto.AccrualInfo{Order: "2", Status: string(dto.StatusCalculatorInvalid)}))
+	assert.Equal(t, []order.Order{
+		{ID: "1", Status: order.StatusProcessed, Amount: model.NewAmount(500, 0)},
+		{ID: "2", Status: order.StatusInvalid, Amount: model.NewAmount(0, 0)},
+	}, repo.updated)
+	assert.Equal(t, []string{"1"}, processed)
+	assert.Empty(t, repo.pendingHooks)
+}
+
+func TestWatcher_retryHooks(t *testing.T) {
+	repo := &fakeRepo{}
+	var calls []string
+	failing := true
+	hook := hookFunc(func(_ context.Context, orderID string) error {
+		calls = append(calls, orderID)
+		if failing {
+			return errors.New("db is down")
+		}
+		return nil
+	})
+	w := New(repo, &order.Lease{Owner: "test", BatchSize: 10},
+		order.NewBackoff(time.Second, time.Minute, time.Hour),
+		make(chan string), make(chan dto.AccrualInfo), hook)
+	ctx := context.Background()
+	log := slog.Default()
+
+	// статус записан, хотя начисление не удалось: заказ ждёт повтора
+	require.NoError(t, w.Apply(ctx, dto.AccrualInfo{Order: "1", Status: string(dto.StatusCalculatorProcessed)}))
+	assert.Equal(t, []string{"1"}, repo.pendingHooks)
+
+	w.retryHooks(ctx, log)
+	assert.Equal(t, []string{"1"}, repo.pendingHooks)
+
+	failing = false
+	w.retryHooks(ctx, log)
+	assert.Empty(t, repo.pendingHooks)
+	assert.Equal(t, []string{"1", "1", "1"}, calls)
+
+	w.retryHooks(ctx, log)
+	assert.Len(t, calls, 3)
+
+	t.Run("every hook is retried until all succeed", func(t *testing.T) {
+		repo := &fakeRepo{}
+		credits := map[string]int{}
+		credit := func(name string, fail *bool) ProcessedHook {
+			return hookFunc(func(context.Context, string) error {
+				if *fail {
+					return errors.New("db is down")
+				}
+				credits[name]++
+				return nil
+			})
+		}
+		promoFails, referralFails := false, true
+		w := New(repo, &order.Lease{Owner: "test", BatchSize: 10},
+			order.NewBackoff(time.Second, time.Minute, time.Hour),
+			make(chan string), make(chan dto.AccrualInfo),
+			credit("promo", &promoFails), credit("referral", &referralFails))
+
+		require.NoError(t, w.Apply(ctx, dto.AccrualInfo{Order: "1", Status: string(dto.StatusCalculatorProcessed)}))
+		assert.Equal(t, []string{"1"}, repo.pendingHooks)
+		assert.Equal(t, map[string]int{"promo": 1}, credits)
+
+		referralFails = false
+		w.retryHooks(ctx, log)
+		assert.Empty(t, repo.pendingHooks)
+		// повторяются все хуки заказа, поэтому каждый должен быть идемпотентным
+		assert.Equal(t, map[string]int{"promo": 2, "referral": 1}, credits)
+	})
+}
+
+type fakeMetrics struct {
+	skipped int
+	dropped int
+}
+
+func (m *fakeMetrics) PointsAccrued(model.Amount) {}
+func (m *fakeMetrics) OrdersSkipped(n int)        { m.skipped += n }
+func (m *fakeMetrics) ResponseDropped()           { m.dropped++ }
+
+func TestWatcher_tick_inflight(t *testing.T) {
+	repo := &fakeRepo{claimed: []string{"1", "2"}}
+	ordersCh := make(chan string, 10)
+	responsesCh := make(chan dto.AccrualInfo)
+	m := &fakeMetrics{}
+	w := New(repo, &order.Lease{Owner: "test", Duration: time.Minute, BatchSize: 10},
+		order.NewBackoff(time.Second, time.Minute, time.Hour),
+		ordersCh, responsesCh).WithMetrics(m)
+	log := slog.Default()
+	ctx := context.Background()
+
+	w.tick(ctx, log)
+	repo.claimed = []string{"1", "2", "3"}
+	w.tick(ctx, log)
+
+	var sent []string
+	for len(ordersCh) > 0 {
+		sent = append(sent, <-ordersCh)
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, sent)
+	assert.Equal(t, 2, m.skipped)
+	assert.Equal(t, int64(3), w.Backlog())
+
+	// запрос, который не вернулся за время аренды, считается потерянным
+	w.inflight.orders["1"] = time.Now().Add(-time.Hour)
+	assert.Equal(t, int64(2), w.Backlog())
+	assert.True(t, w.inflight.add("1", time.Now()))
+
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	responsesCh <- dto.AccrualInfo{Order: "1", Status: string(dto.StatusCalculatorRegistered)}
+	// ответ на потерянный запрос опоздал
+	responsesCh <- dto.AccrualInfo{Order: "1", Status: string(dto.StatusCalculatorRegistered)}
+	responsesCh <- dto.AccrualInfo{Order: "2", Status: string(dto.StatusCalculatorRegistered)}
+	close(responsesCh)
+	<-done
+
+	assert.Equal(t, 1, m.dropped)
+	assert.Equal(t, int64(1), w.Backlog())
+	assert.Equal(t, []rescheduled{{"1", order.Check{Answered: true}}, {"2", order.Check{Answered: true}}}, repo.rescheduled)
+}
+
+func TestWatcher_tick_prunesLostRequests(t *testing.T) {
+	repo := &fakeRepo{}
+	w := New(repo, &order.Lease{Owner: "test", Duration: time.Minute, BatchSize: 10},
+		order.NewBackoff(time.Second, time.Minute, time.Hour),
+		make(chan string), make(chan dto.AccrualInfo))
+	require.True(t, w.inflight.add("lost", time.Now().Add(-time.Hour)))
+	require.True(t, w.inflight.add("waiting", time.Now()))
+	assert.Equal(t, int64(1), w.Backlog())
+
+	w.tick(context.Background(), slog.Default())
+	assert.Len(t, w.inflight.orders, 1)
+	assert.Contains(t, w.inflight.orders, "waiting")
+	// ответ на потерянный запрос уже не ждём
+	assert.False(t, w.inflight.done("lost"))
+}
+
+func TestWatcher_tick_releasesUnsentOnStop(t *testing.T) {
+	repo := &fakeRepo{claimed: []string{"1", "2", "3", "4"}}
+	ordersCh := make(chan string)
+	w := New(repo, &order.Lease{Owner: "test", Duration: time.Minute, BatchSize: 10},
+		order.NewBackoff(time.Second, time.Minute, time.Hour),
+		ordersCh, make(chan dto.AccrualInfo))
+	// по "3" уже ждём ответа с прошлого тика
+	require.True(t, w.inflight.add("3", time.Now()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ordersCh
+		cancel()
+	}()
+	// "1" уходит агенту, дальше агент заказы не берёт, и тик прерывается остановкой
+	w.tick(ctx, slog.Default())
+
+	assert.Equal(t, []string{"2", "4"}, repo.released)
+	assert.Equal(t, int64(2), w.Backlog())
+}
+
+func TestWatcher_Run_savesResponsesAfterStop(t *testing.T) {
+	repo := &fakeRepo{}
+	ordersCh := make(chan string)
+	responsesCh := make(chan dto.AccrualInfo)
+	w := New(repo, &order.Lease{Owner: "test", Duration: time.Minute, BatchSize: 10},
+		order.NewBackoff(time.Second, time.Minute, time.Hour),
+		ordersCh, responsesCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	// агент доделывает начатый запрос: ответ сохраняется с неотменённым ctx
+	require.True(t, w.inflight.add("1", time.Now()))
+	responsesCh <- dto.AccrualInfo{Order: "1", Status: string(dto.StatusCalculatorProcessed), Accrual: "10"}
+	select {
+	case <-done:
+		require.FailNow(t, "watcher stopped before the agent")
+	default:
+	}
+	close(responsesCh)
+	<-done
+
+	assert.Equal(t, []order.Order{
+		{ID: "1", Status: order.StatusProcessed, Amount: model.NewAmount(10, 0)},
+	}, repo.updated)
+	_, ok := <-ordersCh
+	assert.False(t, ok)
+}
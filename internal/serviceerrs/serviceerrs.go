@@ -9,13 +9,20 @@ import (
 var ErrSemaphoreTimeoutExceeded = errors.New(
 	"semaphore acquire timeout exceeded")
 
-var ErrSemaphoreAcquireTemporaryUnavailable = errors.New(
-	"semaphore acquire temporary unavailable")
-
 var ErrNoContent = errors.New("no content")
 
+var ErrProviderBusy = errors.New("accrual provider is busy")
+
+var ErrAgentStopped = errors.New("accrual agent stopped")
+
+var ErrAgentPaused = errors.New("accrual agent paused")
+
+var ErrAgentNotRunning = errors.New("accrual agent is not running")
+
 var ErrNotFound = errors.New("object not found")
 
+var ErrUnknownAccrualStatus = errors.New("unknown accrual status")
+
 var ErrTokenExpired = errors.New("token expired")
 
 var ErrUnexpected = errors.New("unexpected server error")
@@ -24,10 +31,21 @@ var ErrInsufficientFunds = errors.New("insufficient funds")
 
 type TooManyRequestsError struct {
 	RetryAfter time.Duration
-	RPM        uint64
+	// RPM -- лимит, о котором сообщил accrual, 0 -- неизвестен
+	RPM uint64
 }
 
 func (e *TooManyRequestsError) Error() string {
+	rpm := "unknown"
+	if e.RPM != 0 {
+		rpm = strconv.FormatUint(e.RPM, 10)
+	}
 	return "too many requests. Retry after " + e.RetryAfter.String() + ". " +
-		"requested RPM: " + strconv.FormatUint(e.RPM, 10)
+		"requested RPM: " + rpm
 }
+
+var ErrUnknownTier = errors.New("unknown tier")
+
+var ErrUnknownReferralCode = errors.New("unknown referral code")
+
+var ErrReferralNotAllowed = errors.New("referral not allowed")
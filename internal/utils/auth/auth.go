@@ -7,6 +7,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 
+	"github.com/talx-hub/gopher-bonus/internal/model/user"
 	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
 )
 
@@ -15,15 +16,17 @@ const TokenExpire = 3 * time.Hour
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID string
+	Role   user.Role
 }
 
-func buildJWTString(id string, secret []byte) (string, error) {
+func buildJWTString(id string, role user.Role, secret []byte) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		Claims{
 			RegisteredClaims: jwt.RegisteredClaims{
 				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(TokenExpire)),
 			},
 			UserID: id,
+			Role:   role,
 		},
 	)
 	tokenString, err := token.SignedString(secret)
@@ -33,8 +36,10 @@ func buildJWTString(id string, secret []byte) (string, error) {
 	return tokenString, nil
 }
 
-func Authenticate(id string, secret []byte) (http.Cookie, error) {
-	jwtString, err := buildJWTString(id, secret)
+// Authenticate issues a token for the user. The role in the token is only a hint:
+// staff routes check the stored role, so a revoked role stops working at once.
+func Authenticate(id string, role user.Role, secret []byte) (http.Cookie, error) {
+	jwtString, err := buildJWTString(id, role, secret)
 	if err != nil {
 		return http.Cookie{}, fmt.Errorf("authentication failed: %w", err)
 	}
@@ -62,5 +67,9 @@ func CheckToken(tokenString string, secret []byte) (Claims, error) {
 		return Claims{}, serviceerrs.ErrTokenExpired
 	}
 
+	// токены, выданные до появления ролей
+	if claims.Role == "" {
+		claims.Role = user.RoleUser
+	}
 	return *claims, nil
 }
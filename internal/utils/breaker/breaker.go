@@ -0,0 +1,222 @@
+package breaker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+)
+
+type Config struct {
+	// FailureRate -- доля неудачных вызовов в окне, при которой цепь размыкается;
+	// 0 отключает размыкание
+	FailureRate float64
+	// Window -- сколько последних вызовов учитывается
+	Window int
+	// MinRequests -- меньше вызовов в окне не хватает, чтобы судить о доле отказов
+	MinRequests int
+	// CoolDown -- сколько цепь остаётся разомкнутой до пробных вызовов
+	CoolDown time.Duration
+	// HalfOpenProbes -- сколько пробных вызовов подряд должны пройти, чтобы замкнуть цепь
+	HalfOpenProbes int
+}
+
+// Permit -- разрешение на один вызов, полученное через Acquire.
+type Permit struct {
+	generation uint64
+	probe      bool
+}
+
+type Breaker struct {
+	now           func() time.Time
+	onStateChange func(from, to State)
+	changed       chan struct{}
+	transitions   [][2]State
+	state         State
+	openedAt      time.Time
+	results       []bool
+	cfg           Config
+	generation    uint64
+	next          int
+	failures      int
+	probesIn      int
+	probesOK      int
+	mu            sync.Mutex
+}
+
+func New(cfg Config, onStateChange func(from, to State)) *Breaker {
+	if cfg.Window <= 0 {
+		cfg.Window = 1
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	return &Breaker{
+		now:           time.Now,
+		onStateChange: onStateChange,
+		changed:       make(chan struct{}),
+		state:         StateClosed,
+		results:       make([]bool, 0, cfg.Window),
+		cfg:           cfg,
+	}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.unlock()
+	b.refresh()
+	return b.state
+}
+
+// Acquire блокируется, пока цепь разомкнута или все пробные вызовы заняты.
+// Полученный Permit нужно вернуть через Done или Cancel.
+func (b *Breaker) Acquire(ctx context.Context) (Permit, error) {
+	for {
+		b.mu.Lock()
+		b.refresh()
+		var wait time.Duration
+		switch b.state {
+		case StateClosed:
+			p := Permit{generation: b.generation}
+			b.unlock()
+			return p, nil
+		case StateHalfOpen:
+			if b.probesIn+b.probesOK < b.cfg.HalfOpenProbes {
+				b.probesIn++
+				p := Permit{generation: b.generation, probe: true}
+				b.unlock()
+				return p, nil
+			}
+		case StateOpen:
+			wait = b.openedAt.Add(b.cfg.CoolDown).Sub(b.now())
+		}
+		changed := b.changed
+		b.unlock()
+
+		if err := b.wait(ctx, changed, wait); err != nil {
+			return Permit{}, err
+		}
+	}
+}
+
+func (b *Breaker) wait(ctx context.Context, changed <-chan struct{}, d time.Duration) error {
+	var timeout <-chan time.Time
+	if d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint: wrapcheck // caller checks ctx itself
+	case <-changed:
+	case <-timeout:
+	}
+	return nil
+}
+
+// Done записывает результат вызова. Результаты вызовов,
+// начатых до смены состояния, не учитываются.
+func (b *Breaker) Done(p Permit, failed bool) {
+	b.mu.Lock()
+	defer b.unlock()
+	if p.generation != b.generation {
+		return
+	}
+
+	if p.probe {
+		b.probesIn--
+		if failed {
+			b.setState(StateOpen)
+			return
+		}
+		b.probesOK++
+		if b.probesOK >= b.cfg.HalfOpenProbes {
+			b.setState(StateClosed)
+		} else {
+			b.notify()
+		}
+		return
+	}
+
+	b.record(failed)
+	total := len(b.results)
+	if b.cfg.FailureRate > 0 && total >= b.cfg.MinRequests &&
+		float64(b.failures) >= b.cfg.FailureRate*float64(total) {
+		b.setState(StateOpen)
+	}
+}
+
+// Cancel возвращает Permit, по которому вызова так и не было.
+func (b *Breaker) Cancel(p Permit) {
+	b.mu.Lock()
+	defer b.unlock()
+	if p.generation != b.generation || !p.probe {
+		return
+	}
+	b.probesIn--
+	b.notify()
+}
+
+func (b *Breaker) record(failed bool) {
+	if len(b.results) < b.cfg.Window {
+		b.results = append(b.results, failed)
+	} else {
+		if b.results[b.next] {
+			b.failures--
+		}
+		b.results[b.next] = failed
+		b.next = (b.next + 1) % b.cfg.Window
+	}
+	if failed {
+		b.failures++
+	}
+}
+
+// refresh переводит разомкнутую цепь в half-open по истечении CoolDown.
+func (b *Breaker) refresh() {
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.cfg.CoolDown)) {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) setState(to State) {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.results = b.results[:0]
+	b.next, b.failures, b.probesIn, b.probesOK = 0, 0, 0, 0
+	if to == StateOpen {
+		b.openedAt = b.now()
+	}
+	b.notify()
+	if from != to {
+		b.transitions = append(b.transitions, [2]State{from, to})
+	}
+}
+
+// unlock отпускает мьютекс и только потом сообщает о сменах состояния,
+// чтобы onStateChange мог обращаться к Breaker.
+func (b *Breaker) unlock() {
+	transitions := b.transitions
+	b.transitions = nil
+	b.mu.Unlock()
+	if b.onStateChange == nil {
+		return
+	}
+	for _, t := range transitions {
+		b.onStateChange(t[0], t[1])
+	}
+}
+
+func (b *Breaker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
@@ -0,0 +1,167 @@
+package breaker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	t  time.Time
+	mu sync.Mutex
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func newTestBreaker(cfg Config) (*Breaker, *fakeClock, *[][2]State) {
+	var transitions [][2]State
+	var mu sync.Mutex
+	b := New(cfg, func(from, to State) {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, [2]State{from, to})
+	})
+	clock := &fakeClock{t: time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)}
+	b.now = clock.now
+	return b, clock, &transitions
+}
+
+func call(t *testing.T, b *Breaker, failed bool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p, err := b.Acquire(ctx)
+	require.NoError(t, err)
+	b.Done(p, failed)
+}
+
+func TestBreaker_lifecycle(t *testing.T) {
+	b, clock, transitions := newTestBreaker(Config{
+		FailureRate:    0.5,
+		Window:         4,
+		MinRequests:    4,
+		CoolDown:       time.Minute,
+		HalfOpenProbes: 2,
+	})
+
+	// мало вызовов -- не размыкаемся даже при сплошных отказах
+	call(t, b, true)
+	call(t, b, true)
+	call(t, b, false)
+	assert.Equal(t, StateClosed, b.State())
+
+	call(t, b, false)
+	assert.Equal(t, StateOpen, b.State(), "2 of 4 failed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	_, err := b.Acquire(ctx)
+	cancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded, "open breaker blocks callers")
+
+	clock.advance(time.Minute)
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	first, err := b.Acquire(context.Background())
+	require.NoError(t, err)
+	second, err := b.Acquire(context.Background())
+	require.NoError(t, err)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	_, err = b.Acquire(ctx)
+	cancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded, "only HalfOpenProbes calls are let through")
+
+	b.Done(first, false)
+	assert.Equal(t, StateHalfOpen, b.State())
+	b.Done(second, true)
+	assert.Equal(t, StateOpen, b.State(), "failed probe opens the breaker again")
+
+	clock.advance(time.Minute)
+	call(t, b, false)
+	call(t, b, false)
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Equal(t, [][2]State{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateClosed},
+	}, *transitions)
+}
+
+func TestBreaker_slidingWindow(t *testing.T) {
+	b, _, _ := newTestBreaker(Config{FailureRate: 0.75, Window: 4, MinRequests: 4, CoolDown: time.Minute})
+
+	for _, failed := range []bool{true, true, false, false, true, false, false} {
+		call(t, b, failed)
+		assert.Equal(t, StateClosed, b.State())
+	}
+	call(t, b, true)
+	call(t, b, true)
+	assert.Equal(t, StateClosed, b.State(), "2 of last 4 failed")
+	call(t, b, true)
+	assert.Equal(t, StateOpen, b.State(), "3 of last 4 failed")
+}
+
+func TestBreaker_staleResultsIgnored(t *testing.T) {
+	b, clock, _ := newTestBreaker(Config{FailureRate: 0.5, Window: 2, MinRequests: 2, CoolDown: time.Minute})
+
+	slow, err := b.Acquire(context.Background())
+	require.NoError(t, err)
+	call(t, b, true)
+	call(t, b, true)
+	require.Equal(t, StateOpen, b.State())
+
+	clock.advance(time.Minute)
+	probe, err := b.Acquire(context.Background())
+	require.NoError(t, err)
+	// ответ на вызов, начатый до размыкания, не влияет на пробу
+	b.Done(slow, true)
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	b.Cancel(probe)
+	call(t, b, false)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_waitersWakeUp(t *testing.T) {
+	b, clock, _ := newTestBreaker(Config{FailureRate: 1, Window: 1, MinRequests: 1, CoolDown: time.Minute})
+	call(t, b, true)
+	require.Equal(t, StateOpen, b.State())
+
+	acquired := make(chan struct{})
+	go func() {
+		p, err := b.Acquire(context.Background())
+		assert.NoError(t, err)
+		b.Done(p, false)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired while open")
+	case <-time.After(20 * time.Millisecond):
+	}
+	clock.advance(time.Minute)
+	_ = b.State() // переход в half-open будит ожидающих
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not woken up")
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"os"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/talx-hub/gopher-bonus/internal/model"
 )
 
@@ -31,3 +33,31 @@ func FromContext(ctx context.Context) *slog.Logger {
 	}
 	return slog.Default()
 }
+
+// traceHandler дописывает в запись trace_id и span_id текущего спана,
+// чтобы строки лога находились по трейсу.
+type traceHandler struct {
+	slog.Handler
+}
+
+func WithTraceIDs(h slog.Handler) slog.Handler {
+	return &traceHandler{Handler: h}
+}
+
+func (h *traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String(model.KeyLoggerTraceID, sc.TraceID().String()),
+			slog.String(model.KeyLoggerSpanID, sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r) //nolint: wrapcheck // error from wrapped handler
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name)}
+}
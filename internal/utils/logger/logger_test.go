@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTraceIDs(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := slog.New(WithTraceIDs(slog.NewTextHandler(out, nil))).With("service", "watcher")
+
+	log.InfoContext(context.Background(), "no span")
+	assert.NotContains(t, out.String(), "trace_id")
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	log.InfoContext(trace.ContextWithSpanContext(context.Background(), sc), "in span")
+	assert.Contains(t, out.String(), "service=watcher trace_id="+sc.TraceID().String())
+	assert.Contains(t, out.String(), "span_id="+sc.SpanID().String())
+}
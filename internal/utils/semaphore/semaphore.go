@@ -1,66 +1,131 @@
 package semaphore
 
 import (
+	"container/list"
+	"context"
 	"sync"
-	"time"
-
-	"github.com/talx-hub/gopher-bonus/internal/serviceerrs"
 )
 
+// Semaphore -- взвешенный семафор с изменяемым на ходу лимитом.
+// Ожидающие получают разрешения строго в порядке очереди: запрос большего веса
+// не обгоняется меньшими, пришедшими позже.
 type Semaphore struct {
-	semaCh       chan struct{}
-	cond         *sync.Cond
-	m            sync.Mutex
-	activeCount  uint64
-	blockAcquire bool
+	waiters list.List
+	limit   uint64
+	held    uint64
+	mu      sync.Mutex
 }
 
-func New(maxRequestCount uint64) *Semaphore {
-	s := &Semaphore{
-		semaCh: make(chan struct{}, maxRequestCount),
-	}
-	s.cond = sync.NewCond(&s.m)
-	return s
+type waiter struct {
+	ready chan struct{}
+	n     uint64
+}
+
+func New(limit uint64) *Semaphore {
+	return &Semaphore{limit: limit}
 }
 
-func (s *Semaphore) AcquireWithTimeout(timeout time.Duration) error {
-	timer := time.NewTimer(timeout)
-	defer timer.Stop()
+// Acquire занимает одно разрешение, ожидая его не дольше ctx.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	return s.AcquireN(ctx, 1)
+}
 
-	s.m.Lock()
-	if s.blockAcquire {
-		s.m.Unlock()
-		return serviceerrs.ErrSemaphoreAcquireTemporaryUnavailable
+// AcquireN занимает n разрешений разом, ожидая их не дольше ctx.
+// При отмене ctx разрешения не заняты и возвращается ctx.Err().
+// Запрос больше лимита ждёт, пока лимит не увеличат.
+func (s *Semaphore) AcquireN(ctx context.Context, n uint64) error {
+	s.mu.Lock()
+	if s.waiters.Len() == 0 && s.held+n <= s.limit {
+		s.held += n
+		s.mu.Unlock()
+		return nil
 	}
-	s.m.Unlock()
+	if err := ctx.Err(); err != nil {
+		s.mu.Unlock()
+		return err //nolint: wrapcheck // caller's ctx error
+	}
+	w := waiter{ready: make(chan struct{}), n: n}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
 
 	select {
-	case <-timer.C:
-		return serviceerrs.ErrSemaphoreTimeoutExceeded
-	case s.semaCh <- struct{}{}:
+	case <-w.ready:
 		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// разрешения выданы одновременно с отменой: возвращаем их
+			s.held -= n
+		default:
+			s.waiters.Remove(elem)
+		}
+		// ушедший первым мог держать очередь за собой
+		s.notify()
+		s.mu.Unlock()
+		return ctx.Err() //nolint: wrapcheck // caller's ctx error
 	}
 }
 
+// Release возвращает одно разрешение.
 func (s *Semaphore) Release() {
-	s.m.Lock()
-	defer s.m.Unlock()
+	s.ReleaseN(1)
+}
 
-	<-s.semaCh
-	s.activeCount--
-	if s.activeCount == 0 {
-		s.cond.Broadcast()
+// ReleaseN возвращает n разрешений. Вернуть больше, чем занято, -- ошибка программы.
+func (s *Semaphore) ReleaseN(n uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > s.held {
+		panic("semaphore: released more than held")
 	}
+	s.held -= n
+	s.notify()
 }
 
-func (s *Semaphore) ChangeMaxRequests(newMaxRequests uint64) {
-	s.m.Lock()
-	defer s.m.Unlock()
+// ChangeMaxRequests меняет лимит, не дожидаясь возврата разрешений.
+// Если занято больше нового лимита, новые разрешения выдаются, только когда занятых станет меньше.
+func (s *Semaphore) ChangeMaxRequests(limit uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+	s.notify()
+}
+
+// Limit возвращает текущий лимит.
+func (s *Semaphore) Limit() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limit
+}
+
+// Held возвращает число занятых разрешений; после уменьшения лимита оно может быть больше Limit.
+func (s *Semaphore) Held() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.held
+}
+
+// Waiting возвращает число ожидающих Acquire.
+func (s *Semaphore) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiters.Len()
+}
 
-	s.blockAcquire = true
-	for s.activeCount > 0 {
-		s.cond.Wait()
+// notify выдаёт разрешения ожидающим по порядку, пока хватает лимита. Вызывается под s.mu.
+func (s *Semaphore) notify() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w, _ := front.Value.(waiter)
+		if s.held+w.n > s.limit {
+			return
+		}
+		s.held += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
 	}
-	s.semaCh = make(chan struct{}, newMaxRequests)
-	s.blockAcquire = false
 }
@@ -0,0 +1,230 @@
+package semaphore
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitFor ждёт, пока в очереди не окажется n ожидающих.
+func waitFor(t *testing.T, s *Semaphore, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return s.Waiting() == n },
+		time.Second, time.Millisecond)
+}
+
+// acquireAsync занимает разрешения в отдельной горутине и сообщает в канал о результате.
+func acquireAsync(ctx context.Context, s *Semaphore, n uint64) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- s.AcquireN(ctx, n)
+	}()
+	return done
+}
+
+func TestSemaphore_limit(t *testing.T) {
+	s := New(2)
+	ctx := context.Background()
+	require.NoError(t, s.Acquire(ctx))
+	require.NoError(t, s.Acquire(ctx))
+	assert.Equal(t, uint64(2), s.Held())
+
+	third := acquireAsync(ctx, s, 1)
+	waitFor(t, s, 1)
+	select {
+	case <-third:
+		t.Fatal("acquired above the limit")
+	default:
+	}
+
+	s.Release()
+	require.NoError(t, <-third)
+	assert.Equal(t, uint64(2), s.Held())
+	assert.Equal(t, 0, s.Waiting())
+
+	s.Release()
+	s.Release()
+	assert.Equal(t, uint64(0), s.Held())
+	assert.Panics(t, s.Release)
+}
+
+func TestSemaphore_Acquire_contextCancelled(t *testing.T) {
+	s := New(1)
+	require.NoError(t, s.Acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := s.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, uint64(1), s.Held())
+	assert.Equal(t, 0, s.Waiting())
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	require.ErrorIs(t, s.Acquire(cancelled), context.Canceled)
+
+	// свободное разрешение выдаётся и с отменённым контекстом, как в x/sync/semaphore
+	s.Release()
+	require.NoError(t, s.Acquire(cancelled))
+}
+
+func TestSemaphore_fifo(t *testing.T) {
+	s := New(3)
+	ctx := context.Background()
+	require.NoError(t, s.AcquireN(ctx, 3))
+
+	big := acquireAsync(ctx, s, 2)
+	waitFor(t, s, 1)
+	small := acquireAsync(ctx, s, 1)
+	waitFor(t, s, 2)
+
+	// одного разрешения хватило бы маленькому запросу, но он стоит за большим
+	s.Release()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 2, s.Waiting())
+
+	s.Release()
+	require.NoError(t, <-big)
+	s.Release()
+	require.NoError(t, <-small)
+	assert.Equal(t, uint64(3), s.Held())
+}
+
+func TestSemaphore_fifo_headCancelled(t *testing.T) {
+	s := New(2)
+	ctx := context.Background()
+	require.NoError(t, s.Acquire(ctx))
+
+	headCtx, cancel := context.WithCancel(ctx)
+	head := acquireAsync(headCtx, s, 2)
+	waitFor(t, s, 1)
+	next := acquireAsync(ctx, s, 1)
+	waitFor(t, s, 2)
+
+	// ушедший первый больше не держит очередь
+	cancel()
+	require.ErrorIs(t, <-head, context.Canceled)
+	require.NoError(t, <-next)
+	assert.Equal(t, uint64(2), s.Held())
+}
+
+func TestSemaphore_ChangeMaxRequests(t *testing.T) {
+	s := New(4)
+	ctx := context.Background()
+	require.NoError(t, s.AcquireN(ctx, 4))
+
+	// уменьшение не ждёт занятых разрешений
+	s.ChangeMaxRequests(2)
+	assert.Equal(t, uint64(2), s.Limit())
+	assert.Equal(t, uint64(4), s.Held())
+
+	waiting := acquireAsync(ctx, s, 1)
+	waitFor(t, s, 1)
+	s.Release()
+	s.Release()
+	time.Sleep(10 * time.Millisecond)
+	// занято ровно по новому лимиту: ждущий ещё не должен пройти
+	assert.Equal(t, 1, s.Waiting())
+
+	s.Release()
+	require.NoError(t, <-waiting)
+	assert.Equal(t, uint64(2), s.Held())
+
+	// увеличение сразу будит ожидающих
+	first := acquireAsync(ctx, s, 1)
+	waitFor(t, s, 1)
+	second := acquireAsync(ctx, s, 1)
+	waitFor(t, s, 2)
+	s.ChangeMaxRequests(4)
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+	assert.Equal(t, uint64(4), s.Held())
+	assert.Equal(t, 0, s.Waiting())
+}
+
+func TestSemaphore_ChangeMaxRequests_toZero(t *testing.T) {
+	s := New(1)
+	s.ChangeMaxRequests(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, s.Acquire(ctx), context.DeadlineExceeded)
+
+	waiting := acquireAsync(context.Background(), s, 1)
+	waitFor(t, s, 1)
+	s.ChangeMaxRequests(1)
+	require.NoError(t, <-waiting)
+}
+
+// TestSemaphore_stress гоняет захваты, отмены и смену лимита параллельно;
+// смысл имеет с -race.
+func TestSemaphore_stress(t *testing.T) {
+	const (
+		workers    = 64
+		iterations = 300
+		maxLimit   = 8
+	)
+	s := New(maxLimit)
+	var inside atomic.Int64
+	var violations atomic.Int64
+
+	stop := make(chan struct{})
+	resized := make(chan struct{})
+	go func() {
+		defer close(resized)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			limit := uint64(i%maxLimit + 1)
+			s.ChangeMaxRequests(limit)
+			// после уменьшения лишние разрешения только возвращаются
+			for s.Held() > limit {
+				time.Sleep(10 * time.Microsecond)
+			}
+			for range 10 {
+				if uint64(inside.Load()) > limit || s.Held() > limit {
+					violations.Add(1)
+				}
+				time.Sleep(10 * time.Microsecond)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				ctx, cancel := context.WithTimeout(context.Background(),
+					time.Duration((w+i)%5)*100*time.Microsecond)
+				err := s.Acquire(ctx)
+				cancel()
+				if err != nil {
+					continue
+				}
+				if inside.Add(1) > maxLimit {
+					violations.Add(1)
+				}
+				time.Sleep(10 * time.Microsecond)
+				inside.Add(-1)
+				s.Release()
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-resized
+
+	assert.Zero(t, violations.Load())
+	assert.Equal(t, uint64(0), s.Held())
+	assert.Equal(t, 0, s.Waiting())
+}